@@ -0,0 +1,241 @@
+package archive
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ProgressFunc reports the entry being processed and the bytes handled so
+// far. total is -1 when the size is not known up front.
+type ProgressFunc func(name string, done, total int64)
+
+var (
+	ErrUnsafePath   = errors.New("archive entry escapes destination")
+	ErrSizeExceeded = errors.New("archive exceeds size limit")
+	ErrTooManyFiles = errors.New("archive exceeds file limit")
+)
+
+var (
+	defaultArchiver  = NewArchiver()
+	defaultExtractor = NewExtractor()
+)
+
+// Archiver creates archives from a directory tree.
+type Archiver struct {
+	progress ProgressFunc
+}
+
+func NewArchiver() *Archiver {
+	return &Archiver{}
+}
+
+func (a *Archiver) Progress(progress ProgressFunc) *Archiver {
+	a.progress = progress
+	return a
+}
+
+// Extractor unpacks archives into a directory, refusing entries that would
+// land outside it and stopping once the size or file limits are exceeded.
+// Symlinks and other special entries are skipped.
+type Extractor struct {
+	maxSize  int64
+	maxFiles int
+	progress ProgressFunc
+}
+
+func NewExtractor() *Extractor {
+	return &Extractor{
+		maxSize:  1 << 30,
+		maxFiles: 10000,
+	}
+}
+
+// MaxSize limits the total uncompressed bytes extracted; zero disables it.
+func (e *Extractor) MaxSize(bytes int64) *Extractor {
+	e.maxSize = bytes
+	return e
+}
+
+// MaxFiles limits the number of entries extracted; zero disables it.
+func (e *Extractor) MaxFiles(files int) *Extractor {
+	e.maxFiles = files
+	return e
+}
+
+func (e *Extractor) Progress(progress ProgressFunc) *Extractor {
+	e.progress = progress
+	return e
+}
+
+func Zip(dir, dest string) error {
+	return defaultArchiver.Zip(dir, dest)
+}
+
+func Unzip(src, dir string) error {
+	return defaultExtractor.Unzip(src, dir)
+}
+
+func TarGz(dir, dest string) error {
+	return defaultArchiver.TarGz(dir, dest)
+}
+
+func UntarGz(src, dir string) error {
+	return defaultExtractor.UntarGz(src, dir)
+}
+
+type entry struct {
+	path string
+	name string
+	info fs.FileInfo
+}
+
+// walk lists the regular files and directories under dir with slash
+// separated names relative to it, and their total size.
+func walk(dir string) ([]entry, int64, error) {
+	var entries []entry
+	var total int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == dir {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return errors.Wrap(err, "entry.Info")
+		}
+		if !info.IsDir() && !info.Mode().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return errors.Wrap(err, "filepath.Rel")
+		}
+		entries = append(entries, entry{path: path, name: filepath.ToSlash(rel), info: info})
+		if info.Mode().IsRegular() {
+			total += info.Size()
+		}
+		return nil
+	})
+	return entries, total, err
+}
+
+// createFile opens dest and removes it again if build fails.
+func createFile(dest string, build func(w io.Writer) error) error {
+	f, err := os.Create(dest)
+	if err != nil {
+		return errors.Wrap(err, "os.Create")
+	}
+	if err := build(f); err != nil {
+		f.Close()
+		os.Remove(dest)
+		return err
+	}
+	return errors.Wrap(f.Close(), "file.Close")
+}
+
+// safeJoin resolves name inside dir, rejecting absolute paths and any
+// ".." that would escape it.
+func safeJoin(dir, name string) (string, error) {
+	if filepath.IsAbs(name) || strings.HasPrefix(name, "/") || strings.Contains(name, `\`) {
+		return "", errors.Wrapf(ErrUnsafePath, "%q", name)
+	}
+	target := filepath.Join(dir, filepath.FromSlash(name))
+	rel, err := filepath.Rel(dir, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.Wrapf(ErrUnsafePath, "%q", name)
+	}
+	return target, nil
+}
+
+// counter enforces the extraction limits and drives progress reporting.
+type counter struct {
+	e     *Extractor
+	total int64
+	done  int64
+	files int
+}
+
+func (c *counter) addFile() error {
+	c.files++
+	if c.e.maxFiles > 0 && c.files > c.e.maxFiles {
+		return ErrTooManyFiles
+	}
+	return nil
+}
+
+// extract copies r into target, never writing more than the remaining
+// size budget.
+func (c *counter) extract(name, target string, mode fs.FileMode, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return errors.Wrap(err, "os.MkdirAll")
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm()|0o200)
+	if err != nil {
+		return errors.Wrap(err, "os.OpenFile")
+	}
+	defer f.Close()
+
+	if c.e.maxSize > 0 {
+		r = io.LimitReader(r, c.e.maxSize-c.done+1)
+	}
+	buf := make([]byte, 32*1024)
+	for {
+		n, readErr := r.Read(buf)
+		if n > 0 {
+			c.done += int64(n)
+			if c.e.maxSize > 0 && c.done > c.e.maxSize {
+				return ErrSizeExceeded
+			}
+			if _, err := f.Write(buf[:n]); err != nil {
+				return errors.Wrap(err, "file.Write")
+			}
+			if c.e.progress != nil {
+				c.e.progress(name, c.done, c.total)
+			}
+		}
+		if readErr == io.EOF {
+			return errors.Wrap(f.Close(), "file.Close")
+		}
+		if readErr != nil {
+			return errors.Wrap(readErr, "archive.Read")
+		}
+	}
+}
+
+// copyProgress copies a source file into an archive writer.
+func copyProgress(w io.Writer, e entry, done *int64, total int64, progress ProgressFunc) error {
+	f, err := os.Open(e.path)
+	if err != nil {
+		return errors.Wrap(err, "os.Open")
+	}
+	defer f.Close()
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, readErr := f.Read(buf)
+		if n > 0 {
+			if _, err := w.Write(buf[:n]); err != nil {
+				return errors.Wrap(err, "archive.Write")
+			}
+			*done += int64(n)
+			if progress != nil {
+				progress(e.name, *done, total)
+			}
+		}
+		if readErr == io.EOF {
+			return nil
+		}
+		if readErr != nil {
+			return errors.Wrap(readErr, "file.Read")
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package archive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// TarGz writes the contents of dir to dest as a gzip-compressed tarball,
+// with entry names relative to dir.
+func (a *Archiver) TarGz(dir, dest string) error {
+	entries, total, err := walk(dir)
+	if err != nil {
+		return err
+	}
+
+	return createFile(dest, func(w io.Writer) error {
+		gw := gzip.NewWriter(w)
+		tw := tar.NewWriter(gw)
+		var done int64
+		for _, e := range entries {
+			header, err := tar.FileInfoHeader(e.info, "")
+			if err != nil {
+				return errors.Wrap(err, "tar.FileInfoHeader")
+			}
+			header.Name = e.name
+			if e.info.IsDir() {
+				header.Name += "/"
+			}
+
+			if err := tw.WriteHeader(header); err != nil {
+				return errors.Wrap(err, "tar.WriteHeader")
+			}
+			if e.info.IsDir() {
+				continue
+			}
+			if err := copyProgress(tw, e, &done, total, a.progress); err != nil {
+				return err
+			}
+		}
+		if err := tw.Close(); err != nil {
+			return errors.Wrap(err, "tar.Close")
+		}
+		return errors.Wrap(gw.Close(), "gzip.Close")
+	})
+}
+
+// UntarGz extracts a gzip-compressed tarball. The total passed to the
+// progress callback is -1 because tar entries are streamed.
+func (e *Extractor) UntarGz(src, dir string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return errors.Wrap(err, "os.Open")
+	}
+	defer f.Close()
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return errors.Wrap(err, "gzip.NewReader")
+	}
+	defer gr.Close()
+
+	tr := tar.NewReader(gr)
+	c := &counter{e: e, total: -1}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "tar.Next")
+		}
+
+		target, err := safeJoin(dir, header.Name)
+		if err != nil {
+			return err
+		}
+		if err := c.addFile(); err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return errors.Wrap(err, "os.MkdirAll")
+			}
+		case tar.TypeReg:
+			if err := c.extract(header.Name, target, header.FileInfo().Mode(), tr); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package archive
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// Zip writes the contents of dir to dest, with entry names relative to dir.
+func (a *Archiver) Zip(dir, dest string) error {
+	entries, total, err := walk(dir)
+	if err != nil {
+		return err
+	}
+
+	return createFile(dest, func(w io.Writer) error {
+		zw := zip.NewWriter(w)
+		var done int64
+		for _, e := range entries {
+			header, err := zip.FileInfoHeader(e.info)
+			if err != nil {
+				return errors.Wrap(err, "zip.FileInfoHeader")
+			}
+			header.Name = e.name
+			if e.info.IsDir() {
+				header.Name += "/"
+			} else {
+				header.Method = zip.Deflate
+			}
+
+			fw, err := zw.CreateHeader(header)
+			if err != nil {
+				return errors.Wrap(err, "zip.CreateHeader")
+			}
+			if e.info.IsDir() {
+				continue
+			}
+			if err := copyProgress(fw, e, &done, total, a.progress); err != nil {
+				return err
+			}
+		}
+		return errors.Wrap(zw.Close(), "zip.Close")
+	})
+}
+
+func (e *Extractor) Unzip(src, dir string) error {
+	zr, err := zip.OpenReader(src)
+	if err != nil {
+		return errors.Wrap(err, "zip.OpenReader")
+	}
+	defer zr.Close()
+
+	c := &counter{e: e}
+	for _, f := range zr.File {
+		c.total += int64(f.UncompressedSize64)
+	}
+
+	for _, f := range zr.File {
+		target, err := safeJoin(dir, f.Name)
+		if err != nil {
+			return err
+		}
+		if err := c.addFile(); err != nil {
+			return err
+		}
+
+		mode := f.Mode()
+		switch {
+		case mode.IsDir():
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return errors.Wrap(err, "os.MkdirAll")
+			}
+			continue
+		case !mode.IsRegular():
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return errors.Wrap(err, "zip.Open")
+		}
+		err = c.extract(f.Name, target, mode, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
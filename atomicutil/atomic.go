@@ -0,0 +1,146 @@
+package atomicutil
+
+import (
+	"strconv"
+	"sync/atomic"
+)
+
+// Int64 is an int64 accessed atomically. The zero value is 0.
+type Int64 struct {
+	v int64
+}
+
+func NewInt64(v int64) *Int64 {
+	return &Int64{v: v}
+}
+
+func (i *Int64) Load() int64 {
+	return atomic.LoadInt64(&i.v)
+}
+
+func (i *Int64) Store(v int64) {
+	atomic.StoreInt64(&i.v, v)
+}
+
+// Add adds delta and returns the new value.
+func (i *Int64) Add(delta int64) int64 {
+	return atomic.AddInt64(&i.v, delta)
+}
+
+func (i *Int64) Inc() int64 {
+	return i.Add(1)
+}
+
+func (i *Int64) Dec() int64 {
+	return i.Add(-1)
+}
+
+// Swap stores v and returns the previous value.
+func (i *Int64) Swap(v int64) int64 {
+	return atomic.SwapInt64(&i.v, v)
+}
+
+func (i *Int64) CompareAndSwap(old, new int64) bool {
+	return atomic.CompareAndSwapInt64(&i.v, old, new)
+}
+
+func (i *Int64) String() string {
+	return strconv.FormatInt(i.Load(), 10)
+}
+
+func (i *Int64) MarshalJSON() ([]byte, error) {
+	return []byte(i.String()), nil
+}
+
+// Bool is a bool accessed atomically. The zero value is false.
+type Bool struct {
+	v uint32
+}
+
+func NewBool(v bool) *Bool {
+	b := &Bool{}
+	b.Store(v)
+	return b
+}
+
+func boolToUint(v bool) uint32 {
+	if v {
+		return 1
+	}
+	return 0
+}
+
+func (b *Bool) Load() bool {
+	return atomic.LoadUint32(&b.v) == 1
+}
+
+func (b *Bool) Store(v bool) {
+	atomic.StoreUint32(&b.v, boolToUint(v))
+}
+
+// Swap stores v and returns the previous value.
+func (b *Bool) Swap(v bool) bool {
+	return atomic.SwapUint32(&b.v, boolToUint(v)) == 1
+}
+
+func (b *Bool) CompareAndSwap(old, new bool) bool {
+	return atomic.CompareAndSwapUint32(&b.v, boolToUint(old), boolToUint(new))
+}
+
+// Toggle flips the value and returns the new one.
+func (b *Bool) Toggle() bool {
+	for {
+		old := b.Load()
+		if b.CompareAndSwap(old, !old) {
+			return !old
+		}
+	}
+}
+
+func (b *Bool) String() string {
+	return strconv.FormatBool(b.Load())
+}
+
+func (b *Bool) MarshalJSON() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// Value holds a T accessed atomically. Unlike atomic.Value it accepts nil
+// and values of differing concrete types when T is an interface.
+type Value[T any] struct {
+	v atomic.Value
+}
+
+type box[T any] struct {
+	v T
+}
+
+func NewValue[T any](v T) *Value[T] {
+	val := &Value[T]{}
+	val.Store(v)
+	return val
+}
+
+// Load returns the stored value, or T's zero value if none was stored.
+func (v *Value[T]) Load() T {
+	b, ok := v.v.Load().(box[T])
+	if !ok {
+		var zero T
+		return zero
+	}
+	return b.v
+}
+
+func (v *Value[T]) Store(value T) {
+	v.v.Store(box[T]{v: value})
+}
+
+// Swap stores value and returns the previous one.
+func (v *Value[T]) Swap(value T) T {
+	b, ok := v.v.Swap(box[T]{v: value}).(box[T])
+	if !ok {
+		var zero T
+		return zero
+	}
+	return b.v
+}
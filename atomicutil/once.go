@@ -0,0 +1,101 @@
+package atomicutil
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+// Lazy computes a value on first use and caches both the value and any
+// error, so concurrent callers share a single initialization.
+type Lazy[T any] struct {
+	once ResettableOnce
+	init func() (T, error)
+
+	mu    sync.Mutex // guards value and err against Reset
+	value T
+	err   error
+}
+
+func NewLazy[T any](init func() (T, error)) *Lazy[T] {
+	return &Lazy[T]{init: init}
+}
+
+// Get returns the cached result, initializing it first if needed. A
+// panic in init is returned as an error.
+func (l *Lazy[T]) Get() (T, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.once.Do(func() error {
+		l.value, l.err = l.init()
+		return nil
+	}); err != nil {
+		var zero T
+		return zero, err
+	}
+	return l.value, l.err
+}
+
+// MustGet is Get for values whose initialization cannot fail in practice.
+func (l *Lazy[T]) MustGet() T {
+	v, err := l.Get()
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Reset discards the cached result; the next Get initializes again.
+func (l *Lazy[T]) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var zero T
+	l.value, l.err = zero, nil
+	l.once.Reset()
+}
+
+// ResettableOnce runs a function once, like sync.Once, but can be reset
+// and reports the function's error. A panic in the function is returned
+// as an error and, like any error, leaves the once done until Reset.
+type ResettableOnce struct {
+	mu   sync.Mutex
+	done uint32
+	err  error
+}
+
+func (o *ResettableOnce) Do(fn func() error) error {
+	if atomic.LoadUint32(&o.done) == 1 {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		return o.err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.done == 0 {
+		o.err = protect(fn)
+		atomic.StoreUint32(&o.done, 1)
+	}
+	return o.err
+}
+
+func (o *ResettableOnce) Done() bool {
+	return atomic.LoadUint32(&o.done) == 1
+}
+
+func (o *ResettableOnce) Reset() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.err = nil
+	atomic.StoreUint32(&o.done, 0)
+}
+
+func protect(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("panic: %v", r)
+		}
+	}()
+	return fn()
+}
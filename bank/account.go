@@ -0,0 +1,199 @@
+package bank
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+var ErrUnknownBank = errors.New("bank: no rule for bank")
+
+// Rule validates agency and account numbers of one bank. Each check
+// receives digits only, already padded to the bank's length, and returns
+// the expected check digit; a nil check means the bank has no digit for
+// that part.
+type Rule struct {
+	Name          string
+	AgencyLength  int
+	AccountLength int
+	AgencyDigit   func(agency string) string
+	AccountDigit  func(agency, account string) string
+}
+
+var (
+	rulesMu sync.RWMutex
+	rules   = map[string]Rule{}
+)
+
+// RegisterBank adds or replaces the rule for a COMPE bank code such as
+// "001".
+func RegisterBank(code string, rule Rule) {
+	rulesMu.Lock()
+	rules[code] = rule
+	rulesMu.Unlock()
+}
+
+func lookupRule(code string) (Rule, bool) {
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+	rule, ok := rules[code]
+	return rule, ok
+}
+
+// Banks returns the codes with a registered rule.
+func Banks() []string {
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+	codes := make([]string, 0, len(rules))
+	for code := range rules {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Account is a Brazilian bank account as used in payout files.
+type Account struct {
+	Bank        string
+	Agency      string
+	AgencyDigit string
+	Number      string
+	NumberDigit string
+}
+
+// Validate checks the agency and account check digits with the rule of
+// a.Bank. Banks without a rule return ErrUnknownBank.
+func (a Account) Validate() error {
+	rule, ok := lookupRule(a.Bank)
+	if !ok {
+		return errors.Wrap(ErrUnknownBank, a.Bank)
+	}
+
+	agency, err := digits(a.Agency, rule.AgencyLength, "agency")
+	if err != nil {
+		return err
+	}
+	account, err := digits(a.Number, rule.AccountLength, "account")
+	if err != nil {
+		return err
+	}
+
+	if rule.AgencyDigit != nil {
+		if want := rule.AgencyDigit(agency); !strings.EqualFold(a.AgencyDigit, want) {
+			return errors.Errorf("bank: invalid agency digit for %s %s", rule.Name, a.Agency)
+		}
+	}
+	if rule.AccountDigit != nil {
+		if want := rule.AccountDigit(agency, account); !strings.EqualFold(a.NumberDigit, want) {
+			return errors.Errorf("bank: invalid account digit for %s %s", rule.Name, a.Number)
+		}
+	}
+	return nil
+}
+
+func (a Account) Valid() bool {
+	return a.Validate() == nil
+}
+
+// digits strips formatting and left-pads s with zeros to length.
+func digits(s string, length int, what string) (string, error) {
+	d := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		if r == '.' || r == '-' || r == ' ' {
+			return -1
+		}
+		return 'x'
+	}, s)
+	if d == "" || strings.Contains(d, "x") || len(d) > length {
+		return "", errors.Errorf("bank: invalid %s %q", what, s)
+	}
+	return strings.Repeat("0", length-len(d)) + d, nil
+}
+
+func weightedSum(s string, weights []int, combine func(product int) int) int {
+	sum := 0
+	for i, r := range s {
+		sum += combine(int(r-'0') * weights[i%len(weights)])
+	}
+	return sum
+}
+
+func identity(n int) int { return n }
+
+// mod11Digit returns 11 - sum%11, mapping 10 and 11 to the given strings.
+func mod11Digit(sum int, ten, eleven string) string {
+	d := 11 - sum%11
+	switch d {
+	case 10:
+		return ten
+	case 11:
+		return eleven
+	}
+	return string(rune('0' + d))
+}
+
+func init() {
+	RegisterBank("001", Rule{
+		Name:          "Banco do Brasil",
+		AgencyLength:  4,
+		AccountLength: 8,
+		AgencyDigit: func(agency string) string {
+			return mod11Digit(weightedSum(agency, []int{5, 4, 3, 2}, identity), "X", "0")
+		},
+		AccountDigit: func(_, account string) string {
+			return mod11Digit(weightedSum(account, []int{9, 8, 7, 6, 5, 4, 3, 2}, identity), "X", "0")
+		},
+	})
+
+	RegisterBank("033", Rule{
+		Name:          "Santander",
+		AgencyLength:  4,
+		AccountLength: 8,
+		AccountDigit: func(agency, account string) string {
+			weights := []int{9, 7, 3, 1, 0, 0, 9, 7, 1, 3, 1, 9, 7, 3}
+			sum := weightedSum(agency+"00"+account, weights, func(p int) int { return p % 10 })
+			return string(rune('0' + (10-sum%10)%10))
+		},
+	})
+
+	RegisterBank("104", Rule{
+		Name:         "Caixa Econômica Federal",
+		AgencyLength: 4,
+		// Operation code (3) followed by the account number (8).
+		AccountLength: 11,
+		AccountDigit: func(agency, account string) string {
+			weights := []int{8, 7, 6, 5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}
+			d := weightedSum(agency+account, weights, identity) * 10 % 11
+			if d == 10 {
+				d = 0
+			}
+			return string(rune('0' + d))
+		},
+	})
+
+	RegisterBank("237", Rule{
+		Name:          "Bradesco",
+		AgencyLength:  4,
+		AccountLength: 7,
+		AgencyDigit: func(agency string) string {
+			return mod11Digit(weightedSum(agency, []int{5, 4, 3, 2}, identity), "P", "0")
+		},
+		AccountDigit: func(_, account string) string {
+			return mod11Digit(weightedSum(account, []int{2, 7, 6, 5, 4, 3, 2}, identity), "P", "0")
+		},
+	})
+
+	RegisterBank("341", Rule{
+		Name:          "Itaú",
+		AgencyLength:  4,
+		AccountLength: 5,
+		AccountDigit: func(agency, account string) string {
+			sum := weightedSum(agency+account, []int{2, 1}, func(p int) int { return p/10 + p%10 })
+			return string(rune('0' + (10-sum%10)%10))
+		},
+	})
+}
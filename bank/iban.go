@@ -0,0 +1,80 @@
+package bank
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ibanLengths holds the IBAN length per country for the countries we pay
+// out to most often; other countries are checked by checksum only.
+var ibanLengths = map[string]int{
+	"AD": 24, "AE": 23, "AT": 20, "BE": 16, "BG": 22, "BR": 29, "CH": 21,
+	"CY": 28, "CZ": 24, "DE": 22, "DK": 18, "EE": 20, "ES": 24, "FI": 18,
+	"FR": 27, "GB": 22, "GR": 27, "HR": 21, "HU": 28, "IE": 22, "IL": 23,
+	"IS": 26, "IT": 27, "LI": 21, "LT": 20, "LU": 20, "LV": 21, "MC": 27,
+	"MT": 31, "NL": 18, "NO": 15, "PL": 28, "PT": 25, "RO": 24, "SA": 24,
+	"SE": 24, "SI": 19, "SK": 24, "SM": 27, "TR": 26,
+}
+
+// NormalizeIBAN removes spaces and uppercases s.
+func NormalizeIBAN(s string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(s), " ", ""))
+}
+
+// ValidateIBAN checks the format, the country length when known and the
+// ISO 13616 mod-97 checksum.
+func ValidateIBAN(s string) error {
+	iban := NormalizeIBAN(s)
+	if len(iban) < 15 || len(iban) > 34 {
+		return errors.Errorf("bank: invalid IBAN length %d", len(iban))
+	}
+	for i, r := range iban {
+		letter := r >= 'A' && r <= 'Z'
+		digit := r >= '0' && r <= '9'
+		if (i < 2 && !letter) || (i >= 2 && i < 4 && !digit) || (!letter && !digit) {
+			return errors.Errorf("bank: invalid IBAN character %q at %d", r, i)
+		}
+	}
+	if want, ok := ibanLengths[iban[:2]]; ok && len(iban) != want {
+		return errors.Errorf("bank: %s IBAN must have %d characters, got %d", iban[:2], want, len(iban))
+	}
+	if ibanMod97(iban[4:]+iban[:4]) != 1 {
+		return errors.New("bank: invalid IBAN checksum")
+	}
+	return nil
+}
+
+func ValidIBAN(s string) bool {
+	return ValidateIBAN(s) == nil
+}
+
+// FormatIBAN groups a valid IBAN in blocks of four for display.
+func FormatIBAN(s string) string {
+	iban := NormalizeIBAN(s)
+	var b strings.Builder
+	for i, r := range iban {
+		if i > 0 && i%4 == 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func ibanMod97(s string) int {
+	var digits strings.Builder
+	for _, r := range s {
+		if r >= 'A' && r <= 'Z' {
+			digits.WriteString(strconv.Itoa(int(r-'A') + 10))
+		} else {
+			digits.WriteRune(r)
+		}
+	}
+	remainder := 0
+	for _, r := range digits.String() {
+		remainder = (remainder*10 + int(r-'0')) % 97
+	}
+	return remainder
+}
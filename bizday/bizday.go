@@ -0,0 +1,185 @@
+package bizday
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// maxGap is how many consecutive days may be non-business days before a
+// search gives up; a year without one means the calendar is broken, e.g.
+// every weekday was declared weekend.
+const maxGap = 366
+
+var ErrNoBusinessDay = errors.New("bizday: no business day within a year")
+
+type day struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+func dayOf(t time.Time) day {
+	y, m, d := t.Date()
+	return day{y, m, d}
+}
+
+// midnight drops the time of day, keeping the calendar date.
+func midnight(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// Calendar answers business-day questions from a weekend definition and a
+// set of holiday providers. Holidays are computed once per year.
+type Calendar struct {
+	providers []Provider
+	weekend   map[time.Weekday]bool
+
+	mu    sync.Mutex
+	years map[int]map[day]string
+}
+
+func NewCalendar(providers ...Provider) *Calendar {
+	return &Calendar{
+		providers: providers,
+		weekend:   map[time.Weekday]bool{time.Saturday: true, time.Sunday: true},
+		years:     make(map[int]map[day]string),
+	}
+}
+
+// Weekend replaces the non-working weekdays, Saturday and Sunday by
+// default.
+func (c *Calendar) Weekend(days ...time.Weekday) *Calendar {
+	c.weekend = make(map[time.Weekday]bool, len(days))
+	for _, d := range days {
+		c.weekend[d] = true
+	}
+	return c
+}
+
+// Add registers more holiday providers, e.g. state or municipal ones.
+func (c *Calendar) Add(providers ...Provider) *Calendar {
+	c.mu.Lock()
+	c.providers = append(c.providers, providers...)
+	c.years = make(map[int]map[day]string)
+	c.mu.Unlock()
+	return c
+}
+
+func (c *Calendar) holidays(year int) map[day]string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if holidays, ok := c.years[year]; ok {
+		return holidays
+	}
+	holidays := make(map[day]string)
+	for _, p := range c.providers {
+		for _, h := range p.Holidays(year) {
+			holidays[dayOf(h.Date)] = h.Name
+		}
+	}
+	c.years[year] = holidays
+	return holidays
+}
+
+// Holiday returns the name of the holiday on t's calendar day, if any.
+func (c *Calendar) Holiday(t time.Time) (string, bool) {
+	name, ok := c.holidays(t.Year())[dayOf(t)]
+	return name, ok
+}
+
+func (c *Calendar) IsBusinessDay(t time.Time) bool {
+	if c.weekend[t.Weekday()] {
+		return false
+	}
+	_, holiday := c.Holiday(t)
+	return !holiday
+}
+
+// NextBusinessDay returns t if it is a business day, otherwise the
+// following one, keeping the time of day.
+func (c *Calendar) NextBusinessDay(t time.Time) (time.Time, error) {
+	return c.nearest(t, 1)
+}
+
+// PreviousBusinessDay returns t if it is a business day, otherwise the
+// preceding one.
+func (c *Calendar) PreviousBusinessDay(t time.Time) (time.Time, error) {
+	return c.nearest(t, -1)
+}
+
+// nearest walks from t in direction step until a business day, failing
+// with ErrNoBusinessDay after maxGap days.
+func (c *Calendar) nearest(t time.Time, step int) (time.Time, error) {
+	for i := 0; i < maxGap; i++ {
+		day := t.AddDate(0, 0, i*step)
+		if c.IsBusinessDay(day) {
+			return day, nil
+		}
+	}
+	return time.Time{}, errors.Wrapf(ErrNoBusinessDay, "from %s", t.Format("2006-01-02"))
+}
+
+// AddBusinessDays moves n business days from t, backwards when n is
+// negative. Starting on a non-business day, the first step lands on the
+// nearest business day in that direction.
+func (c *Calendar) AddBusinessDays(t time.Time, n int) (time.Time, error) {
+	step := 1
+	if n < 0 {
+		step, n = -1, -n
+	}
+	for n > 0 {
+		next, err := c.nearest(t.AddDate(0, 0, step), step)
+		if err != nil {
+			return time.Time{}, err
+		}
+		t = next
+		n--
+	}
+	return t, nil
+}
+
+// BusinessDaysBetween counts business days in (from, to], negative when to
+// is before from.
+func (c *Calendar) BusinessDaysBetween(from, to time.Time) int {
+	sign := 1
+	if to.Before(from) {
+		from, to, sign = to, from, -1
+	}
+
+	count := 0
+	end := midnight(to)
+	for t := midnight(from).AddDate(0, 0, 1); !t.After(end); t = t.AddDate(0, 0, 1) {
+		if c.IsBusinessDay(t) {
+			count++
+		}
+	}
+	return sign * count
+}
+
+// Default uses the Brazilian banking calendar, which is what due dates and
+// settlement rules follow.
+var Default = NewCalendar(BrazilBanking())
+
+func IsBusinessDay(t time.Time) bool {
+	return Default.IsBusinessDay(t)
+}
+
+func NextBusinessDay(t time.Time) (time.Time, error) {
+	return Default.NextBusinessDay(t)
+}
+
+func PreviousBusinessDay(t time.Time) (time.Time, error) {
+	return Default.PreviousBusinessDay(t)
+}
+
+func AddBusinessDays(t time.Time, n int) (time.Time, error) {
+	return Default.AddBusinessDays(t, n)
+}
+
+func BusinessDaysBetween(from, to time.Time) int {
+	return Default.BusinessDaysBetween(from, to)
+}
@@ -0,0 +1,94 @@
+package bizday
+
+import "time"
+
+type Holiday struct {
+	Date time.Time
+	Name string
+}
+
+// Provider lists the holidays of a year. Dates are compared by calendar
+// day only, so their time and location do not matter.
+type Provider interface {
+	Holidays(year int) []Holiday
+}
+
+type ProviderFunc func(year int) []Holiday
+
+func (f ProviderFunc) Holidays(year int) []Holiday {
+	return f(year)
+}
+
+// Fixed returns a provider for a static list of dated holidays, such as
+// municipal holidays loaded from configuration.
+func Fixed(holidays ...Holiday) Provider {
+	return ProviderFunc(func(year int) []Holiday {
+		var out []Holiday
+		for _, h := range holidays {
+			if h.Date.Year() == year {
+				out = append(out, h)
+			}
+		}
+		return out
+	})
+}
+
+// Easter returns Easter Sunday of the given year (Gregorian calendar).
+func Easter(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// BrazilNational lists the national holidays set by federal law, including
+// Good Friday and, from 2024, Dia da Consciência Negra.
+func BrazilNational() Provider {
+	return ProviderFunc(func(year int) []Holiday {
+		easter := Easter(year)
+		holidays := []Holiday{
+			{date(year, time.January, 1), "Confraternização Universal"},
+			{easter.AddDate(0, 0, -2), "Sexta-feira Santa"},
+			{date(year, time.April, 21), "Tiradentes"},
+			{date(year, time.May, 1), "Dia do Trabalho"},
+			{date(year, time.September, 7), "Independência do Brasil"},
+			{date(year, time.October, 12), "Nossa Senhora Aparecida"},
+			{date(year, time.November, 2), "Finados"},
+			{date(year, time.November, 15), "Proclamação da República"},
+			{date(year, time.December, 25), "Natal"},
+		}
+		if year >= 2024 {
+			holidays = append(holidays, Holiday{date(year, time.November, 20), "Dia Nacional de Zumbi e da Consciência Negra"})
+		}
+		return holidays
+	})
+}
+
+// BrazilBanking adds the days banks close nationwide on top of the
+// national holidays: Carnival Monday and Tuesday and Corpus Christi.
+func BrazilBanking() Provider {
+	national := BrazilNational()
+	return ProviderFunc(func(year int) []Holiday {
+		easter := Easter(year)
+		return append(national.Holidays(year),
+			Holiday{easter.AddDate(0, 0, -48), "Carnaval"},
+			Holiday{easter.AddDate(0, 0, -47), "Carnaval"},
+			Holiday{easter.AddDate(0, 0, 60), "Corpus Christi"},
+		)
+	})
+}
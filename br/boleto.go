@@ -0,0 +1,311 @@
+package br
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type BoletoKind string
+
+const (
+	// BoletoBank is a bank collection slip (47-digit line).
+	BoletoBank BoletoKind = "bank"
+	// BoletoCollection is a utility, tax or agreement slip (48-digit line
+	// starting with 8), known as arrecadação.
+	BoletoCollection BoletoKind = "collection"
+)
+
+var ErrInvalidBoleto = errors.New("br: invalid boleto")
+
+type Boleto struct {
+	Kind      BoletoKind
+	Barcode   string
+	DigitLine string
+	// BankCode is the issuing bank for bank slips.
+	BankCode string
+	// Segment and CompanyID identify the collector for collection slips.
+	Segment   string
+	CompanyID string
+	// Amount is in centavos; zero when the slip does not fix an amount.
+	Amount int64
+	// DueDate is zero when the slip has no due date.
+	DueDate   time.Time
+	FreeField string
+}
+
+// ParseBoleto accepts a digit line (linha digitável) or a barcode, with or
+// without punctuation, validates every check digit and extracts its fields.
+func ParseBoleto(s string) (*Boleto, error) {
+	d := Digits(s)
+
+	var barcode string
+	var err error
+	switch len(d) {
+	case 44:
+		barcode = d
+	case 47, 48:
+		if barcode, err = BoletoLineToBarcode(d); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.Wrapf(ErrInvalidBoleto, "unexpected length %d", len(d))
+	}
+
+	if barcode[0] == '8' {
+		return parseCollection(barcode)
+	}
+	return parseBank(barcode)
+}
+
+// BoletoLineToBarcode converts a 47 or 48-digit line into the 44-digit
+// barcode, checking the line's field check digits.
+func BoletoLineToBarcode(line string) (string, error) {
+	d := Digits(line)
+	switch len(d) {
+	case 47:
+		fields := []string{d[0:10], d[10:21], d[21:32]}
+		for i, f := range fields {
+			if mod10(f[:len(f)-1]) != int(f[len(f)-1]-'0') {
+				return "", errors.Wrapf(ErrInvalidBoleto, "field %d check digit", i+1)
+			}
+		}
+		barcode := d[0:4] + d[32:33] + d[33:47] + d[4:9] + d[10:20] + d[21:31]
+		return barcode, validateBankBarcode(barcode)
+
+	case 48:
+		if d[0] != '8' {
+			return "", errors.Wrap(ErrInvalidBoleto, "48-digit line must start with 8")
+		}
+		check := collectionChecker(d[2])
+		if check == nil {
+			return "", errors.Wrap(ErrInvalidBoleto, "unknown value identifier")
+		}
+		barcode := ""
+		for i := 0; i < 4; i++ {
+			block := d[i*12 : i*12+12]
+			if check(block[:11]) != int(block[11]-'0') {
+				return "", errors.Wrapf(ErrInvalidBoleto, "block %d check digit", i+1)
+			}
+			barcode += block[:11]
+		}
+		return barcode, validateCollectionBarcode(barcode)
+	}
+	return "", errors.Wrapf(ErrInvalidBoleto, "unexpected length %d", len(d))
+}
+
+// BoletoBarcodeToLine converts a 44-digit barcode into its digit line.
+func BoletoBarcodeToLine(barcode string) (string, error) {
+	b := Digits(barcode)
+	if len(b) != 44 {
+		return "", errors.Wrapf(ErrInvalidBoleto, "unexpected length %d", len(b))
+	}
+
+	if b[0] == '8' {
+		if err := validateCollectionBarcode(b); err != nil {
+			return "", err
+		}
+		check := collectionChecker(b[2])
+		line := ""
+		for i := 0; i < 4; i++ {
+			block := b[i*11 : i*11+11]
+			line += block + strconv.Itoa(check(block))
+		}
+		return line, nil
+	}
+
+	if err := validateBankBarcode(b); err != nil {
+		return "", err
+	}
+	f1 := b[0:4] + b[19:24]
+	f2 := b[24:34]
+	f3 := b[34:44]
+	return f1 + strconv.Itoa(mod10(f1)) +
+		f2 + strconv.Itoa(mod10(f2)) +
+		f3 + strconv.Itoa(mod10(f3)) +
+		b[4:5] + b[5:19], nil
+}
+
+// FormatBoletoLine adds the conventional punctuation to a digit line.
+func FormatBoletoLine(line string) string {
+	d := Digits(line)
+	switch len(d) {
+	case 47:
+		return d[0:5] + "." + d[5:10] + " " + d[10:15] + "." + d[15:21] + " " +
+			d[21:26] + "." + d[26:32] + " " + d[32:33] + " " + d[33:47]
+	case 48:
+		return d[0:11] + "-" + d[11:12] + " " + d[12:23] + "-" + d[23:24] + " " +
+			d[24:35] + "-" + d[35:36] + " " + d[36:47] + "-" + d[47:48]
+	}
+	return line
+}
+
+func parseBank(barcode string) (*Boleto, error) {
+	if err := validateBankBarcode(barcode); err != nil {
+		return nil, err
+	}
+
+	line, _ := BoletoBarcodeToLine(barcode)
+	amount, _ := strconv.ParseInt(barcode[9:19], 10, 64)
+	factor, _ := strconv.Atoi(barcode[5:9])
+
+	return &Boleto{
+		Kind:      BoletoBank,
+		Barcode:   barcode,
+		DigitLine: line,
+		BankCode:  barcode[0:3],
+		Amount:    amount,
+		DueDate:   DueDateFromFactor(factor, time.Now()),
+		FreeField: barcode[19:44],
+	}, nil
+}
+
+func parseCollection(barcode string) (*Boleto, error) {
+	if err := validateCollectionBarcode(barcode); err != nil {
+		return nil, err
+	}
+
+	line, _ := BoletoBarcodeToLine(barcode)
+	b := &Boleto{
+		Kind:      BoletoCollection,
+		Barcode:   barcode,
+		DigitLine: line,
+		Segment:   barcode[1:2],
+	}
+
+	// identifiers 6 and 8 carry the actual amount, 7 and 9 a reference value
+	if barcode[2] == '6' || barcode[2] == '8' {
+		b.Amount, _ = strconv.ParseInt(barcode[4:15], 10, 64)
+	}
+
+	// segment 6 uses an 8-digit CNPJ root as company ID, others 4 digits
+	if b.Segment == "6" {
+		b.CompanyID = barcode[15:23]
+		b.FreeField = barcode[23:44]
+	} else {
+		b.CompanyID = barcode[15:19]
+		b.FreeField = barcode[19:44]
+	}
+	return b, nil
+}
+
+func validateBankBarcode(barcode string) error {
+	if len(barcode) != 44 {
+		return ErrInvalidBoleto
+	}
+	if bankMod11(barcode[:4]+barcode[5:]) != int(barcode[4]-'0') {
+		return errors.Wrap(ErrInvalidBoleto, "general check digit")
+	}
+	return nil
+}
+
+func validateCollectionBarcode(barcode string) error {
+	if len(barcode) != 44 || barcode[0] != '8' {
+		return ErrInvalidBoleto
+	}
+	check := collectionChecker(barcode[2])
+	if check == nil {
+		return errors.Wrap(ErrInvalidBoleto, "unknown value identifier")
+	}
+	if check(barcode[:3]+barcode[4:]) != int(barcode[3]-'0') {
+		return errors.Wrap(ErrInvalidBoleto, "general check digit")
+	}
+	return nil
+}
+
+func collectionChecker(identifier byte) func(string) int {
+	switch identifier {
+	case '6', '7':
+		return mod10
+	case '8', '9':
+		return collectionMod11
+	}
+	return nil
+}
+
+var (
+	factorBase      = time.Date(1997, 10, 7, 0, 0, 0, 0, time.UTC)
+	factorRestarted = time.Date(2025, 2, 22, 0, 0, 0, 0, time.UTC)
+)
+
+// DueDateFromFactor converts a fator de vencimento into a date. The factor
+// reached 9999 on 2025-02-21 and restarted at 1000, so each value maps to
+// two dates 9000 days apart; the one closest to ref is returned.
+func DueDateFromFactor(factor int, ref time.Time) time.Time {
+	if factor == 0 {
+		return time.Time{}
+	}
+
+	first := factorBase.AddDate(0, 0, factor)
+	if factor < 1000 {
+		return first
+	}
+	second := factorRestarted.AddDate(0, 0, factor-1000)
+
+	if absDuration(ref.Sub(first)) <= absDuration(ref.Sub(second)) {
+		return first
+	}
+	return second
+}
+
+// FactorFromDueDate is the inverse of DueDateFromFactor.
+func FactorFromDueDate(due time.Time) int {
+	due = time.Date(due.Year(), due.Month(), due.Day(), 0, 0, 0, 0, time.UTC)
+	if due.Before(factorRestarted) {
+		return int(due.Sub(factorBase).Hours() / 24)
+	}
+	return 1000 + int(due.Sub(factorRestarted).Hours()/24)%9000
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// mod10 multiplies digits by 2 and 1 alternately from the right, summing
+// the digits of each product.
+func mod10(s string) int {
+	sum := 0
+	weight := 2
+	for i := len(s) - 1; i >= 0; i-- {
+		p := int(s[i]-'0') * weight
+		if p > 9 {
+			p -= 9
+		}
+		sum += p
+		weight = 3 - weight
+	}
+	return (10 - sum%10) % 10
+}
+
+func weightedMod11(s string) int {
+	sum := 0
+	weight := 2
+	for i := len(s) - 1; i >= 0; i-- {
+		sum += int(s[i]-'0') * weight
+		weight++
+		if weight > 9 {
+			weight = 2
+		}
+	}
+	return sum % 11
+}
+
+func bankMod11(s string) int {
+	dv := 11 - weightedMod11(s)
+	if dv == 0 || dv == 10 || dv == 11 {
+		return 1
+	}
+	return dv
+}
+
+func collectionMod11(s string) int {
+	dv := 11 - weightedMod11(s)
+	if dv >= 10 {
+		return 0
+	}
+	return dv
+}
@@ -0,0 +1,75 @@
+package br
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+)
+
+// Digits strips everything but ASCII digits, e.g. punctuation from
+// formatted documents.
+func Digits(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func allSame(s string) bool {
+	for i := 1; i < len(s); i++ {
+		if s[i] != s[0] {
+			return false
+		}
+	}
+	return true
+}
+
+// mod11 computes the usual Brazilian check digit: 11 minus the weighted sum
+// modulo 11, with 10 and 11 mapping to 0.
+func mod11(values []int, weights []int) int {
+	sum := 0
+	for i, v := range values {
+		sum += v * weights[i]
+	}
+	rest := sum % 11
+	if rest < 2 {
+		return 0
+	}
+	return 11 - rest
+}
+
+func toInts(s string) []int {
+	out := make([]int, len(s))
+	for i := 0; i < len(s); i++ {
+		out[i] = int(s[i] - '0')
+	}
+	return out
+}
+
+func randomDigits(n int) []int {
+	out := make([]int, n)
+	for i := range out {
+		out[i] = randomInt(10)
+	}
+	return out
+}
+
+func randomInt(max int) int {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(max)))
+	if err != nil {
+		panic(err)
+	}
+	return int(n.Int64())
+}
+
+func join(values []int) string {
+	var b strings.Builder
+	for _, v := range values {
+		b.WriteByte(byte('0' + v))
+	}
+	return b.String()
+}
@@ -0,0 +1,255 @@
+package br
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+	"utils"
+	"utils/cache"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrInvalidCEP  = errors.New("br: invalid CEP")
+	ErrCEPNotFound = errors.New("br: CEP not found")
+)
+
+type Address struct {
+	CEP          string `json:"cep"`
+	Street       string `json:"street"`
+	Complement   string `json:"complement,omitempty"`
+	Neighborhood string `json:"neighborhood"`
+	City         string `json:"city"`
+	State        string `json:"state"`
+	IBGE         string `json:"ibge,omitempty"`
+	Source       string `json:"source"`
+}
+
+func ValidCEP(cep string) bool {
+	return len(Digits(cep)) == 8 && len(strings.Trim(cep, "0123456789- .")) == 0
+}
+
+func FormatCEP(cep string) string {
+	d := Digits(cep)
+	if len(d) != 8 {
+		return cep
+	}
+	return d[:5] + "-" + d[5:]
+}
+
+type cepProvider struct {
+	name  string
+	url   func(cep string) string
+	parse func(body string) (*Address, error)
+}
+
+var (
+	viaCEP = cepProvider{
+		name: "viacep",
+		url:  func(cep string) string { return "https://viacep.com.br/ws/" + cep + "/json/" },
+		parse: func(body string) (*Address, error) {
+			var res struct {
+				CEP         string      `json:"cep"`
+				Logradouro  string      `json:"logradouro"`
+				Complemento string      `json:"complemento"`
+				Bairro      string      `json:"bairro"`
+				Localidade  string      `json:"localidade"`
+				UF          string      `json:"uf"`
+				IBGE        string      `json:"ibge"`
+				Erro        interface{} `json:"erro"`
+			}
+			if err := json.Unmarshal([]byte(body), &res); err != nil {
+				return nil, errors.Wrap(err, "json.Unmarshal")
+			}
+			if res.Erro != nil {
+				return nil, ErrCEPNotFound
+			}
+			return &Address{
+				CEP:          res.CEP,
+				Street:       res.Logradouro,
+				Complement:   res.Complemento,
+				Neighborhood: res.Bairro,
+				City:         res.Localidade,
+				State:        res.UF,
+				IBGE:         res.IBGE,
+			}, nil
+		},
+	}
+
+	brasilAPI = cepProvider{
+		name: "brasilapi",
+		url:  func(cep string) string { return "https://brasilapi.com.br/api/cep/v1/" + cep },
+		parse: func(body string) (*Address, error) {
+			var res struct {
+				CEP          string `json:"cep"`
+				Street       string `json:"street"`
+				Neighborhood string `json:"neighborhood"`
+				City         string `json:"city"`
+				State        string `json:"state"`
+			}
+			if err := json.Unmarshal([]byte(body), &res); err != nil {
+				return nil, errors.Wrap(err, "json.Unmarshal")
+			}
+			return &Address{
+				CEP:          res.CEP,
+				Street:       res.Street,
+				Neighborhood: res.Neighborhood,
+				City:         res.City,
+				State:        res.State,
+			}, nil
+		},
+	}
+)
+
+// CEPService looks addresses up on ViaCEP, falling back to BrasilAPI when
+// the first provider fails, and caches successful results.
+type CEPService struct {
+	timeout   time.Duration
+	providers []cepProvider
+	cache     *cache.LRU[string, Address]
+	backend   cache.Backend
+	prefix    string
+}
+
+// cepTTL is how long addresses are cached, locally and in the backend.
+const cepTTL = 24 * time.Hour
+
+func NewCEPService() *CEPService {
+	return &CEPService{
+		timeout:   5 * time.Second,
+		providers: []cepProvider{viaCEP, brasilAPI},
+		cache:     cache.NewLRU[string, Address](1024).TTL(cepTTL),
+	}
+}
+
+func (s *CEPService) Timeout(timeout time.Duration) *CEPService {
+	s.timeout = timeout
+	return s
+}
+
+func (s *CEPService) Cache(c *cache.LRU[string, Address]) *CEPService {
+	s.cache = c
+	return s
+}
+
+// Backend adds a shared second tier behind the local cache, so replicas
+// of a service reuse each other's lookups. Addresses are stored as JSON
+// under prefix followed by the CEP digits; backend failures are treated
+// as misses.
+func (s *CEPService) Backend(backend cache.Backend, prefix string) *CEPService {
+	s.backend = backend
+	s.prefix = prefix
+	return s
+}
+
+var defaultCEPService = NewCEPService()
+
+func LookupCEP(ctx context.Context, cep string) (*Address, error) {
+	return defaultCEPService.Lookup(ctx, cep)
+}
+
+func (s *CEPService) Lookup(ctx context.Context, cep string) (*Address, error) {
+	if !ValidCEP(cep) {
+		return nil, ErrInvalidCEP
+	}
+	cep = Digits(cep)
+
+	if s.cache != nil {
+		if addr, ok := s.cache.Get(cep); ok {
+			return &addr, nil
+		}
+	}
+	if addr, ttl, ok := s.load(ctx, cep); ok {
+		if s.cache != nil {
+			s.cache.SetWithTTL(cep, *addr, ttl)
+		}
+		return addr, nil
+	}
+
+	var lastErr error
+	notFound := 0
+	for _, provider := range s.providers {
+		addr, err := s.fetch(ctx, provider, cep)
+		if err == nil {
+			if s.cache != nil {
+				s.cache.Set(cep, *addr)
+			}
+			if s.backend != nil {
+				if raw, err := json.Marshal(addr); err == nil {
+					s.backend.Set(ctx, s.prefix+cep, raw, cepTTL)
+				}
+			}
+			return addr, nil
+		}
+		if errors.Is(err, ErrCEPNotFound) {
+			notFound++
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		lastErr = err
+	}
+
+	if notFound == len(s.providers) {
+		return nil, ErrCEPNotFound
+	}
+	return nil, lastErr
+}
+
+// load reads cep from the backend with the time it has left there, so the
+// local copy does not outlive the shared one.
+func (s *CEPService) load(ctx context.Context, cep string) (*Address, time.Duration, bool) {
+	if s.backend == nil {
+		return nil, 0, false
+	}
+	raw, found, err := s.backend.Get(ctx, s.prefix+cep)
+	if err != nil || !found {
+		return nil, 0, false
+	}
+	var addr Address
+	if err := json.Unmarshal(raw, &addr); err != nil {
+		return nil, 0, false
+	}
+	ttl, found, err := s.backend.TTL(ctx, s.prefix+cep)
+	if err != nil || !found || ttl < 0 {
+		return nil, 0, false
+	}
+	if ttl == 0 || ttl > cepTTL {
+		ttl = cepTTL
+	}
+	return &addr, ttl, true
+}
+
+func (s *CEPService) fetch(ctx context.Context, provider cepProvider, cep string) (*Address, error) {
+	res, err := utils.NewRest(http.MethodGet, provider.url(cep)).
+		Context(ctx).
+		Timeout(s.timeout).
+		AddHeader("Accept", "application/json").
+		Send()
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: Send", provider.name)
+	}
+
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return nil, ErrCEPNotFound
+	case res.StatusCode != http.StatusOK:
+		return nil, errors.Errorf("%s: unexpected status %d", provider.name, res.StatusCode)
+	}
+
+	addr, err := provider.parse(res.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, provider.name)
+	}
+
+	addr.CEP = FormatCEP(cep)
+	addr.State = strings.ToUpper(strings.TrimSpace(addr.State))
+	addr.Street = strings.TrimSpace(addr.Street)
+	addr.Neighborhood = strings.TrimSpace(addr.Neighborhood)
+	addr.City = strings.TrimSpace(addr.City)
+	addr.Source = provider.name
+	return addr, nil
+}
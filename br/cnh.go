@@ -0,0 +1,56 @@
+package br
+
+func ValidCNH(cnh string) bool {
+	cnh = Digits(cnh)
+	if len(cnh) != 11 || allSame(cnh) {
+		return false
+	}
+
+	digits := toInts(cnh)
+	dv1, dv2 := cnhCheckDigits(digits[:9])
+	return dv1 == digits[9] && dv2 == digits[10]
+}
+
+// cnhCheckDigits follows the DENATRAN algorithm, where a first digit of 10
+// or more lowers the second one by 2.
+func cnhCheckDigits(base []int) (int, int) {
+	sum := 0
+	for i, d := range base {
+		sum += d * (9 - i)
+	}
+	dv1 := sum % 11
+	discount := 0
+	if dv1 >= 10 {
+		dv1, discount = 0, 2
+	}
+
+	sum = 0
+	for i, d := range base {
+		sum += d * (i + 1)
+	}
+	dv2 := sum%11 - discount
+	if dv2 < 0 {
+		dv2 += 11
+	}
+	if dv2 >= 10 {
+		dv2 = 0
+	}
+	return dv1, dv2
+}
+
+func MaskCNH(cnh string) string {
+	d := Digits(cnh)
+	if len(d) != 11 {
+		return cnh
+	}
+	return d[:2] + "*******" + d[9:]
+}
+
+func GenerateCNH() string {
+	digits := randomDigits(9)
+	for allSame(join(digits)) {
+		digits = randomDigits(9)
+	}
+	dv1, dv2 := cnhCheckDigits(digits)
+	return join(append(digits, dv1, dv2))
+}
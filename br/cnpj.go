@@ -0,0 +1,97 @@
+package br
+
+import "strings"
+
+var (
+	cnpjWeights1 = []int{5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}
+	cnpjWeights2 = []int{6, 5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}
+)
+
+const cnpjAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// NormalizeCNPJ strips punctuation and uppercases letters, keeping the
+// characters allowed in both the numeric and the alphanumeric formats.
+func NormalizeCNPJ(cnpj string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(cnpj) {
+		if (r >= '0' && r <= '9') || (r >= 'A' && r <= 'Z') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// ValidCNPJ accepts both the traditional numeric CNPJ and the alphanumeric
+// format, where the first 12 characters may be letters and each character
+// is valued by its ASCII code minus 48 in the check digit calculation.
+func ValidCNPJ(cnpj string) bool {
+	cnpj = NormalizeCNPJ(cnpj)
+	if len(cnpj) != 14 || allSame(cnpj) {
+		return false
+	}
+
+	values := make([]int, 14)
+	for i := 0; i < 14; i++ {
+		c := cnpj[i]
+		if i >= 12 && (c < '0' || c > '9') {
+			return false
+		}
+		values[i] = int(c) - '0'
+	}
+
+	return mod11(values[:12], cnpjWeights1) == values[12] &&
+		mod11(values[:13], cnpjWeights2) == values[13]
+}
+
+// IsAlphanumericCNPJ reports whether cnpj uses letters in its root or
+// branch, as allowed by the alphanumeric format.
+func IsAlphanumericCNPJ(cnpj string) bool {
+	return strings.IndexFunc(NormalizeCNPJ(cnpj), func(r rune) bool { return r >= 'A' && r <= 'Z' }) >= 0
+}
+
+// FormatCNPJ returns 00.000.000/0000-00, or the input unchanged when it does
+// not have 14 characters.
+func FormatCNPJ(cnpj string) string {
+	c := NormalizeCNPJ(cnpj)
+	if len(c) != 14 {
+		return cnpj
+	}
+	return c[:2] + "." + c[2:5] + "." + c[5:8] + "/" + c[8:12] + "-" + c[12:]
+}
+
+// MaskCNPJ hides the root digits after the first two: 12.***.***/0001-95.
+func MaskCNPJ(cnpj string) string {
+	c := NormalizeCNPJ(cnpj)
+	if len(c) != 14 {
+		return cnpj
+	}
+	return c[:2] + ".***.***/" + c[8:12] + "-" + c[12:]
+}
+
+// GenerateCNPJ returns a random valid numeric CNPJ for the head office
+// (branch 0001), unformatted, for use in tests.
+func GenerateCNPJ() string {
+	values := append(randomDigits(8), 0, 0, 0, 1)
+	return completeCNPJ(values)
+}
+
+// GenerateAlphanumericCNPJ returns a random valid CNPJ in the alphanumeric
+// format, unformatted, for use in tests.
+func GenerateAlphanumericCNPJ() string {
+	values := make([]int, 12)
+	for i := range values {
+		values[i] = int(cnpjAlphabet[randomInt(len(cnpjAlphabet))]) - '0'
+	}
+	return completeCNPJ(values)
+}
+
+func completeCNPJ(values []int) string {
+	values = append(values, mod11(values, cnpjWeights1))
+	values = append(values, mod11(values, cnpjWeights2))
+
+	var b strings.Builder
+	for _, v := range values {
+		b.WriteByte(byte(v + '0'))
+	}
+	return b.String()
+}
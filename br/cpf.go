@@ -0,0 +1,47 @@
+package br
+
+var (
+	cpfWeights1 = []int{10, 9, 8, 7, 6, 5, 4, 3, 2}
+	cpfWeights2 = []int{11, 10, 9, 8, 7, 6, 5, 4, 3, 2}
+)
+
+func ValidCPF(cpf string) bool {
+	cpf = Digits(cpf)
+	if len(cpf) != 11 || allSame(cpf) {
+		return false
+	}
+
+	digits := toInts(cpf)
+	return mod11(digits[:9], cpfWeights1) == digits[9] &&
+		mod11(digits[:10], cpfWeights2) == digits[10]
+}
+
+// FormatCPF returns 000.000.000-00, or the input unchanged when it does not
+// have 11 digits.
+func FormatCPF(cpf string) string {
+	d := Digits(cpf)
+	if len(d) != 11 {
+		return cpf
+	}
+	return d[:3] + "." + d[3:6] + "." + d[6:9] + "-" + d[9:]
+}
+
+// MaskCPF hides the middle digits: 123.***.***-09.
+func MaskCPF(cpf string) string {
+	d := Digits(cpf)
+	if len(d) != 11 {
+		return cpf
+	}
+	return d[:3] + ".***.***-" + d[9:]
+}
+
+// GenerateCPF returns a random valid CPF, unformatted, for use in tests.
+func GenerateCPF() string {
+	digits := randomDigits(9)
+	for allSame(join(digits)) {
+		digits = randomDigits(9)
+	}
+	digits = append(digits, mod11(digits, cpfWeights1))
+	digits = append(digits, mod11(digits, cpfWeights2))
+	return join(digits)
+}
@@ -0,0 +1,37 @@
+package br
+
+var pisWeights = []int{3, 2, 9, 8, 7, 6, 5, 4, 3, 2}
+
+// ValidPIS validates PIS/PASEP/NIT numbers, which share the same layout.
+func ValidPIS(pis string) bool {
+	pis = Digits(pis)
+	if len(pis) != 11 || allSame(pis) {
+		return false
+	}
+
+	digits := toInts(pis)
+	return mod11(digits[:10], pisWeights) == digits[10]
+}
+
+// FormatPIS returns 000.00000.00-0, or the input unchanged when it does not
+// have 11 digits.
+func FormatPIS(pis string) string {
+	d := Digits(pis)
+	if len(d) != 11 {
+		return pis
+	}
+	return d[:3] + "." + d[3:8] + "." + d[8:10] + "-" + d[10:]
+}
+
+func MaskPIS(pis string) string {
+	d := Digits(pis)
+	if len(d) != 11 {
+		return pis
+	}
+	return d[:3] + ".*****.**-" + d[10:]
+}
+
+func GeneratePIS() string {
+	digits := randomDigits(10)
+	return join(append(digits, mod11(digits, pisWeights)))
+}
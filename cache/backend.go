@@ -0,0 +1,165 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"time"
+	"utils/clock"
+	"utils/fsutil"
+
+	"github.com/pkg/errors"
+)
+
+// Backend stores raw values shared beyond a single cache instance, such
+// as a Redis server used by every replica of a service. A zero ttl keeps
+// a value until deleted. TTL reports zero for values without expiration
+// and found false for missing keys. redisutil.Client implements it.
+type Backend interface {
+	Get(ctx context.Context, key string) (value []byte, found bool, err error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+	TTL(ctx context.Context, key string) (ttl time.Duration, found bool, err error)
+}
+
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// MemoryBackend is a Backend kept in a process-local LRU, for tests and
+// single-instance deployments. Values are copied in and out, so callers
+// may modify the slices they pass to Set and get from Get.
+type MemoryBackend struct {
+	lru   *LRU[string, memoryEntry]
+	clock clock.Clock
+}
+
+var _ Backend = (*MemoryBackend)(nil)
+
+func NewMemoryBackend(capacity int) *MemoryBackend {
+	return &MemoryBackend{lru: NewLRU[string, memoryEntry](capacity), clock: clock.Real}
+}
+
+// Clock sets the time source used for expiration, for deterministic tests.
+func (b *MemoryBackend) Clock(clk clock.Clock) *MemoryBackend {
+	b.clock = clock.Or(clk)
+	b.lru.Clock(clk)
+	return b
+}
+
+func (b *MemoryBackend) Get(_ context.Context, key string) ([]byte, bool, error) {
+	entry, ok := b.lru.Get(key)
+	if !ok {
+		return nil, false, nil
+	}
+	return append([]byte(nil), entry.value...), true, nil
+}
+
+func (b *MemoryBackend) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	entry := memoryEntry{value: append([]byte(nil), value...), expiresAt: expiry(b.clock.Now(), ttl)}
+	b.lru.SetWithTTL(key, entry, ttl)
+	return nil
+}
+
+func (b *MemoryBackend) Delete(_ context.Context, key string) error {
+	b.lru.Delete(key)
+	return nil
+}
+
+func (b *MemoryBackend) TTL(_ context.Context, key string) (time.Duration, bool, error) {
+	entry, ok := b.lru.Peek(key)
+	if !ok {
+		return 0, false, nil
+	}
+	if entry.expiresAt.IsZero() {
+		return 0, true, nil
+	}
+	return entry.expiresAt.Sub(b.clock.Now()), true, nil
+}
+
+// FileBackend is a Backend storing one file per key in a directory, which
+// survives restarts and can be shared by processes on the same host.
+// Each file holds the expiration as unix nanoseconds followed by the
+// value; expired files are removed when read.
+type FileBackend struct {
+	dir   string
+	clock clock.Clock
+}
+
+var _ Backend = (*FileBackend)(nil)
+
+// NewFileBackend creates dir when it does not exist.
+func NewFileBackend(dir string) (*FileBackend, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, errors.Wrap(err, "os.MkdirAll")
+	}
+	return &FileBackend{dir: dir, clock: clock.Real}, nil
+}
+
+// Clock sets the time source used for expiration, for deterministic tests.
+func (b *FileBackend) Clock(clk clock.Clock) *FileBackend {
+	b.clock = clock.Or(clk)
+	return b
+}
+
+// path hashes key so any string maps to a safe file name.
+func (b *FileBackend) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(b.dir, hex.EncodeToString(sum[:]))
+}
+
+func (b *FileBackend) read(key string) ([]byte, time.Time, bool, error) {
+	raw, err := os.ReadFile(b.path(key))
+	if os.IsNotExist(err) {
+		return nil, time.Time{}, false, nil
+	}
+	if err != nil {
+		return nil, time.Time{}, false, errors.Wrap(err, "os.ReadFile")
+	}
+	if len(raw) < 8 {
+		return nil, time.Time{}, false, errors.Errorf("cache: corrupt entry for %q", key)
+	}
+
+	var expiresAt time.Time
+	if nanos := int64(binary.BigEndian.Uint64(raw)); nanos != 0 {
+		expiresAt = time.Unix(0, nanos)
+	}
+	if expired(b.clock.Now(), expiresAt) {
+		os.Remove(b.path(key))
+		return nil, time.Time{}, false, nil
+	}
+	return raw[8:], expiresAt, true, nil
+}
+
+func (b *FileBackend) Get(_ context.Context, key string) ([]byte, bool, error) {
+	value, _, found, err := b.read(key)
+	return value, found, err
+}
+
+func (b *FileBackend) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	raw := make([]byte, 8+len(value))
+	if expiresAt := expiry(b.clock.Now(), ttl); !expiresAt.IsZero() {
+		binary.BigEndian.PutUint64(raw, uint64(expiresAt.UnixNano()))
+	}
+	copy(raw[8:], value)
+	return errors.Wrap(fsutil.AtomicWrite(b.path(key), raw), "fsutil.AtomicWrite")
+}
+
+func (b *FileBackend) Delete(_ context.Context, key string) error {
+	if err := os.Remove(b.path(key)); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "os.Remove")
+	}
+	return nil
+}
+
+func (b *FileBackend) TTL(_ context.Context, key string) (time.Duration, bool, error) {
+	_, expiresAt, found, err := b.read(key)
+	if err != nil || !found || expiresAt.IsZero() {
+		return 0, found, err
+	}
+	return expiresAt.Sub(b.clock.Now()), true, nil
+}
@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+type Stats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+func (s Stats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+type counters struct {
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+func (c *counters) hit()   { atomic.AddUint64(&c.hits, 1) }
+func (c *counters) miss()  { atomic.AddUint64(&c.misses, 1) }
+func (c *counters) evict() { atomic.AddUint64(&c.evictions, 1) }
+
+func (c *counters) snapshot() Stats {
+	return Stats{
+		Hits:      atomic.LoadUint64(&c.hits),
+		Misses:    atomic.LoadUint64(&c.misses),
+		Evictions: atomic.LoadUint64(&c.evictions),
+	}
+}
+
+func (c *counters) reset() {
+	atomic.StoreUint64(&c.hits, 0)
+	atomic.StoreUint64(&c.misses, 0)
+	atomic.StoreUint64(&c.evictions, 0)
+}
+
+func expiry(now time.Time, ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return now.Add(ttl)
+}
+
+func expired(now, expiresAt time.Time) bool {
+	return !expiresAt.IsZero() && now.After(expiresAt)
+}
@@ -0,0 +1,125 @@
+package cache
+
+import (
+	"strconv"
+	"testing"
+)
+
+// naiveCache is the baseline the benchmarks compare against: a map with
+// a use counter per entry, scanned in full to find the victim.
+type naiveCache struct {
+	capacity int
+	tick     int
+	items    map[string]*naiveEntry
+}
+
+type naiveEntry struct {
+	value    int
+	lastUsed int
+	uses     int
+}
+
+func newNaiveCache(capacity int) *naiveCache {
+	return &naiveCache{capacity: capacity, items: make(map[string]*naiveEntry)}
+}
+
+func (c *naiveCache) Get(key string) (int, bool) {
+	e, ok := c.items[key]
+	if !ok {
+		return 0, false
+	}
+	c.tick++
+	e.lastUsed, e.uses = c.tick, e.uses+1
+	return e.value, true
+}
+
+// Set evicts the least recently used entry, or the least frequently used
+// one when lfu is true.
+func (c *naiveCache) Set(key string, value int, lfu bool) {
+	c.tick++
+	if e, ok := c.items[key]; ok {
+		e.value, e.lastUsed, e.uses = value, c.tick, e.uses+1
+		return
+	}
+	if len(c.items) >= c.capacity {
+		var victim string
+		var best *naiveEntry
+		for k, e := range c.items {
+			if best == nil || (lfu && e.uses < best.uses) || ((!lfu || e.uses == best.uses) && e.lastUsed < best.lastUsed) {
+				victim, best = k, e
+			}
+		}
+		delete(c.items, victim)
+	}
+	c.items[key] = &naiveEntry{value: value, lastUsed: c.tick, uses: 1}
+}
+
+const benchCapacity = 10000
+
+// benchKeys cycles over twice the capacity, so half the lookups miss and
+// most sets evict.
+func benchKeys() []string {
+	keys := make([]string, 2*benchCapacity)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+	return keys
+}
+
+func BenchmarkLRU(b *testing.B) {
+	keys := benchKeys()
+	c := NewLRU[string, int](benchCapacity)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		key := keys[i%len(keys)]
+		if _, ok := c.Get(key); !ok {
+			c.Set(key, i)
+		}
+	}
+}
+
+func BenchmarkLFU(b *testing.B) {
+	keys := benchKeys()
+	c := NewLFU[string, int](benchCapacity)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		key := keys[i%len(keys)]
+		if _, ok := c.Get(key); !ok {
+			c.Set(key, i)
+		}
+	}
+}
+
+func BenchmarkNaiveLRU(b *testing.B) {
+	benchmarkNaive(b, false)
+}
+
+func BenchmarkNaiveLFU(b *testing.B) {
+	benchmarkNaive(b, true)
+}
+
+func benchmarkNaive(b *testing.B, lfu bool) {
+	keys := benchKeys()
+	c := newNaiveCache(benchCapacity)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		key := keys[i%len(keys)]
+		if _, ok := c.Get(key); !ok {
+			c.Set(key, i, lfu)
+		}
+	}
+}
+
+func BenchmarkLRUParallel(b *testing.B) {
+	keys := benchKeys()
+	c := NewLRU[string, int](benchCapacity)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			key := keys[i%len(keys)]
+			if _, ok := c.Get(key); !ok {
+				c.Set(key, i)
+			}
+		}
+	})
+}
@@ -0,0 +1,202 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+	"utils/clock"
+)
+
+type lfuEntry[K comparable, V any] struct {
+	key       K
+	value     V
+	node      *list.Element // of freqs, holding a *freqNode
+	expiresAt time.Time
+}
+
+// freqNode holds the entries used freq times, most recent first.
+type freqNode struct {
+	freq    int
+	entries *list.List
+}
+
+// LFU evicts the least frequently used entry, breaking ties by recency.
+// Entries are kept in per-frequency lists, themselves in a list ordered
+// by frequency, so Get, Set, Delete and eviction are O(1).
+type LFU[K comparable, V any] struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	clock    clock.Clock
+	items    map[K]*list.Element
+	freqs    *list.List // of *freqNode, lowest frequency first
+	counters counters
+}
+
+func NewLFU[K comparable, V any](capacity int) *LFU[K, V] {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &LFU[K, V]{
+		capacity: capacity,
+		clock:    clock.Real,
+		items:    make(map[K]*list.Element),
+		freqs:    list.New(),
+	}
+}
+
+func (c *LFU[K, V]) TTL(ttl time.Duration) *LFU[K, V] {
+	c.mu.Lock()
+	c.ttl = ttl
+	c.mu.Unlock()
+	return c
+}
+
+// Clock sets the time source used for expiration, for deterministic tests.
+func (c *LFU[K, V]) Clock(clk clock.Clock) *LFU[K, V] {
+	c.mu.Lock()
+	c.clock = clock.Or(clk)
+	c.mu.Unlock()
+	return c
+}
+
+func (c *LFU[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	c.set(key, value, c.ttl)
+	c.mu.Unlock()
+}
+
+func (c *LFU[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	c.mu.Lock()
+	c.set(key, value, ttl)
+	c.mu.Unlock()
+}
+
+func (c *LFU[K, V]) set(key K, value V, ttl time.Duration) {
+	expiresAt := expiry(c.clock.Now(), ttl)
+
+	if elem, ok := c.items[key]; ok {
+		entry := elem.Value.(*lfuEntry[K, V])
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.touch(elem)
+		return
+	}
+
+	if len(c.items) >= c.capacity {
+		if lowest := c.freqs.Front(); lowest != nil {
+			c.removeElement(lowest.Value.(*freqNode).entries.Back())
+			c.counters.evict()
+		}
+	}
+
+	node := c.freqs.Front()
+	if node == nil || node.Value.(*freqNode).freq != 1 {
+		node = c.freqs.PushFront(&freqNode{freq: 1, entries: list.New()})
+	}
+	entry := &lfuEntry[K, V]{key: key, value: value, node: node, expiresAt: expiresAt}
+	c.items[key] = node.Value.(*freqNode).entries.PushFront(entry)
+}
+
+func (c *LFU[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+	elem, ok := c.items[key]
+	if !ok {
+		c.counters.miss()
+		return zero, false
+	}
+
+	entry := elem.Value.(*lfuEntry[K, V])
+	if expired(c.clock.Now(), entry.expiresAt) {
+		c.removeElement(elem)
+		c.counters.miss()
+		return zero, false
+	}
+
+	c.touch(elem)
+	c.counters.hit()
+	return entry.value, true
+}
+
+func (c *LFU[K, V]) Peek(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+	elem, ok := c.items[key]
+	if !ok {
+		return zero, false
+	}
+
+	entry := elem.Value.(*lfuEntry[K, V])
+	if expired(c.clock.Now(), entry.expiresAt) {
+		return zero, false
+	}
+	return entry.value, true
+}
+
+func (c *LFU[K, V]) Delete(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return false
+	}
+	c.removeElement(elem)
+	return true
+}
+
+func (c *LFU[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.items)
+}
+
+func (c *LFU[K, V]) Purge() {
+	c.mu.Lock()
+	c.items = make(map[K]*list.Element)
+	c.freqs = list.New()
+	c.mu.Unlock()
+}
+
+func (c *LFU[K, V]) Stats() Stats {
+	return c.counters.snapshot()
+}
+
+func (c *LFU[K, V]) ResetStats() {
+	c.counters.reset()
+}
+
+// touch moves an entry to the node of the next frequency, creating it
+// if needed.
+func (c *LFU[K, V]) touch(elem *list.Element) {
+	entry := elem.Value.(*lfuEntry[K, V])
+	node := entry.node
+	current := node.Value.(*freqNode)
+
+	next := node.Next()
+	if next == nil || next.Value.(*freqNode).freq != current.freq+1 {
+		next = c.freqs.InsertAfter(&freqNode{freq: current.freq + 1, entries: list.New()}, node)
+	}
+	current.entries.Remove(elem)
+	if current.entries.Len() == 0 {
+		c.freqs.Remove(node)
+	}
+
+	entry.node = next
+	c.items[entry.key] = next.Value.(*freqNode).entries.PushFront(entry)
+}
+
+func (c *LFU[K, V]) removeElement(elem *list.Element) {
+	entry := elem.Value.(*lfuEntry[K, V])
+	node := entry.node.Value.(*freqNode)
+	node.entries.Remove(elem)
+	if node.entries.Len() == 0 {
+		c.freqs.Remove(entry.node)
+	}
+	delete(c.items, entry.key)
+}
@@ -0,0 +1,173 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+	"utils/clock"
+)
+
+type lruEntry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+type LRU[K comparable, V any] struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	clock    clock.Clock
+	items    map[K]*list.Element
+	order    *list.List
+	counters counters
+}
+
+func NewLRU[K comparable, V any](capacity int) *LRU[K, V] {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &LRU[K, V]{
+		capacity: capacity,
+		clock:    clock.Real,
+		items:    make(map[K]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// TTL sets the default time to live applied by Set; zero disables expiration.
+func (c *LRU[K, V]) TTL(ttl time.Duration) *LRU[K, V] {
+	c.mu.Lock()
+	c.ttl = ttl
+	c.mu.Unlock()
+	return c
+}
+
+// Clock sets the time source used for expiration, for deterministic tests.
+func (c *LRU[K, V]) Clock(clk clock.Clock) *LRU[K, V] {
+	c.mu.Lock()
+	c.clock = clock.Or(clk)
+	c.mu.Unlock()
+	return c
+}
+
+func (c *LRU[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	c.set(key, value, c.ttl)
+	c.mu.Unlock()
+}
+
+func (c *LRU[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	c.mu.Lock()
+	c.set(key, value, ttl)
+	c.mu.Unlock()
+}
+
+func (c *LRU[K, V]) set(key K, value V, ttl time.Duration) {
+	expiresAt := expiry(c.clock.Now(), ttl)
+
+	if elem, ok := c.items[key]; ok {
+		entry := elem.Value.(*lruEntry[K, V])
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	if c.order.Len() >= c.capacity {
+		c.removeElement(c.order.Back())
+		c.counters.evict()
+	}
+
+	c.items[key] = c.order.PushFront(&lruEntry[K, V]{key: key, value: value, expiresAt: expiresAt})
+}
+
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+	elem, ok := c.items[key]
+	if !ok {
+		c.counters.miss()
+		return zero, false
+	}
+
+	entry := elem.Value.(*lruEntry[K, V])
+	if expired(c.clock.Now(), entry.expiresAt) {
+		c.removeElement(elem)
+		c.counters.miss()
+		return zero, false
+	}
+
+	c.order.MoveToFront(elem)
+	c.counters.hit()
+	return entry.value, true
+}
+
+// Peek returns the value without updating recency or metrics.
+func (c *LRU[K, V]) Peek(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+	elem, ok := c.items[key]
+	if !ok {
+		return zero, false
+	}
+
+	entry := elem.Value.(*lruEntry[K, V])
+	if expired(c.clock.Now(), entry.expiresAt) {
+		return zero, false
+	}
+	return entry.value, true
+}
+
+func (c *LRU[K, V]) Delete(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return false
+	}
+	c.removeElement(elem)
+	return true
+}
+
+func (c *LRU[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *LRU[K, V]) Keys() []K {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	keys := make([]K, 0, c.order.Len())
+	for elem := c.order.Front(); elem != nil; elem = elem.Next() {
+		keys = append(keys, elem.Value.(*lruEntry[K, V]).key)
+	}
+	return keys
+}
+
+func (c *LRU[K, V]) Purge() {
+	c.mu.Lock()
+	c.items = make(map[K]*list.Element)
+	c.order.Init()
+	c.mu.Unlock()
+}
+
+func (c *LRU[K, V]) Stats() Stats {
+	return c.counters.snapshot()
+}
+
+func (c *LRU[K, V]) ResetStats() {
+	c.counters.reset()
+}
+
+func (c *LRU[K, V]) removeElement(elem *list.Element) {
+	entry := c.order.Remove(elem).(*lruEntry[K, V])
+	delete(c.items, entry.key)
+}
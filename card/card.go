@@ -0,0 +1,209 @@
+package card
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type Brand string
+
+const (
+	Unknown    Brand = ""
+	Visa       Brand = "visa"
+	Mastercard Brand = "mastercard"
+	Amex       Brand = "amex"
+	Elo        Brand = "elo"
+	Hipercard  Brand = "hipercard"
+	Diners     Brand = "diners"
+	Discover   Brand = "discover"
+	JCB        Brand = "jcb"
+)
+
+// binRange matches card numbers whose first len(strconv.Itoa(from))
+// digits fall in [from, to].
+type binRange struct {
+	from, to int
+}
+
+type brandRule struct {
+	brand   Brand
+	lengths []int
+	cvv     int
+	ranges  []binRange
+}
+
+// rules are checked in order: Elo and Hipercard overlap the Visa,
+// Mastercard and Discover prefixes, so they come first.
+var rules = []brandRule{
+	{Elo, []int{16}, 3, []binRange{
+		{401178, 401179}, {431274, 431274}, {438935, 438935}, {451416, 451416},
+		{457393, 457393}, {457631, 457632}, {504175, 504175}, {506699, 506778},
+		{509000, 509999}, {627780, 627780}, {636297, 636297}, {636368, 636368},
+		{650031, 650033}, {650035, 650051}, {650405, 650439}, {650485, 650538},
+		{650541, 650598}, {650700, 650718}, {650720, 650727}, {650901, 650920},
+		{651652, 651679}, {655000, 655019}, {655021, 655058},
+	}},
+	{Hipercard, []int{13, 16, 19}, 3, []binRange{
+		{606282, 606282}, {384100, 384100}, {384140, 384140}, {384160, 384160},
+		{637095, 637095}, {637568, 637568}, {637599, 637599}, {637609, 637609},
+		{637612, 637612},
+	}},
+	{Amex, []int{15}, 4, []binRange{{34, 34}, {37, 37}}},
+	{Diners, []int{14, 16}, 3, []binRange{{300, 305}, {36, 36}, {38, 38}}},
+	{JCB, []int{16, 17, 18, 19}, 3, []binRange{{3528, 3589}}},
+	{Discover, []int{16, 17, 18, 19}, 3, []binRange{{6011, 6011}, {644, 649}, {65, 65}}},
+	{Mastercard, []int{16}, 3, []binRange{{51, 55}, {2221, 2720}}},
+	{Visa, []int{13, 16, 19}, 3, []binRange{{4, 4}}},
+}
+
+// Normalize strips spaces and dashes from a card number.
+func Normalize(number string) string {
+	return strings.NewReplacer(" ", "", "-", "", ".", "").Replace(strings.TrimSpace(number))
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// Luhn reports whether number passes the Luhn (mod 10) checksum.
+func Luhn(number string) bool {
+	number = Normalize(number)
+	if len(number) < 2 || !isDigits(number) {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		d := int(number[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+func lookup(number string) *brandRule {
+	number = Normalize(number)
+	if !isDigits(number) {
+		return nil
+	}
+	for i := range rules {
+		for _, r := range rules[i].ranges {
+			digits := len(strconv.Itoa(r.from))
+			if len(number) < digits {
+				continue
+			}
+			prefix, _ := strconv.Atoi(number[:digits])
+			if prefix >= r.from && prefix <= r.to {
+				return &rules[i]
+			}
+		}
+	}
+	return nil
+}
+
+// DetectBrand identifies the brand from the BIN (the leading digits); it
+// only needs the first six digits.
+func DetectBrand(number string) Brand {
+	if rule := lookup(number); rule != nil {
+		return rule.brand
+	}
+	return Unknown
+}
+
+// Valid reports whether number has a known brand, a length accepted by
+// that brand and a valid Luhn checksum.
+func Valid(number string) bool {
+	return Validate(number) == nil
+}
+
+// Validate is like Valid but explains the failure.
+func Validate(number string) error {
+	number = Normalize(number)
+	if !isDigits(number) {
+		return errors.New("card: number must contain only digits")
+	}
+	rule := lookup(number)
+	if rule == nil {
+		return errors.New("card: unknown brand")
+	}
+	if !containsInt(rule.lengths, len(number)) {
+		return errors.Errorf("card: invalid length %d for %s", len(number), rule.brand)
+	}
+	if !Luhn(number) {
+		return errors.New("card: invalid checksum")
+	}
+	return nil
+}
+
+// ValidCVV checks the security code length for the brand: four digits for
+// Amex, three for the others.
+func ValidCVV(cvv string, brand Brand) bool {
+	want := 3
+	for _, rule := range rules {
+		if rule.brand == brand {
+			want = rule.cvv
+		}
+	}
+	return len(cvv) == want && isDigits(cvv)
+}
+
+// ParseExpiry parses "MM/YY", "MM/YYYY" or "MMYY" into month and a four
+// digit year.
+func ParseExpiry(s string) (month, year int, err error) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), " ", "")
+	var m, y string
+	if i := strings.IndexAny(s, "/-"); i >= 0 {
+		m, y = s[:i], s[i+1:]
+	} else if len(s) == 4 || len(s) == 6 {
+		m, y = s[:2], s[2:]
+	}
+	month, errM := strconv.Atoi(m)
+	year, errY := strconv.Atoi(y)
+	if errM != nil || errY != nil || month < 1 || month > 12 || (len(y) != 2 && len(y) != 4) {
+		return 0, 0, errors.Errorf("card: invalid expiry %q", s)
+	}
+	if len(y) == 2 {
+		year += 2000
+	}
+	return month, year, nil
+}
+
+// Expired reports whether a card expiring in month/year is expired at
+// now; cards are valid through the last day of the expiry month.
+func Expired(month, year int, now time.Time) bool {
+	end := time.Date(year, time.Month(month)+1, 1, 0, 0, 0, 0, now.Location())
+	return !now.Before(end)
+}
+
+// ValidExpiry parses s and reports whether it is a well-formed, not yet
+// expired date no more than 20 years ahead.
+func ValidExpiry(s string, now time.Time) bool {
+	month, year, err := ParseExpiry(s)
+	if err != nil {
+		return false
+	}
+	return !Expired(month, year, now) && year <= now.Year()+20
+}
+
+func containsInt(values []int, v int) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,239 @@
+package chanutil
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrTimeout = errors.New("channel operation timed out")
+	ErrClosed  = errors.New("channel closed")
+)
+
+// OrDone forwards values from in until it is closed or ctx is done, so
+// range loops over in can be abandoned without leaking.
+func OrDone[T any](ctx context.Context, in <-chan T) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case v, ok := <-in:
+				if !ok {
+					return
+				}
+				select {
+				case out <- v:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// FanIn merges several channels into one, closed once every input is
+// closed or ctx is done.
+func FanIn[T any](ctx context.Context, ins ...<-chan T) <-chan T {
+	out := make(chan T)
+	var wg sync.WaitGroup
+	wg.Add(len(ins))
+	for _, in := range ins {
+		go func(in <-chan T) {
+			defer wg.Done()
+			for v := range OrDone(ctx, in) {
+				select {
+				case out <- v:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(in)
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// FanOut distributes values from in across n channels, each value going to
+// whichever consumer is ready first. All outputs close when in does.
+func FanOut[T any](ctx context.Context, in <-chan T, n int) []<-chan T {
+	if n < 1 {
+		n = 1
+	}
+	outs := make([]<-chan T, n)
+	source := OrDone(ctx, in)
+	for i := range outs {
+		out := make(chan T)
+		outs[i] = out
+		go func() {
+			defer close(out)
+			for v := range source {
+				select {
+				case out <- v:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	return outs
+}
+
+// Broadcast copies every value from in to each of n channels. A slow
+// consumer slows down all of them.
+func Broadcast[T any](ctx context.Context, in <-chan T, n int) []<-chan T {
+	chans := make([]chan T, n)
+	outs := make([]<-chan T, n)
+	for i := range chans {
+		chans[i] = make(chan T)
+		outs[i] = chans[i]
+	}
+	go func() {
+		defer func() {
+			for _, ch := range chans {
+				close(ch)
+			}
+		}()
+		for v := range OrDone(ctx, in) {
+			for _, ch := range chans {
+				select {
+				case ch <- v:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return outs
+}
+
+// Batch groups values from in into slices of up to size elements, emitting
+// a partial batch once maxWait has passed since its first element. The
+// final partial batch is flushed when in closes.
+func Batch[T any](ctx context.Context, in <-chan T, size int, maxWait time.Duration) <-chan []T {
+	if size < 1 {
+		size = 1
+	}
+	out := make(chan []T)
+	go func() {
+		defer close(out)
+
+		var batch []T
+		var timer *time.Timer
+		var timeout <-chan time.Time
+
+		flush := func() bool {
+			if timer != nil {
+				timer.Stop()
+				timer, timeout = nil, nil
+			}
+			if len(batch) == 0 {
+				return true
+			}
+			select {
+			case out <- batch:
+				batch = nil
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case v, ok := <-in:
+				if !ok {
+					flush()
+					return
+				}
+				batch = append(batch, v)
+				if len(batch) == 1 && maxWait > 0 {
+					timer = time.NewTimer(maxWait)
+					timeout = timer.C
+				}
+				if len(batch) >= size && !flush() {
+					return
+				}
+			case <-timeout:
+				timer, timeout = nil, nil
+				if !flush() {
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// RecvTimeout receives one value, failing with ErrTimeout after d or
+// ErrClosed if the channel is closed.
+func RecvTimeout[T any](ch <-chan T, d time.Duration) (T, error) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	var zero T
+	select {
+	case v, ok := <-ch:
+		if !ok {
+			return zero, ErrClosed
+		}
+		return v, nil
+	case <-timer.C:
+		return zero, ErrTimeout
+	}
+}
+
+// SendTimeout sends v, failing with ErrTimeout if nobody receives within d.
+func SendTimeout[T any](ch chan<- T, v T, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case ch <- v:
+		return nil
+	case <-timer.C:
+		return ErrTimeout
+	}
+}
+
+// Drain discards everything left in ch until it is closed.
+func Drain[T any](ch <-chan T) {
+	for range ch {
+	}
+}
+
+// Collect reads ch until it is closed or ctx is done.
+func Collect[T any](ctx context.Context, ch <-chan T) []T {
+	var out []T
+	for v := range OrDone(ctx, ch) {
+		out = append(out, v)
+	}
+	return out
+}
+
+// FromSlice returns a closed-when-exhausted channel yielding items.
+func FromSlice[T any](ctx context.Context, items []T) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		for _, v := range items {
+			select {
+			case out <- v:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,392 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"utils/config"
+	"utils/strcase"
+
+	"github.com/pkg/errors"
+)
+
+// ErrHelp is returned by Run and Parse after printing the help text for
+// -h or --help; callers usually exit with status 0.
+var ErrHelp = errors.New("cli: help requested")
+
+// flagSpec is a struct field bound to a flag. Fields are tagged like
+//
+//	type Options struct {
+//		Port    int      `flag:"port,p" default:"8080" env:"PORT" usage:"listen port"`
+//		Verbose bool     `flag:"verbose,v" usage:"log more"`
+//		Tags    []string `usage:"repeatable, or comma separated"`
+//		Token   string   `env:"TOKEN" required:"true"`
+//	}
+//
+// Untagged exported fields become kebab-case flags; `flag:"-"` skips one.
+type flagSpec struct {
+	name     string
+	short    string
+	usage    string
+	def      string
+	env      string
+	required bool
+	value    reflect.Value
+}
+
+func (f *flagSpec) isBool() bool {
+	return f.value.Kind() == reflect.Bool
+}
+
+func flagSpecs(dst interface{}) ([]*flagSpec, error) {
+	if dst == nil {
+		return nil, nil
+	}
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return nil, errors.New("cli: flags destination must be a pointer to struct")
+	}
+	rv = rv.Elem()
+
+	var specs []*flagSpec
+	for i := 0; i < rv.NumField(); i++ {
+		field := rv.Type().Field(i)
+		tag := field.Tag.Get("flag")
+		if !field.IsExported() || tag == "-" {
+			continue
+		}
+		name, short, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = strcase.ToKebab(field.Name)
+		}
+		specs = append(specs, &flagSpec{
+			name:     name,
+			short:    short,
+			usage:    field.Tag.Get("usage"),
+			def:      field.Tag.Get("default"),
+			env:      field.Tag.Get("env"),
+			required: field.Tag.Get("required") == "true",
+			value:    rv.Field(i),
+		})
+	}
+	return specs, nil
+}
+
+// Command is a subcommand with its own flags.
+type Command struct {
+	name    string
+	summary string
+	flags   interface{}
+	run     func(args []string) error
+}
+
+// App parses command lines into flag structs and dispatches subcommands,
+// for small tools that don't need a full CLI framework. Flag values come
+// from, in increasing precedence, the `default` tag, the `env` tag and
+// the command line.
+type App struct {
+	name        string
+	description string
+	version     string
+	flags       interface{}
+	action      func(args []string) error
+	commands    []*Command
+	out         io.Writer
+	lookupEnv   func(string) (string, bool)
+}
+
+func NewApp(name, description string) *App {
+	return &App{
+		name:        name,
+		description: description,
+		out:         os.Stdout,
+		lookupEnv:   os.LookupEnv,
+	}
+}
+
+// Version enables a --version flag printing version.
+func (a *App) Version(version string) *App {
+	a.version = version
+	return a
+}
+
+// Flags binds global flags, accepted before or after the subcommand.
+func (a *App) Flags(dst interface{}) *App {
+	a.flags = dst
+	return a
+}
+
+// Action runs when no subcommand is given; without one the help text is
+// printed instead.
+func (a *App) Action(run func(args []string) error) *App {
+	a.action = run
+	return a
+}
+
+// Command registers a subcommand; flags may be nil.
+func (a *App) Command(name, summary string, flags interface{}, run func(args []string) error) *App {
+	a.commands = append(a.commands, &Command{name: name, summary: summary, flags: flags, run: run})
+	return a
+}
+
+func (a *App) Output(out io.Writer) *App {
+	a.out = out
+	return a
+}
+
+func (a *App) LookupEnv(lookup func(string) (string, bool)) *App {
+	a.lookupEnv = lookup
+	return a
+}
+
+func (a *App) command(name string) *Command {
+	for _, c := range a.commands {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// Run parses args, usually os.Args[1:], and calls the matching command.
+func (a *App) Run(args []string) error {
+	global, err := flagSpecs(a.flags)
+	if err != nil {
+		return err
+	}
+
+	// The first positional argument selects the subcommand, so global and
+	// command flags may be mixed around it.
+	var cmd *Command
+	specs := global
+	if len(a.commands) > 0 {
+		if name := firstPositional(args, global); name != "" {
+			if cmd = a.command(name); cmd == nil {
+				return errors.Errorf("unknown command %q", name)
+			}
+			local, err := flagSpecs(cmd.flags)
+			if err != nil {
+				return err
+			}
+			specs = append(append([]*flagSpec(nil), global...), local...)
+		}
+	}
+
+	positional, err := a.parse(args, specs, cmd)
+	if err != nil {
+		return err
+	}
+	if cmd != nil {
+		return cmd.run(positional[1:])
+	}
+	if a.action != nil {
+		return a.action(positional)
+	}
+	a.printHelp(specs, nil)
+	return ErrHelp
+}
+
+func firstPositional(args []string, specs []*flagSpec) string {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			if i+1 < len(args) {
+				return args[i+1]
+			}
+			return ""
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			return arg
+		}
+		if spec := lookupFlag(specs, arg); spec != nil && !spec.isBool() && !strings.Contains(arg, "=") {
+			i++
+		}
+	}
+	return ""
+}
+
+func lookupFlag(specs []*flagSpec, arg string) *flagSpec {
+	name, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+	for _, spec := range specs {
+		if (strings.HasPrefix(arg, "--") && spec.name == name) || (!strings.HasPrefix(arg, "--") && spec.short == name) {
+			return spec
+		}
+	}
+	return nil
+}
+
+func (a *App) parse(args []string, specs []*flagSpec, cmd *Command) ([]string, error) {
+	values := make(map[*flagSpec][]string)
+	var positional []string
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			positional = append(positional, args[i+1:]...)
+			break
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			positional = append(positional, arg)
+			continue
+		}
+
+		switch arg {
+		case "-h", "--help":
+			a.printHelp(specs, cmd)
+			return nil, ErrHelp
+		case "--version":
+			if a.version != "" {
+				fmt.Fprintln(a.out, a.name, a.version)
+				return nil, ErrHelp
+			}
+		}
+
+		spec := lookupFlag(specs, arg)
+		if spec == nil {
+			return nil, errors.Errorf("unknown flag %s", arg)
+		}
+		_, raw, hasValue := strings.Cut(arg, "=")
+		if !hasValue {
+			if spec.isBool() {
+				raw = "true"
+			} else {
+				if i+1 >= len(args) {
+					return nil, errors.Errorf("flag %s requires a value", arg)
+				}
+				i++
+				raw = args[i]
+			}
+		}
+		values[spec] = append(values[spec], raw)
+	}
+
+	for _, spec := range specs {
+		raw, ok := values[spec]
+		source := "--" + spec.name
+		if !ok && spec.env != "" {
+			if v, found := a.lookupEnv(spec.env); found {
+				raw, ok, source = []string{v}, true, "env "+spec.env
+			}
+		}
+		if !ok && spec.def != "" {
+			raw, ok, source = []string{spec.def}, true, "default of --"+spec.name
+		}
+		if !ok {
+			if spec.required {
+				return nil, errors.Errorf("missing required flag --%s", spec.name)
+			}
+			continue
+		}
+
+		joined := raw[len(raw)-1]
+		if spec.value.Kind() == reflect.Slice {
+			joined = strings.Join(raw, ",")
+		}
+		if err := config.SetValue(spec.value, joined, ","); err != nil {
+			return nil, errors.Wrapf(err, "invalid value for %s", source)
+		}
+	}
+	return positional, nil
+}
+
+func (a *App) printHelp(specs []*flagSpec, cmd *Command) {
+	var b strings.Builder
+	name := a.name
+	description := a.description
+	if cmd != nil {
+		name += " " + cmd.name
+		description = cmd.summary
+	}
+	if description != "" {
+		fmt.Fprintf(&b, "%s - %s\n\n", name, description)
+	}
+
+	usage := name + " [flags]"
+	if cmd == nil && len(a.commands) > 0 {
+		usage += " <command>"
+	}
+	fmt.Fprintf(&b, "Usage:\n  %s [args]\n", usage)
+
+	if cmd == nil && len(a.commands) > 0 {
+		b.WriteString("\nCommands:\n")
+		width := 0
+		for _, c := range a.commands {
+			if len(c.name) > width {
+				width = len(c.name)
+			}
+		}
+		for _, c := range a.commands {
+			fmt.Fprintf(&b, "  %s  %s\n", pad(c.name, width, AlignLeft), c.summary)
+		}
+	}
+
+	lines := [][2]string{}
+	for _, spec := range specs {
+		left := "    "
+		if spec.short != "" {
+			left = "-" + spec.short + ", "
+		}
+		left += "--" + spec.name
+		if !spec.isBool() {
+			left += " " + typeName(spec.value.Type())
+		}
+
+		var notes []string
+		if spec.def != "" {
+			notes = append(notes, "default "+spec.def)
+		}
+		if spec.env != "" {
+			notes = append(notes, "env "+spec.env)
+		}
+		if spec.required {
+			notes = append(notes, "required")
+		}
+		right := spec.usage
+		if len(notes) > 0 {
+			right = strings.TrimSpace(right + " (" + strings.Join(notes, ", ") + ")")
+		}
+		lines = append(lines, [2]string{left, right})
+	}
+	lines = append(lines, [2]string{"-h, --help", "show this help"})
+	if a.version != "" && cmd == nil {
+		lines = append(lines, [2]string{"    --version", "print the version"})
+	}
+
+	width := 0
+	for _, line := range lines {
+		if len(line[0]) > width {
+			width = len(line[0])
+		}
+	}
+	b.WriteString("\nFlags:\n")
+	for _, line := range lines {
+		fmt.Fprintf(&b, "  %s  %s\n", pad(line[0], width, AlignLeft), line[1])
+	}
+	fmt.Fprint(a.out, b.String())
+}
+
+func typeName(t reflect.Type) string {
+	switch {
+	case t.Kind() == reflect.Slice:
+		return typeName(t.Elem()) + "..."
+	case t.Kind() == reflect.Ptr:
+		return typeName(t.Elem())
+	case t.String() == "time.Duration":
+		return "duration"
+	}
+	return strings.ToLower(t.Kind().String())
+}
+
+// Parse binds the flags of os.Args to dst and returns the positional
+// arguments.
+func Parse(dst interface{}) ([]string, error) {
+	var positional []string
+	err := NewApp(filepath.Base(os.Args[0]), "").Flags(dst).Action(func(args []string) error {
+		positional = args
+		return nil
+	}).Run(os.Args[1:])
+	return positional, err
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Color is an ANSI SGR attribute.
+type Color string
+
+const (
+	Reset     Color = "0"
+	Bold      Color = "1"
+	Dim       Color = "2"
+	Underline Color = "4"
+	Red       Color = "31"
+	Green     Color = "32"
+	Yellow    Color = "33"
+	Blue      Color = "34"
+	Magenta   Color = "35"
+	Cyan      Color = "36"
+	Gray      Color = "90"
+)
+
+var (
+	colorMu       sync.RWMutex
+	colorOverride *bool
+)
+
+// SetColor forces colors on or off for every output, overriding detection;
+// useful for a --color/--no-color flag.
+func SetColor(enabled bool) {
+	colorMu.Lock()
+	colorOverride = &enabled
+	colorMu.Unlock()
+}
+
+// ColorEnabled reports whether colors should be written to w: it must be
+// a terminal, NO_COLOR must be unset and TERM must not be "dumb".
+func ColorEnabled(w io.Writer) bool {
+	colorMu.RLock()
+	override := colorOverride
+	colorMu.RUnlock()
+	if override != nil {
+		return *override
+	}
+	if _, ok := os.LookupEnv("NO_COLOR"); ok || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return IsTerminal(w)
+}
+
+// Colorize wraps s in the given attributes when stdout supports colors.
+func Colorize(s string, colors ...Color) string {
+	return ColorizeFor(os.Stdout, s, colors...)
+}
+
+// ColorizeFor wraps s in the given attributes when w supports colors.
+func ColorizeFor(w io.Writer, s string, colors ...Color) string {
+	if len(colors) == 0 || !ColorEnabled(w) {
+		return s
+	}
+	codes := make([]string, len(colors))
+	for i, c := range colors {
+		codes[i] = string(c)
+	}
+	return "\x1b[" + strings.Join(codes, ";") + "m" + s + "\x1b[0m"
+}
+
+func Success(s string) string { return Colorize(s, Green) }
+func Warning(s string) string { return Colorize(s, Yellow) }
+func Failure(s string) string { return Colorize(s, Red) }
+func Muted(s string) string   { return Colorize(s, Gray) }
+
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
+// StripANSI removes ANSI escape sequences from s.
+func StripANSI(s string) string {
+	return ansiPattern.ReplaceAllString(s, "")
+}
@@ -0,0 +1,207 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"utils/format"
+	"utils/timeutil"
+)
+
+// Bar is a thread-safe progress bar. On a terminal it redraws in place at
+// most every refresh interval; on other outputs, such as CI logs, it
+// prints a plain line at every 10% step instead.
+type Bar struct {
+	mu          sync.Mutex
+	out         io.Writer
+	tty         bool
+	description string
+	total       int64
+	current     int64
+	bytes       bool
+	width       int
+	refresh     time.Duration
+	start       time.Time
+	lastDraw    time.Time
+	lastStep    int64
+	finished    bool
+}
+
+// NewBar creates a bar writing to stderr; a non-positive total renders a
+// counter without percent or ETA.
+func NewBar(total int64) *Bar {
+	return &Bar{
+		out:      os.Stderr,
+		tty:      IsTerminal(os.Stderr),
+		total:    total,
+		width:    30,
+		refresh:  100 * time.Millisecond,
+		start:    time.Now(),
+		lastStep: -1,
+	}
+}
+
+func (b *Bar) Output(out io.Writer) *Bar {
+	b.mu.Lock()
+	b.out = out
+	b.tty = IsTerminal(out)
+	b.mu.Unlock()
+	return b
+}
+
+func (b *Bar) Description(description string) *Bar {
+	b.mu.Lock()
+	b.description = description
+	b.mu.Unlock()
+	return b
+}
+
+// Bytes renders amounts and rates as byte sizes, e.g. "1.5 MB/s".
+func (b *Bar) Bytes(bytes bool) *Bar {
+	b.mu.Lock()
+	b.bytes = bytes
+	b.mu.Unlock()
+	return b
+}
+
+// Width sets the number of cells of the bar itself.
+func (b *Bar) Width(width int) *Bar {
+	b.mu.Lock()
+	b.width = width
+	b.mu.Unlock()
+	return b
+}
+
+func (b *Bar) SetTotal(total int64) {
+	b.mu.Lock()
+	b.total = total
+	b.draw(false)
+	b.mu.Unlock()
+}
+
+func (b *Bar) Add(n int64) {
+	b.mu.Lock()
+	b.current += n
+	b.draw(false)
+	b.mu.Unlock()
+}
+
+func (b *Bar) Increment() {
+	b.Add(1)
+}
+
+func (b *Bar) Set(current int64) {
+	b.mu.Lock()
+	b.current = current
+	b.draw(false)
+	b.mu.Unlock()
+}
+
+// Write counts len(p) as progress, so the bar can sit in an io.Copy via
+// io.TeeReader or io.MultiWriter.
+func (b *Bar) Write(p []byte) (int, error) {
+	b.Add(int64(len(p)))
+	return len(p), nil
+}
+
+// Progress matches the archive.ProgressFunc signature so the bar can be
+// passed directly as a progress callback.
+func (b *Bar) Progress(name string, done, total int64) {
+	b.mu.Lock()
+	if name != "" {
+		b.description = name
+	}
+	b.current, b.total = done, total
+	b.draw(false)
+	b.mu.Unlock()
+}
+
+// Finish draws the final state and moves to the next line.
+func (b *Bar) Finish() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.finished {
+		return
+	}
+	if b.total > 0 && b.current < b.total {
+		b.current = b.total
+	}
+	b.draw(true)
+	b.finished = true
+	if b.tty {
+		fmt.Fprintln(b.out)
+	}
+}
+
+func (b *Bar) draw(force bool) {
+	if b.finished {
+		return
+	}
+	now := time.Now()
+	if b.tty {
+		if !force && now.Sub(b.lastDraw) < b.refresh {
+			return
+		}
+		b.lastDraw = now
+		fmt.Fprintf(b.out, "\r%s\x1b[K", b.render(now))
+		return
+	}
+
+	step := int64(0)
+	if b.total > 0 {
+		step = b.current * 10 / b.total
+	}
+	if step > b.lastStep || (force && step < 10) {
+		b.lastStep = step
+		fmt.Fprintln(b.out, b.render(now))
+	}
+}
+
+func (b *Bar) render(now time.Time) string {
+	var parts []string
+	if b.description != "" {
+		parts = append(parts, b.description)
+	}
+
+	elapsed := now.Sub(b.start)
+	rate := 0.0
+	if elapsed > 0 {
+		rate = float64(b.current) / elapsed.Seconds()
+	}
+
+	if b.total > 0 {
+		ratio := float64(b.current) / float64(b.total)
+		if ratio > 1 {
+			ratio = 1
+		}
+		if b.tty && b.width > 0 {
+			filled := int(ratio * float64(b.width))
+			cells := strings.Repeat("=", filled)
+			if filled < b.width {
+				cells += ">" + strings.Repeat(" ", b.width-filled-1)
+			}
+			parts = append(parts, "["+cells+"]")
+		}
+		parts = append(parts, fmt.Sprintf("%3.0f%%", ratio*100))
+		parts = append(parts, b.amount(b.current)+"/"+b.amount(b.total))
+	} else {
+		parts = append(parts, b.amount(b.current))
+	}
+
+	parts = append(parts, b.amount(int64(rate))+"/s")
+	if b.total > 0 && rate > 0 && b.current < b.total {
+		eta := time.Duration(float64(b.total-b.current) / rate * float64(time.Second))
+		parts = append(parts, "ETA "+timeutil.Humanize(eta.Round(time.Second)))
+	}
+	return strings.Join(parts, " ")
+}
+
+func (b *Bar) amount(n int64) string {
+	if b.bytes {
+		return format.Bytes(n)
+	}
+	return fmt.Sprint(n)
+}
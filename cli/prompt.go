@@ -0,0 +1,260 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/term"
+)
+
+// Validator rejects an answer with an error that is shown before asking
+// again.
+type Validator func(answer string) error
+
+// Required rejects empty answers.
+func Required(answer string) error {
+	if strings.TrimSpace(answer) == "" {
+		return errors.New("a value is required")
+	}
+	return nil
+}
+
+// Prompter asks questions on an output and reads answers from an input,
+// repeating a question until the answer is valid. Reaching the end of the
+// input returns io.EOF.
+type Prompter struct {
+	in     io.Reader
+	reader *bufio.Reader
+	out    io.Writer
+}
+
+// NewPrompter reads from stdin and writes to stderr, keeping stdout free
+// for the tool's actual output.
+func NewPrompter() *Prompter {
+	return &Prompter{in: os.Stdin, reader: bufio.NewReader(os.Stdin), out: os.Stderr}
+}
+
+func (p *Prompter) Input(in io.Reader) *Prompter {
+	p.in = in
+	p.reader = bufio.NewReader(in)
+	return p
+}
+
+func (p *Prompter) Output(out io.Writer) *Prompter {
+	p.out = out
+	return p
+}
+
+func (p *Prompter) readLine() (string, error) {
+	line, err := p.reader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (p *Prompter) ask(label string, read func() (string, error), validate Validator) (string, error) {
+	for {
+		fmt.Fprint(p.out, label)
+		answer, err := read()
+		if err != nil {
+			return "", err
+		}
+		if validate != nil {
+			if err := validate(answer); err != nil {
+				fmt.Fprintf(p.out, "  %s\n", err)
+				continue
+			}
+		}
+		return answer, nil
+	}
+}
+
+// Ask returns a free-form answer, or def when the answer is empty.
+func (p *Prompter) Ask(question, def string, validate Validator) (string, error) {
+	label := question + ": "
+	if def != "" {
+		label = fmt.Sprintf("%s [%s]: ", question, def)
+	}
+	return p.ask(label, func() (string, error) {
+		answer, err := p.readLine()
+		if strings.TrimSpace(answer) == "" {
+			answer = def
+		}
+		return strings.TrimSpace(answer), err
+	}, validate)
+}
+
+// Confirm asks a yes/no question; an empty answer returns def.
+func (p *Prompter) Confirm(question string, def bool) (bool, error) {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	answer, err := p.ask(fmt.Sprintf("%s [%s]: ", question, hint), p.readLine, func(answer string) error {
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "", "y", "yes", "s", "sim", "n", "no", "nao", "não":
+			return nil
+		}
+		return errors.New("please answer yes or no")
+	})
+	if err != nil {
+		return false, err
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "":
+		return def, nil
+	case "y", "yes", "s", "sim":
+		return true, nil
+	}
+	return false, nil
+}
+
+func (p *Prompter) listOptions(question string, options []string, selected func(i int) bool) {
+	fmt.Fprintln(p.out, question)
+	for i, option := range options {
+		mark := " "
+		if selected(i) {
+			mark = "*"
+		}
+		fmt.Fprintf(p.out, " %s %d) %s\n", mark, i+1, option)
+	}
+}
+
+// Select lists options and returns the index of the chosen one; def is
+// the index returned for an empty answer, or -1 to require a choice.
+func (p *Prompter) Select(question string, options []string, def int) (int, error) {
+	if len(options) == 0 {
+		return -1, errors.New("cli: no options to select")
+	}
+	p.listOptions(question, options, func(i int) bool { return i == def })
+
+	answer, err := p.ask("Choice: ", p.readLine, func(answer string) error {
+		answer = strings.TrimSpace(answer)
+		if answer == "" && def >= 0 && def < len(options) {
+			return nil
+		}
+		_, err := parseChoice(answer, len(options))
+		return err
+	})
+	if err != nil {
+		return -1, err
+	}
+	if strings.TrimSpace(answer) == "" {
+		return def, nil
+	}
+	return parseChoice(strings.TrimSpace(answer), len(options))
+}
+
+// MultiSelect lists options and returns the indexes chosen as a comma
+// separated list of numbers or ranges, e.g. "1,3-5"; an empty answer
+// returns defaults.
+func (p *Prompter) MultiSelect(question string, options []string, defaults []int) ([]int, error) {
+	if len(options) == 0 {
+		return nil, errors.New("cli: no options to select")
+	}
+	isDefault := make(map[int]bool, len(defaults))
+	for _, i := range defaults {
+		isDefault[i] = true
+	}
+	p.listOptions(question, options, func(i int) bool { return isDefault[i] })
+
+	answer, err := p.ask("Choices (e.g. 1,3-5): ", p.readLine, func(answer string) error {
+		if strings.TrimSpace(answer) == "" {
+			return nil
+		}
+		_, err := parseChoices(answer, len(options))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(answer) == "" {
+		return append([]int(nil), defaults...), nil
+	}
+	return parseChoices(answer, len(options))
+}
+
+// Password reads an answer without echoing it when the input is a
+// terminal.
+func (p *Prompter) Password(question string, validate Validator) (string, error) {
+	return p.ask(question+": ", func() (string, error) {
+		if f, ok := p.in.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+			secret, err := term.ReadPassword(int(f.Fd()))
+			fmt.Fprintln(p.out)
+			if err != nil {
+				return "", errors.Wrap(err, "term.ReadPassword")
+			}
+			return string(secret), nil
+		}
+		return p.readLine()
+	}, validate)
+}
+
+func parseChoice(s string, n int) (int, error) {
+	i, err := strconv.Atoi(s)
+	if err != nil || i < 1 || i > n {
+		return -1, errors.Errorf("choose a number between 1 and %d", n)
+	}
+	return i - 1, nil
+}
+
+func parseChoices(s string, n int) ([]int, error) {
+	var out []int
+	seen := make(map[int]bool)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		from, to := part, part
+		if i := strings.Index(part, "-"); i > 0 {
+			from, to = strings.TrimSpace(part[:i]), strings.TrimSpace(part[i+1:])
+		}
+		start, err := parseChoice(from, n)
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseChoice(to, n)
+		if err != nil {
+			return nil, err
+		}
+		if start > end {
+			return nil, errors.Errorf("invalid range %q", part)
+		}
+		for i := start; i <= end; i++ {
+			if !seen[i] {
+				seen[i] = true
+				out = append(out, i)
+			}
+		}
+	}
+	return out, nil
+}
+
+var defaultPrompter = NewPrompter()
+
+func Ask(question, def string, validate Validator) (string, error) {
+	return defaultPrompter.Ask(question, def, validate)
+}
+
+func Confirm(question string, def bool) (bool, error) {
+	return defaultPrompter.Confirm(question, def)
+}
+
+func Select(question string, options []string, def int) (int, error) {
+	return defaultPrompter.Select(question, options, def)
+}
+
+func MultiSelect(question string, options []string, defaults []int) ([]int, error) {
+	return defaultPrompter.MultiSelect(question, options, defaults)
+}
+
+func Password(question string, validate Validator) (string, error) {
+	return defaultPrompter.Password(question, validate)
+}
@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+var DefaultFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// Spinner shows activity of unknown duration. On outputs that are not a
+// terminal it prints the message once on Start and the final message on
+// Stop.
+type Spinner struct {
+	mu       sync.Mutex
+	out      io.Writer
+	tty      bool
+	message  string
+	frames   []string
+	interval time.Duration
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func NewSpinner(message string) *Spinner {
+	return &Spinner{
+		out:      os.Stderr,
+		tty:      IsTerminal(os.Stderr),
+		message:  message,
+		frames:   DefaultFrames,
+		interval: 100 * time.Millisecond,
+	}
+}
+
+func (s *Spinner) Output(out io.Writer) *Spinner {
+	s.mu.Lock()
+	s.out = out
+	s.tty = IsTerminal(out)
+	s.mu.Unlock()
+	return s
+}
+
+func (s *Spinner) Frames(frames ...string) *Spinner {
+	s.mu.Lock()
+	if len(frames) > 0 {
+		s.frames = frames
+	}
+	s.mu.Unlock()
+	return s
+}
+
+func (s *Spinner) Interval(interval time.Duration) *Spinner {
+	s.mu.Lock()
+	s.interval = interval
+	s.mu.Unlock()
+	return s
+}
+
+// Message replaces the text shown next to the spinner.
+func (s *Spinner) Message(message string) {
+	s.mu.Lock()
+	s.message = message
+	s.mu.Unlock()
+}
+
+func (s *Spinner) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		return
+	}
+	if !s.tty {
+		fmt.Fprintln(s.out, s.message)
+		s.stop = make(chan struct{})
+		return
+	}
+
+	s.stop, s.done = make(chan struct{}), make(chan struct{})
+	go s.loop(s.stop, s.done)
+}
+
+func (s *Spinner) loop(stop, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for i := 0; ; i++ {
+		s.mu.Lock()
+		fmt.Fprintf(s.out, "\r%s %s\x1b[K", s.frames[i%len(s.frames)], s.message)
+		s.mu.Unlock()
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop halts the spinner and prints final in its place; an empty final
+// just clears the line.
+func (s *Spinner) Stop(final string) {
+	s.mu.Lock()
+	stop, done := s.stop, s.done
+	s.stop, s.done = nil, nil
+	s.mu.Unlock()
+	if stop == nil {
+		return
+	}
+
+	close(stop)
+	if done != nil {
+		<-done
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tty {
+		fmt.Fprint(s.out, "\r\x1b[K")
+	}
+	if final != "" {
+		fmt.Fprintln(s.out, final)
+	}
+}
@@ -0,0 +1,200 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+	"utils/text"
+)
+
+type Align int
+
+const (
+	// AlignAuto aligns columns whose cells are all numeric to the right and
+	// the others to the left.
+	AlignAuto Align = iota
+	AlignLeft
+	AlignRight
+	AlignCenter
+)
+
+// TableWriter renders rows as aligned plain-text columns. Cells may carry
+// colors; widths are measured without the escape sequences.
+type TableWriter struct {
+	headers   []string
+	rows      [][]string
+	align     map[int]Align
+	maxWidth  map[int]int
+	separator string
+}
+
+func NewTable(headers ...string) *TableWriter {
+	return &TableWriter{
+		headers:   headers,
+		align:     make(map[int]Align),
+		maxWidth:  make(map[int]int),
+		separator: "  ",
+	}
+}
+
+func (t *TableWriter) Align(column int, align Align) *TableWriter {
+	t.align[column] = align
+	return t
+}
+
+// MaxWidth truncates the cells of column to width runes with "…".
+func (t *TableWriter) MaxWidth(column, width int) *TableWriter {
+	t.maxWidth[column] = width
+	return t
+}
+
+func (t *TableWriter) Separator(separator string) *TableWriter {
+	t.separator = separator
+	return t
+}
+
+func (t *TableWriter) AddRow(cells ...string) *TableWriter {
+	t.rows = append(t.rows, cells)
+	return t
+}
+
+func (t *TableWriter) AddRows(rows [][]string) *TableWriter {
+	t.rows = append(t.rows, rows...)
+	return t
+}
+
+func (t *TableWriter) columns() int {
+	n := len(t.headers)
+	for _, row := range t.rows {
+		if len(row) > n {
+			n = len(row)
+		}
+	}
+	return n
+}
+
+func (t *TableWriter) cell(row []string, column int) string {
+	if column >= len(row) {
+		return ""
+	}
+	s := row[column]
+	if max, ok := t.maxWidth[column]; ok && visibleWidth(s) > max {
+		s = text.Truncate(StripANSI(s), max, "…")
+	}
+	return s
+}
+
+func (t *TableWriter) alignment(column int) Align {
+	if a := t.align[column]; a != AlignAuto {
+		return a
+	}
+	numeric := false
+	for _, row := range t.rows {
+		s := strings.TrimSpace(StripANSI(t.cell(row, column)))
+		if s == "" {
+			continue
+		}
+		if !isNumeric(s) {
+			return AlignLeft
+		}
+		numeric = true
+	}
+	if numeric {
+		return AlignRight
+	}
+	return AlignLeft
+}
+
+// Render writes the table to w, with a bold header when w supports colors.
+func (t *TableWriter) Render(w io.Writer) error {
+	columns := t.columns()
+	widths := make([]int, columns)
+	aligns := make([]Align, columns)
+	for c := 0; c < columns; c++ {
+		widths[c] = visibleWidth(t.cell(t.headers, c))
+		for _, row := range t.rows {
+			if n := visibleWidth(t.cell(row, c)); n > widths[c] {
+				widths[c] = n
+			}
+		}
+		aligns[c] = t.alignment(c)
+	}
+
+	var buf bytes.Buffer
+	writeLine := func(cells []string) {
+		parts := make([]string, columns)
+		for c := range parts {
+			parts[c] = pad(cells[c], widths[c], aligns[c])
+		}
+		buf.WriteString(strings.TrimRight(strings.Join(parts, t.separator), " "))
+		buf.WriteByte('\n')
+	}
+
+	if len(t.headers) > 0 {
+		cells := make([]string, columns)
+		rule := make([]string, columns)
+		for c := range cells {
+			cells[c] = ColorizeFor(w, t.cell(t.headers, c), Bold)
+			rule[c] = strings.Repeat("-", widths[c])
+		}
+		writeLine(cells)
+		writeLine(rule)
+	}
+	for _, row := range t.rows {
+		cells := make([]string, columns)
+		for c := range cells {
+			cells[c] = t.cell(row, c)
+		}
+		writeLine(cells)
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// String renders the table; the header is only bold when colors are
+// forced with SetColor.
+func (t *TableWriter) String() string {
+	var buf bytes.Buffer
+	t.Render(&buf)
+	return buf.String()
+}
+
+// Table renders headers and rows as aligned columns, e.g. for printing a
+// response summary.
+func Table(headers []string, rows [][]string) string {
+	return NewTable(headers...).AddRows(rows).String()
+}
+
+// PrintTable writes headers and rows to stdout.
+func PrintTable(headers []string, rows [][]string) error {
+	return NewTable(headers...).AddRows(rows).Render(os.Stdout)
+}
+
+func visibleWidth(s string) int {
+	return utf8.RuneCountInString(StripANSI(s))
+}
+
+func pad(s string, width int, align Align) string {
+	missing := width - visibleWidth(s)
+	if missing <= 0 {
+		return s
+	}
+	switch align {
+	case AlignRight:
+		return strings.Repeat(" ", missing) + s
+	case AlignCenter:
+		left := missing / 2
+		return strings.Repeat(" ", left) + s + strings.Repeat(" ", missing-left)
+	}
+	return s + strings.Repeat(" ", missing)
+}
+
+func isNumeric(s string) bool {
+	s = strings.NewReplacer(",", "", "%", "", "$", "", "R$", "").Replace(s)
+	_, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return err == nil
+}
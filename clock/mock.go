@@ -0,0 +1,155 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Mock is a Clock that only moves when told to. Sleepers, After channels
+// and tickers fire as Advance or SetTime moves the time past their
+// deadlines.
+type Mock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*waiter
+}
+
+type waiter struct {
+	deadline time.Time
+	period   time.Duration
+	ch       chan time.Time
+	stopped  bool
+}
+
+// NewMock returns a Mock set to start.
+func NewMock(start time.Time) *Mock {
+	return &Mock{now: start}
+}
+
+func (m *Mock) Now() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.now
+}
+
+// Sleep blocks until the clock has been advanced by d.
+func (m *Mock) Sleep(d time.Duration) {
+	<-m.After(d)
+}
+
+func (m *Mock) After(d time.Duration) <-chan time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	w := &waiter{deadline: m.now.Add(d), ch: make(chan time.Time, 1)}
+	if d <= 0 {
+		w.ch <- m.now
+		return w.ch
+	}
+	m.waiters = append(m.waiters, w)
+	return w.ch
+}
+
+func (m *Mock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	w := &waiter{deadline: m.now.Add(d), period: d, ch: make(chan time.Time, 1)}
+	m.waiters = append(m.waiters, w)
+	return &mockTicker{mock: m, w: w}
+}
+
+// Advance moves the clock forward by d, firing everything that comes due
+// in deadline order.
+func (m *Mock) Advance(d time.Duration) {
+	m.SetTime(m.Now().Add(d))
+}
+
+// SetTime moves the clock to t. Moving backwards fires nothing.
+func (m *Mock) SetTime(t time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for {
+		sort.Slice(m.waiters, func(i, j int) bool {
+			return m.waiters[i].deadline.Before(m.waiters[j].deadline)
+		})
+		if len(m.waiters) == 0 || m.waiters[0].deadline.After(t) {
+			break
+		}
+
+		w := m.waiters[0]
+		m.now = w.deadline
+		select {
+		case w.ch <- w.deadline:
+		default:
+			// Like time.Ticker, drop ticks nobody has read.
+		}
+
+		if w.period > 0 {
+			w.deadline = w.deadline.Add(w.period)
+		} else {
+			m.waiters = m.waiters[1:]
+		}
+	}
+	m.now = t
+}
+
+// Waiters reports how many sleepers, After channels and tickers are
+// pending, letting tests wait until a goroutine has started waiting
+// before advancing the clock.
+func (m *Mock) Waiters() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.waiters)
+}
+
+// BlockUntil waits until at least n waiters are pending.
+func (m *Mock) BlockUntil(n int) {
+	for m.Waiters() < n {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func (m *Mock) remove(w *waiter) {
+	for i, other := range m.waiters {
+		if other == w {
+			m.waiters = append(m.waiters[:i], m.waiters[i+1:]...)
+			return
+		}
+	}
+}
+
+type mockTicker struct {
+	mock *Mock
+	w    *waiter
+}
+
+func (t *mockTicker) C() <-chan time.Time {
+	return t.w.ch
+}
+
+func (t *mockTicker) Stop() {
+	t.mock.mu.Lock()
+	defer t.mock.mu.Unlock()
+
+	t.w.stopped = true
+	t.mock.remove(t.w)
+}
+
+func (t *mockTicker) Reset(d time.Duration) {
+	t.mock.mu.Lock()
+	defer t.mock.mu.Unlock()
+
+	t.w.period = d
+	t.w.deadline = t.mock.now.Add(d)
+	if t.w.stopped {
+		t.w.stopped = false
+		t.mock.waiters = append(t.mock.waiters, t.w)
+	}
+}
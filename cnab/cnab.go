@@ -0,0 +1,268 @@
+package cnab
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"utils/bank"
+	"utils/br"
+	"utils/money"
+	"utils/text"
+
+	"github.com/pkg/errors"
+)
+
+var ErrUnsupportedBank = errors.New("cnab: unsupported bank")
+
+// Bank describes how a bank fills the CNAB 240 headers. The versions are
+// the layout versions written to the file and lot headers.
+type Bank struct {
+	Name        string
+	FileVersion string
+	LotVersion  string
+}
+
+var (
+	banksMu sync.RWMutex
+	banks   = map[string]Bank{
+		"001": {Name: "BANCO DO BRASIL S.A.", FileVersion: "083", LotVersion: "042"},
+		"033": {Name: "BANCO SANTANDER", FileVersion: "040", LotVersion: "030"},
+		"104": {Name: "CAIXA ECONOMICA FEDERAL", FileVersion: "107", LotVersion: "067"},
+		"237": {Name: "BRADESCO", FileVersion: "084", LotVersion: "042"},
+		"341": {Name: "BANCO ITAU SA", FileVersion: "040", LotVersion: "030"},
+		"748": {Name: "SICREDI", FileVersion: "081", LotVersion: "040"},
+		"756": {Name: "SICOOB", FileVersion: "081", LotVersion: "040"},
+	}
+)
+
+// RegisterBank adds or replaces the CNAB settings of a COMPE bank code.
+func RegisterBank(code string, b Bank) {
+	banksMu.Lock()
+	banks[code] = b
+	banksMu.Unlock()
+}
+
+func lookupBank(code string) (Bank, bool) {
+	banksMu.RLock()
+	defer banksMu.RUnlock()
+	b, ok := banks[code]
+	return b, ok
+}
+
+// Banks returns the codes with registered CNAB settings.
+func Banks() []string {
+	banksMu.RLock()
+	defer banksMu.RUnlock()
+	codes := make([]string, 0, len(banks))
+	for code := range banks {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Company is the beneficiary (cedente) of the titles.
+type Company struct {
+	Name     string
+	Document string // CPF or CNPJ
+	Account  bank.Account
+	// Agreement is the bank's code for the collection contract: the
+	// convênio in CNAB 240, the company code in Bradesco 400.
+	Agreement string
+	Wallet    string
+}
+
+// Payer is the debtor (sacado) of a title.
+type Payer struct {
+	Name     string
+	Document string // CPF or CNPJ
+	Address  string
+	District string
+	City     string
+	State    string
+	CEP      string
+}
+
+// Movement codes of a remessa title.
+const (
+	Register      = 1
+	WriteOff      = 2
+	GrantRebate   = 4
+	ChangeDue     = 6
+	Protest       = 9
+	CancelProtest = 10
+)
+
+// Title is one boleto in a remessa.
+type Title struct {
+	OurNumber      string
+	DocumentNumber string
+	Kind           int // espécie, e.g. 2 for duplicata mercantil
+	Movement       int // defaults to Register
+	IssueDate      time.Time
+	DueDate        time.Time
+	Amount         money.Money
+	InterestPerDay money.Money
+	Discount       money.Money
+	DiscountUntil  time.Time
+	ProtestDays    int
+	Reference      string // returned as-is in the retorno
+	Payer          Payer
+}
+
+func (t Title) movement() int {
+	if t.Movement == 0 {
+		return Register
+	}
+	return t.Movement
+}
+
+// ValidationError reports an invalid field of a remessa; Title is the
+// 0-based index of the title, or -1 for the company.
+type ValidationError struct {
+	Title int
+	Field string
+	Err   error
+}
+
+func (e *ValidationError) Error() string {
+	if e.Title < 0 {
+		return fmt.Sprintf("cnab: company %s: %v", e.Field, e.Err)
+	}
+	return fmt.Sprintf("cnab: title %d %s: %v", e.Title, e.Field, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// LineError reports a record of a retorno that is malformed as a whole,
+// such as an unknown record type or a trailer whose counts do not match.
+// Problems with a single field are reported as *fixedwidth.FieldError.
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("cnab: line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// Errors collects every problem found in a file.
+type Errors []error
+
+func (e Errors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("cnab: %d errors: %s", len(e), strings.Join(messages, "; "))
+}
+
+func (e Errors) orNil() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// collector builds Errors for one title or the company.
+type collector struct {
+	errs  Errors
+	title int
+}
+
+func (c *collector) add(field, format string, args ...interface{}) {
+	c.errs = append(c.errs, &ValidationError{Title: c.title, Field: field, Err: errors.Errorf(format, args...)})
+}
+
+func validateCompany(c Company, errs *Errors) {
+	v := collector{title: -1}
+	if strings.TrimSpace(c.Name) == "" {
+		v.add("Name", "empty")
+	}
+	if docType(c.Document) == 0 {
+		v.add("Document", "invalid CPF or CNPJ %q", c.Document)
+	}
+	if err := c.Account.Validate(); err != nil && errors.Cause(err) != bank.ErrUnknownBank {
+		v.add("Account", "%v", err)
+	}
+	*errs = append(*errs, v.errs...)
+}
+
+func validateTitle(i int, t Title, errs *Errors) {
+	v := collector{title: i}
+	if br.Digits(t.OurNumber) != t.OurNumber || t.OurNumber == "" {
+		v.add("OurNumber", "must be digits, got %q", t.OurNumber)
+	}
+	if !t.Amount.IsPositive() || t.Amount.Currency() != "BRL" {
+		v.add("Amount", "must be a positive BRL amount, got %s", t.Amount)
+	}
+	if t.DueDate.IsZero() {
+		v.add("DueDate", "empty")
+	} else if t.DueDate.Before(t.IssueDate) {
+		v.add("DueDate", "before the issue date")
+	}
+	if strings.TrimSpace(t.Payer.Name) == "" {
+		v.add("Payer.Name", "empty")
+	}
+	if docType(t.Payer.Document) == 0 {
+		v.add("Payer.Document", "invalid CPF or CNPJ %q", t.Payer.Document)
+	}
+	if !br.ValidCEP(t.Payer.CEP) {
+		v.add("Payer.CEP", "invalid CEP %q", t.Payer.CEP)
+	}
+	if len(strings.TrimSpace(t.Payer.State)) != 2 {
+		v.add("Payer.State", "must be a two-letter UF, got %q", t.Payer.State)
+	}
+	*errs = append(*errs, v.errs...)
+}
+
+// docType returns the CNAB document type: 1 for CPF, 2 for CNPJ and 0
+// for an invalid document.
+func docType(doc string) int {
+	switch {
+	case br.ValidCPF(doc):
+		return 1
+	case br.ValidCNPJ(doc):
+		return 2
+	}
+	return 0
+}
+
+// clean makes s fit the CNAB character set: upper-case ASCII.
+func clean(s string) string {
+	return strings.ToUpper(strings.TrimSpace(text.StripAccents(s)))
+}
+
+var occurrences = map[int]string{
+	2:  "Entrada confirmada",
+	3:  "Entrada rejeitada",
+	6:  "Liquidação",
+	9:  "Baixa",
+	10: "Baixa conforme instrução",
+	12: "Abatimento concedido",
+	13: "Abatimento cancelado",
+	14: "Vencimento alterado",
+	15: "Liquidação em cartório",
+	17: "Liquidação após baixa",
+	19: "Confirmação de instrução de protesto",
+	23: "Remessa a cartório",
+	25: "Protestado e baixado",
+	28: "Débito de tarifas",
+}
+
+// Describe returns the common meaning of a retorno occurrence code, or
+// an empty string for codes specific to a bank.
+func Describe(occurrence int) string {
+	return occurrences[occurrence]
+}
@@ -0,0 +1,181 @@
+package cnab
+
+import "time"
+
+// Records of the FEBRABAN CNAB 240 layout for boleto collection
+// (cobrança). Banks follow it with small variations, mostly in the
+// convênio and reserved fields; check the bank's manual. Amounts are in
+// centavos.
+
+// Control opens every CNAB 240 record: bank, lot (0000 for the file
+// header, 9999 for the file trailer) and record type.
+type Control struct {
+	Bank   string `fw:"1,3,num"`
+	Lot    int    `fw:"4,4"`
+	Record int    `fw:"8,1"`
+}
+
+// FileHeader240 is record type 0.
+type FileHeader240 struct {
+	Control
+	CompanyDocType     int       `fw:"18,1"`
+	CompanyDoc         string    `fw:"19,14,num"`
+	Agreement          string    `fw:"33,20"`
+	Agency             string    `fw:"53,5,num"`
+	AgencyDigit        string    `fw:"58,1"`
+	Account            string    `fw:"59,12,num"`
+	AccountDigit       string    `fw:"71,1"`
+	AgencyAccountDigit string    `fw:"72,1"`
+	CompanyName        string    `fw:"73,30,truncate"`
+	BankName           string    `fw:"103,30,truncate"`
+	Direction          int       `fw:"143,1"`
+	GeneratedOn        time.Time `fw:"144,8"`
+	GeneratedAt        string    `fw:"152,6,num"`
+	Sequence           int       `fw:"158,6"`
+	Version            string    `fw:"164,3,num"`
+	Density            int       `fw:"167,5"`
+	BankReserved       string    `fw:"172,20"`
+	CompanyReserved    string    `fw:"192,20"`
+}
+
+// LotHeader240 is record type 1.
+type LotHeader240 struct {
+	Control
+	Operation          string    `fw:"9,1"`
+	Service            int       `fw:"10,2"`
+	Version            string    `fw:"14,3,num"`
+	CompanyDocType     int       `fw:"18,1"`
+	CompanyDoc         string    `fw:"19,15,num"`
+	Agreement          string    `fw:"34,20"`
+	Agency             string    `fw:"54,5,num"`
+	AgencyDigit        string    `fw:"59,1"`
+	Account            string    `fw:"60,12,num"`
+	AccountDigit       string    `fw:"72,1"`
+	AgencyAccountDigit string    `fw:"73,1"`
+	CompanyName        string    `fw:"74,30,truncate"`
+	Message1           string    `fw:"104,40,truncate"`
+	Message2           string    `fw:"144,40,truncate"`
+	Sequence           int       `fw:"184,8"`
+	RecordedOn         time.Time `fw:"192,8"`
+	CreditOn           time.Time `fw:"200,8"`
+}
+
+// Detail opens every detail (type 3) record: sequence within the lot,
+// segment letter and movement (remessa) or occurrence (retorno) code.
+type Detail struct {
+	Control
+	Number   int    `fw:"9,5"`
+	Segment  string `fw:"14,1"`
+	Movement int    `fw:"16,2"`
+}
+
+// SegmentP carries the title of a remessa.
+type SegmentP struct {
+	Detail
+	Agency             string    `fw:"18,5,num"`
+	AgencyDigit        string    `fw:"23,1"`
+	Account            string    `fw:"24,12,num"`
+	AccountDigit       string    `fw:"36,1"`
+	AgencyAccountDigit string    `fw:"37,1"`
+	OurNumber          string    `fw:"38,20"`
+	Wallet             string    `fw:"58,1"`
+	Registration       int       `fw:"59,1"`
+	DocumentType       string    `fw:"60,1"`
+	Issuer             int       `fw:"61,1"`
+	Distribution       string    `fw:"62,1"`
+	DocumentNumber     string    `fw:"63,15,truncate"`
+	DueDate            time.Time `fw:"78,8"`
+	Amount             int64     `fw:"86,15"`
+	CollectingAgency   string    `fw:"101,5,num"`
+	CollectingDigit    string    `fw:"106,1"`
+	Kind               int       `fw:"107,2"`
+	Accepted           string    `fw:"109,1"`
+	IssueDate          time.Time `fw:"110,8"`
+	InterestCode       int       `fw:"118,1"`
+	InterestFrom       time.Time `fw:"119,8"`
+	Interest           int64     `fw:"127,15"`
+	DiscountCode       int       `fw:"142,1"`
+	DiscountUntil      time.Time `fw:"143,8"`
+	Discount           int64     `fw:"151,15"`
+	IOF                int64     `fw:"166,15"`
+	Rebate             int64     `fw:"181,15"`
+	CompanyReference   string    `fw:"196,25,truncate"`
+	ProtestCode        int       `fw:"221,1"`
+	ProtestDays        int       `fw:"222,2"`
+	WriteOffCode       int       `fw:"224,1"`
+	WriteOffDays       string    `fw:"225,3,num"`
+	Currency           int       `fw:"228,2"`
+	Contract           string    `fw:"230,10,num"`
+}
+
+// SegmentQ carries the payer of a remessa title.
+type SegmentQ struct {
+	Detail
+	PayerDocType     int    `fw:"18,1"`
+	PayerDoc         string `fw:"19,15,num"`
+	PayerName        string `fw:"34,40,truncate"`
+	Address          string `fw:"74,40,truncate"`
+	District         string `fw:"114,15,truncate"`
+	CEP              string `fw:"129,8,num"`
+	City             string `fw:"137,15,truncate"`
+	State            string `fw:"152,2"`
+	GuarantorDocType int    `fw:"154,1"`
+	GuarantorDoc     string `fw:"155,15,num"`
+	GuarantorName    string `fw:"170,40,truncate"`
+}
+
+// SegmentT carries a title in a retorno.
+type SegmentT struct {
+	Detail
+	Agency           string    `fw:"18,5,num"`
+	AgencyDigit      string    `fw:"23,1"`
+	Account          string    `fw:"24,12,num"`
+	AccountDigit     string    `fw:"36,1"`
+	OurNumber        string    `fw:"38,20"`
+	Wallet           string    `fw:"58,1"`
+	DocumentNumber   string    `fw:"59,15"`
+	DueDate          time.Time `fw:"74,8"`
+	Amount           int64     `fw:"82,15"`
+	CollectingBank   string    `fw:"97,3,num"`
+	CollectingAgency string    `fw:"100,5,num"`
+	CompanyReference string    `fw:"106,25"`
+	Currency         int       `fw:"131,2"`
+	PayerDocType     int       `fw:"133,1"`
+	PayerDoc         string    `fw:"134,15,num"`
+	PayerName        string    `fw:"149,40"`
+	Contract         string    `fw:"189,10,num"`
+	Fee              int64     `fw:"199,15"`
+	Reasons          string    `fw:"214,10"`
+}
+
+// SegmentU carries the amounts and dates of a retorno title.
+type SegmentU struct {
+	Detail
+	Charges    int64     `fw:"18,15"`
+	Discount   int64     `fw:"33,15"`
+	Rebate     int64     `fw:"48,15"`
+	IOF        int64     `fw:"63,15"`
+	Paid       int64     `fw:"78,15"`
+	Net        int64     `fw:"93,15"`
+	Expenses   int64     `fw:"108,15"`
+	Credits    int64     `fw:"123,15"`
+	OccurredOn time.Time `fw:"138,8"`
+	CreditOn   time.Time `fw:"146,8"`
+}
+
+// LotTrailer240 is record type 5.
+type LotTrailer240 struct {
+	Control
+	Records      int    `fw:"18,6"`
+	SimpleCount  int    `fw:"24,6"`
+	SimpleAmount int64  `fw:"30,17"`
+	Notice       string `fw:"116,8"`
+}
+
+// FileTrailer240 is record type 9.
+type FileTrailer240 struct {
+	Control
+	Lots     int `fw:"18,6"`
+	Records  int `fw:"24,6"`
+	Accounts int `fw:"30,6"`
+}
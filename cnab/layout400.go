@@ -0,0 +1,116 @@
+package cnab
+
+import "time"
+
+// Records of Bradesco's CNAB 400 collection layout. Dates are DDMMAA and
+// amounts are in centavos.
+
+// Header400 is the first record of a remessa (Direction 1) or retorno
+// (Direction 2).
+type Header400 struct {
+	Record      int       `fw:"1,1"`
+	Direction   int       `fw:"2,1"`
+	Literal     string    `fw:"3,7"`
+	Service     int       `fw:"10,2"`
+	ServiceName string    `fw:"12,15"`
+	CompanyCode string    `fw:"27,20,num"`
+	CompanyName string    `fw:"47,30,truncate"`
+	Bank        string    `fw:"77,3,num"`
+	BankName    string    `fw:"80,15"`
+	GeneratedOn time.Time `fw:"95,6,date=020106"`
+	System      string    `fw:"109,2"`
+	Sequence    int       `fw:"111,7"`
+	Number      int       `fw:"395,6"`
+}
+
+// Detail400 is a title of a remessa, record type 1.
+type Detail400 struct {
+	Record           int       `fw:"1,1"`
+	DebitAgency      string    `fw:"2,5,num"`
+	DebitAgencyDigit string    `fw:"7,1"`
+	DebitBranch      string    `fw:"8,5,num"`
+	DebitAccount     string    `fw:"13,7,num"`
+	DebitDigit       string    `fw:"20,1"`
+	CompanyID        string    `fw:"21,17,num"`
+	CompanyReference string    `fw:"38,25,truncate"`
+	DebitBank        string    `fw:"63,3,num"`
+	FineCode         int       `fw:"66,1"`
+	FinePercent      int       `fw:"67,4"`
+	OurNumber        string    `fw:"71,11,num"`
+	OurNumberDigit   string    `fw:"82,1"`
+	DailyDiscount    int64     `fw:"83,10"`
+	IssueCondition   int       `fw:"93,1"`
+	DebitNotice      string    `fw:"94,1"`
+	Occurrence       int       `fw:"109,2"`
+	DocumentNumber   string    `fw:"111,10,truncate"`
+	DueDate          time.Time `fw:"121,6,date=020106"`
+	Amount           int64     `fw:"127,13"`
+	CollectingBank   string    `fw:"140,3,num"`
+	CollectingAgency string    `fw:"143,5,num"`
+	Kind             int       `fw:"148,2"`
+	Accepted         string    `fw:"150,1"`
+	IssueDate        time.Time `fw:"151,6,date=020106"`
+	Instruction1     int       `fw:"157,2"`
+	Instruction2     int       `fw:"159,2"`
+	Interest         int64     `fw:"161,13"`
+	DiscountUntil    time.Time `fw:"174,6,date=020106"`
+	Discount         int64     `fw:"180,13"`
+	IOF              int64     `fw:"193,13"`
+	Rebate           int64     `fw:"206,13"`
+	PayerDocType     int       `fw:"219,2"`
+	PayerDoc         string    `fw:"221,14,num"`
+	PayerName        string    `fw:"235,40,truncate"`
+	Address          string    `fw:"275,40,truncate"`
+	Message1         string    `fw:"315,12,truncate"`
+	CEP              string    `fw:"327,8,num"`
+	Message2         string    `fw:"335,60,truncate"`
+	Number           int       `fw:"395,6"`
+}
+
+// ReturnDetail400 is a title of a retorno, record type 1.
+type ReturnDetail400 struct {
+	Record           int       `fw:"1,1"`
+	CompanyDocType   int       `fw:"2,2"`
+	CompanyDoc       string    `fw:"4,14,num"`
+	CompanyID        string    `fw:"21,17,num"`
+	CompanyReference string    `fw:"38,25"`
+	OurNumber        string    `fw:"71,12,num"`
+	Wallet           string    `fw:"108,1"`
+	Occurrence       int       `fw:"109,2"`
+	OccurredOn       time.Time `fw:"111,6,date=020106"`
+	DocumentNumber   string    `fw:"117,10"`
+	DueDate          time.Time `fw:"147,6,date=020106"`
+	Amount           int64     `fw:"153,13"`
+	CollectingBank   string    `fw:"166,3,num"`
+	CollectingAgency string    `fw:"169,5,num"`
+	Kind             string    `fw:"174,2"`
+	Fee              int64     `fw:"176,13"`
+	Expenses         int64     `fw:"189,13"`
+	LateInterest     int64     `fw:"202,13"`
+	IOF              int64     `fw:"215,13"`
+	Rebate           int64     `fw:"228,13"`
+	Discount         int64     `fw:"241,13"`
+	Paid             int64     `fw:"254,13"`
+	Charges          int64     `fw:"267,13"`
+	Credits          int64     `fw:"280,13"`
+	CreditOn         time.Time `fw:"296,6,date=020106"`
+	Reasons          string    `fw:"319,10"`
+	Number           int       `fw:"395,6"`
+}
+
+// Trailer400 closes a retorno with the count and total of its titles.
+type Trailer400 struct {
+	Record    int    `fw:"1,1"`
+	Direction string `fw:"2,1"`
+	Service   string `fw:"3,2"`
+	Bank      string `fw:"5,3"`
+	Count     int    `fw:"18,8"`
+	Total     int64  `fw:"26,14"`
+	Number    int    `fw:"395,6"`
+}
+
+// remessaTrailer400 closes a remessa; positions 2 to 394 are blank.
+type remessaTrailer400 struct {
+	Record int `fw:"1,1"`
+	Number int `fw:"395,6"`
+}
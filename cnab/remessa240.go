@@ -0,0 +1,235 @@
+package cnab
+
+import (
+	"io"
+	"strings"
+	"time"
+	"utils/br"
+	"utils/clock"
+	"utils/fixedwidth"
+
+	"github.com/pkg/errors"
+)
+
+// Remessa240 builds a CNAB 240 collection file with one lot of titles,
+// each written as a P and a Q segment.
+type Remessa240 struct {
+	company     Company
+	sequence    int
+	fileVersion string
+	lotVersion  string
+	message     string
+	clock       clock.Clock
+	titles      []Title
+}
+
+// NewRemessa240 uses the versions registered for the company's bank.
+func NewRemessa240(company Company) *Remessa240 {
+	r := &Remessa240{company: company, sequence: 1}
+	if b, ok := lookupBank(company.Account.Bank); ok {
+		r.fileVersion, r.lotVersion = b.FileVersion, b.LotVersion
+	}
+	return r
+}
+
+// Sequence sets the file number (NSA), which banks require to increase
+// with every file sent.
+func (r *Remessa240) Sequence(n int) *Remessa240 {
+	r.sequence = n
+	return r
+}
+
+// Versions overrides the layout versions of the file and lot headers.
+func (r *Remessa240) Versions(file, lot string) *Remessa240 {
+	r.fileVersion, r.lotVersion = file, lot
+	return r
+}
+
+// Message sets the text printed on every boleto of the lot.
+func (r *Remessa240) Message(message string) *Remessa240 {
+	r.message = message
+	return r
+}
+
+func (r *Remessa240) Clock(c clock.Clock) *Remessa240 {
+	r.clock = c
+	return r
+}
+
+func (r *Remessa240) Add(titles ...Title) *Remessa240 {
+	r.titles = append(r.titles, titles...)
+	return r
+}
+
+func (r *Remessa240) Len() int {
+	return len(r.titles)
+}
+
+// Validate returns Errors with a *ValidationError for every invalid
+// field of the company and the titles.
+func (r *Remessa240) Validate() error {
+	var errs Errors
+	if _, ok := lookupBank(r.company.Account.Bank); !ok {
+		errs = append(errs, &ValidationError{Title: -1, Field: "Account.Bank", Err: errors.Wrap(ErrUnsupportedBank, r.company.Account.Bank)})
+	}
+	validateCompany(r.company, &errs)
+	if len(r.company.Wallet) > 1 {
+		errs = append(errs, &ValidationError{Title: -1, Field: "Wallet", Err: errors.Errorf("CNAB 240 wallet is a single position, got %q", r.company.Wallet)})
+	}
+	if r.sequence < 1 {
+		errs = append(errs, &ValidationError{Title: -1, Field: "Sequence", Err: errors.New("must be positive")})
+	}
+	if len(r.titles) == 0 {
+		errs = append(errs, &ValidationError{Title: -1, Field: "Titles", Err: errors.New("no titles")})
+	}
+	for i, t := range r.titles {
+		validateTitle(i, t, &errs)
+		if len(t.OurNumber) > 20 {
+			errs = append(errs, &ValidationError{Title: i, Field: "OurNumber", Err: errors.New("longer than 20 digits")})
+		}
+	}
+	return errs.orNil()
+}
+
+// Write validates the remessa and writes it to w.
+func (r *Remessa240) Write(w io.Writer) error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+
+	out := fixedwidth.NewWriter(w).Width(240)
+	for _, record := range r.records(clock.Or(r.clock).Now()) {
+		if err := out.Encode(record); err != nil {
+			return err
+		}
+	}
+	return out.Flush()
+}
+
+func (r *Remessa240) records(now time.Time) []interface{} {
+	c := r.company
+	code := c.Account.Bank
+	b, _ := lookupBank(code)
+	doc := br.Digits(c.Document)
+
+	records := make([]interface{}, 0, len(r.titles)*2+4)
+	records = append(records, &FileHeader240{
+		Control:        Control{Bank: code, Lot: 0, Record: 0},
+		CompanyDocType: docType(doc),
+		CompanyDoc:     doc,
+		Agreement:      c.Agreement,
+		Agency:         c.Account.Agency,
+		AgencyDigit:    c.Account.AgencyDigit,
+		Account:        c.Account.Number,
+		AccountDigit:   c.Account.NumberDigit,
+		CompanyName:    clean(c.Name),
+		BankName:       b.Name,
+		Direction:      1,
+		GeneratedOn:    now,
+		GeneratedAt:    now.Format("150405"),
+		Sequence:       r.sequence,
+		Version:        r.fileVersion,
+	}, &LotHeader240{
+		Control:        Control{Bank: code, Lot: 1, Record: 1},
+		Operation:      "R",
+		Service:        1,
+		Version:        r.lotVersion,
+		CompanyDocType: docType(doc),
+		CompanyDoc:     doc,
+		Agreement:      c.Agreement,
+		Agency:         c.Account.Agency,
+		AgencyDigit:    c.Account.AgencyDigit,
+		Account:        c.Account.Number,
+		AccountDigit:   c.Account.NumberDigit,
+		CompanyName:    clean(c.Name),
+		Message1:       clean(r.message),
+		Sequence:       r.sequence,
+		RecordedOn:     now,
+	})
+
+	wallet := c.Wallet
+	if wallet == "" {
+		wallet = "1"
+	}
+	var total int64
+	for i, t := range r.titles {
+		total += t.Amount.Amount()
+		records = append(records, r.segmentP(2*i+1, wallet, t), r.segmentQ(2*i+2, t))
+	}
+
+	records = append(records, &LotTrailer240{
+		Control:      Control{Bank: code, Lot: 1, Record: 5},
+		Records:      len(r.titles)*2 + 2,
+		SimpleCount:  len(r.titles),
+		SimpleAmount: total,
+	}, &FileTrailer240{
+		Control: Control{Bank: code, Lot: 9999, Record: 9},
+		Lots:    1,
+		Records: len(r.titles)*2 + 4,
+	})
+	return records
+}
+
+func (r *Remessa240) segmentP(number int, wallet string, t Title) *SegmentP {
+	c := r.company
+	p := &SegmentP{
+		Detail:           Detail{Control: Control{Bank: c.Account.Bank, Lot: 1, Record: 3}, Number: number, Segment: "P", Movement: t.movement()},
+		Agency:           c.Account.Agency,
+		AgencyDigit:      c.Account.AgencyDigit,
+		Account:          c.Account.Number,
+		AccountDigit:     c.Account.NumberDigit,
+		OurNumber:        t.OurNumber,
+		Wallet:           wallet,
+		Registration:     1,
+		DocumentType:     "1",
+		Issuer:           2,
+		Distribution:     "2",
+		DocumentNumber:   clean(t.DocumentNumber),
+		DueDate:          t.DueDate,
+		Amount:           t.Amount.Amount(),
+		Kind:             t.Kind,
+		Accepted:         "N",
+		IssueDate:        t.IssueDate,
+		InterestCode:     3,
+		DiscountCode:     0,
+		CompanyReference: clean(t.Reference),
+		ProtestCode:      3,
+		Currency:         9,
+	}
+	if p.IssueDate.IsZero() {
+		p.IssueDate = clock.Or(r.clock).Now()
+	}
+	if t.InterestPerDay.IsPositive() {
+		p.InterestCode = 1
+		p.InterestFrom = t.DueDate.AddDate(0, 0, 1)
+		p.Interest = t.InterestPerDay.Amount()
+	}
+	if t.Discount.IsPositive() {
+		p.DiscountCode = 1
+		p.DiscountUntil = t.DiscountUntil
+		if p.DiscountUntil.IsZero() {
+			p.DiscountUntil = t.DueDate
+		}
+		p.Discount = t.Discount.Amount()
+	}
+	if t.ProtestDays > 0 {
+		p.ProtestCode = 1
+		p.ProtestDays = t.ProtestDays
+	}
+	return p
+}
+
+func (r *Remessa240) segmentQ(number int, t Title) *SegmentQ {
+	doc := br.Digits(t.Payer.Document)
+	return &SegmentQ{
+		Detail:       Detail{Control: Control{Bank: r.company.Account.Bank, Lot: 1, Record: 3}, Number: number, Segment: "Q", Movement: t.movement()},
+		PayerDocType: docType(doc),
+		PayerDoc:     doc,
+		PayerName:    clean(t.Payer.Name),
+		Address:      clean(t.Payer.Address),
+		District:     clean(t.Payer.District),
+		CEP:          br.Digits(t.Payer.CEP),
+		City:         clean(t.Payer.City),
+		State:        strings.ToUpper(strings.TrimSpace(t.Payer.State)),
+	}
+}
@@ -0,0 +1,187 @@
+package cnab
+
+import (
+	"io"
+	"strconv"
+	"time"
+	"utils/br"
+	"utils/clock"
+	"utils/fixedwidth"
+	"utils/text"
+
+	"github.com/pkg/errors"
+)
+
+// Remessa400 builds a CNAB 400 collection file in Bradesco's layout, the
+// only 400-column layout supported.
+type Remessa400 struct {
+	company  Company
+	sequence int
+	clock    clock.Clock
+	titles   []Title
+}
+
+func NewRemessa400(company Company) *Remessa400 {
+	return &Remessa400{company: company, sequence: 1}
+}
+
+// Sequence sets the remessa number, which must increase with every file
+// sent.
+func (r *Remessa400) Sequence(n int) *Remessa400 {
+	r.sequence = n
+	return r
+}
+
+func (r *Remessa400) Clock(c clock.Clock) *Remessa400 {
+	r.clock = c
+	return r
+}
+
+func (r *Remessa400) Add(titles ...Title) *Remessa400 {
+	r.titles = append(r.titles, titles...)
+	return r
+}
+
+func (r *Remessa400) Len() int {
+	return len(r.titles)
+}
+
+// Validate returns Errors with a *ValidationError for every invalid
+// field of the company and the titles.
+func (r *Remessa400) Validate() error {
+	var errs Errors
+	c := r.company
+	if c.Account.Bank != "237" {
+		errs = append(errs, &ValidationError{Title: -1, Field: "Account.Bank", Err: errors.Wrap(ErrUnsupportedBank, c.Account.Bank)})
+	}
+	validateCompany(c, &errs)
+	if len(br.Digits(c.Agreement)) == 0 || len(c.Agreement) > 20 {
+		errs = append(errs, &ValidationError{Title: -1, Field: "Agreement", Err: errors.Errorf("invalid company code %q", c.Agreement)})
+	}
+	if len(br.Digits(c.Wallet)) == 0 || len(c.Wallet) > 3 {
+		errs = append(errs, &ValidationError{Title: -1, Field: "Wallet", Err: errors.Errorf("invalid wallet %q", c.Wallet)})
+	}
+	if r.sequence < 1 {
+		errs = append(errs, &ValidationError{Title: -1, Field: "Sequence", Err: errors.New("must be positive")})
+	}
+	if len(r.titles) == 0 {
+		errs = append(errs, &ValidationError{Title: -1, Field: "Titles", Err: errors.New("no titles")})
+	}
+	for i, t := range r.titles {
+		validateTitle(i, t, &errs)
+		if len(t.OurNumber) > 11 {
+			errs = append(errs, &ValidationError{Title: i, Field: "OurNumber", Err: errors.New("longer than 11 digits")})
+		}
+	}
+	return errs.orNil()
+}
+
+// Write validates the remessa and writes it to w.
+func (r *Remessa400) Write(w io.Writer) error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+
+	out := fixedwidth.NewWriter(w).Width(400)
+	for _, record := range r.records(clock.Or(r.clock).Now()) {
+		if err := out.Encode(record); err != nil {
+			return err
+		}
+	}
+	return out.Flush()
+}
+
+func (r *Remessa400) records(now time.Time) []interface{} {
+	c := r.company
+	records := make([]interface{}, 0, len(r.titles)+2)
+	records = append(records, &Header400{
+		Record:      0,
+		Direction:   1,
+		Literal:     "REMESSA",
+		Service:     1,
+		ServiceName: "COBRANCA",
+		CompanyCode: br.Digits(c.Agreement),
+		CompanyName: clean(c.Name),
+		Bank:        "237",
+		BankName:    "BRADESCO",
+		GeneratedOn: now,
+		System:      "MX",
+		Sequence:    r.sequence,
+		Number:      1,
+	})
+
+	wallet := text.PadLeft(br.Digits(c.Wallet), 3, '0')
+	companyID := "0" + wallet + text.PadLeft(br.Digits(c.Account.Agency), 5, '0') +
+		text.PadLeft(br.Digits(c.Account.Number), 7, '0') + c.Account.NumberDigit
+	for i, t := range r.titles {
+		records = append(records, r.detail(i+2, wallet, companyID, t, now))
+	}
+
+	records = append(records, &remessaTrailer400{Record: 9, Number: len(r.titles) + 2})
+	return records
+}
+
+func (r *Remessa400) detail(number int, wallet, companyID string, t Title, now time.Time) *Detail400 {
+	doc := br.Digits(t.Payer.Document)
+	d := &Detail400{
+		Record:           1,
+		CompanyID:        companyID,
+		CompanyReference: clean(t.Reference),
+		OurNumber:        t.OurNumber,
+		OurNumberDigit:   BradescoDigit(wallet, t.OurNumber),
+		IssueCondition:   2,
+		DebitNotice:      "N",
+		Occurrence:       t.movement(),
+		DocumentNumber:   clean(t.DocumentNumber),
+		DueDate:          t.DueDate,
+		Amount:           t.Amount.Amount(),
+		Kind:             t.Kind,
+		Accepted:         "N",
+		IssueDate:        t.IssueDate,
+		Interest:         t.InterestPerDay.Amount(),
+		Discount:         t.Discount.Amount(),
+		PayerDocType:     docType(doc),
+		PayerDoc:         doc,
+		PayerName:        clean(t.Payer.Name),
+		Address:          clean(t.Payer.Address),
+		CEP:              br.Digits(t.Payer.CEP),
+		Number:           number,
+	}
+	if d.IssueDate.IsZero() {
+		d.IssueDate = now
+	}
+	if t.Discount.IsPositive() {
+		d.DiscountUntil = t.DiscountUntil
+		if d.DiscountUntil.IsZero() {
+			d.DiscountUntil = t.DueDate
+		}
+	}
+	if t.ProtestDays > 0 {
+		d.Instruction1 = 6
+		d.Instruction2 = t.ProtestDays
+	}
+	return d
+}
+
+// BradescoDigit returns the check digit of a Bradesco nosso número: the
+// wallet and the 11-digit number weighted 2 to 7 from the right, modulo
+// 11, with "P" for a remainder of 1.
+func BradescoDigit(wallet, ourNumber string) string {
+	s := text.PadLeft(br.Digits(wallet), 2, '0') + text.PadLeft(br.Digits(ourNumber), 11, '0')
+	s = s[len(s)-13:]
+	sum, weight := 0, 2
+	for i := len(s) - 1; i >= 0; i-- {
+		sum += int(s[i]-'0') * weight
+		if weight++; weight > 7 {
+			weight = 2
+		}
+	}
+	switch rest := sum % 11; rest {
+	case 0:
+		return "0"
+	case 1:
+		return "P"
+	default:
+		return strconv.Itoa(11 - rest)
+	}
+}
@@ -0,0 +1,272 @@
+package cnab
+
+import (
+	"io"
+	"time"
+	"unicode/utf8"
+	"utils/fixedwidth"
+	"utils/money"
+
+	"github.com/pkg/errors"
+)
+
+// Event is one occurrence reported for a title in a retorno, such as an
+// accepted registration or a payment.
+type Event struct {
+	Line           int
+	Occurrence     int
+	OurNumber      string
+	DocumentNumber string
+	Reference      string
+	DueDate        time.Time
+	OccurredOn     time.Time
+	CreditOn       time.Time
+	Amount         money.Money
+	Paid           money.Money
+	Charges        money.Money
+	Discount       money.Money
+	Rebate         money.Money
+	Fee            money.Money
+	Net            money.Money
+	// Reasons holds the bank's rejection or settlement reason codes.
+	Reasons   string
+	PayerName string
+	PayerDoc  string
+}
+
+// Description returns the common meaning of the occurrence code.
+func (e Event) Description() string {
+	return Describe(e.Occurrence)
+}
+
+// Settled reports whether the event settles the title.
+func (e Event) Settled() bool {
+	switch e.Occurrence {
+	case 6, 15, 17:
+		return true
+	}
+	return false
+}
+
+// Retorno is a parsed retorno file.
+type Retorno struct {
+	Bank        string
+	CompanyName string
+	Sequence    int
+	GeneratedOn time.Time
+	Events      []Event
+}
+
+// ParseRetorno240 reads a CNAB 240 retorno. Parsing goes on past bad
+// records, so the returned Retorno holds every event that could be read;
+// the error is then Errors, with a *fixedwidth.FieldError or *LineError
+// for each problem.
+func ParseRetorno240(r io.Reader) (*Retorno, error) {
+	p := parser{width: 240}
+	ret := &Retorno{}
+	in := fixedwidth.NewReader(r)
+
+	var (
+		pending     *Event
+		pendingLine int
+		lotRecords  int
+		records     int
+	)
+	flush := func() {
+		if pending != nil {
+			p.lineError(pendingLine, errors.New("segment T without segment U"))
+			ret.Events = append(ret.Events, *pending)
+			pending = nil
+		}
+	}
+
+	for in.Scan() {
+		line := in.Text()
+		records++
+		if !p.checkWidth(in.Line(), line, 14) {
+			continue
+		}
+
+		switch kind := line[7]; kind {
+		case '0':
+			var h FileHeader240
+			if p.decode(in, &h) {
+				ret.Bank, ret.CompanyName = h.Bank, h.CompanyName
+				ret.Sequence, ret.GeneratedOn = h.Sequence, h.GeneratedOn
+			}
+		case '1':
+			lotRecords = 1
+		case '3':
+			lotRecords++
+			switch segment := line[13]; segment {
+			case 'T':
+				flush()
+				var t SegmentT
+				if p.decode(in, &t) {
+					e := eventFromT(in.Line(), t)
+					pending, pendingLine = &e, in.Line()
+				}
+			case 'U':
+				var u SegmentU
+				if !p.decode(in, &u) {
+					pending = nil
+					continue
+				}
+				if pending == nil {
+					p.lineError(in.Line(), errors.New("segment U without segment T"))
+					continue
+				}
+				applyU(pending, u)
+				ret.Events = append(ret.Events, *pending)
+				pending = nil
+			default:
+				// other segments, such as Y, carry optional data
+			}
+		case '5':
+			flush()
+			lotRecords++
+			var t LotTrailer240
+			if p.decode(in, &t) && t.Records != lotRecords {
+				p.lineError(in.Line(), errors.Errorf("lot trailer counts %d records, found %d", t.Records, lotRecords))
+			}
+		case '9':
+			flush()
+			var t FileTrailer240
+			if p.decode(in, &t) && t.Records != records {
+				p.lineError(in.Line(), errors.Errorf("file trailer counts %d records, found %d", t.Records, records))
+			}
+		default:
+			p.lineError(in.Line(), errors.Errorf("unknown record type %q", kind))
+		}
+	}
+	flush()
+	if err := in.Err(); err != nil {
+		return ret, err
+	}
+	return ret, p.errs.orNil()
+}
+
+// ParseRetorno400 reads a retorno in Bradesco's CNAB 400 layout, with
+// the same error handling as ParseRetorno240. Records must be numbered
+// in order; the trailer totals refer to the whole wallet and are not
+// checked against the file.
+func ParseRetorno400(r io.Reader) (*Retorno, error) {
+	p := parser{width: 400}
+	ret := &Retorno{}
+	in := fixedwidth.NewReader(r)
+
+	records := 0
+	sequence := func(number int) {
+		if number != records {
+			p.lineError(in.Line(), errors.Errorf("record number %d, expected %d", number, records))
+		}
+	}
+	for in.Scan() {
+		line := in.Text()
+		records++
+		if !p.checkWidth(in.Line(), line, 1) {
+			continue
+		}
+
+		switch kind := line[0]; kind {
+		case '0':
+			var h Header400
+			if p.decode(in, &h) {
+				ret.Bank, ret.CompanyName = h.Bank, h.CompanyName
+				ret.Sequence, ret.GeneratedOn = h.Sequence, h.GeneratedOn
+				sequence(h.Number)
+			}
+		case '1':
+			var d ReturnDetail400
+			if !p.decode(in, &d) {
+				continue
+			}
+			sequence(d.Number)
+			ret.Events = append(ret.Events, Event{
+				Line:           in.Line(),
+				Occurrence:     d.Occurrence,
+				OurNumber:      d.OurNumber,
+				DocumentNumber: d.DocumentNumber,
+				Reference:      d.CompanyReference,
+				DueDate:        d.DueDate,
+				OccurredOn:     d.OccurredOn,
+				CreditOn:       d.CreditOn,
+				Amount:         money.BRL(d.Amount),
+				Paid:           money.BRL(d.Paid),
+				Charges:        money.BRL(d.Charges),
+				Discount:       money.BRL(d.Discount),
+				Rebate:         money.BRL(d.Rebate),
+				Fee:            money.BRL(d.Fee),
+				Net:            money.BRL(d.Paid - d.Fee),
+				Reasons:        d.Reasons,
+			})
+		case '9':
+			var t Trailer400
+			if p.decode(in, &t) {
+				sequence(t.Number)
+			}
+		default:
+			p.lineError(in.Line(), errors.Errorf("unknown record type %q", kind))
+		}
+	}
+	if err := in.Err(); err != nil {
+		return ret, err
+	}
+	return ret, p.errs.orNil()
+}
+
+func eventFromT(line int, t SegmentT) Event {
+	return Event{
+		Line:           line,
+		Occurrence:     t.Movement,
+		OurNumber:      t.OurNumber,
+		DocumentNumber: t.DocumentNumber,
+		Reference:      t.CompanyReference,
+		DueDate:        t.DueDate,
+		Amount:         money.BRL(t.Amount),
+		Fee:            money.BRL(t.Fee),
+		Reasons:        t.Reasons,
+		PayerName:      t.PayerName,
+		PayerDoc:       t.PayerDoc,
+	}
+}
+
+func applyU(e *Event, u SegmentU) {
+	e.Charges = money.BRL(u.Charges)
+	e.Discount = money.BRL(u.Discount)
+	e.Rebate = money.BRL(u.Rebate)
+	e.Paid = money.BRL(u.Paid)
+	e.Net = money.BRL(u.Net)
+	e.OccurredOn = u.OccurredOn
+	e.CreditOn = u.CreditOn
+}
+
+// parser collects the errors of a retorno.
+type parser struct {
+	width int
+	errs  Errors
+}
+
+func (p *parser) lineError(line int, err error) {
+	p.errs = append(p.errs, &LineError{Line: line, Err: err})
+}
+
+// checkWidth rejects lines longer than the layout or too short to hold
+// the record type. Shorter lines are accepted, as some banks strip the
+// trailing blanks.
+func (p *parser) checkWidth(line int, text string, min int) bool {
+	n := utf8.RuneCountInString(text)
+	if n > p.width || n < min {
+		p.lineError(line, errors.Errorf("expected %d positions, found %d", p.width, n))
+		return false
+	}
+	return true
+}
+
+func (p *parser) decode(in *fixedwidth.Reader, dst interface{}) bool {
+	if err := in.Unmarshal(dst); err != nil {
+		p.errs = append(p.errs, err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,121 @@
+package compress
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// Level is a codec-independent compression level.
+type Level int
+
+const (
+	Fastest Level = iota
+	Default
+	Best
+)
+
+// DefaultMaxSize bounds decompressed output for the helpers that do not
+// take an explicit limit, guarding against decompression bombs.
+const DefaultMaxSize = 256 << 20
+
+var ErrTooLarge = errors.New("decompressed data exceeds size limit")
+
+// Encodings supported by Encode, Decode and NewWriter, named as in the
+// HTTP Content-Encoding header.
+const (
+	EncodingGzip = "gzip"
+	EncodingZstd = "zstd"
+)
+
+// NewWriter returns a compressing writer for the named encoding.
+func NewWriter(w io.Writer, encoding string, level Level) (io.WriteCloser, error) {
+	switch encoding {
+	case EncodingGzip:
+		return NewGzipWriter(w, level)
+	case EncodingZstd:
+		return NewZstdWriter(w, level)
+	}
+	return nil, errors.Errorf("unsupported encoding %q", encoding)
+}
+
+// NewReader returns a decompressing reader for the named encoding that
+// fails with ErrTooLarge after maxSize bytes; zero disables the limit.
+func NewReader(r io.Reader, encoding string, maxSize int64) (io.ReadCloser, error) {
+	switch encoding {
+	case EncodingGzip:
+		return NewGunzipReader(r, maxSize)
+	case EncodingZstd:
+		return NewZstdReader(r, maxSize)
+	}
+	return nil, errors.Errorf("unsupported encoding %q", encoding)
+}
+
+func Encode(data []byte, encoding string, level Level) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, encoding, level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return nil, errors.Wrap(err, encoding+".Write")
+	}
+	if err := w.Close(); err != nil {
+		return nil, errors.Wrap(err, encoding+".Close")
+	}
+	return buf.Bytes(), nil
+}
+
+func Decode(data []byte, encoding string, maxSize int64) ([]byte, error) {
+	r, err := NewReader(bytes.NewReader(data), encoding, maxSize)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	out, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// limitReader fails instead of silently truncating once more than max
+// bytes have been read.
+type limitReader struct {
+	r    io.Reader
+	left int64
+}
+
+func limit(r io.Reader, max int64) io.Reader {
+	if max <= 0 {
+		return r
+	}
+	return &limitReader{r: r, left: max}
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	if l.left < 0 {
+		return 0, ErrTooLarge
+	}
+	if int64(len(p)) > l.left+1 {
+		p = p[:l.left+1]
+	}
+	n, err := l.r.Read(p)
+	l.left -= int64(n)
+	if l.left < 0 {
+		return n - int(-l.left), ErrTooLarge
+	}
+	return n, err
+}
+
+type readCloser struct {
+	io.Reader
+	close func() error
+}
+
+func (rc readCloser) Close() error {
+	return rc.close()
+}
@@ -0,0 +1,53 @@
+package compress
+
+import (
+	"compress/gzip"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+func gzipLevel(level Level) int {
+	switch level {
+	case Fastest:
+		return gzip.BestSpeed
+	case Best:
+		return gzip.BestCompression
+	}
+	return gzip.DefaultCompression
+}
+
+func NewGzipWriter(w io.Writer, level Level) (io.WriteCloser, error) {
+	gw, err := gzip.NewWriterLevel(w, gzipLevel(level))
+	if err != nil {
+		return nil, errors.Wrap(err, "gzip.NewWriterLevel")
+	}
+	return gw, nil
+}
+
+// NewGunzipReader decompresses r, failing with ErrTooLarge after maxSize
+// bytes; zero disables the limit.
+func NewGunzipReader(r io.Reader, maxSize int64) (io.ReadCloser, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "gzip.NewReader")
+	}
+	return readCloser{Reader: limit(gr, maxSize), close: gr.Close}, nil
+}
+
+func Gzip(data []byte) ([]byte, error) {
+	return Encode(data, EncodingGzip, Default)
+}
+
+func GzipLevel(data []byte, level Level) ([]byte, error) {
+	return Encode(data, EncodingGzip, level)
+}
+
+// Gunzip decompresses data up to DefaultMaxSize bytes.
+func Gunzip(data []byte) ([]byte, error) {
+	return Decode(data, EncodingGzip, DefaultMaxSize)
+}
+
+func GunzipLimit(data []byte, maxSize int64) ([]byte, error) {
+	return Decode(data, EncodingGzip, maxSize)
+}
@@ -0,0 +1,56 @@
+package compress
+
+import (
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+)
+
+func zstdLevel(level Level) zstd.EncoderLevel {
+	switch level {
+	case Fastest:
+		return zstd.SpeedFastest
+	case Best:
+		return zstd.SpeedBestCompression
+	}
+	return zstd.SpeedDefault
+}
+
+func NewZstdWriter(w io.Writer, level Level) (io.WriteCloser, error) {
+	zw, err := zstd.NewWriter(w, zstd.WithEncoderLevel(zstdLevel(level)))
+	if err != nil {
+		return nil, errors.Wrap(err, "zstd.NewWriter")
+	}
+	return zw, nil
+}
+
+// NewZstdReader decompresses r, failing with ErrTooLarge after maxSize
+// bytes; zero disables the limit.
+func NewZstdReader(r io.Reader, maxSize int64) (io.ReadCloser, error) {
+	zr, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, errors.Wrap(err, "zstd.NewReader")
+	}
+	return readCloser{Reader: limit(zr, maxSize), close: func() error {
+		zr.Close()
+		return nil
+	}}, nil
+}
+
+func Zstd(data []byte) ([]byte, error) {
+	return Encode(data, EncodingZstd, Default)
+}
+
+func ZstdLevel(data []byte, level Level) ([]byte, error) {
+	return Encode(data, EncodingZstd, level)
+}
+
+// Unzstd decompresses data up to DefaultMaxSize bytes.
+func Unzstd(data []byte) ([]byte, error) {
+	return Decode(data, EncodingZstd, DefaultMaxSize)
+}
+
+func UnzstdLimit(data []byte, maxSize int64) ([]byte, error) {
+	return Decode(data, EncodingZstd, maxSize)
+}
@@ -0,0 +1,167 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Loader populates a struct from, in order of increasing precedence, the
+// `default` struct tags, an optional JSON or YAML file and environment
+// variables named by the `env` struct tags.
+//
+//	type Config struct {
+//		Port    int           `json:"port" yaml:"port" env:"PORT" default:"8080"`
+//		Timeout time.Duration `env:"TIMEOUT" default:"5s"`
+//		Hosts   []string      `env:"HOSTS" required:"true"`
+//	}
+type Loader struct {
+	prefix    string
+	file      string
+	separator string
+	lookupEnv func(string) (string, bool)
+}
+
+func NewLoader() *Loader {
+	return &Loader{
+		separator: ",",
+		lookupEnv: os.LookupEnv,
+	}
+}
+
+func Load(dst interface{}) error {
+	return NewLoader().Load(dst)
+}
+
+// Prefix is prepended to every env tag, e.g. Prefix("APP_") reads APP_PORT.
+func (l *Loader) Prefix(prefix string) *Loader {
+	l.prefix = prefix
+	return l
+}
+
+func (l *Loader) File(path string) *Loader {
+	l.file = path
+	return l
+}
+
+// Separator splits env and default values into slice elements.
+func (l *Loader) Separator(separator string) *Loader {
+	l.separator = separator
+	return l
+}
+
+func (l *Loader) LookupEnv(lookup func(string) (string, bool)) *Loader {
+	l.lookupEnv = lookup
+	return l
+}
+
+func (l *Loader) Load(dst interface{}) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("config: destination must be a pointer to struct")
+	}
+
+	if err := l.walk(rv.Elem(), l.applyDefault); err != nil {
+		return err
+	}
+
+	if l.file != "" {
+		if err := l.loadFile(dst); err != nil {
+			return err
+		}
+	}
+
+	if err := l.walk(rv.Elem(), l.applyEnv); err != nil {
+		return err
+	}
+
+	var missing []string
+	_ = l.walk(rv.Elem(), func(field reflect.StructField, value reflect.Value) error {
+		if field.Tag.Get("required") == "true" && value.IsZero() {
+			missing = append(missing, l.fieldName(field))
+		}
+		return nil
+	})
+	if len(missing) > 0 {
+		return errors.Errorf("config: missing required fields: %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+func (l *Loader) loadFile(dst interface{}) error {
+	data, err := os.ReadFile(l.file)
+	if err != nil {
+		return errors.Wrap(err, "os.ReadFile")
+	}
+
+	switch strings.ToLower(filepath.Ext(l.file)) {
+	case ".json":
+		if err := json.Unmarshal(data, dst); err != nil {
+			return errors.Wrap(err, "json.Unmarshal")
+		}
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, dst); err != nil {
+			return errors.Wrap(err, "yaml.Unmarshal")
+		}
+	default:
+		return errors.Errorf("config: unsupported file format %q", filepath.Ext(l.file))
+	}
+	return nil
+}
+
+func (l *Loader) applyDefault(field reflect.StructField, value reflect.Value) error {
+	def, ok := field.Tag.Lookup("default")
+	if !ok || !value.IsZero() {
+		return nil
+	}
+	return errors.Wrapf(setValue(value, def, l.separator), "config: default of %s", field.Name)
+}
+
+func (l *Loader) applyEnv(field reflect.StructField, value reflect.Value) error {
+	name := field.Tag.Get("env")
+	if name == "" || name == "-" {
+		return nil
+	}
+
+	raw, ok := l.lookupEnv(l.prefix + name)
+	if !ok {
+		return nil
+	}
+	return errors.Wrapf(setValue(value, raw, l.separator), "config: env %s", l.prefix+name)
+}
+
+func (l *Loader) fieldName(field reflect.StructField) string {
+	if name := field.Tag.Get("env"); name != "" && name != "-" {
+		return l.prefix + name
+	}
+	return field.Name
+}
+
+func (l *Loader) walk(rv reflect.Value, fn func(reflect.StructField, reflect.Value) error) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		value := rv.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if value.Kind() == reflect.Struct && !isScalarStruct(value.Type()) {
+			if err := l.walk(value, fn); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := fn(field, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"encoding"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	timeType            = reflect.TypeOf(time.Time{})
+	urlType             = reflect.TypeOf(url.URL{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+func isScalarStruct(t reflect.Type) bool {
+	return t == timeType || t == urlType || reflect.PtrTo(t).Implements(textUnmarshalerType)
+}
+
+// SetValue parses raw into value using the same rules as Loader: durations,
+// RFC3339 times, URLs, TextUnmarshalers, scalars, pointers and slices split
+// on separator. Other packages binding strings to structs reuse it.
+func SetValue(value reflect.Value, raw string, separator string) error {
+	return setValue(value, raw, separator)
+}
+
+func setValue(value reflect.Value, raw string, separator string) error {
+	if value.CanAddr() && value.Addr().Type().Implements(textUnmarshalerType) {
+		return value.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw))
+	}
+
+	switch value.Type() {
+	case durationType:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return errors.Wrap(err, "time.ParseDuration")
+		}
+		value.SetInt(int64(d))
+		return nil
+	case timeType:
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return errors.Wrap(err, "time.Parse")
+		}
+		value.Set(reflect.ValueOf(t))
+		return nil
+	case urlType:
+		u, err := url.Parse(raw)
+		if err != nil {
+			return errors.Wrap(err, "url.Parse")
+		}
+		value.Set(reflect.ValueOf(*u))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return errors.Wrap(err, "strconv.ParseBool")
+		}
+		value.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, value.Type().Bits())
+		if err != nil {
+			return errors.Wrap(err, "strconv.ParseInt")
+		}
+		value.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, value.Type().Bits())
+		if err != nil {
+			return errors.Wrap(err, "strconv.ParseUint")
+		}
+		value.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, value.Type().Bits())
+		if err != nil {
+			return errors.Wrap(err, "strconv.ParseFloat")
+		}
+		value.SetFloat(f)
+	case reflect.Slice:
+		parts := []string{}
+		if raw != "" {
+			parts = strings.Split(raw, separator)
+		}
+		slice := reflect.MakeSlice(value.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := setValue(slice.Index(i), strings.TrimSpace(part), separator); err != nil {
+				return err
+			}
+		}
+		value.Set(slice)
+	case reflect.Ptr:
+		elem := reflect.New(value.Type().Elem())
+		if err := setValue(elem.Elem(), raw, separator); err != nil {
+			return err
+		}
+		value.Set(elem)
+	default:
+		return errors.Errorf("unsupported type %s", value.Type())
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package config
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// LoadDotenv reads KEY=value pairs from the given files (".env" when none is
+// given) into the process environment, keeping variables that are already set.
+func LoadDotenv(paths ...string) error {
+	return loadDotenv(false, paths)
+}
+
+// OverloadDotenv is like LoadDotenv but overrides variables already set.
+func OverloadDotenv(paths ...string) error {
+	return loadDotenv(true, paths)
+}
+
+func loadDotenv(override bool, paths []string) error {
+	if len(paths) == 0 {
+		paths = []string{".env"}
+	}
+
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return errors.Wrap(err, "os.Open")
+		}
+
+		vars, err := ParseDotenv(f)
+		f.Close()
+		if err != nil {
+			return errors.Wrapf(err, "config: parsing %s", path)
+		}
+
+		for key, value := range vars {
+			if _, exists := os.LookupEnv(key); exists && !override {
+				continue
+			}
+			if err := os.Setenv(key, value); err != nil {
+				return errors.Wrap(err, "os.Setenv")
+			}
+		}
+	}
+	return nil
+}
+
+// ParseDotenv parses dotenv content without touching the environment.
+// Double-quoted values may span lines and support escapes, single-quoted
+// values are literal, and $VAR or ${VAR} references are expanded from
+// previously parsed keys or the environment.
+func ParseDotenv(r io.Reader) (map[string]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "io.ReadAll")
+	}
+
+	p := &dotenvParser{src: string(bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))), vars: map[string]string{}}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return p.vars, nil
+}
+
+type dotenvParser struct {
+	src  string
+	pos  int
+	line int
+	vars map[string]string
+}
+
+func (p *dotenvParser) parse() error {
+	p.line = 1
+	for p.pos < len(p.src) {
+		p.skipBlank()
+		if p.pos >= len(p.src) {
+			break
+		}
+
+		switch p.src[p.pos] {
+		case '\n':
+			p.pos++
+			p.line++
+			continue
+		case '#':
+			p.skipLine()
+			continue
+		}
+
+		key, err := p.key()
+		if err != nil {
+			return err
+		}
+
+		value, err := p.value()
+		if err != nil {
+			return err
+		}
+		p.vars[key] = value
+	}
+	return nil
+}
+
+func (p *dotenvParser) key() (string, error) {
+	rest := p.src[p.pos:]
+	if strings.HasPrefix(rest, "export ") {
+		p.pos += len("export ")
+		p.skipBlank()
+	}
+
+	start := p.pos
+	for p.pos < len(p.src) && isKeyChar(p.src[p.pos]) {
+		p.pos++
+	}
+	key := p.src[start:p.pos]
+
+	p.skipBlank()
+	if key == "" || p.pos >= len(p.src) || (p.src[p.pos] != '=' && p.src[p.pos] != ':') {
+		return "", errors.Errorf("line %d: expected KEY=value", p.line)
+	}
+	p.pos++
+	p.skipBlank()
+	return key, nil
+}
+
+func (p *dotenvParser) value() (string, error) {
+	if p.pos >= len(p.src) {
+		return "", nil
+	}
+
+	switch quote := p.src[p.pos]; quote {
+	case '\'', '"', '`':
+		startLine := p.line
+		p.pos++
+		var b strings.Builder
+		for {
+			if p.pos >= len(p.src) {
+				return "", errors.Errorf("line %d: unterminated %c quote", startLine, quote)
+			}
+			c := p.src[p.pos]
+			p.pos++
+			if c == quote {
+				break
+			}
+			if c == '\n' {
+				p.line++
+			}
+			if c == '\\' && quote == '"' && p.pos < len(p.src) {
+				next := p.src[p.pos]
+				p.pos++
+				switch next {
+				case 'n':
+					b.WriteByte('\n')
+				case 'r':
+					b.WriteByte('\r')
+				case 't':
+					b.WriteByte('\t')
+				case '$':
+					b.WriteString(`\$`)
+				default:
+					b.WriteByte(next)
+				}
+				continue
+			}
+			b.WriteByte(c)
+		}
+		p.skipLine()
+
+		if quote == '"' {
+			return p.expand(b.String()), nil
+		}
+		return b.String(), nil
+	}
+
+	start := p.pos
+	for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+		if p.src[p.pos] == '#' && p.pos > start && (p.src[p.pos-1] == ' ' || p.src[p.pos-1] == '\t') {
+			break
+		}
+		p.pos++
+	}
+	value := strings.TrimSpace(p.src[start:p.pos])
+	p.skipLine()
+	return p.expand(value), nil
+}
+
+func (p *dotenvParser) expand(value string) string {
+	const escaped = "\x00"
+	value = strings.ReplaceAll(value, `\$`, escaped)
+	value = os.Expand(value, func(name string) string {
+		if v, ok := p.vars[name]; ok {
+			return v
+		}
+		return os.Getenv(name)
+	})
+	return strings.ReplaceAll(value, escaped, "$")
+}
+
+func (p *dotenvParser) skipBlank() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *dotenvParser) skipLine() {
+	for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+		p.pos++
+	}
+}
+
+func isKeyChar(c byte) bool {
+	return c == '_' || c == '.' || c == '-' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
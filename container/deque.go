@@ -0,0 +1,116 @@
+package container
+
+import "encoding/json"
+
+// Deque is a double-ended queue on a growable ring, usable as a FIFO
+// queue through PushBack and PopFront. It is not safe for concurrent use.
+type Deque[T any] struct {
+	items []T
+	head  int
+	size  int
+}
+
+func NewDeque[T any]() *Deque[T] {
+	return &Deque[T]{}
+}
+
+func (d *Deque[T]) Len() int {
+	return d.size
+}
+
+func (d *Deque[T]) grow() {
+	if d.size < len(d.items) {
+		return
+	}
+	capacity := len(d.items) * 2
+	if capacity == 0 {
+		capacity = 8
+	}
+	items := make([]T, capacity)
+	for i := 0; i < d.size; i++ {
+		items[i] = d.items[(d.head+i)%len(d.items)]
+	}
+	d.items = items
+	d.head = 0
+}
+
+func (d *Deque[T]) PushBack(item T) {
+	d.grow()
+	d.items[(d.head+d.size)%len(d.items)] = item
+	d.size++
+}
+
+func (d *Deque[T]) PushFront(item T) {
+	d.grow()
+	d.head = (d.head - 1 + len(d.items)) % len(d.items)
+	d.items[d.head] = item
+	d.size++
+}
+
+func (d *Deque[T]) PopFront() (T, bool) {
+	var zero T
+	if d.size == 0 {
+		return zero, false
+	}
+	item := d.items[d.head]
+	d.items[d.head] = zero
+	d.head = (d.head + 1) % len(d.items)
+	d.size--
+	return item, true
+}
+
+func (d *Deque[T]) PopBack() (T, bool) {
+	var zero T
+	if d.size == 0 {
+		return zero, false
+	}
+	i := (d.head + d.size - 1) % len(d.items)
+	item := d.items[i]
+	d.items[i] = zero
+	d.size--
+	return item, true
+}
+
+func (d *Deque[T]) Front() (T, bool) {
+	return d.At(0)
+}
+
+func (d *Deque[T]) Back() (T, bool) {
+	return d.At(d.size - 1)
+}
+
+// At returns the i-th element from the front.
+func (d *Deque[T]) At(i int) (T, bool) {
+	if i < 0 || i >= d.size {
+		var zero T
+		return zero, false
+	}
+	return d.items[(d.head+i)%len(d.items)], true
+}
+
+func (d *Deque[T]) Clear() {
+	*d = Deque[T]{}
+}
+
+// Each visits the elements from front to back until fn returns false.
+func (d *Deque[T]) Each(fn func(item T) bool) {
+	for i := 0; i < d.size; i++ {
+		if !fn(d.items[(d.head+i)%len(d.items)]) {
+			return
+		}
+	}
+}
+
+// Items returns the elements from front to back.
+func (d *Deque[T]) Items() []T {
+	out := make([]T, 0, d.size)
+	d.Each(func(item T) bool {
+		out = append(out, item)
+		return true
+	})
+	return out
+}
+
+func (d *Deque[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.Items())
+}
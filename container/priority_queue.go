@@ -0,0 +1,107 @@
+package container
+
+import "encoding/json"
+
+// PriorityQueue is a binary heap ordered by less: Pop returns the element
+// for which less holds against every other. It is not safe for concurrent
+// use.
+type PriorityQueue[T any] struct {
+	items []T
+	less  func(a, b T) bool
+}
+
+func NewPriorityQueue[T any](less func(a, b T) bool) *PriorityQueue[T] {
+	return &PriorityQueue[T]{less: less}
+}
+
+func (q *PriorityQueue[T]) Len() int {
+	return len(q.items)
+}
+
+func (q *PriorityQueue[T]) Push(items ...T) {
+	for _, item := range items {
+		q.items = append(q.items, item)
+		q.up(len(q.items) - 1)
+	}
+}
+
+// Peek returns the top element without removing it.
+func (q *PriorityQueue[T]) Peek() (T, bool) {
+	if len(q.items) == 0 {
+		var zero T
+		return zero, false
+	}
+	return q.items[0], true
+}
+
+func (q *PriorityQueue[T]) Pop() (T, bool) {
+	var zero T
+	if len(q.items) == 0 {
+		return zero, false
+	}
+	top := q.items[0]
+	last := len(q.items) - 1
+	q.items[0] = q.items[last]
+	q.items[last] = zero
+	q.items = q.items[:last]
+	if last > 0 {
+		q.down(0)
+	}
+	return top, true
+}
+
+func (q *PriorityQueue[T]) Clear() {
+	q.items = nil
+}
+
+// Items returns the elements in heap order, not priority order.
+func (q *PriorityQueue[T]) Items() []T {
+	return append([]T(nil), q.items...)
+}
+
+// Sorted returns the elements in priority order without modifying the
+// queue.
+func (q *PriorityQueue[T]) Sorted() []T {
+	clone := &PriorityQueue[T]{items: q.Items(), less: q.less}
+	out := make([]T, 0, len(q.items))
+	for clone.Len() > 0 {
+		item, _ := clone.Pop()
+		out = append(out, item)
+	}
+	return out
+}
+
+// MarshalJSON encodes the elements in priority order.
+func (q *PriorityQueue[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(q.Sorted())
+}
+
+func (q *PriorityQueue[T]) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !q.less(q.items[i], q.items[parent]) {
+			return
+		}
+		q.items[i], q.items[parent] = q.items[parent], q.items[i]
+		i = parent
+	}
+}
+
+func (q *PriorityQueue[T]) down(i int) {
+	n := len(q.items)
+	for {
+		smallest := i
+		left, right := 2*i+1, 2*i+2
+		if left < n && q.less(q.items[left], q.items[smallest]) {
+			smallest = left
+		}
+		if right < n && q.less(q.items[right], q.items[smallest]) {
+			smallest = right
+		}
+		if smallest == i {
+			return
+		}
+		q.items[i], q.items[smallest] = q.items[smallest], q.items[i]
+		i = smallest
+	}
+}
@@ -0,0 +1,109 @@
+package container
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+var ErrFull = errors.New("ring buffer is full")
+
+// RingBuffer is a fixed-capacity FIFO buffer. When full, Push either fails
+// with ErrFull or, in overwrite mode, drops the oldest element, which
+// suits "last N events" debugging buffers. It is not safe for concurrent
+// use.
+type RingBuffer[T any] struct {
+	items     []T
+	head      int
+	size      int
+	overwrite bool
+}
+
+func NewRingBuffer[T any](capacity int) *RingBuffer[T] {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &RingBuffer[T]{items: make([]T, capacity)}
+}
+
+// Overwrite makes Push replace the oldest element when the buffer is full.
+func (r *RingBuffer[T]) Overwrite(overwrite bool) *RingBuffer[T] {
+	r.overwrite = overwrite
+	return r
+}
+
+func (r *RingBuffer[T]) Len() int {
+	return r.size
+}
+
+func (r *RingBuffer[T]) Cap() int {
+	return len(r.items)
+}
+
+func (r *RingBuffer[T]) Full() bool {
+	return r.size == len(r.items)
+}
+
+func (r *RingBuffer[T]) Push(item T) error {
+	if r.Full() {
+		if !r.overwrite {
+			return ErrFull
+		}
+		r.items[r.head] = item
+		r.head = (r.head + 1) % len(r.items)
+		return nil
+	}
+	r.items[(r.head+r.size)%len(r.items)] = item
+	r.size++
+	return nil
+}
+
+// Pop removes and returns the oldest element.
+func (r *RingBuffer[T]) Pop() (T, bool) {
+	var zero T
+	if r.size == 0 {
+		return zero, false
+	}
+	item := r.items[r.head]
+	r.items[r.head] = zero
+	r.head = (r.head + 1) % len(r.items)
+	r.size--
+	return item, true
+}
+
+// Peek returns the oldest element without removing it.
+func (r *RingBuffer[T]) Peek() (T, bool) {
+	if r.size == 0 {
+		var zero T
+		return zero, false
+	}
+	return r.items[r.head], true
+}
+
+func (r *RingBuffer[T]) Clear() {
+	r.items = make([]T, len(r.items))
+	r.head, r.size = 0, 0
+}
+
+// Each visits the elements from oldest to newest until fn returns false.
+func (r *RingBuffer[T]) Each(fn func(item T) bool) {
+	for i := 0; i < r.size; i++ {
+		if !fn(r.items[(r.head+i)%len(r.items)]) {
+			return
+		}
+	}
+}
+
+// Items returns the elements from oldest to newest.
+func (r *RingBuffer[T]) Items() []T {
+	out := make([]T, 0, r.size)
+	r.Each(func(item T) bool {
+		out = append(out, item)
+		return true
+	})
+	return out
+}
+
+func (r *RingBuffer[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.Items())
+}
@@ -0,0 +1,67 @@
+package container
+
+import "encoding/json"
+
+// Stack is a LIFO stack. It is not safe for concurrent use.
+type Stack[T any] struct {
+	items []T
+}
+
+func NewStack[T any]() *Stack[T] {
+	return &Stack[T]{}
+}
+
+func (s *Stack[T]) Len() int {
+	return len(s.items)
+}
+
+func (s *Stack[T]) Push(items ...T) {
+	s.items = append(s.items, items...)
+}
+
+func (s *Stack[T]) Pop() (T, bool) {
+	var zero T
+	if len(s.items) == 0 {
+		return zero, false
+	}
+	last := len(s.items) - 1
+	item := s.items[last]
+	s.items[last] = zero
+	s.items = s.items[:last]
+	return item, true
+}
+
+func (s *Stack[T]) Peek() (T, bool) {
+	if len(s.items) == 0 {
+		var zero T
+		return zero, false
+	}
+	return s.items[len(s.items)-1], true
+}
+
+func (s *Stack[T]) Clear() {
+	s.items = nil
+}
+
+// Items returns the elements from top to bottom.
+func (s *Stack[T]) Items() []T {
+	out := make([]T, len(s.items))
+	for i, item := range s.items {
+		out[len(s.items)-1-i] = item
+	}
+	return out
+}
+
+// Each visits the elements from top to bottom until fn returns false.
+func (s *Stack[T]) Each(fn func(item T) bool) {
+	for i := len(s.items) - 1; i >= 0; i-- {
+		if !fn(s.items[i]) {
+			return
+		}
+	}
+}
+
+// MarshalJSON encodes the elements from top to bottom.
+func (s *Stack[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Items())
+}
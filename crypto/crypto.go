@@ -0,0 +1,121 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/subtle"
+	"io"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+const KeySize = 32
+
+var (
+	ErrInvalidKey         = errors.New("crypto: key must be 32 bytes")
+	ErrCiphertextTooShort = errors.New("crypto: ciphertext too short")
+)
+
+func GenerateKey() ([]byte, error) {
+	return RandomBytes(KeySize)
+}
+
+func RandomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return nil, errors.Wrap(err, "rand.Read")
+	}
+	return b, nil
+}
+
+// Encrypt seals plaintext with AES-256-GCM under a random nonce, which is
+// prepended to the returned ciphertext. additionalData is authenticated but
+// not encrypted and must be passed unchanged to Decrypt.
+func Encrypt(key, plaintext, additionalData []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := RandomBytes(aead.NonceSize())
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func Decrypt(key, ciphertext, additionalData []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < aead.NonceSize()+aead.Overhead() {
+		return nil, ErrCiphertextTooShort
+	}
+	nonce, sealed := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+
+	plaintext, err := aead.Open(nil, nonce, sealed, additionalData)
+	if err != nil {
+		return nil, errors.Wrap(err, "aead.Open")
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, ErrInvalidKey
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "aes.NewCipher")
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "cipher.NewGCM")
+	}
+	return aead, nil
+}
+
+type KDF int
+
+const (
+	Argon2id KDF = iota
+	Scrypt
+)
+
+// DeriveKey stretches a password into a 32-byte key. Use a random salt of
+// at least 16 bytes per secret and store it alongside the ciphertext.
+func DeriveKey(password, salt []byte, kdf KDF) ([]byte, error) {
+	if len(salt) < 8 {
+		return nil, errors.New("crypto: salt must have at least 8 bytes")
+	}
+
+	switch kdf {
+	case Argon2id:
+		return argon2.IDKey(password, salt, 1, 64*1024, 4, KeySize), nil
+	case Scrypt:
+		key, err := scrypt.Key(password, salt, 1<<15, 8, 1, KeySize)
+		if err != nil {
+			return nil, errors.Wrap(err, "scrypt.Key")
+		}
+		return key, nil
+	}
+	return nil, errors.Errorf("crypto: unknown KDF %d", kdf)
+}
+
+func NewSalt() ([]byte, error) {
+	return RandomBytes(16)
+}
+
+// Equal compares in constant time to avoid leaking where inputs differ.
+func Equal(a, b []byte) bool {
+	return subtle.ConstantTimeCompare(a, b) == 1
+}
+
+func EqualString(a, b string) bool {
+	return Equal([]byte(a), []byte(b))
+}
@@ -0,0 +1,190 @@
+package csvutil
+
+import (
+	"encoding"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	timeType            = reflect.TypeOf(time.Time{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// isText reports whether t or *t converts itself to and from text.
+func isText(t reflect.Type) bool {
+	for _, iface := range []reflect.Type{textMarshalerType, textUnmarshalerType} {
+		if t.Implements(iface) || reflect.PtrTo(t).Implements(iface) {
+			return true
+		}
+	}
+	return false
+}
+
+// options holds the conversion settings shared by Reader and Writer.
+type options struct {
+	timeLayouts  []string
+	decimalComma bool
+}
+
+func defaultOptions() options {
+	return options{
+		timeLayouts: []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02", "02/01/2006 15:04:05", "02/01/2006"},
+	}
+}
+
+// parseFloat accepts both "1234.56" and, with decimalComma, "1.234,56".
+func (o options) parseFloat(raw string, bits int) (float64, error) {
+	if o.decimalComma {
+		raw = strings.ReplaceAll(raw, ".", "")
+		raw = strings.Replace(raw, ",", ".", 1)
+	}
+	return strconv.ParseFloat(raw, bits)
+}
+
+func (o options) parseTime(raw string) (time.Time, error) {
+	var lastErr error
+	for _, layout := range o.timeLayouts {
+		t, err := time.Parse(layout, raw)
+		if err == nil {
+			return t, nil
+		}
+		lastErr = err
+	}
+	return time.Time{}, errors.Wrap(lastErr, "time.Parse")
+}
+
+// set converts raw into value. Empty cells leave the zero value in place.
+func (o options) set(value reflect.Value, raw string) error {
+	if value.Kind() == reflect.Ptr {
+		if raw == "" {
+			return nil
+		}
+		elem := reflect.New(value.Type().Elem())
+		if err := o.set(elem.Elem(), raw); err != nil {
+			return err
+		}
+		value.Set(elem)
+		return nil
+	}
+
+	if raw == "" && value.Kind() != reflect.String {
+		return nil
+	}
+
+	switch value.Type() {
+	case durationType:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return errors.Wrap(err, "time.ParseDuration")
+		}
+		value.SetInt(int64(d))
+		return nil
+	case timeType:
+		t, err := o.parseTime(raw)
+		if err != nil {
+			return err
+		}
+		value.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	if value.CanAddr() && value.Addr().Type().Implements(textUnmarshalerType) {
+		return value.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw))
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return errors.Wrap(err, "strconv.ParseBool")
+		}
+		value.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, value.Type().Bits())
+		if err != nil {
+			return errors.Wrap(err, "strconv.ParseInt")
+		}
+		value.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, value.Type().Bits())
+		if err != nil {
+			return errors.Wrap(err, "strconv.ParseUint")
+		}
+		value.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := o.parseFloat(raw, value.Type().Bits())
+		if err != nil {
+			return errors.Wrap(err, "strconv.ParseFloat")
+		}
+		value.SetFloat(f)
+	default:
+		return errors.Errorf("unsupported type %s", value.Type())
+	}
+	return nil
+}
+
+// format renders value as a cell, using the first time layout for dates.
+func (o options) format(value reflect.Value) (string, error) {
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return "", nil
+		}
+		value = value.Elem()
+	}
+
+	switch value.Type() {
+	case durationType:
+		return time.Duration(value.Int()).String(), nil
+	case timeType:
+		t := value.Interface().(time.Time)
+		if t.IsZero() {
+			return "", nil
+		}
+		return t.Format(o.timeLayouts[0]), nil
+	}
+
+	if !value.Type().Implements(textMarshalerType) && reflect.PtrTo(value.Type()).Implements(textMarshalerType) {
+		// MarshalText has a pointer receiver; take the address of a copy
+		// when the value itself is not addressable.
+		if !value.CanAddr() {
+			v := reflect.New(value.Type())
+			v.Elem().Set(value)
+			value = v.Elem()
+		}
+		value = value.Addr()
+	}
+	if value.Type().Implements(textMarshalerType) {
+		text, err := value.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return "", errors.Wrap(err, "MarshalText")
+		}
+		return string(text), nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		return value.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		s := strconv.FormatFloat(value.Float(), 'f', -1, value.Type().Bits())
+		if o.decimalComma {
+			s = strings.Replace(s, ".", ",", 1)
+		}
+		return s, nil
+	}
+	return "", errors.Errorf("unsupported type %s", value.Type())
+}
@@ -0,0 +1,71 @@
+package csvutil
+
+import (
+	"reflect"
+	"strings"
+)
+
+type field struct {
+	name  string
+	index []int
+}
+
+// fieldsOf lists the exported fields of t in declaration order, named by
+// the csv tag when present. Embedded structs and pointers to structs are
+// flattened and a tag of "-" skips the field.
+func fieldsOf(t reflect.Type) []field {
+	var out []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous {
+			continue
+		}
+
+		tag := sf.Tag.Get("csv")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+
+		if t := embeddedStruct(sf); t != nil && name == "" {
+			for _, inner := range fieldsOf(t) {
+				inner.index = append([]int{i}, inner.index...)
+				out = append(out, inner)
+			}
+			continue
+		}
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = sf.Name
+		}
+		out = append(out, field{name: name, index: []int{i}})
+	}
+	return out
+}
+
+// embeddedStruct returns the struct type whose fields sf promotes, or nil
+// when sf is not flattened: it is not embedded, converts itself to text,
+// or is a pointer that cannot be allocated because its type is unexported.
+func embeddedStruct(sf reflect.StructField) reflect.Type {
+	if !sf.Anonymous || isText(sf.Type) {
+		return nil
+	}
+	t := sf.Type
+	if t.Kind() == reflect.Ptr {
+		if sf.PkgPath != "" {
+			return nil
+		}
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	return t
+}
+
+func normalizeHeader(s string) string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(s, "\ufeff")))
+}
@@ -0,0 +1,192 @@
+package csvutil
+
+import (
+	"encoding/csv"
+	"io"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// Reader decodes CSV rows into structs one at a time.
+//
+// By default the first row is treated as a header when at least one of its
+// cells matches a field name; otherwise columns map to fields by position.
+type Reader struct {
+	csv     *csv.Reader
+	options options
+	header  *bool
+	columns map[int][]int
+	fields  []field
+	line    int
+	pending []string
+}
+
+func NewReader(r io.Reader) *Reader {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	return &Reader{
+		csv:     cr,
+		options: defaultOptions(),
+	}
+}
+
+func (r *Reader) Delimiter(delimiter rune) *Reader {
+	r.csv.Comma = delimiter
+	return r
+}
+
+// Header forces the first row to be read as a header (true) or as data
+// (false), disabling detection.
+func (r *Reader) Header(header bool) *Reader {
+	r.header = &header
+	return r
+}
+
+// TimeLayouts replaces the layouts tried, in order, when parsing dates.
+func (r *Reader) TimeLayouts(layouts ...string) *Reader {
+	r.options.timeLayouts = layouts
+	return r
+}
+
+// DecimalComma parses floats written as "1.234,56".
+func (r *Reader) DecimalComma(decimalComma bool) *Reader {
+	r.options.decimalComma = decimalComma
+	return r
+}
+
+// Decode reads the next row into dst, a pointer to a struct. It returns
+// io.EOF when there are no more rows.
+func (r *Reader) Decode(dst interface{}) error {
+	value := reflect.ValueOf(dst)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return errors.Errorf("csvutil: Decode needs a pointer to a struct, got %T", dst)
+	}
+	value = value.Elem()
+
+	if r.columns == nil {
+		if err := r.prepare(value.Type()); err != nil {
+			return err
+		}
+	}
+
+	row := r.pending
+	r.pending = nil
+	if row == nil {
+		var err error
+		row, err = r.csv.Read()
+		if err == io.EOF {
+			return io.EOF
+		}
+		if err != nil {
+			return errors.Wrap(err, "csv.Read")
+		}
+		r.line++
+	}
+
+	value.Set(reflect.Zero(value.Type()))
+	for i, cell := range row {
+		index, ok := r.columns[i]
+		if !ok {
+			continue
+		}
+		if err := r.options.set(fieldByIndex(value, index), cell); err != nil {
+			return errors.Wrapf(err, "line %d, column %d", r.line, i+1)
+		}
+	}
+	return nil
+}
+
+// prepare reads the first row and builds the column-to-field mapping.
+func (r *Reader) prepare(t reflect.Type) error {
+	r.fields = fieldsOf(t)
+	r.columns = make(map[int][]int)
+
+	first, err := r.csv.Read()
+	if err == io.EOF {
+		return io.EOF
+	}
+	if err != nil {
+		return errors.Wrap(err, "csv.Read")
+	}
+	r.line++
+
+	byName := make(map[string][]int, len(r.fields))
+	for _, f := range r.fields {
+		byName[normalizeHeader(f.name)] = f.index
+	}
+
+	matched := 0
+	for i, cell := range first {
+		if index, ok := byName[normalizeHeader(cell)]; ok {
+			r.columns[i] = index
+			matched++
+		}
+	}
+
+	isHeader := matched > 0
+	if r.header != nil {
+		isHeader = *r.header
+	}
+	if isHeader {
+		return nil
+	}
+
+	r.columns = make(map[int][]int)
+	for i, f := range r.fields {
+		r.columns[i] = f.index
+	}
+	r.pending = first
+	return nil
+}
+
+// ReadAll decodes every remaining row into dst, a pointer to a slice of
+// structs or struct pointers.
+func (r *Reader) ReadAll(dst interface{}) error {
+	slice := reflect.ValueOf(dst)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return errors.Errorf("csvutil: ReadAll needs a pointer to a slice, got %T", dst)
+	}
+	slice = slice.Elem()
+
+	elemType := slice.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+
+	for {
+		item := reflect.New(elemType)
+		err := r.Decode(item.Interface())
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if isPtr {
+			slice.Set(reflect.Append(slice, item))
+		} else {
+			slice.Set(reflect.Append(slice, item.Elem()))
+		}
+	}
+}
+
+// Read decodes all of r into dst using the default settings.
+func Read(r io.Reader, dst interface{}) error {
+	return NewReader(r).ReadAll(dst)
+}
+
+func fieldByIndex(value reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				value.Set(reflect.New(value.Type().Elem()))
+			}
+			value = value.Elem()
+		}
+		value = value.Field(x)
+	}
+	return value
+}
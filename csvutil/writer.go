@@ -0,0 +1,127 @@
+package csvutil
+
+import (
+	"encoding/csv"
+	"io"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// Writer encodes structs as CSV rows, writing the header before the first
+// row unless disabled.
+type Writer struct {
+	csv     *csv.Writer
+	options options
+	header  bool
+	fields  []field
+	started bool
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{
+		csv:     csv.NewWriter(w),
+		options: defaultOptions(),
+		header:  true,
+	}
+}
+
+func (w *Writer) Delimiter(delimiter rune) *Writer {
+	w.csv.Comma = delimiter
+	return w
+}
+
+func (w *Writer) Header(header bool) *Writer {
+	w.header = header
+	return w
+}
+
+// TimeLayout sets the layout used to format dates.
+func (w *Writer) TimeLayout(layout string) *Writer {
+	w.options.timeLayouts = []string{layout}
+	return w
+}
+
+// DecimalComma writes floats with a comma as the decimal separator.
+func (w *Writer) DecimalComma(decimalComma bool) *Writer {
+	w.options.decimalComma = decimalComma
+	return w
+}
+
+// Encode writes record, a struct or pointer to struct, as one row.
+func (w *Writer) Encode(record interface{}) error {
+	value := reflect.Indirect(reflect.ValueOf(record))
+	if value.Kind() != reflect.Struct {
+		return errors.Errorf("csvutil: Encode needs a struct, got %T", record)
+	}
+
+	if !w.started {
+		w.started = true
+		w.fields = fieldsOf(value.Type())
+		if w.header {
+			names := make([]string, len(w.fields))
+			for i, f := range w.fields {
+				names[i] = f.name
+			}
+			if err := w.csv.Write(names); err != nil {
+				return errors.Wrap(err, "csv.Write")
+			}
+		}
+	}
+
+	row := make([]string, len(w.fields))
+	for i, f := range w.fields {
+		cell, err := w.lookup(value, f.index)
+		if err != nil {
+			return errors.Wrapf(err, "field %s", f.name)
+		}
+		row[i] = cell
+	}
+	return errors.Wrap(w.csv.Write(row), "csv.Write")
+}
+
+func (w *Writer) lookup(value reflect.Value, index []int) (string, error) {
+	for i, x := range index {
+		if i > 0 && value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				return "", nil
+			}
+			value = value.Elem()
+		}
+		value = value.Field(x)
+	}
+	return w.options.format(value)
+}
+
+// WriteAll encodes every element of records, a slice of structs, and
+// flushes the output.
+func (w *Writer) WriteAll(records interface{}) error {
+	slice := reflect.ValueOf(records)
+	if slice.Kind() != reflect.Slice && slice.Kind() != reflect.Array {
+		return errors.Errorf("csvutil: WriteAll needs a slice, got %T", records)
+	}
+	for i := 0; i < slice.Len(); i++ {
+		if err := w.Encode(slice.Index(i).Interface()); err != nil {
+			return errors.Wrapf(err, "record %d", i)
+		}
+	}
+	return w.Flush()
+}
+
+// WriteRows writes raw rows without any struct mapping.
+func (w *Writer) WriteRows(rows [][]string) error {
+	if err := w.csv.WriteAll(rows); err != nil {
+		return errors.Wrap(err, "csv.WriteAll")
+	}
+	return nil
+}
+
+func (w *Writer) Flush() error {
+	w.csv.Flush()
+	return errors.Wrap(w.csv.Error(), "csv.Flush")
+}
+
+// Write encodes records to w using the default settings.
+func Write(w io.Writer, records interface{}) error {
+	return NewWriter(w).WriteAll(records)
+}
@@ -0,0 +1,141 @@
+package ctxutil
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+type signalKey struct{}
+
+type signalBox struct {
+	mu  sync.Mutex
+	sig os.Signal
+}
+
+// WithSignals returns a context canceled when one of signals arrives
+// (SIGINT and SIGTERM when none are given), when ctx is done, or when
+// cancel is called. The received signal is available through Signal.
+// Signal handling is restored once the context is done.
+func WithSignals(ctx context.Context, signals ...os.Signal) (context.Context, context.CancelFunc) {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+
+	box := &signalBox{}
+	ctx, cancel := context.WithCancel(context.WithValue(ctx, signalKey{}, box))
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+	go func() {
+		defer signal.Stop(ch)
+		select {
+		case sig := <-ch:
+			box.mu.Lock()
+			box.sig = sig
+			box.mu.Unlock()
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// Signal returns the signal that canceled a WithSignals context, or nil.
+func Signal(ctx context.Context) os.Signal {
+	box, ok := ctx.Value(signalKey{}).(*signalBox)
+	if !ok {
+		return nil
+	}
+	box.mu.Lock()
+	defer box.mu.Unlock()
+	return box.sig
+}
+
+type merged struct {
+	context.Context
+	other context.Context
+}
+
+// Merge returns a context done as soon as either parent is done, with the
+// earlier of their deadlines. Values are looked up in a first, then b.
+// Call cancel to release resources when the merged context is no longer
+// needed.
+func Merge(a, b context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(a)
+	if deadline, ok := b.Deadline(); ok {
+		var cancelDeadline context.CancelFunc
+		ctx, cancelDeadline = context.WithDeadline(ctx, deadline)
+		parent := cancel
+		cancel = func() {
+			cancelDeadline()
+			parent()
+		}
+	}
+
+	stop := make(chan struct{})
+	var once sync.Once
+	release := func() {
+		once.Do(func() { close(stop) })
+		cancel()
+	}
+
+	go func() {
+		select {
+		case <-b.Done():
+			cancel()
+		case <-ctx.Done():
+		case <-stop:
+		}
+	}()
+
+	return &merged{Context: ctx, other: b}, release
+}
+
+// Err reports b's cancellation cause when b finished first.
+func (m *merged) Err() error {
+	err := m.Context.Err()
+	if err == context.Canceled {
+		if otherErr := m.other.Err(); otherErr != nil {
+			return otherErr
+		}
+	}
+	return err
+}
+
+func (m *merged) Value(key interface{}) interface{} {
+	if v := m.Context.Value(key); v != nil {
+		return v
+	}
+	return m.other.Value(key)
+}
+
+type detached struct {
+	parent context.Context
+}
+
+// Detach returns a context carrying ctx's values but none of its deadline
+// or cancellation, for work that must outlive a request, such as audit
+// writes after the response is sent.
+func Detach(ctx context.Context) context.Context {
+	return detached{parent: ctx}
+}
+
+func (detached) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detached) Done() <-chan struct{} {
+	return nil
+}
+
+func (detached) Err() error {
+	return nil
+}
+
+func (d detached) Value(key interface{}) interface{} {
+	return d.parent.Value(key)
+}
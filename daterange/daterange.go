@@ -0,0 +1,177 @@
+package daterange
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Iterator yields dates from start up to and including end.
+type Iterator struct {
+	start time.Time
+	end   time.Time
+	step  Step
+	n     int
+	done  bool
+}
+
+// Between iterates from start to end (inclusive) by step. A zero step
+// yields start only.
+func Between(start, end time.Time, step Step) *Iterator {
+	return &Iterator{start: start, end: end, step: step}
+}
+
+func (it *Iterator) Next() (time.Time, bool) {
+	if it.done {
+		return time.Time{}, false
+	}
+	t := it.step.nth(it.start, it.n)
+	if t.After(it.end) || (it.n > 0 && !t.After(it.step.nth(it.start, it.n-1))) {
+		it.done = true
+		return time.Time{}, false
+	}
+	it.n++
+	if it.step.isZero() {
+		it.done = true
+	}
+	return t, true
+}
+
+// All collects the remaining dates.
+func (it *Iterator) All() []time.Time {
+	var out []time.Time
+	for t, ok := it.Next(); ok; t, ok = it.Next() {
+		out = append(out, t)
+	}
+	return out
+}
+
+// Range is the half-open interval [Start, End).
+type Range struct {
+	Start time.Time
+	End   time.Time
+}
+
+func New(start, end time.Time) Range {
+	return Range{Start: start, End: end}
+}
+
+func (r Range) Duration() time.Duration {
+	return r.End.Sub(r.Start)
+}
+
+func (r Range) IsEmpty() bool {
+	return !r.End.After(r.Start)
+}
+
+func (r Range) Contains(t time.Time) bool {
+	return !t.Before(r.Start) && t.Before(r.End)
+}
+
+// ContainsRange reports whether other lies entirely within r.
+func (r Range) ContainsRange(other Range) bool {
+	return !other.Start.Before(r.Start) && !other.End.After(r.End)
+}
+
+func (r Range) Overlaps(other Range) bool {
+	return r.Start.Before(other.End) && other.Start.Before(r.End)
+}
+
+// Intersection returns the overlapping part of r and other; ok is false
+// when they do not overlap.
+func (r Range) Intersection(other Range) (Range, bool) {
+	if !r.Overlaps(other) {
+		return Range{}, false
+	}
+	out := r
+	if other.Start.After(out.Start) {
+		out.Start = other.Start
+	}
+	if other.End.Before(out.End) {
+		out.End = other.End
+	}
+	return out, true
+}
+
+// Split cuts r into consecutive ranges of the given step, the last one
+// truncated at r.End, e.g. monthly billing periods. Steps that do not move
+// forward, such as zero, negative or mixed-sign steps, are an error.
+func (r Range) Split(step Step) ([]Range, error) {
+	var out []Range
+	for n := 0; ; n++ {
+		start := step.nth(r.Start, n)
+		if !start.Before(r.End) {
+			return out, nil
+		}
+		end := step.nth(r.Start, n+1)
+		if !end.After(start) {
+			return nil, errors.Errorf("daterange: step %+v does not advance from %s", step, start)
+		}
+		if end.After(r.End) {
+			end = r.End
+		}
+		out = append(out, Range{Start: start, End: end})
+	}
+}
+
+func StartOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// EndOfDay returns the last nanosecond of t's day.
+func EndOfDay(t time.Time) time.Time {
+	return StartOfDay(t).AddDate(0, 0, 1).Add(-time.Nanosecond)
+}
+
+// StartOfWeek returns midnight of the Monday starting t's ISO week.
+func StartOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return StartOfDay(t).AddDate(0, 0, -offset)
+}
+
+func EndOfWeek(t time.Time) time.Time {
+	return StartOfWeek(t).AddDate(0, 0, 7).Add(-time.Nanosecond)
+}
+
+func StartOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+func EndOfMonth(t time.Time) time.Time {
+	return StartOfMonth(t).AddDate(0, 1, 0).Add(-time.Nanosecond)
+}
+
+func StartOfQuarter(t time.Time) time.Time {
+	month := (t.Month()-1)/3*3 + 1
+	return time.Date(t.Year(), month, 1, 0, 0, 0, 0, t.Location())
+}
+
+func EndOfQuarter(t time.Time) time.Time {
+	return StartOfQuarter(t).AddDate(0, 3, 0).Add(-time.Nanosecond)
+}
+
+// Quarter returns 1 to 4.
+func Quarter(t time.Time) int {
+	return int(t.Month()-1)/3 + 1
+}
+
+func StartOfYear(t time.Time) time.Time {
+	return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, t.Location())
+}
+
+func EndOfYear(t time.Time) time.Time {
+	return StartOfYear(t).AddDate(1, 0, 0).Add(-time.Nanosecond)
+}
+
+// Month returns the range covering t's month.
+func Month(t time.Time) Range {
+	start := StartOfMonth(t)
+	return Range{Start: start, End: start.AddDate(0, 1, 0)}
+}
+
+// QuarterRange returns the range covering t's quarter.
+func QuarterRange(t time.Time) Range {
+	start := StartOfQuarter(t)
+	return Range{Start: start, End: start.AddDate(0, 3, 0)}
+}
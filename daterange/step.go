@@ -0,0 +1,55 @@
+package daterange
+
+import "time"
+
+// Step is the distance between consecutive dates. Calendar parts are
+// applied first, then Duration.
+type Step struct {
+	Years    int
+	Months   int
+	Days     int
+	Duration time.Duration
+}
+
+var (
+	Daily     = Step{Days: 1}
+	Weekly    = Step{Days: 7}
+	Monthly   = Step{Months: 1}
+	Quarterly = Step{Months: 3}
+	Yearly    = Step{Years: 1}
+)
+
+func Every(d time.Duration) Step {
+	return Step{Duration: d}
+}
+
+func (s Step) isZero() bool {
+	return s == Step{}
+}
+
+// nth returns the n-th date after start. Steps are multiplied from start
+// rather than accumulated, and month steps clamp to the last day of the
+// month, so a cycle anchored on the 31st stays on month ends.
+func (s Step) nth(start time.Time, n int) time.Time {
+	t := start
+	if s.Years != 0 || s.Months != 0 {
+		t = addMonths(start, (s.Years*12+s.Months)*n)
+	}
+	if s.Days != 0 {
+		t = t.AddDate(0, 0, s.Days*n)
+	}
+	return t.Add(s.Duration * time.Duration(n))
+}
+
+func addMonths(t time.Time, months int) time.Time {
+	y, m, d := t.Date()
+	first := time.Date(y, m+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	if last := daysIn(first); d > last {
+		d = last
+	}
+	return first.AddDate(0, 0, d-1)
+}
+
+func daysIn(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+}
@@ -0,0 +1,186 @@
+package dbutil
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Placeholder is the bind parameter style of a driver.
+type Placeholder int
+
+const (
+	// Question is used by SQLite and MySQL: ?, ?, ?.
+	Question Placeholder = iota
+	// Dollar is used by PostgreSQL: $1, $2, $3.
+	Dollar
+)
+
+// scan walks query calling fn for every byte outside string literals,
+// quoted identifiers and comments, which are copied untouched.
+func scan(query string, fn func(i int, b *strings.Builder) int) string {
+	var b strings.Builder
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end := strings.IndexByte(query[i+1:], c)
+			if end < 0 {
+				b.WriteString(query[i:])
+				return b.String()
+			}
+			b.WriteString(query[i : i+end+2])
+			i += end + 2
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query) - i
+			}
+			b.WriteString(query[i : i+end])
+			i += end
+		default:
+			i += fn(i, &b)
+		}
+	}
+	return b.String()
+}
+
+// Rebind converts ? placeholders to the style of the driver.
+func Rebind(placeholder Placeholder, query string) string {
+	if placeholder != Dollar {
+		return query
+	}
+	n := 0
+	return scan(query, func(i int, b *strings.Builder) int {
+		if query[i] == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+		} else {
+			b.WriteByte(query[i])
+		}
+		return 1
+	})
+}
+
+// Named converts :name parameters to ? placeholders, taking values from
+// arg, a map[string]interface{} or a struct with `db` tags. PostgreSQL
+// casts such as ::text are left alone.
+func Named(query string, arg interface{}) (string, []interface{}, error) {
+	lookup, err := namedLookup(arg)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var args []interface{}
+	var missing error
+	out := scan(query, func(i int, b *strings.Builder) int {
+		if query[i] != ':' {
+			b.WriteByte(query[i])
+			return 1
+		}
+		if i+1 < len(query) && query[i+1] == ':' {
+			b.WriteString("::")
+			return 2
+		}
+		end := i + 1
+		for end < len(query) && isNameByte(query[end]) {
+			end++
+		}
+		if end == i+1 {
+			b.WriteByte(':')
+			return 1
+		}
+		name := query[i+1 : end]
+		value, ok := lookup(name)
+		if !ok && missing == nil {
+			missing = errors.Errorf("dbutil: missing value for :%s", name)
+		}
+		args = append(args, value)
+		b.WriteByte('?')
+		return end - i
+	})
+	if missing != nil {
+		return "", nil, missing
+	}
+	return out, args, nil
+}
+
+func isNameByte(c byte) bool {
+	return c == '_' || c == '.' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func namedLookup(arg interface{}) (func(string) (interface{}, bool), error) {
+	if m, ok := arg.(map[string]interface{}); ok {
+		return func(name string) (interface{}, bool) {
+			v, ok := m[name]
+			return v, ok
+		}, nil
+	}
+
+	v := reflect.ValueOf(arg)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, errors.Errorf("dbutil: named argument must be a map or struct, got %T", arg)
+	}
+	fields := fieldsOf(v.Type())
+	return func(name string) (interface{}, bool) {
+		path, ok := fields[name]
+		if !ok {
+			return nil, false
+		}
+		return v.FieldByIndex(path).Interface(), true
+	}, nil
+}
+
+// In expands every ? whose argument is a slice into one placeholder per
+// element, so "id IN (?)" works with []int64{1, 2, 3}. []byte arguments
+// are kept as single values. Empty slices are an error, as "IN ()" is
+// invalid SQL.
+func In(query string, args ...interface{}) (string, []interface{}, error) {
+	var out []interface{}
+	n := 0
+	var expandErr error
+	expanded := scan(query, func(i int, b *strings.Builder) int {
+		if query[i] != '?' {
+			b.WriteByte(query[i])
+			return 1
+		}
+		if n >= len(args) {
+			if expandErr == nil {
+				expandErr = errors.New("dbutil: more placeholders than arguments")
+			}
+			return 1
+		}
+		arg := args[n]
+		n++
+
+		v := reflect.ValueOf(arg)
+		if v.Kind() != reflect.Slice || v.Type().Elem().Kind() == reflect.Uint8 {
+			out = append(out, arg)
+			b.WriteByte('?')
+			return 1
+		}
+		if v.Len() == 0 && expandErr == nil {
+			expandErr = errors.Errorf("dbutil: empty slice for placeholder %d", n)
+		}
+		for j := 0; j < v.Len(); j++ {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteByte('?')
+			out = append(out, v.Index(j).Interface())
+		}
+		return 1
+	})
+	if expandErr == nil && n != len(args) {
+		expandErr = errors.New("dbutil: more arguments than placeholders")
+	}
+	if expandErr != nil {
+		return "", nil, expandErr
+	}
+	return expanded, out, nil
+}
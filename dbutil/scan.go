@@ -0,0 +1,163 @@
+package dbutil
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strings"
+	"sync"
+	"utils/strcase"
+
+	"github.com/pkg/errors"
+)
+
+// Querier is implemented by *sql.DB, *sql.Tx and *sql.Conn.
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// fieldMap maps column names to struct field index paths. Columns come
+// from the `db` tag or the snake_case field name, lowercased so that
+// columns match case-insensitively; `db:"-"` skips a field and embedded
+// structs are flattened. When two fields map to the same name the first
+// one wins.
+type fieldMap map[string][]int
+
+var fieldMaps sync.Map
+
+func fieldsOf(t reflect.Type) fieldMap {
+	if cached, ok := fieldMaps.Load(t); ok {
+		return cached.(fieldMap)
+	}
+	fields := make(fieldMap)
+	collectFields(t, nil, fields)
+	fieldMaps.Store(t, fields)
+	return fields
+}
+
+func collectFields(t reflect.Type, index []int, fields fieldMap) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("db")
+		if tag == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+		path := append(append([]int(nil), index...), i)
+
+		if field.Anonymous && tag == "" && field.Type.Kind() == reflect.Struct && !isScalar(field.Type) {
+			collectFields(field.Type, path, fields)
+			continue
+		}
+		name := strings.ToLower(strings.Split(tag, ",")[0])
+		if name == "" {
+			name = strcase.ToSnake(field.Name)
+		}
+		if _, exists := fields[name]; !exists {
+			fields[name] = path
+		}
+	}
+}
+
+// isScalar reports types scanned as a single column, such as time.Time
+// or sql.NullString.
+func isScalar(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return true
+	}
+	return t.String() == "time.Time" || reflect.PtrTo(t).Implements(scannerType)
+}
+
+// targets returns the scan destinations of columns inside v, a struct.
+func targets(v reflect.Value, columns []string) ([]interface{}, error) {
+	fields := fieldsOf(v.Type())
+	out := make([]interface{}, len(columns))
+	for i, column := range columns {
+		path, ok := fields[strings.ToLower(column)]
+		if !ok {
+			return nil, errors.Errorf("dbutil: no field for column %q in %s", column, v.Type())
+		}
+		out[i] = v.FieldByIndex(path).Addr().Interface()
+	}
+	return out, nil
+}
+
+func scanRow(rows *sql.Rows, dest reflect.Value, columns []string) error {
+	if dest.Kind() == reflect.Struct && !isScalar(dest.Type()) {
+		ptrs, err := targets(dest, columns)
+		if err != nil {
+			return err
+		}
+		return errors.Wrap(rows.Scan(ptrs...), "rows.Scan")
+	}
+	if len(columns) != 1 {
+		return errors.Errorf("dbutil: scanning %d columns into %s", len(columns), dest.Type())
+	}
+	return errors.Wrap(rows.Scan(dest.Addr().Interface()), "rows.Scan")
+}
+
+// Select runs query and appends every row to dest, a pointer to a slice
+// of structs, struct pointers or scalars for single-column queries.
+func Select(ctx context.Context, q Querier, dest interface{}, query string, args ...interface{}) error {
+	slice := reflect.ValueOf(dest)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return errors.New("dbutil: Select destination must be a pointer to slice")
+	}
+	slice = slice.Elem()
+	elemType := slice.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return errors.Wrap(err, "QueryContext")
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return errors.Wrap(err, "rows.Columns")
+	}
+	for rows.Next() {
+		elem := reflect.New(elemType)
+		if err := scanRow(rows, elem.Elem(), columns); err != nil {
+			return err
+		}
+		if isPtr {
+			slice.Set(reflect.Append(slice, elem))
+		} else {
+			slice.Set(reflect.Append(slice, elem.Elem()))
+		}
+	}
+	return errors.Wrap(rows.Err(), "rows.Err")
+}
+
+// Get scans the first row of query into dest, a pointer to a struct or
+// scalar, returning sql.ErrNoRows when there is none.
+func Get(ctx context.Context, q Querier, dest interface{}, query string, args ...interface{}) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return errors.New("dbutil: Get destination must be a non-nil pointer")
+	}
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return errors.Wrap(err, "QueryContext")
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return errors.Wrap(err, "rows.Err")
+		}
+		return sql.ErrNoRows
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		return errors.Wrap(err, "rows.Columns")
+	}
+	return scanRow(rows, v.Elem(), columns)
+}
@@ -0,0 +1,153 @@
+package dbutil
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+	"utils/clock"
+	"utils/log"
+
+	"github.com/pkg/errors"
+)
+
+// TxFunc is the body of a transaction. Returning an error rolls it back.
+type TxFunc func(ctx context.Context, tx *sql.Tx) error
+
+// Tx runs functions in transactions, retrying those aborted by deadlocks
+// or serialization failures. Its zero value is not usable; create it
+// with NewTx.
+type Tx struct {
+	db       *sql.DB
+	options  *sql.TxOptions
+	attempts int
+	backoff  func(attempt int) time.Duration
+	clock    clock.Clock
+	logger   *log.Logger
+}
+
+// NewTx does not retry by default; see Retry.
+func NewTx(db *sql.DB) *Tx {
+	return &Tx{
+		db:       db,
+		attempts: 1,
+		backoff:  func(attempt int) time.Duration { return time.Duration(attempt) * 50 * time.Millisecond },
+		clock:    clock.Real,
+		logger:   log.Default().Named("dbutil"),
+	}
+}
+
+// Options sets the isolation level and read-only flag of each transaction.
+func (t *Tx) Options(options *sql.TxOptions) *Tx {
+	t.options = options
+	return t
+}
+
+// Retry runs the function up to attempts times while it fails with an
+// error accepted by Retryable.
+func (t *Tx) Retry(attempts int) *Tx {
+	if attempts < 1 {
+		attempts = 1
+	}
+	t.attempts = attempts
+	return t
+}
+
+// Backoff sets the wait before each retry; attempt starts at 1.
+func (t *Tx) Backoff(backoff func(attempt int) time.Duration) *Tx {
+	t.backoff = backoff
+	return t
+}
+
+// Clock sets the time source used to wait between retries.
+func (t *Tx) Clock(clk clock.Clock) *Tx {
+	t.clock = clock.Or(clk)
+	return t
+}
+
+func (t *Tx) Logger(logger *log.Logger) *Tx {
+	t.logger = logger
+	return t
+}
+
+// Run executes fn in a transaction, committing when it returns nil and
+// rolling back on error or panic; a panic is re-raised after rollback.
+// fn may run more than once, so it must not have side effects outside tx.
+func (t *Tx) Run(ctx context.Context, fn TxFunc) error {
+	for attempt := 1; ; attempt++ {
+		err := t.run(ctx, fn)
+		if err == nil || attempt >= t.attempts || !Retryable(err) {
+			return err
+		}
+
+		delay := t.backoff(attempt)
+		t.logger.Warn("retrying transaction", log.Int("attempt", attempt), log.Duration("delay", delay), log.Err(err))
+		select {
+		case <-ctx.Done():
+			return errors.Wrap(ctx.Err(), "dbutil: transaction retry")
+		case <-t.clock.After(delay):
+		}
+	}
+}
+
+func (t *Tx) run(ctx context.Context, fn TxFunc) (err error) {
+	tx, err := t.db.BeginTx(ctx, t.options)
+	if err != nil {
+		return errors.Wrap(err, "BeginTx")
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	if err := fn(ctx, tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
+			return errors.Wrapf(err, "rollback failed: %v", rbErr)
+		}
+		return err
+	}
+	return errors.Wrap(tx.Commit(), "Commit")
+}
+
+// InTx runs fn in a transaction of db without retries.
+func InTx(ctx context.Context, db *sql.DB, fn TxFunc) error {
+	return NewTx(db).Run(ctx, fn)
+}
+
+// sqlState is implemented by PostgreSQL driver errors such as pgconn.PgError.
+type sqlState interface {
+	SQLState() string
+}
+
+// Retryable reports whether err is a transient conflict worth retrying:
+// PostgreSQL serialization failures and deadlocks (SQLSTATE 40001 and
+// 40P01), MySQL deadlocks and lock wait timeouts (1213 and 1205) and
+// busy or locked SQLite databases. Drivers are recognised by interface
+// or message so none of them has to be imported; SQLSTATE codes are only
+// trusted from drivers that report them through a SQLState method.
+func Retryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var state sqlState
+	if errors.As(err, &state) {
+		code := state.SQLState()
+		return code == "40001" || code == "40P01"
+	}
+
+	msg := strings.ToLower(fmt.Sprint(err))
+	for _, marker := range []string{
+		"could not serialize access", "deadlock detected",
+		"error 1213", "error 1205", "deadlock found",
+		"database is locked", "sqlite_busy", "database table is locked",
+	} {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
+}
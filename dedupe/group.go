@@ -0,0 +1,103 @@
+package dedupe
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type call[V any] struct {
+	wg    sync.WaitGroup
+	value V
+	err   error
+	dups  int
+}
+
+type result[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// Group coalesces concurrent calls sharing a key into a single execution.
+type Group[K comparable, V any] struct {
+	mu      sync.Mutex
+	window  time.Duration
+	calls   map[K]*call[V]
+	results map[K]result[V]
+}
+
+func NewGroup[K comparable, V any]() *Group[K, V] {
+	return &Group[K, V]{
+		calls:   make(map[K]*call[V]),
+		results: make(map[K]result[V]),
+	}
+}
+
+// Window keeps successful results for the given duration so calls arriving
+// shortly after an execution finished reuse its value instead of running again.
+func (g *Group[K, V]) Window(window time.Duration) *Group[K, V] {
+	g.mu.Lock()
+	g.window = window
+	g.mu.Unlock()
+	return g
+}
+
+// Do runs fn once per key among concurrent callers. The shared flag reports
+// whether the value was handed to more than one caller or came from the window.
+func (g *Group[K, V]) Do(key K, fn func() (V, error)) (value V, shared bool, err error) {
+	g.mu.Lock()
+
+	if res, ok := g.results[key]; ok {
+		if time.Now().Before(res.expiresAt) {
+			g.mu.Unlock()
+			return res.value, true, nil
+		}
+		delete(g.results, key)
+	}
+
+	if c, ok := g.calls[key]; ok {
+		c.dups++
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.value, true, c.err
+	}
+
+	c := &call[V]{}
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	g.run(key, c, fn)
+	return c.value, c.dups > 0, c.err
+}
+
+func (g *Group[K, V]) run(key K, c *call[V], fn func() (V, error)) {
+	defer func() {
+		if r := recover(); r != nil {
+			c.err = errors.Errorf("dedupe: panic in call: %v", r)
+		}
+
+		g.mu.Lock()
+		if g.calls[key] == c {
+			delete(g.calls, key)
+		}
+		if c.err == nil && g.window > 0 {
+			g.results[key] = result[V]{value: c.value, expiresAt: time.Now().Add(g.window)}
+		}
+		g.mu.Unlock()
+
+		c.wg.Done()
+	}()
+
+	c.value, c.err = fn()
+}
+
+// Forget drops any in-flight call and cached result for key, so the next Do
+// executes fn again.
+func (g *Group[K, V]) Forget(key K) {
+	g.mu.Lock()
+	delete(g.calls, key)
+	delete(g.results, key)
+	g.mu.Unlock()
+}
@@ -0,0 +1,123 @@
+package deepcopy
+
+import (
+	"reflect"
+	"time"
+)
+
+// Copier lets a type provide its own deep copy, e.g. to handle unexported
+// fields that reflection cannot reach.
+type Copier interface {
+	DeepCopy() interface{}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Copy returns a deep copy of v. Maps, slices, arrays, pointers and
+// interfaces are duplicated recursively and shared or cyclic pointers are
+// preserved. Structs are copied field by field; unexported fields cannot be
+// reached through reflection and are copied shallowly with the struct value.
+// Channels and funcs are shared.
+func Copy[T any](v T) T {
+	src := reflect.ValueOf(&v).Elem()
+	dst := reflect.New(src.Type()).Elem()
+	c := copier{visited: make(map[pointer]reflect.Value)}
+	c.copy(dst, src)
+	// A nil interface T holds no value to assert.
+	out, _ := dst.Interface().(T)
+	return out
+}
+
+// pointer identifies a visited pointer. The type is part of the key since
+// a struct and its first field share an address.
+type pointer struct {
+	typ  reflect.Type
+	addr uintptr
+}
+
+type copier struct {
+	visited map[pointer]reflect.Value
+}
+
+func (c *copier) copy(dst, src reflect.Value) {
+	if !src.IsValid() {
+		return
+	}
+	if src.CanInterface() {
+		if custom, ok := src.Interface().(Copier); ok && !(src.Kind() == reflect.Ptr && src.IsNil()) {
+			if out := reflect.ValueOf(custom.DeepCopy()); out.IsValid() && out.Type().AssignableTo(dst.Type()) {
+				dst.Set(out)
+				return
+			}
+		}
+	}
+
+	switch src.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() {
+			return
+		}
+		key := pointer{src.Type(), src.Pointer()}
+		if seen, ok := c.visited[key]; ok {
+			dst.Set(seen)
+			return
+		}
+		ptr := reflect.New(src.Elem().Type())
+		c.visited[key] = ptr
+		c.copy(ptr.Elem(), src.Elem())
+		dst.Set(ptr)
+
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+		elem := reflect.New(src.Elem().Type()).Elem()
+		c.copy(elem, src.Elem())
+		dst.Set(elem)
+
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		out := reflect.MakeMapWithSize(src.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			key := reflect.New(src.Type().Key()).Elem()
+			c.copy(key, iter.Key())
+			value := reflect.New(src.Type().Elem()).Elem()
+			c.copy(value, iter.Value())
+			out.SetMapIndex(key, value)
+		}
+		dst.Set(out)
+
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		out := reflect.MakeSlice(src.Type(), src.Len(), src.Cap())
+		for i := 0; i < src.Len(); i++ {
+			c.copy(out.Index(i), src.Index(i))
+		}
+		dst.Set(out)
+
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			c.copy(dst.Index(i), src.Index(i))
+		}
+
+	case reflect.Struct:
+		dst.Set(src)
+		if src.Type() == timeType {
+			return
+		}
+		for i := 0; i < src.NumField(); i++ {
+			if !src.Type().Field(i).IsExported() {
+				continue
+			}
+			c.copy(dst.Field(i), src.Field(i))
+		}
+
+	default:
+		dst.Set(src)
+	}
+}
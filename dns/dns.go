@@ -0,0 +1,291 @@
+package dns
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+	"utils/random"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+var ErrNotFound = errors.New("dns: name not found")
+
+// FallbackServers are queried when no server is given and
+// /etc/resolv.conf lists none.
+var FallbackServers = []string{"8.8.8.8:53", "1.1.1.1:53"}
+
+type Type = dnsmessage.Type
+
+const (
+	TypeA     = dnsmessage.TypeA
+	TypeAAAA  = dnsmessage.TypeAAAA
+	TypeCNAME = dnsmessage.TypeCNAME
+	TypeMX    = dnsmessage.TypeMX
+	TypeTXT   = dnsmessage.TypeTXT
+	TypeNS    = dnsmessage.TypeNS
+)
+
+// Record is one answer. Value holds the address, target host or joined
+// TXT strings; Priority is only set for MX records.
+type Record struct {
+	Name     string
+	Type     string
+	TTL      time.Duration
+	Value    string
+	Priority uint16
+}
+
+// Resolver sends queries directly to the given DNS servers, unlike
+// net.Resolver, so it can target specific servers and report TTLs.
+type Resolver struct {
+	servers []string
+	timeout time.Duration
+	retries int
+}
+
+// NewResolver queries servers ("host" or "host:port") in order; without
+// servers it uses the ones in /etc/resolv.conf.
+func NewResolver(servers ...string) *Resolver {
+	if len(servers) == 0 {
+		servers = systemServers()
+	}
+	normalized := make([]string, len(servers))
+	for i, s := range servers {
+		if _, _, err := net.SplitHostPort(s); err != nil {
+			s = net.JoinHostPort(strings.Trim(s, "[]"), "53")
+		}
+		normalized[i] = s
+	}
+	return &Resolver{servers: normalized, timeout: 2 * time.Second, retries: 1}
+}
+
+// Timeout bounds each query to a single server.
+func (r *Resolver) Timeout(timeout time.Duration) *Resolver {
+	r.timeout = timeout
+	return r
+}
+
+// Retries sets how many extra rounds through the server list are made
+// after every server failed.
+func (r *Resolver) Retries(retries int) *Resolver {
+	r.retries = retries
+	return r
+}
+
+// Lookup queries name for records of type qtype. A missing name returns
+// ErrNotFound; an existing name without such records returns no records.
+func (r *Resolver) Lookup(ctx context.Context, name string, qtype Type) ([]Record, error) {
+	qname, err := dnsmessage.NewName(fqdn(name))
+	if err != nil {
+		return nil, errors.Wrap(err, "dnsmessage.NewName")
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= r.retries; attempt++ {
+		for _, server := range r.servers {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			records, err := r.query(ctx, server, qname, qtype)
+			if err == nil || errors.Is(err, ErrNotFound) {
+				return records, err
+			}
+			lastErr = errors.Wrapf(err, "dns: query %s at %s", name, server)
+		}
+	}
+	return nil, lastErr
+}
+
+func (r *Resolver) query(ctx context.Context, server string, name dnsmessage.Name, qtype Type) ([]Record, error) {
+	// The ID is what keeps off-path spoofed answers out, so it must not
+	// be predictable.
+	n, err := random.Int(1 << 16)
+	if err != nil {
+		return nil, err
+	}
+	id := uint16(n)
+	query, err := (&dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: id, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: name, Type: qtype, Class: dnsmessage.ClassINET}},
+	}).Pack()
+	if err != nil {
+		return nil, errors.Wrap(err, "message.Pack")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	response, err := exchange(ctx, "udp", server, query)
+	if err != nil {
+		return nil, err
+	}
+	var msg dnsmessage.Message
+	if err := msg.Unpack(response); err != nil {
+		return nil, errors.Wrap(err, "message.Unpack")
+	}
+	if msg.Truncated {
+		if response, err = exchange(ctx, "tcp", server, query); err != nil {
+			return nil, err
+		}
+		if err := msg.Unpack(response); err != nil {
+			return nil, errors.Wrap(err, "message.Unpack")
+		}
+	}
+	if msg.ID != id {
+		return nil, errors.New("dns: response ID mismatch")
+	}
+
+	switch msg.RCode {
+	case dnsmessage.RCodeSuccess:
+	case dnsmessage.RCodeNameError:
+		return nil, ErrNotFound
+	default:
+		return nil, errors.Errorf("dns: server returned %s", msg.RCode)
+	}
+	return records(msg.Answers, qtype), nil
+}
+
+func exchange(ctx context.Context, network, server string, query []byte) ([]byte, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, network, server)
+	if err != nil {
+		return nil, errors.Wrap(err, "dialer.DialContext")
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if network == "tcp" {
+		framed := make([]byte, 2+len(query))
+		binary.BigEndian.PutUint16(framed, uint16(len(query)))
+		copy(framed[2:], query)
+		if _, err := conn.Write(framed); err != nil {
+			return nil, errors.Wrap(err, "conn.Write")
+		}
+		var length [2]byte
+		if _, err := io.ReadFull(conn, length[:]); err != nil {
+			return nil, errors.Wrap(err, "io.ReadFull")
+		}
+		response := make([]byte, binary.BigEndian.Uint16(length[:]))
+		if _, err := io.ReadFull(conn, response); err != nil {
+			return nil, errors.Wrap(err, "io.ReadFull")
+		}
+		return response, nil
+	}
+
+	if _, err := conn.Write(query); err != nil {
+		return nil, errors.Wrap(err, "conn.Write")
+	}
+	response := make([]byte, 4096)
+	n, err := conn.Read(response)
+	if err != nil {
+		return nil, errors.Wrap(err, "conn.Read")
+	}
+	return response[:n], nil
+}
+
+// records converts the answers of type qtype, skipping the CNAME chain
+// that recursive servers include when following aliases.
+func records(answers []dnsmessage.Resource, qtype Type) []Record {
+	var out []Record
+	for _, answer := range answers {
+		if answer.Header.Type != qtype {
+			continue
+		}
+		record := Record{
+			Name: strings.TrimSuffix(answer.Header.Name.String(), "."),
+			Type: strings.TrimPrefix(answer.Header.Type.String(), "Type"),
+			TTL:  time.Duration(answer.Header.TTL) * time.Second,
+		}
+		switch body := answer.Body.(type) {
+		case *dnsmessage.AResource:
+			record.Value = net.IP(body.A[:]).String()
+		case *dnsmessage.AAAAResource:
+			record.Value = net.IP(body.AAAA[:]).String()
+		case *dnsmessage.CNAMEResource:
+			record.Value = strings.TrimSuffix(body.CNAME.String(), ".")
+		case *dnsmessage.NSResource:
+			record.Value = strings.TrimSuffix(body.NS.String(), ".")
+		case *dnsmessage.MXResource:
+			record.Value = strings.TrimSuffix(body.MX.String(), ".")
+			record.Priority = body.Pref
+		case *dnsmessage.TXTResource:
+			record.Value = strings.Join(body.TXT, "")
+		default:
+			continue
+		}
+		out = append(out, record)
+	}
+	return out
+}
+
+func fqdn(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
+
+func systemServers() []string {
+	f, err := os.Open("/etc/resolv.conf")
+	if err != nil {
+		return FallbackServers
+	}
+	defer f.Close()
+
+	var servers []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			servers = append(servers, fields[1])
+		}
+	}
+	if len(servers) == 0 {
+		return FallbackServers
+	}
+	return servers
+}
+
+func LookupA(ctx context.Context, name string, servers ...string) ([]Record, error) {
+	return NewResolver(servers...).Lookup(ctx, name, TypeA)
+}
+
+func LookupAAAA(ctx context.Context, name string, servers ...string) ([]Record, error) {
+	return NewResolver(servers...).Lookup(ctx, name, TypeAAAA)
+}
+
+func LookupTXT(ctx context.Context, name string, servers ...string) ([]Record, error) {
+	return NewResolver(servers...).Lookup(ctx, name, TypeTXT)
+}
+
+func LookupMX(ctx context.Context, name string, servers ...string) ([]Record, error) {
+	return NewResolver(servers...).Lookup(ctx, name, TypeMX)
+}
+
+func LookupCNAME(ctx context.Context, name string, servers ...string) ([]Record, error) {
+	return NewResolver(servers...).Lookup(ctx, name, TypeCNAME)
+}
+
+// HasTXT reports whether name has a TXT record equal to value, the usual
+// domain ownership check during onboarding.
+func HasTXT(ctx context.Context, name, value string, servers ...string) (bool, error) {
+	records, err := LookupTXT(ctx, name, servers...)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return false, err
+	}
+	for _, record := range records {
+		if record.Value == value {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,118 @@
+package enc
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+func B64Encode(data []byte) string {
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+// B64Decode accepts standard base64 with or without padding and ignores
+// embedded whitespace such as line breaks.
+func B64Decode(s string) ([]byte, error) {
+	data, err := base64.RawStdEncoding.DecodeString(clean(s))
+	if err != nil {
+		return nil, errors.Wrap(err, "base64.Decode")
+	}
+	return data, nil
+}
+
+func MustB64Decode(s string) []byte {
+	data, err := B64Decode(s)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// B64URLEncode uses the URL-safe alphabet without padding, as in JWTs.
+func B64URLEncode(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// B64URLDecode accepts URL-safe base64 with or without padding.
+func B64URLDecode(s string) ([]byte, error) {
+	data, err := base64.RawURLEncoding.DecodeString(clean(s))
+	if err != nil {
+		return nil, errors.Wrap(err, "base64.Decode")
+	}
+	return data, nil
+}
+
+func MustB64URLDecode(s string) []byte {
+	data, err := B64URLDecode(s)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+func HexEncode(data []byte) string {
+	return hex.EncodeToString(data)
+}
+
+// HexDecode accepts upper or lower case digits and an optional "0x" prefix.
+func HexDecode(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		s = s[2:]
+	}
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, errors.Wrap(err, "hex.Decode")
+	}
+	return data, nil
+}
+
+func MustHexDecode(s string) []byte {
+	data, err := HexDecode(s)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// NewB64Encoder returns a writer that base64-encodes into w. Close must be
+// called to flush the final partial block.
+func NewB64Encoder(w io.Writer) io.WriteCloser {
+	return base64.NewEncoder(base64.StdEncoding, w)
+}
+
+func NewB64Decoder(r io.Reader) io.Reader {
+	return base64.NewDecoder(base64.StdEncoding, r)
+}
+
+func NewB64URLEncoder(w io.Writer) io.WriteCloser {
+	return base64.NewEncoder(base64.RawURLEncoding, w)
+}
+
+func NewB64URLDecoder(r io.Reader) io.Reader {
+	return base64.NewDecoder(base64.RawURLEncoding, r)
+}
+
+func NewHexEncoder(w io.Writer) io.Writer {
+	return hex.NewEncoder(w)
+}
+
+func NewHexDecoder(r io.Reader) io.Reader {
+	return hex.NewDecoder(r)
+}
+
+// clean drops whitespace and trailing padding so the raw encodings can
+// decode both padded and unpadded input.
+func clean(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\r', '\n':
+			return -1
+		}
+		return r
+	}, s)
+	return strings.TrimRight(s, "=")
+}
@@ -0,0 +1,182 @@
+package env
+
+import (
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type Value interface {
+	string | int | int64 | uint | float64 | bool | time.Duration | *url.URL | []string
+}
+
+// Get returns the parsed value of key, or def when it is unset, empty or
+// cannot be parsed as T.
+func Get[T Value](key string, def T) T {
+	value, err := Lookup[T](key)
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+// Lookup returns the parsed value of key, failing when it is unset, empty or
+// malformed.
+func Lookup[T Value](key string) (T, error) {
+	var zero T
+	raw, ok := os.LookupEnv(key)
+	if !ok || strings.TrimSpace(raw) == "" {
+		return zero, &MissingError{Keys: []string{key}}
+	}
+
+	value, err := parse[T](raw)
+	if err != nil {
+		return zero, errors.Wrapf(err, "env: %s", key)
+	}
+	return value, nil
+}
+
+// Must is like Lookup but panics on failure; use it only during startup.
+func Must[T Value](key string) T {
+	value, err := Lookup[T](key)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+func parse[T Value](raw string) (T, error) {
+	var value T
+	raw = strings.TrimSpace(raw)
+
+	switch v := any(&value).(type) {
+	case *string:
+		*v = raw
+	case *int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return value, errors.Wrap(err, "strconv.Atoi")
+		}
+		*v = n
+	case *int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return value, errors.Wrap(err, "strconv.ParseInt")
+		}
+		*v = n
+	case *uint:
+		n, err := strconv.ParseUint(raw, 10, 0)
+		if err != nil {
+			return value, errors.Wrap(err, "strconv.ParseUint")
+		}
+		*v = uint(n)
+	case *float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return value, errors.Wrap(err, "strconv.ParseFloat")
+		}
+		*v = f
+	case *bool:
+		b, err := parseBool(raw)
+		if err != nil {
+			return value, err
+		}
+		*v = b
+	case *time.Duration:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return value, errors.Wrap(err, "time.ParseDuration")
+		}
+		*v = d
+	case **url.URL:
+		u, err := url.Parse(raw)
+		if err != nil {
+			return value, errors.Wrap(err, "url.Parse")
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return value, errors.Errorf("invalid absolute URL %q", raw)
+		}
+		*v = u
+	case *[]string:
+		parts := strings.Split(raw, ",")
+		out := make([]string, 0, len(parts))
+		for _, part := range parts {
+			if part = strings.TrimSpace(part); part != "" {
+				out = append(out, part)
+			}
+		}
+		*v = out
+	}
+	return value, nil
+}
+
+func parseBool(raw string) (bool, error) {
+	switch strings.ToLower(raw) {
+	case "1", "t", "true", "y", "yes", "on":
+		return true, nil
+	case "0", "f", "false", "n", "no", "off":
+		return false, nil
+	}
+	return false, errors.Errorf("invalid boolean %q", raw)
+}
+
+type MissingError struct {
+	Keys []string
+}
+
+func (e *MissingError) Error() string {
+	return "env: missing required variables: " + strings.Join(e.Keys, ", ")
+}
+
+// Collector gathers every missing or invalid key so they can be reported in a
+// single error at startup instead of failing on the first one.
+//
+//	c := env.NewCollector()
+//	port := env.Require[int](c, "PORT")
+//	dsn := env.Require[string](c, "DATABASE_URL")
+//	if err := c.Err(); err != nil {
+//		log.Fatal(err)
+//	}
+type Collector struct {
+	mu      sync.Mutex
+	missing []string
+	invalid []string
+}
+
+func NewCollector() *Collector {
+	return &Collector{}
+}
+
+func Require[T Value](c *Collector, key string) T {
+	value, err := Lookup[T](key)
+	if err != nil {
+		c.mu.Lock()
+		if _, ok := err.(*MissingError); ok {
+			c.missing = append(c.missing, key)
+		} else {
+			c.invalid = append(c.invalid, err.Error())
+		}
+		c.mu.Unlock()
+	}
+	return value
+}
+
+func (c *Collector) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var parts []string
+	if len(c.missing) > 0 {
+		parts = append(parts, (&MissingError{Keys: c.missing}).Error())
+	}
+	parts = append(parts, c.invalid...)
+	if len(parts) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(parts, "; "))
+}
@@ -0,0 +1,293 @@
+package fixedwidth
+
+import (
+	"encoding"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// FieldError locates a value that could not be parsed or generated.
+type FieldError struct {
+	Line  int
+	Field string
+	Start int
+	End   int
+	Value string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	location := fmt.Sprintf("field %s (%d-%d)", e.Field, e.Start, e.End)
+	if e.Line > 0 {
+		location = fmt.Sprintf("line %d, %s", e.Line, location)
+	}
+	return fmt.Sprintf("fixedwidth: %s: %q: %v", location, e.Value, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// slice returns the runes of line at f, padding lines that end early.
+func slice(line []rune, f field) string {
+	start := f.start - 1
+	if start >= len(line) {
+		return ""
+	}
+	end := start + f.length
+	if end > len(line) {
+		end = len(line)
+	}
+	return string(line[start:end])
+}
+
+// trim removes the padding of f from raw.
+func trim(raw string, f field) string {
+	if f.kind == Alpha {
+		if f.right {
+			return strings.TrimLeft(raw, string(f.pad))
+		}
+		return strings.TrimRight(raw, string(f.pad))
+	}
+	return strings.TrimSpace(raw)
+}
+
+func decode(value reflect.Value, raw string, f field) error {
+	if value.Kind() == reflect.Ptr {
+		if strings.TrimSpace(raw) == "" {
+			return nil
+		}
+		// All-zero dates are the zero time, which a pointer leaves nil.
+		if value.Type().Elem() == timeType && strings.Trim(trim(raw, f), "0") == "" {
+			return nil
+		}
+		elem := reflect.New(value.Type().Elem())
+		if err := decode(elem.Elem(), raw, f); err != nil {
+			return err
+		}
+		value.Set(elem)
+		return nil
+	}
+
+	text := trim(raw, f)
+	if value.Type() == timeType {
+		if text == "" || strings.Trim(text, "0") == "" {
+			value.Set(reflect.Zero(timeType))
+			return nil
+		}
+		t, err := time.Parse(f.layout, text)
+		if err != nil {
+			return errors.Wrap(err, "time.Parse")
+		}
+		value.Set(reflect.ValueOf(t))
+		return nil
+	}
+	if value.CanAddr() && value.Addr().Type().Implements(textUnmarshalerType) {
+		return value.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(text))
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(text)
+		return nil
+	case reflect.Bool:
+		switch strings.ToUpper(text) {
+		case "", "0", "N", "F":
+			value.SetBool(false)
+		case "1", "S", "Y", "T":
+			value.SetBool(true)
+		default:
+			return errors.Errorf("invalid boolean")
+		}
+		return nil
+	}
+
+	if text == "" {
+		value.Set(reflect.Zero(value.Type()))
+		return nil
+	}
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(text, 10, value.Type().Bits())
+		if err != nil {
+			return errors.Wrap(err, "strconv.ParseInt")
+		}
+		value.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(text, 10, value.Type().Bits())
+		if err != nil {
+			return errors.Wrap(err, "strconv.ParseUint")
+		}
+		value.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return errors.Wrap(err, "strconv.ParseInt")
+		}
+		value.SetFloat(float64(n) / math.Pow10(f.decimals))
+	default:
+		return errors.Errorf("unsupported type %s", value.Type())
+	}
+	return nil
+}
+
+func encode(value reflect.Value, f field) (string, error) {
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return strings.Repeat(string(f.pad), f.length), nil
+		}
+		value = value.Elem()
+	}
+
+	var text string
+	switch {
+	case value.Type() == timeType:
+		t := value.Interface().(time.Time)
+		if t.IsZero() {
+			text = strings.Repeat("0", f.length)
+		} else {
+			text = t.Format(f.layout)
+		}
+	case value.Type().Implements(textMarshalerType),
+		value.CanAddr() && reflect.PtrTo(value.Type()).Implements(textMarshalerType):
+		if value.CanAddr() {
+			value = value.Addr()
+		}
+		raw, err := value.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return "", err
+		}
+		text = string(raw)
+	default:
+		switch value.Kind() {
+		case reflect.String:
+			text = value.String()
+		case reflect.Bool:
+			text = "0"
+			if value.Bool() {
+				text = "1"
+			}
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if value.Int() < 0 {
+				return "", errors.New("negative numbers are not representable")
+			}
+			text = strconv.FormatInt(value.Int(), 10)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			text = strconv.FormatUint(value.Uint(), 10)
+		case reflect.Float32, reflect.Float64:
+			scaled := math.Round(value.Float() * math.Pow10(f.decimals))
+			if scaled < 0 {
+				return "", errors.New("negative numbers are not representable")
+			}
+			text = strconv.FormatFloat(scaled, 'f', 0, 64)
+		default:
+			return "", errors.Errorf("unsupported type %s", value.Type())
+		}
+	}
+	return pad(text, f)
+}
+
+func pad(text string, f field) (string, error) {
+	n := utf8.RuneCountInString(text)
+	if n > f.length {
+		if !f.truncate || f.kind != Alpha {
+			return "", errors.Errorf("value needs %d positions, field has %d", n, f.length)
+		}
+		return string([]rune(text)[:f.length]), nil
+	}
+	padding := strings.Repeat(string(f.pad), f.length-n)
+	if f.right {
+		return padding + text, nil
+	}
+	return text + padding, nil
+}
+
+func structValue(v interface{}, op string) (reflect.Value, error) {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return reflect.Value{}, errors.Errorf("fixedwidth: %s of nil %T", op, v)
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return reflect.Value{}, errors.Errorf("fixedwidth: %s needs a struct, got %T", op, v)
+	}
+	return value, nil
+}
+
+// Unmarshal parses line into dst, a pointer to a struct with fw tags.
+// Missing trailing positions read as blank.
+func Unmarshal(line string, dst interface{}) error {
+	return unmarshal(line, dst, 0)
+}
+
+func unmarshal(line string, dst interface{}, lineNumber int) error {
+	if reflect.ValueOf(dst).Kind() != reflect.Ptr {
+		return errors.Errorf("fixedwidth: Unmarshal needs a pointer, got %T", dst)
+	}
+	value, err := structValue(dst, "Unmarshal")
+	if err != nil {
+		return err
+	}
+	fields, err := fieldsOf(value.Type())
+	if err != nil {
+		return err
+	}
+
+	runes := []rune(strings.TrimRight(line, "\r\n"))
+	for _, f := range fields {
+		raw := slice(runes, f)
+		if err := decode(value.FieldByIndex(f.index), raw, f); err != nil {
+			return &FieldError{Line: lineNumber, Field: f.name, Start: f.start, End: f.end(), Value: raw, Err: err}
+		}
+	}
+	return nil
+}
+
+// Marshal generates the line for src, a struct with fw tags. The line
+// ends at the last field; gaps between fields are filled with spaces.
+// Pointer-receiver MarshalText methods are only used when src is a
+// pointer, as with encoding/json.
+func Marshal(src interface{}) (string, error) {
+	return marshal(src, 0, 0)
+}
+
+func marshal(src interface{}, lineNumber, width int) (string, error) {
+	value, err := structValue(src, "Marshal")
+	if err != nil {
+		return "", err
+	}
+	fields, err := fieldsOf(value.Type())
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	position := 1
+	for _, f := range fields {
+		if f.start > position {
+			b.WriteString(strings.Repeat(" ", f.start-position))
+		}
+		text, err := encode(value.FieldByIndex(f.index), f)
+		if err != nil {
+			return "", &FieldError{Line: lineNumber, Field: f.name, Start: f.start, End: f.end(), Value: fmt.Sprint(value.FieldByIndex(f.index).Interface()), Err: err}
+		}
+		b.WriteString(text)
+		position = f.end() + 1
+	}
+	if width > 0 {
+		if position-1 > width {
+			return "", errors.Errorf("fixedwidth: line %d has %d positions, layout allows %d", lineNumber, position-1, width)
+		}
+		b.WriteString(strings.Repeat(" ", width-position+1))
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,218 @@
+package fixedwidth
+
+import (
+	"encoding"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Kind is how a field is laid out, following the alphanumeric (X) and
+// numeric (9) pictures of bank layouts.
+type Kind int
+
+const (
+	// Alpha is left aligned and padded with spaces.
+	Alpha Kind = iota
+	// Numeric is right aligned and padded with zeros; floats use an
+	// implied decimal point.
+	Numeric
+	// Date is formatted with a time layout, "02012006" (DDMMYYYY) by
+	// default; all-zero or blank dates are the zero time.
+	Date
+)
+
+// field is a parsed `fw:"start,length,options..."` tag. Start is 1-based,
+// as in layout specifications.
+type field struct {
+	name     string
+	index    []int
+	start    int
+	length   int
+	kind     Kind
+	pad      rune
+	right    bool
+	decimals int
+	layout   string
+	truncate bool
+}
+
+func (f field) end() int {
+	return f.start + f.length - 1
+}
+
+var layouts sync.Map
+
+// fieldsOf parses the fw tags of t, sorted by position. Fields must not
+// overlap; untagged fields are ignored and embedded structs flattened.
+func fieldsOf(t reflect.Type) ([]field, error) {
+	if cached, ok := layouts.Load(t); ok {
+		return cached.([]field), nil
+	}
+	fields, err := collect(t, nil)
+	if err != nil {
+		return nil, err
+	}
+	sortFields(fields)
+	for i := 1; i < len(fields); i++ {
+		if fields[i].start <= fields[i-1].end() {
+			return nil, errors.Errorf("fixedwidth: %s: fields %s and %s overlap", t, fields[i-1].name, fields[i].name)
+		}
+	}
+	layouts.Store(t, fields)
+	return fields, nil
+}
+
+func sortFields(fields []field) {
+	for i := 1; i < len(fields); i++ {
+		for j := i; j > 0 && fields[j].start < fields[j-1].start; j-- {
+			fields[j], fields[j-1] = fields[j-1], fields[j]
+		}
+	}
+}
+
+func collect(t reflect.Type, index []int) ([]field, error) {
+	var out []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		path := append(append([]int(nil), index...), i)
+		tag, ok := sf.Tag.Lookup("fw")
+
+		if !ok && sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			inner, err := collect(sf.Type, path)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, inner...)
+			continue
+		}
+		if !ok || tag == "-" || sf.PkgPath != "" {
+			continue
+		}
+
+		f, err := parseTag(sf, tag)
+		if err != nil {
+			return nil, errors.Wrapf(err, "fixedwidth: %s.%s", t, sf.Name)
+		}
+		f.index = path
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+func parseTag(sf reflect.StructField, tag string) (field, error) {
+	parts := strings.Split(tag, ",")
+	if len(parts) < 2 {
+		return field{}, errors.New("tag needs start and length")
+	}
+	start, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil || start < 1 {
+		return field{}, errors.Errorf("invalid start %q", parts[0])
+	}
+	length, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil || length < 1 {
+		return field{}, errors.Errorf("invalid length %q", parts[1])
+	}
+
+	f := field{name: sf.Name, start: start, length: length, kind: defaultKind(sf.Type)}
+	explicitPad := false
+	for _, option := range parts[2:] {
+		key, raw, _ := strings.Cut(strings.TrimLeft(option, " "), "=")
+		key, value := strings.TrimSpace(key), strings.TrimSpace(raw)
+		switch key {
+		case "alpha", "x":
+			f.kind = Alpha
+		case "num", "9":
+			f.kind = Numeric
+		case "date":
+			f.kind = Date
+			f.layout = value
+		case "pad":
+			// A space pad is written `pad= ` or `pad=space`.
+			if value == "space" || (value == "" && raw != "") {
+				value = " "
+			}
+			runes := []rune(value)
+			if len(runes) != 1 {
+				return field{}, errors.Errorf("invalid pad %q", value)
+			}
+			f.pad = runes[0]
+			explicitPad = true
+		case "left":
+			f.right = false
+		case "right":
+			f.right = true
+		case "decimals":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return field{}, errors.Errorf("invalid decimals %q", value)
+			}
+			f.decimals = n
+		case "truncate":
+			f.truncate = true
+		case "":
+		default:
+			return field{}, errors.Errorf("unknown option %q", key)
+		}
+	}
+
+	switch f.kind {
+	case Numeric:
+		if !explicitPad {
+			f.pad = '0'
+		}
+		if !hasAlign(parts[2:]) {
+			f.right = true
+		}
+	case Date:
+		if f.layout == "" {
+			f.layout = "02012006"
+		}
+		if !explicitPad {
+			f.pad = '0'
+		}
+	default:
+		if !explicitPad {
+			f.pad = ' '
+		}
+	}
+	return f, nil
+}
+
+func hasAlign(options []string) bool {
+	for _, option := range options {
+		if option := strings.TrimSpace(option); option == "left" || option == "right" {
+			return true
+		}
+	}
+	return false
+}
+
+func defaultKind(t reflect.Type) Kind {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return Date
+	}
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return Alpha
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return Numeric
+	}
+	return Alpha
+}
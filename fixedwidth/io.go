@@ -0,0 +1,120 @@
+package fixedwidth
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Reader reads a positional file line by line. Files with several record
+// types, such as header, detail and trailer records, are read with Scan
+// and Text and each line is decoded with the matching struct.
+type Reader struct {
+	scanner *bufio.Scanner
+	line    int
+	text    string
+}
+
+func NewReader(r io.Reader) *Reader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	return &Reader{scanner: scanner}
+}
+
+// Scan advances to the next non-empty line.
+func (r *Reader) Scan() bool {
+	for r.scanner.Scan() {
+		r.line++
+		r.text = strings.TrimRight(r.scanner.Text(), "\r")
+		if strings.TrimSpace(r.text) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Text returns the current line without its line ending.
+func (r *Reader) Text() string {
+	return r.text
+}
+
+// Line returns the 1-based number of the current line.
+func (r *Reader) Line() int {
+	return r.line
+}
+
+func (r *Reader) Err() error {
+	return errors.Wrap(r.scanner.Err(), "bufio.Scanner")
+}
+
+// Unmarshal decodes the current line into dst; a *FieldError carries the
+// line number.
+func (r *Reader) Unmarshal(dst interface{}) error {
+	return unmarshal(r.text, dst, r.line)
+}
+
+// Decode advances to the next line and decodes it into dst, returning
+// io.EOF at the end of the file.
+func (r *Reader) Decode(dst interface{}) error {
+	if !r.Scan() {
+		if err := r.Err(); err != nil {
+			return err
+		}
+		return io.EOF
+	}
+	return r.Unmarshal(dst)
+}
+
+// Writer generates a positional file.
+type Writer struct {
+	w          *bufio.Writer
+	lineEnding string
+	width      int
+	line       int
+}
+
+// NewWriter ends lines with CRLF, as bank layouts expect.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w), lineEnding: "\r\n"}
+}
+
+func (w *Writer) LineEnding(ending string) *Writer {
+	w.lineEnding = ending
+	return w
+}
+
+// Width pads every line with spaces to n positions, such as 240 or 400
+// for CNAB files, and rejects records that do not fit.
+func (w *Writer) Width(n int) *Writer {
+	w.width = n
+	return w
+}
+
+// Encode writes the line generated for record.
+func (w *Writer) Encode(record interface{}) error {
+	line, err := marshal(record, w.line+1, w.width)
+	if err != nil {
+		return err
+	}
+	return w.WriteLine(line)
+}
+
+// WriteLine writes a pre-formatted line.
+func (w *Writer) WriteLine(line string) error {
+	w.line++
+	if _, err := w.w.WriteString(line + w.lineEnding); err != nil {
+		return errors.Wrap(err, "bufio.Writer.WriteString")
+	}
+	return nil
+}
+
+// Lines returns how many lines were written.
+func (w *Writer) Lines() int {
+	return w.line
+}
+
+func (w *Writer) Flush() error {
+	return errors.Wrap(w.w.Flush(), "bufio.Writer.Flush")
+}
@@ -0,0 +1,256 @@
+package flags
+
+import (
+	"context"
+	"encoding/json"
+	"hash/fnv"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Rule turns a flag on or off for users whose Attribute is one of Values.
+type Rule struct {
+	Attribute string   `json:"attribute"`
+	Values    []string `json:"values"`
+	Enabled   bool     `json:"enabled"`
+}
+
+// Flag is a flag definition. A disabled flag is off for everyone;
+// otherwise the first matching rule decides, then the rollout, which
+// enables the flag for a stable percentage of users bucketed by the
+// RolloutBy attribute ("id" by default). Without a rollout the flag is on.
+type Flag struct {
+	Name      string   `json:"name"`
+	Enabled   bool     `json:"enabled"`
+	Rules     []Rule   `json:"rules,omitempty"`
+	Rollout   *float64 `json:"rollout,omitempty"`
+	RolloutBy string   `json:"rollout_by,omitempty"`
+}
+
+// Attributes describe the user or tenant a flag is evaluated for.
+type Attributes map[string]string
+
+type attributesKey struct{}
+
+// WithAttributes returns a copy of ctx carrying attrs for evaluation.
+func WithAttributes(ctx context.Context, attrs Attributes) context.Context {
+	return context.WithValue(ctx, attributesKey{}, attrs)
+}
+
+// AttributesFrom returns the attributes stored by WithAttributes.
+func AttributesFrom(ctx context.Context) Attributes {
+	attrs, _ := ctx.Value(attributesKey{}).(Attributes)
+	return attrs
+}
+
+// normalize makes "new-checkout", "New.Checkout" and NEW_CHECKOUT from
+// the environment name the same flag.
+func normalize(name string) string {
+	return strings.NewReplacer("-", "_", ".", "_").Replace(strings.ToLower(strings.TrimSpace(name)))
+}
+
+// Evaluate reports whether f is on for attrs.
+func (f Flag) Evaluate(attrs Attributes) bool {
+	if !f.Enabled {
+		return false
+	}
+	for _, rule := range f.Rules {
+		value, ok := attrs[rule.Attribute]
+		if !ok {
+			continue
+		}
+		for _, candidate := range rule.Values {
+			if candidate == value {
+				return rule.Enabled
+			}
+		}
+	}
+	if f.Rollout == nil {
+		return true
+	}
+
+	by := f.RolloutBy
+	if by == "" {
+		by = "id"
+	}
+	key, ok := attrs[by]
+	if !ok {
+		// Without the attribute there is no stable bucket; only a full
+		// rollout applies.
+		return *f.Rollout >= 100
+	}
+	return float64(bucket(normalize(f.Name), key)) < *f.Rollout*100
+}
+
+// bucket maps a user to 0..9999, independently for each flag so the same
+// users are not always the first to get every feature.
+func bucket(name, key string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write([]byte(key))
+	return h.Sum32() % 10000
+}
+
+// Set holds flag definitions and test overrides. It is safe for
+// concurrent use; loading replaces definitions atomically.
+type Set struct {
+	mu        sync.RWMutex
+	flags     map[string]Flag
+	overrides map[string]bool
+}
+
+func NewSet() *Set {
+	return &Set{flags: make(map[string]Flag), overrides: make(map[string]bool)}
+}
+
+// Load replaces every definition with flags.
+func (s *Set) Load(flags ...Flag) {
+	loaded := make(map[string]Flag, len(flags))
+	for _, flag := range flags {
+		loaded[normalize(flag.Name)] = flag
+	}
+	s.mu.Lock()
+	s.flags = loaded
+	s.mu.Unlock()
+}
+
+// Merge adds flags, replacing definitions with the same name.
+func (s *Set) Merge(flags ...Flag) {
+	s.mu.Lock()
+	for _, flag := range flags {
+		s.flags[normalize(flag.Name)] = flag
+	}
+	s.mu.Unlock()
+}
+
+// LoadJSON replaces definitions with a JSON array of flags.
+func (s *Set) LoadJSON(data []byte) error {
+	var flags []Flag
+	if err := json.Unmarshal(data, &flags); err != nil {
+		return errors.Wrap(err, "flags: decoding definitions")
+	}
+	s.Load(flags...)
+	return nil
+}
+
+// LoadFile replaces definitions with the JSON array in path.
+func (s *Set) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err, "os.ReadFile")
+	}
+	return s.LoadJSON(data)
+}
+
+// LoadEnv merges flags from variables named prefix plus the flag name,
+// such as FLAG_NEW_CHECKOUT. Values are booleans ("true", "0", "on") or a
+// rollout percentage such as "25%".
+func (s *Set) LoadEnv(prefix string) error {
+	var flags []Flag
+	for _, entry := range os.Environ() {
+		key, value, _ := strings.Cut(entry, "=")
+		if !strings.HasPrefix(key, prefix) || key == prefix {
+			continue
+		}
+		flag, err := parseEnv(strings.TrimPrefix(key, prefix), value)
+		if err != nil {
+			return errors.Wrapf(err, "flags: %s", key)
+		}
+		flags = append(flags, flag)
+	}
+	s.Merge(flags...)
+	return nil
+}
+
+func parseEnv(name, value string) (Flag, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasSuffix(value, "%") {
+		percent, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+		if err != nil || percent < 0 || percent > 100 {
+			return Flag{}, errors.Errorf("invalid rollout %q", value)
+		}
+		return Flag{Name: name, Enabled: true, Rollout: &percent}, nil
+	}
+	switch strings.ToLower(value) {
+	case "1", "t", "true", "on", "yes":
+		return Flag{Name: name, Enabled: true}, nil
+	case "0", "f", "false", "off", "no", "":
+		return Flag{Name: name}, nil
+	}
+	return Flag{}, errors.Errorf("invalid value %q", value)
+}
+
+// Override forces name on or off regardless of its definition, for tests.
+// The returned function restores the previous state.
+func (s *Set) Override(name string, enabled bool) (restore func()) {
+	name = normalize(name)
+	s.mu.Lock()
+	previous, had := s.overrides[name]
+	s.overrides[name] = enabled
+	s.mu.Unlock()
+
+	return func() {
+		s.mu.Lock()
+		if had {
+			s.overrides[name] = previous
+		} else {
+			delete(s.overrides, name)
+		}
+		s.mu.Unlock()
+	}
+}
+
+// ClearOverrides removes every override.
+func (s *Set) ClearOverrides() {
+	s.mu.Lock()
+	s.overrides = make(map[string]bool)
+	s.mu.Unlock()
+}
+
+// Enabled evaluates name for the attributes in ctx. Unknown flags are off.
+func (s *Set) Enabled(ctx context.Context, name string) bool {
+	name = normalize(name)
+	s.mu.RLock()
+	enabled, overridden := s.overrides[name]
+	flag, defined := s.flags[name]
+	s.mu.RUnlock()
+
+	if overridden {
+		return enabled
+	}
+	return defined && flag.Evaluate(AttributesFrom(ctx))
+}
+
+// Flags returns a copy of the current definitions.
+func (s *Set) Flags() []Flag {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	flags := make([]Flag, 0, len(s.flags))
+	for _, flag := range s.flags {
+		flags = append(flags, flag)
+	}
+	return flags
+}
+
+var defaultSet = NewSet()
+
+// Default returns the process-wide Set used by the package-level functions.
+func Default() *Set {
+	return defaultSet
+}
+
+// Enabled evaluates name in the default Set.
+func Enabled(ctx context.Context, name string) bool {
+	return defaultSet.Enabled(ctx, name)
+}
+
+// Override forces name in the default Set; call the returned function,
+// usually with defer, to restore it.
+func Override(name string, enabled bool) (restore func()) {
+	return defaultSet.Override(name, enabled)
+}
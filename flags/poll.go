@@ -0,0 +1,124 @@
+package flags
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+	"utils"
+	"utils/clock"
+	"utils/log"
+
+	"github.com/pkg/errors"
+)
+
+// Poller refreshes a Set from a URL serving a JSON array of flags. It
+// sends the last ETag in If-None-Match, so unchanged definitions cost a
+// 304 and no parsing. Its zero value is not usable; create it with
+// NewPoller.
+type Poller struct {
+	set      *Set
+	url      string
+	interval time.Duration
+	timeout  time.Duration
+	header   map[string]string
+	clock    clock.Clock
+	logger   *log.Logger
+
+	// mu serializes syncs, so that Run and direct Sync calls neither race
+	// on etag nor load older definitions over newer ones.
+	mu   sync.Mutex
+	etag string
+}
+
+func NewPoller(set *Set, url string) *Poller {
+	return &Poller{
+		set:      set,
+		url:      url,
+		interval: 30 * time.Second,
+		timeout:  5 * time.Second,
+		header:   make(map[string]string),
+		clock:    clock.Real,
+		logger:   log.Default().Named("flags"),
+	}
+}
+
+func (p *Poller) Interval(d time.Duration) *Poller {
+	p.interval = d
+	return p
+}
+
+func (p *Poller) Timeout(d time.Duration) *Poller {
+	p.timeout = d
+	return p
+}
+
+// Header adds a request header, such as Authorization.
+func (p *Poller) Header(name, value string) *Poller {
+	p.header[name] = value
+	return p
+}
+
+// Clock sets the time source of the polling interval, for tests.
+func (p *Poller) Clock(clk clock.Clock) *Poller {
+	p.clock = clock.Or(clk)
+	return p
+}
+
+func (p *Poller) Logger(logger *log.Logger) *Poller {
+	p.logger = logger
+	return p
+}
+
+// Sync fetches the definitions once, reporting whether they changed. It is
+// safe to call while Run is polling.
+func (p *Poller) Sync(ctx context.Context) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	req := utils.NewRest(http.MethodGet, p.url).Context(ctx).Timeout(p.timeout)
+	for name, value := range p.header {
+		req.AddHeader(name, value)
+	}
+	if p.etag != "" {
+		req.AddHeader("If-None-Match", p.etag)
+	}
+
+	res, err := req.Send()
+	if err != nil {
+		return false, errors.Wrap(err, "flags: fetching definitions")
+	}
+	switch {
+	case res.StatusCode == http.StatusNotModified:
+		return false, nil
+	case res.StatusCode != http.StatusOK:
+		return false, errors.Errorf("flags: fetching definitions: status %d", res.StatusCode)
+	}
+
+	if err := p.set.LoadJSON([]byte(res.Body)); err != nil {
+		return false, err
+	}
+	p.etag = ""
+	if values := http.Header(res.Header).Values("ETag"); len(values) > 0 {
+		p.etag = values[0]
+	}
+	return true, nil
+}
+
+// Run syncs immediately and then every interval until ctx is done. Failed
+// syncs are logged and the last good definitions stay in effect.
+func (p *Poller) Run(ctx context.Context) error {
+	for {
+		if changed, err := p.Sync(ctx); err != nil {
+			p.logger.Warn("sync failed", log.String("url", p.url), log.Err(err))
+		} else if changed {
+			p.logger.Info("flags updated", log.String("url", p.url))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-p.clock.After(p.interval):
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package fn
+
+import (
+	"sync"
+	"time"
+)
+
+// Debouncer delays f until calls have stopped for the configured wait, so a
+// burst of calls results in a single run.
+type Debouncer struct {
+	wait time.Duration
+	f    func()
+
+	mu      sync.Mutex
+	timer   *time.Timer
+	pending bool
+	stopped bool
+}
+
+// Debounce returns a Debouncer running f once d has passed since the last
+// Call.
+func Debounce(d time.Duration, f func()) *Debouncer {
+	return &Debouncer{wait: d, f: f}
+}
+
+func (d *Debouncer) Call() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.stopped {
+		return
+	}
+	d.pending = true
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.timer = time.AfterFunc(d.wait, d.fire)
+}
+
+func (d *Debouncer) fire() {
+	d.mu.Lock()
+	run := d.pending && !d.stopped
+	d.pending = false
+	d.mu.Unlock()
+
+	if run {
+		d.f()
+	}
+}
+
+// Flush runs a pending call immediately, in the caller's goroutine.
+func (d *Debouncer) Flush() {
+	d.mu.Lock()
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.mu.Unlock()
+	d.fire()
+}
+
+// Stop drops any pending call; later calls are ignored.
+func (d *Debouncer) Stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.stopped = true
+	d.pending = false
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+}
@@ -0,0 +1,85 @@
+package fn
+
+import (
+	"sync"
+	"time"
+)
+
+// Throttler runs f at most once per interval. The first call runs
+// immediately; calls made during the interval collapse into one trailing
+// run at its end.
+type Throttler struct {
+	interval time.Duration
+	f        func()
+
+	mu      sync.Mutex
+	last    time.Time
+	timer   *time.Timer
+	pending bool
+	stopped bool
+}
+
+func Throttle(interval time.Duration, f func()) *Throttler {
+	return &Throttler{interval: interval, f: f}
+}
+
+func (t *Throttler) Call() {
+	t.mu.Lock()
+	if t.stopped {
+		t.mu.Unlock()
+		return
+	}
+
+	wait := t.interval - time.Since(t.last)
+	if wait <= 0 && t.timer == nil {
+		t.last = time.Now()
+		t.mu.Unlock()
+		t.f()
+		return
+	}
+
+	t.pending = true
+	if t.timer == nil {
+		t.timer = time.AfterFunc(wait, t.trailing)
+	}
+	t.mu.Unlock()
+}
+
+func (t *Throttler) trailing() {
+	t.mu.Lock()
+	t.timer = nil
+	run := t.pending && !t.stopped
+	t.pending = false
+	if run {
+		t.last = time.Now()
+	}
+	t.mu.Unlock()
+
+	if run {
+		t.f()
+	}
+}
+
+// Flush runs a pending trailing call immediately, in the caller's
+// goroutine.
+func (t *Throttler) Flush() {
+	t.mu.Lock()
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+	t.mu.Unlock()
+	t.trailing()
+}
+
+// Stop drops any pending call; later calls are ignored.
+func (t *Throttler) Stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.stopped = true
+	t.pending = false
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+}
@@ -0,0 +1,160 @@
+package format
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	KB int64 = 1000
+	MB       = 1000 * KB
+	GB       = 1000 * MB
+	TB       = 1000 * GB
+	PB       = 1000 * TB
+
+	KiB int64 = 1024
+	MiB       = 1024 * KiB
+	GiB       = 1024 * MiB
+	TiB       = 1024 * GiB
+	PiB       = 1024 * TiB
+)
+
+var byteUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"k":   KB,
+	"kb":  KB,
+	"m":   MB,
+	"mb":  MB,
+	"g":   GB,
+	"gb":  GB,
+	"t":   TB,
+	"tb":  TB,
+	"p":   PB,
+	"pb":  PB,
+	"kib": KiB,
+	"mib": MiB,
+	"gib": GiB,
+	"tib": TiB,
+	"pib": PiB,
+}
+
+// Bytes formats n with SI (power of 1000) units: Bytes(1536000) returns
+// "1.5 MB".
+func Bytes(n int64) string {
+	return formatBytes(n, 1000, []string{"kB", "MB", "GB", "TB", "PB", "EB"})
+}
+
+// BytesIEC formats n with IEC (power of 1024) units: BytesIEC(1572864)
+// returns "1.5 MiB".
+func BytesIEC(n int64) string {
+	return formatBytes(n, 1024, []string{"KiB", "MiB", "GiB", "TiB", "PiB", "EiB"})
+}
+
+func formatBytes(n int64, base float64, units []string) string {
+	sign, magnitude := "", uint64(n)
+	if n < 0 {
+		// Negating in uint64 also covers math.MinInt64.
+		sign, magnitude = "-", -uint64(n)
+	}
+	v := float64(magnitude)
+	if v < base {
+		return sign + strconv.FormatUint(magnitude, 10) + " B"
+	}
+	exp := 0
+	for v /= base; v >= base && exp < len(units)-1; v /= base {
+		exp++
+	}
+	v = math.Round(v*10) / 10
+	if v >= base && exp < len(units)-1 {
+		v /= base
+		exp++
+	}
+	return sign + strings.TrimSuffix(strconv.FormatFloat(v, 'f', 1, 64), ".0") + " " + units[exp]
+}
+
+// ParseBytes parses sizes such as "512", "10 kB", "1.5MB" or "2GiB". Units
+// are case insensitive; k/M/G/T/P are powers of 1000 and the "i" forms
+// powers of 1024. Negative sizes are rejected.
+func ParseBytes(s string) (int64, error) {
+	trimmed := strings.TrimSpace(s)
+	i := strings.IndexFunc(trimmed, func(r rune) bool {
+		return !unicode.IsDigit(r) && r != '.' && r != '-' && r != '+'
+	})
+	number, unit := trimmed, ""
+	if i >= 0 {
+		number, unit = trimmed[:i], strings.TrimSpace(trimmed[i:])
+	}
+
+	multiplier, ok := byteUnits[strings.ToLower(unit)]
+	if !ok || number == "" {
+		return 0, errors.Errorf("invalid byte size %q", s)
+	}
+	if whole, err := strconv.ParseInt(number, 10, 64); err == nil {
+		// Whole numbers are exact, up to math.MaxInt64.
+		switch {
+		case whole < 0:
+			return 0, errors.Errorf("negative byte size %q", s)
+		case whole > math.MaxInt64/multiplier:
+			return 0, errors.Errorf("byte size %q out of range", s)
+		}
+		return whole * multiplier, nil
+	}
+	v, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid byte size %q", s)
+	}
+	if v < 0 {
+		return 0, errors.Errorf("negative byte size %q", s)
+	}
+	size := v * float64(multiplier)
+	if size >= math.MaxInt64 {
+		return 0, errors.Errorf("byte size %q out of range", s)
+	}
+	return int64(size), nil
+}
+
+// ByteSize is a size that reads and prints in human units, so config
+// structs can declare limits such as MaxBody ByteSize `env:"MAX_BODY"`
+// with values like "10MB".
+type ByteSize int64
+
+func (b ByteSize) String() string {
+	return Bytes(int64(b))
+}
+
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	n, err := ParseBytes(string(text))
+	if err != nil {
+		return err
+	}
+	*b = ByteSize(n)
+	return nil
+}
+
+// exactUnits are tried, largest first, to marshal sizes without rounding.
+var exactUnits = []struct {
+	name string
+	size int64
+}{
+	{"PiB", PiB}, {"PB", PB}, {"TiB", TiB}, {"TB", TB}, {"GiB", GiB},
+	{"GB", GB}, {"MiB", MiB}, {"MB", MB}, {"KiB", KiB}, {"kB", KB},
+}
+
+// MarshalText writes the exact size, in the largest unit that divides it
+// ("10MB", "512KiB") or in bytes, so it reads back unchanged.
+func (b ByteSize) MarshalText() ([]byte, error) {
+	n := int64(b)
+	if n != 0 {
+		for _, u := range exactUnits {
+			if n%u.size == 0 {
+				return []byte(strconv.FormatInt(n/u.size, 10) + u.name), nil
+			}
+		}
+	}
+	return []byte(strconv.FormatInt(n, 10)), nil
+}
@@ -0,0 +1,114 @@
+package format
+
+import (
+	"strings"
+	"sync"
+)
+
+// Locale holds the separators and patterns of a locale. In patterns, '#' is
+// replaced by the formatted number and '¤' by the currency symbol.
+type Locale struct {
+	Tag             string
+	Decimal         string
+	Thousands       string
+	CurrencyPattern string
+	PercentPattern  string
+	// Symbols overrides currency symbols for this locale, e.g. US$ in pt-BR.
+	Symbols map[string]string
+}
+
+const DefaultLocale = "en-US"
+
+var (
+	localesMu sync.RWMutex
+	locales   = map[string]Locale{
+		"en-US": {Tag: "en-US", Decimal: ".", Thousands: ",", CurrencyPattern: "¤#", PercentPattern: "#%"},
+		"en-GB": {Tag: "en-GB", Decimal: ".", Thousands: ",", CurrencyPattern: "¤#", PercentPattern: "#%", Symbols: map[string]string{"USD": "US$"}},
+		"pt-BR": {Tag: "pt-BR", Decimal: ",", Thousands: ".", CurrencyPattern: "¤ #", PercentPattern: "#%", Symbols: map[string]string{"USD": "US$"}},
+		"pt-PT": {Tag: "pt-PT", Decimal: ",", Thousands: " ", CurrencyPattern: "# ¤", PercentPattern: "#%", Symbols: map[string]string{"USD": "US$"}},
+		"es-ES": {Tag: "es-ES", Decimal: ",", Thousands: ".", CurrencyPattern: "# ¤", PercentPattern: "# %", Symbols: map[string]string{"USD": "US$"}},
+		"de-DE": {Tag: "de-DE", Decimal: ",", Thousands: ".", CurrencyPattern: "# ¤", PercentPattern: "# %"},
+		"fr-FR": {Tag: "fr-FR", Decimal: ",", Thousands: " ", CurrencyPattern: "# ¤", PercentPattern: "# %", Symbols: map[string]string{"USD": "$US"}},
+		"ja-JP": {Tag: "ja-JP", Decimal: ".", Thousands: ",", CurrencyPattern: "¤#", PercentPattern: "#%", Symbols: map[string]string{"JPY": "￥"}},
+	}
+)
+
+func RegisterLocale(l Locale) {
+	localesMu.Lock()
+	locales[l.Tag] = l
+	localesMu.Unlock()
+}
+
+// LookupLocale finds a locale by tag, accepting pt_BR or pt-br spellings and
+// falling back to any locale of the same language, then to en-US.
+func LookupLocale(tag string) Locale {
+	tag = strings.ReplaceAll(strings.TrimSpace(tag), "_", "-")
+
+	localesMu.RLock()
+	defer localesMu.RUnlock()
+
+	for t, l := range locales {
+		if strings.EqualFold(t, tag) {
+			return l
+		}
+	}
+
+	lang := strings.ToLower(strings.SplitN(tag, "-", 2)[0])
+	for _, preferred := range []string{"pt-BR", "en-US"} {
+		if strings.HasPrefix(strings.ToLower(preferred), lang+"-") {
+			return locales[preferred]
+		}
+	}
+	for t, l := range locales {
+		if strings.HasPrefix(strings.ToLower(t), lang+"-") {
+			return l
+		}
+	}
+	return locales[DefaultLocale]
+}
+
+type CurrencyInfo struct {
+	Code     string
+	Symbol   string
+	Decimals int
+}
+
+var (
+	currenciesMu sync.RWMutex
+	currencies   = map[string]CurrencyInfo{
+		"BRL": {Code: "BRL", Symbol: "R$", Decimals: 2},
+		"USD": {Code: "USD", Symbol: "$", Decimals: 2},
+		"EUR": {Code: "EUR", Symbol: "€", Decimals: 2},
+		"GBP": {Code: "GBP", Symbol: "£", Decimals: 2},
+		"JPY": {Code: "JPY", Symbol: "¥", Decimals: 0},
+		"ARS": {Code: "ARS", Symbol: "ARS", Decimals: 2},
+		"CLP": {Code: "CLP", Symbol: "CLP", Decimals: 0},
+	}
+)
+
+func RegisterCurrency(c CurrencyInfo) {
+	currenciesMu.Lock()
+	currencies[strings.ToUpper(c.Code)] = c
+	currenciesMu.Unlock()
+}
+
+// LookupCurrency returns the currency metadata; unknown codes get two
+// decimals and their code as symbol.
+func LookupCurrency(code string) CurrencyInfo {
+	code = strings.ToUpper(code)
+
+	currenciesMu.RLock()
+	defer currenciesMu.RUnlock()
+
+	if c, ok := currencies[code]; ok {
+		return c
+	}
+	return CurrencyInfo{Code: code, Symbol: code, Decimals: 2}
+}
+
+func (l Locale) symbol(code string) string {
+	if s, ok := l.Symbols[strings.ToUpper(code)]; ok {
+		return s
+	}
+	return LookupCurrency(code).Symbol
+}
@@ -0,0 +1,82 @@
+package format
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Number formats v with the given decimals and the locale separators,
+// e.g. Number(1234.5, 2, "pt-BR") returns 1.234,50.
+func Number(v float64, decimals int, locale string) string {
+	return Decimal(strconv.FormatFloat(round(v, decimals), 'f', decimals, 64), locale)
+}
+
+// Decimal applies locale separators to a plain decimal string such as
+// "-1234.56", without going through floating point.
+func Decimal(decimal string, locale string) string {
+	l := LookupLocale(locale)
+	return groupDecimal(decimal, l.Decimal, l.Thousands)
+}
+
+// Currency formats amount in major units, e.g.
+// Currency(1234.5, "BRL", "pt-BR") returns R$ 1.234,50.
+func Currency(amount float64, currency string, locale string) string {
+	decimals := LookupCurrency(currency).Decimals
+	return CurrencyDecimal(strconv.FormatFloat(round(amount, decimals), 'f', decimals, 64), currency, locale)
+}
+
+// CurrencyDecimal is like Currency for an exact decimal string, as produced
+// by money types that keep integer minor units.
+func CurrencyDecimal(decimal string, currency string, locale string) string {
+	l := LookupLocale(locale)
+
+	negative := strings.HasPrefix(decimal, "-")
+	number := groupDecimal(strings.TrimPrefix(decimal, "-"), l.Decimal, l.Thousands)
+
+	out := strings.Replace(l.CurrencyPattern, "#", number, 1)
+	out = strings.Replace(out, "¤", l.symbol(currency), 1)
+	if negative {
+		return "-" + out
+	}
+	return out
+}
+
+// Percent formats a ratio as a percentage: Percent(0.125, 1, "pt-BR")
+// returns 12,5%.
+func Percent(ratio float64, decimals int, locale string) string {
+	l := LookupLocale(locale)
+	number := Number(ratio*100, decimals, locale)
+	return strings.Replace(l.PercentPattern, "#", number, 1)
+}
+
+func round(v float64, decimals int) float64 {
+	p := math.Pow10(decimals)
+	return math.Round(v*p) / p
+}
+
+func groupDecimal(decimal, decimalSep, thousandsSep string) string {
+	sign := ""
+	if strings.HasPrefix(decimal, "-") {
+		sign, decimal = "-", decimal[1:]
+	}
+
+	whole, frac := decimal, ""
+	if i := strings.IndexByte(decimal, '.'); i >= 0 {
+		whole, frac = decimal[:i], decimal[i+1:]
+	}
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(thousandsSep)
+		}
+		b.WriteRune(r)
+	}
+	if frac != "" {
+		b.WriteString(decimalSep)
+		b.WriteString(frac)
+	}
+	return b.String()
+}
@@ -0,0 +1,216 @@
+package fsutil
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// Exists reports whether path exists. Errors other than "not exist", such
+// as permission problems, are returned so they are not mistaken for absence.
+func Exists(path string) (bool, error) {
+	_, err := os.Stat(path)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return false, errors.Wrap(err, "os.Stat")
+}
+
+func IsDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+func IsFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// CopyFile copies src to dst atomically, preserving the file mode and
+// modification time.
+func CopyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return errors.Wrap(err, "os.Open")
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return errors.Wrap(err, "file.Stat")
+	}
+	if !info.Mode().IsRegular() {
+		return errors.Errorf("%s is not a regular file", src)
+	}
+
+	if err := writeAtomic(dst, info.Mode().Perm(), func(w io.Writer) error {
+		_, err := io.Copy(w, in)
+		return errors.Wrap(err, "io.Copy")
+	}); err != nil {
+		return err
+	}
+	return errors.Wrap(os.Chtimes(dst, info.ModTime(), info.ModTime()), "os.Chtimes")
+}
+
+// CopyDir recursively copies src into dst, recreating directories with
+// their modes and symlinks as symlinks. Directories stay writable while
+// their contents are copied and get their modes at the end, so read-only
+// directories are copied too.
+func CopyDir(src, dst string) error {
+	type dirMode struct {
+		path string
+		mode fs.FileMode
+	}
+	var dirs []dirMode
+
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return errors.Wrap(err, "filepath.Rel")
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return errors.Wrap(err, "entry.Info")
+		}
+
+		switch {
+		case d.IsDir():
+			dirs = append(dirs, dirMode{target, info.Mode().Perm()})
+			return errors.Wrap(os.MkdirAll(target, info.Mode().Perm()|0o700), "os.MkdirAll")
+		case info.Mode()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return errors.Wrap(err, "os.Readlink")
+			}
+			return errors.Wrap(os.Symlink(link, target), "os.Symlink")
+		case info.Mode().IsRegular():
+			return CopyFile(path, target)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Deepest first, so a parent loses its write bit last.
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := os.Chmod(dirs[i].path, dirs[i].mode); err != nil {
+			return errors.Wrap(err, "os.Chmod")
+		}
+	}
+	return nil
+}
+
+// Move renames src to dst, falling back to copy and delete when they are
+// on different filesystems.
+func Move(src, dst string) error {
+	err := os.Rename(src, dst)
+	if err == nil {
+		return nil
+	}
+
+	var linkErr *os.LinkError
+	if !errors.As(err, &linkErr) || !isCrossDevice(linkErr.Err) {
+		return errors.Wrap(err, "os.Rename")
+	}
+
+	if IsDir(src) {
+		err = CopyDir(src, dst)
+	} else {
+		err = CopyFile(src, dst)
+	}
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(os.RemoveAll(src), "os.RemoveAll")
+}
+
+func SHA256File(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", errors.Wrap(err, "os.Open")
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", errors.Wrap(err, "io.Copy")
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// AtomicWrite replaces path with data through a temporary file in the same
+// directory, so readers never observe a partial write. An existing file
+// keeps its permissions; new files are created 0644.
+func AtomicWrite(path string, data []byte) error {
+	perm := fs.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	return writeAtomic(path, perm, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return errors.Wrap(err, "file.Write")
+	})
+}
+
+func writeAtomic(path string, perm fs.FileMode, fill func(w io.Writer) error) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return errors.Wrap(err, "os.CreateTemp")
+	}
+
+	ok := false
+	defer func() {
+		if !ok {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if err := fill(tmp); err != nil {
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		return errors.Wrap(err, "file.Chmod")
+	}
+	if err := tmp.Sync(); err != nil {
+		return errors.Wrap(err, "file.Sync")
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "file.Close")
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return errors.Wrap(err, "os.Rename")
+	}
+	ok = true
+
+	syncDir(dir)
+	return nil
+}
+
+// syncDir flushes the directory entry after a rename; failures are ignored
+// because some platforms do not support syncing directories.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	d.Sync()
+	d.Close()
+}
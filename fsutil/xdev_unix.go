@@ -0,0 +1,13 @@
+//go:build !windows
+
+package fsutil
+
+import (
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+func isCrossDevice(err error) bool {
+	return errors.Is(err, syscall.EXDEV)
+}
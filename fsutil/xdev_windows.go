@@ -0,0 +1,16 @@
+//go:build windows
+
+package fsutil
+
+import (
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+// errNotSameDevice is ERROR_NOT_SAME_DEVICE.
+const errNotSameDevice = syscall.Errno(17)
+
+func isCrossDevice(err error) bool {
+	return errors.Is(err, errNotSameDevice)
+}
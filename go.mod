@@ -1,5 +1,19 @@
 module utils
 
-go 1.17
+go 1.18
 
-require github.com/pkg/errors v0.9.1 // indirect
+require (
+	github.com/klauspost/compress v1.15.15
+	github.com/pkg/errors v0.9.1
+	github.com/redis/go-redis/v9 v9.0.5
+	golang.org/x/crypto v0.14.0
+	golang.org/x/net v0.17.0
+	golang.org/x/sys v0.13.0
+	golang.org/x/term v0.13.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+)
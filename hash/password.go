@@ -0,0 +1,162 @@
+package hash
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type Params struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultParams are above the OWASP minimum for argon2id (19 MiB of
+// memory, 2 iterations, parallelism 1), trading some login latency for a
+// margin against faster hardware. Hashes made with weaker parameters are
+// reported by NeedsRehash.
+var DefaultParams = Params{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+var (
+	ErrInvalidHash         = errors.New("hash: invalid encoded hash")
+	ErrIncompatibleVersion = errors.New("hash: incompatible argon2 version")
+)
+
+// Password hashes pw with argon2id using DefaultParams, returning the
+// encoded form $argon2id$v=19$m=65536,t=3,p=2$salt$key.
+func Password(pw string) (string, error) {
+	return PasswordWithParams(pw, DefaultParams)
+}
+
+func PasswordWithParams(pw string, p Params) (string, error) {
+	salt := make([]byte, p.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", errors.Wrap(err, "rand.Read")
+	}
+
+	key := argon2.IDKey([]byte(pw), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, p.Memory, p.Iterations, p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// VerifyPassword checks pw against an argon2id hash or a legacy bcrypt hash.
+func VerifyPassword(pw, encoded string) (bool, error) {
+	if isBcrypt(encoded) {
+		err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(pw))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		return err == nil, errors.Wrap(err, "bcrypt.CompareHashAndPassword")
+	}
+
+	p, salt, key, err := decode(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	other := argon2.IDKey([]byte(pw), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+// NeedsRehash reports whether encoded is a bcrypt hash or an argon2id hash
+// weaker than DefaultParams.
+func NeedsRehash(encoded string) bool {
+	if isBcrypt(encoded) {
+		return true
+	}
+
+	p, _, _, err := decode(encoded)
+	if err != nil {
+		return true
+	}
+	return p.Memory < DefaultParams.Memory ||
+		p.Iterations < DefaultParams.Iterations ||
+		p.Parallelism < DefaultParams.Parallelism ||
+		p.KeyLength < DefaultParams.KeyLength
+}
+
+// VerifyAndUpgrade verifies pw and, when it matches a hash that NeedsRehash,
+// also returns a fresh hash to be stored in place of the old one.
+//
+//	ok, upgraded, err := hash.VerifyAndUpgrade(pw, user.PasswordHash)
+//	if ok && upgraded != "" {
+//		user.PasswordHash = upgraded
+//	}
+func VerifyAndUpgrade(pw, encoded string) (ok bool, upgraded string, err error) {
+	ok, err = VerifyPassword(pw, encoded)
+	if err != nil || !ok {
+		return ok, "", err
+	}
+
+	if NeedsRehash(encoded) {
+		upgraded, err = Password(pw)
+		if err != nil {
+			return true, "", err
+		}
+	}
+	return true, upgraded, nil
+}
+
+func isBcrypt(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+func decode(encoded string) (Params, []byte, []byte, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return Params{}, nil, nil, ErrInvalidHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return Params{}, nil, nil, ErrInvalidHash
+	}
+	if version != argon2.Version {
+		return Params{}, nil, nil, ErrIncompatibleVersion
+	}
+
+	var p Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism); err != nil {
+		return Params{}, nil, nil, ErrInvalidHash
+	}
+	// argon2 panics on zero iterations or parallelism.
+	if p.Iterations < 1 || p.Parallelism < 1 {
+		return Params{}, nil, nil, ErrInvalidHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return Params{}, nil, nil, ErrInvalidHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return Params{}, nil, nil, ErrInvalidHash
+	}
+
+	// An empty key would match the empty output of a zero KeyLength,
+	// accepting any password.
+	if len(salt) == 0 || len(key) == 0 {
+		return Params{}, nil, nil, ErrInvalidHash
+	}
+
+	p.SaltLength = uint32(len(salt))
+	p.KeyLength = uint32(len(key))
+	return p, salt, key, nil
+}
@@ -0,0 +1,55 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+	"utils"
+	"utils/format"
+
+	"github.com/pkg/errors"
+)
+
+// DB checks that db answers a ping.
+func DB(db *sql.DB) Check {
+	return func(ctx context.Context) error {
+		return errors.Wrap(db.PingContext(ctx), "ping")
+	}
+}
+
+// HTTP checks that a GET on url answers with a status below 400, using
+// the package's HTTP client.
+func HTTP(url string) Check {
+	return func(ctx context.Context) error {
+		req := utils.NewRest(http.MethodGet, url).Context(ctx)
+		if deadline, ok := ctx.Deadline(); ok {
+			req.Timeout(time.Until(deadline))
+		}
+		res, err := req.Send()
+		if err != nil {
+			return errors.Wrap(err, "request")
+		}
+		if res.StatusCode >= 400 {
+			return errors.Errorf("status %d", res.StatusCode)
+		}
+		return nil
+	}
+}
+
+// DiskSpace checks that the filesystem holding path has at least minFree
+// bytes available to unprivileged users. It is supported on Linux, macOS,
+// FreeBSD and Windows and fails on other platforms.
+func DiskSpace(path string, minFree uint64) Check {
+	return func(context.Context) error {
+		free, err := freeSpace(path)
+		if err != nil {
+			return err
+		}
+		if free < minFree {
+			return fmt.Errorf("%s free on %s, below %s", format.Bytes(int64(free)), path, format.Bytes(int64(minFree)))
+		}
+		return nil
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package health
+
+import (
+	"runtime"
+
+	"github.com/pkg/errors"
+)
+
+func freeSpace(path string) (uint64, error) {
+	return 0, errors.Errorf("health: disk space is unsupported on %s", runtime.GOOS)
+}
@@ -0,0 +1,17 @@
+//go:build linux || darwin || freebsd
+
+package health
+
+import (
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+func freeSpace(path string) (uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, errors.Wrap(err, "statfs")
+	}
+	return uint64(stat.Bavail) * uint64(stat.Bsize), nil
+}
@@ -0,0 +1,20 @@
+//go:build windows
+
+package health
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/sys/windows"
+)
+
+func freeSpace(path string) (uint64, error) {
+	dir, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, errors.Wrap(err, "UTF16PtrFromString")
+	}
+	var free uint64
+	if err := windows.GetDiskFreeSpaceEx(dir, &free, nil, nil); err != nil {
+		return 0, errors.Wrap(err, "GetDiskFreeSpaceEx")
+	}
+	return free, nil
+}
@@ -0,0 +1,246 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Check reports a problem with a dependency by returning an error.
+type Check func(ctx context.Context) error
+
+// Status is the outcome of a check or of a whole report.
+type Status string
+
+const (
+	StatusOK   Status = "ok"
+	StatusFail Status = "fail"
+)
+
+// Result is the outcome of a single check.
+type Result struct {
+	Status  Status        `json:"status"`
+	Latency time.Duration `json:"-"`
+	Error   string        `json:"error,omitempty"`
+}
+
+func (r Result) MarshalJSON() ([]byte, error) {
+	type result Result
+	return json.Marshal(struct {
+		result
+		LatencyMS float64 `json:"latency_ms"`
+	}{result(r), float64(r.Latency.Microseconds()) / 1000})
+}
+
+// Report aggregates the results of every check; it fails when any
+// check fails.
+type Report struct {
+	Status Status            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// Failed lists the names of failing checks in order.
+func (r Report) Failed() []string {
+	var names []string
+	for name, result := range r.Checks {
+		if result.Status != StatusOK {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Checker holds the liveness and readiness checks of a service. Liveness
+// checks tell whether the process should be restarted; readiness checks
+// tell whether it should receive traffic, and usually cover its
+// dependencies. Its zero value is not usable; create it with New.
+type Checker struct {
+	mu        sync.RWMutex
+	liveness  map[string]Check
+	readiness map[string]Check
+	timeout   time.Duration
+}
+
+func New() *Checker {
+	return &Checker{
+		liveness:  make(map[string]Check),
+		readiness: make(map[string]Check),
+		timeout:   5 * time.Second,
+	}
+}
+
+// Timeout bounds each check run; a check still running is reported as
+// failed.
+func (c *Checker) Timeout(d time.Duration) *Checker {
+	c.mu.Lock()
+	c.timeout = d
+	c.mu.Unlock()
+	return c
+}
+
+// Liveness registers a check under name, replacing any previous one.
+func (c *Checker) Liveness(name string, check Check) *Checker {
+	c.mu.Lock()
+	c.liveness[name] = check
+	c.mu.Unlock()
+	return c
+}
+
+// Readiness registers a check under name, replacing any previous one.
+func (c *Checker) Readiness(name string, check Check) *Checker {
+	c.mu.Lock()
+	c.readiness[name] = check
+	c.mu.Unlock()
+	return c
+}
+
+// Live runs the liveness checks concurrently.
+func (c *Checker) Live(ctx context.Context) Report {
+	return c.run(ctx, c.liveness)
+}
+
+// Ready runs the liveness and readiness checks concurrently, since a
+// service that is not alive is not ready either.
+func (c *Checker) Ready(ctx context.Context) Report {
+	c.mu.RLock()
+	checks := make(map[string]Check, len(c.liveness)+len(c.readiness))
+	for name, check := range c.liveness {
+		checks[name] = check
+	}
+	for name, check := range c.readiness {
+		checks[name] = check
+	}
+	c.mu.RUnlock()
+	return c.run(ctx, checks)
+}
+
+func (c *Checker) run(ctx context.Context, checks map[string]Check) Report {
+	c.mu.RLock()
+	timeout := c.timeout
+	snapshot := make(map[string]Check, len(checks))
+	for name, check := range checks {
+		snapshot[name] = check
+	}
+	c.mu.RUnlock()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]Result, len(snapshot))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range snapshot {
+		wg.Add(1)
+		go func(name string, check Check) {
+			defer wg.Done()
+			result := runCheck(ctx, check, timeout)
+			mu.Lock()
+			report.Checks[name] = result
+			if result.Status != StatusOK {
+				report.Status = StatusFail
+			}
+			mu.Unlock()
+		}(name, check)
+	}
+	wg.Wait()
+	return report
+}
+
+func runCheck(ctx context.Context, check Check, timeout time.Duration) Result {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("health: check panicked: %v", r)
+			}
+		}()
+		done <- check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := Result{Status: StatusOK, Latency: time.Since(start)}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+	return result
+}
+
+func serve(w http.ResponseWriter, report Report) {
+	status := http.StatusOK
+	if report.Status != StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
+
+// LiveHandler answers 200 or 503 with the liveness report as JSON.
+func (c *Checker) LiveHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serve(w, c.Live(r.Context()))
+	})
+}
+
+// ReadyHandler answers 200 or 503 with the readiness report as JSON.
+func (c *Checker) ReadyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serve(w, c.Ready(r.Context()))
+	})
+}
+
+// Handler serves the liveness report on paths ending in /healthz or
+// /livez and the readiness report on /readyz.
+func (c *Checker) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/readyz"):
+			serve(w, c.Ready(r.Context()))
+		case strings.HasSuffix(r.URL.Path, "/healthz"), strings.HasSuffix(r.URL.Path, "/livez"):
+			serve(w, c.Live(r.Context()))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+var defaultChecker = New()
+
+// Default returns the process-wide Checker used by the package-level
+// functions, so components can register checks without wiring.
+func Default() *Checker {
+	return defaultChecker
+}
+
+// Liveness registers a check with the default Checker.
+func Liveness(name string, check Check) {
+	defaultChecker.Liveness(name, check)
+}
+
+// Readiness registers a check with the default Checker.
+func Readiness(name string, check Check) {
+	defaultChecker.Readiness(name, check)
+}
+
+// Handler serves the default Checker; mount it at /healthz and /readyz.
+func Handler() http.Handler {
+	return defaultChecker.Handler()
+}
@@ -2,23 +2,36 @@ package utils
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net"
 	"net/http"
 	"net/url"
 	"time"
+	"utils/clock"
+	"utils/compress"
+	"utils/log"
+	"utils/mask"
+	"utils/ratelimit"
+	"utils/requestid"
 
 	"github.com/pkg/errors"
 )
 
 type Client struct {
+	ctx           context.Context
 	method        string
 	url           string
 	timeout       time.Duration
 	retryAttempts int
 	retryDelay    time.Duration
 	retryRuleF    func(request *Client, response *Response, err error) bool
+	clock         clock.Clock
+	limiter       ratelimit.Limiter
+	debug         bool
+	compression   string
+	requestID     string
 	param         map[string]string
 	query         map[string][]string
 	header        map[string][]string
@@ -35,10 +48,13 @@ type Response struct {
 
 func NewRest(method string, url string) *Client {
 	rest := &Client{
+		ctx:           context.Background(),
 		method:        method,
 		url:           url,
 		timeout:       2 * time.Second,
 		retryAttempts: 0,
+		clock:         clock.Real,
+		requestID:     requestid.DefaultHeader,
 		param:         make(map[string]string),
 		query:         make(map[string][]string),
 		header:        make(map[string][]string),
@@ -58,6 +74,11 @@ func add(current []string, values ...interface{}) []string {
 	return current
 }
 
+func (c *Client) Context(ctx context.Context) *Client {
+	c.ctx = ctx
+	return c
+}
+
 func (c *Client) Timeout(timeout time.Duration) *Client {
 	c.timeout = timeout
 	return c
@@ -70,6 +91,38 @@ func (c *Client) Retry(attempts int, delay time.Duration, ruleF func(request *Cl
 	return c
 }
 
+// Clock sets the time source used to wait between retries.
+func (c *Client) Clock(clk clock.Clock) *Client {
+	c.clock = clock.Or(clk)
+	return c
+}
+
+func (c *Client) RateLimit(limiter ratelimit.Limiter) *Client {
+	c.limiter = limiter
+	return c
+}
+
+func (c *Client) Debug(debug bool) *Client {
+	c.debug = debug
+	return c
+}
+
+// Compress encodes the request body with the given Content-Encoding
+// ("gzip" or "zstd"). Responses carrying a gzip or zstd Content-Encoding
+// are always decoded.
+func (c *Client) Compress(encoding string) *Client {
+	c.compression = encoding
+	return c
+}
+
+// RequestIDHeader sets the header forwarding the request ID found in the
+// client's context, such as one stored by httpserver.RequestID; an empty
+// name stops forwarding it.
+func (c *Client) RequestIDHeader(name string) *Client {
+	c.requestID = name
+	return c
+}
+
 func (c *Client) Param(param map[string]string) *Client {
 	c.param = param
 	return c
@@ -140,9 +193,20 @@ func (c *Client) send(attempts int) (*Response, error) {
 
 	urlParsed.RawQuery = query.Encode()
 
-	req, err := http.NewRequest(c.method, urlParsed.String(), bytes.NewReader(c.body))
+	reqBody := c.body
+	if c.compression != "" && len(reqBody) > 0 {
+		reqBody, err = compress.Encode(reqBody, c.compression, compress.Default)
+		if err != nil {
+			return nil, errors.Wrap(err, "compress.Encode")
+		}
+	}
+
+	req, err := http.NewRequestWithContext(c.ctx, c.method, urlParsed.String(), bytes.NewReader(reqBody))
 	if err != nil {
-		return nil, errors.Wrap(err, "http.NewRequest")
+		return nil, errors.Wrap(err, "http.NewRequestWithContext")
+	}
+	if c.compression != "" && len(reqBody) > 0 {
+		req.Header.Set("Content-Encoding", c.compression)
 	}
 
 	for name, values := range c.header {
@@ -151,12 +215,24 @@ func (c *Client) send(attempts int) (*Response, error) {
 		}
 	}
 
+	if c.requestID != "" && req.Header.Get(c.requestID) == "" {
+		if id := requestid.FromContext(c.ctx); id != "" {
+			req.Header.Set(c.requestID, id)
+		}
+	}
+
 	for name, values := range c.form {
 		for _, value := range values {
 			req.Form.Add(name, value)
 		}
 	}
 
+	if c.limiter != nil {
+		if err := c.limiter.Wait(c.ctx); err != nil {
+			return nil, errors.Wrap(err, "limiter.Wait")
+		}
+	}
+
 	transport := http.Transport{
 		Dial: func(network, addr string) (net.Conn, error) {
 			return net.Dial(network, addr)
@@ -173,6 +249,7 @@ func (c *Client) send(attempts int) (*Response, error) {
 
 	var body []byte
 	var res *http.Response
+	start := time.Now()
 	res, responseErr = httpClient.Do(req)
 
 	if responseErr == nil {
@@ -180,6 +257,11 @@ func (c *Client) send(attempts int) (*Response, error) {
 
 		body, responseErr = ioutil.ReadAll(res.Body)
 
+		encoding := res.Header.Get("Content-Encoding")
+		if responseErr == nil && (encoding == compress.EncodingGzip || encoding == compress.EncodingZstd) {
+			body, responseErr = compress.Decode(body, encoding, compress.DefaultMaxSize)
+		}
+
 		if responseErr == nil {
 			response = &Response{
 				StatusCode: res.StatusCode,
@@ -189,12 +271,39 @@ func (c *Client) send(attempts int) (*Response, error) {
 		}
 	}
 
+	if c.debug {
+		c.logResponse(req, response, responseErr, time.Since(start))
+	}
+
 	if attempts > 0 {
 		if retry := c.retryRuleF(c, response, responseErr); retry {
-			time.Sleep(c.retryDelay)
+			select {
+			case <-c.ctx.Done():
+				return nil, errors.Wrap(c.ctx.Err(), "Client.Send")
+			case <-c.clock.After(c.retryDelay):
+			}
 			return c.send(attempts - 1)
 		}
 	}
 
 	return response, responseErr
 }
+
+func (c *Client) logResponse(req *http.Request, response *Response, err error, elapsed time.Duration) {
+	fields := []log.Field{
+		log.String("method", req.Method),
+		log.String("url", mask.URL(req.URL.String())),
+		log.Duration("elapsed", elapsed),
+	}
+	if id := requestid.FromContext(req.Context()); id != "" {
+		fields = append(fields, log.String("request_id", id))
+	}
+
+	if err != nil {
+		log.Default().Named("http").Error("request failed", append(fields, log.Err(err))...)
+		return
+	}
+
+	fields = append(fields, log.Int("status", response.StatusCode), log.Int("bytes", len(response.Body)))
+	log.Default().Named("http").Info("request sent", fields...)
+}
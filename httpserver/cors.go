@@ -0,0 +1,117 @@
+package httpserver
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORS answers preflight requests and adds the CORS headers allowing
+// browsers on the configured origins to call the wrapped handler.
+type CORS struct {
+	origins     map[string]bool
+	anyOrigin   bool
+	methods     string
+	headers     string
+	expose      string
+	credentials bool
+	maxAge      time.Duration
+}
+
+// NewCORS allows origins such as "https://app.example.com"; "*" allows any
+// origin.
+func NewCORS(origins ...string) *CORS {
+	c := &CORS{
+		origins: make(map[string]bool),
+		methods: "GET, POST, PUT, PATCH, DELETE, OPTIONS",
+		headers: "Accept, Authorization, Content-Type, " + RequestIDHeader,
+		maxAge:  10 * time.Minute,
+	}
+	for _, origin := range origins {
+		if origin == "*" {
+			c.anyOrigin = true
+		}
+		c.origins[strings.TrimSuffix(origin, "/")] = true
+	}
+	return c
+}
+
+func (c *CORS) Methods(methods ...string) *CORS {
+	c.methods = strings.Join(methods, ", ")
+	return c
+}
+
+// Headers sets the request headers a browser may send.
+func (c *CORS) Headers(headers ...string) *CORS {
+	c.headers = strings.Join(headers, ", ")
+	return c
+}
+
+// Expose sets the response headers readable by scripts.
+func (c *CORS) Expose(headers ...string) *CORS {
+	c.expose = strings.Join(headers, ", ")
+	return c
+}
+
+// Credentials allows cookies and authorization headers. The request
+// origin is then echoed instead of "*", as browsers require. It panics
+// when any origin is allowed, which would let every site make
+// credentialed reads.
+func (c *CORS) Credentials(allow bool) *CORS {
+	if allow && c.anyOrigin {
+		panic("httpserver: CORS credentials with the \"*\" origin")
+	}
+	c.credentials = allow
+	return c
+}
+
+// MaxAge is how long browsers may cache a preflight response.
+func (c *CORS) MaxAge(d time.Duration) *CORS {
+	c.maxAge = d
+	return c
+}
+
+func (c *CORS) allowed(origin string) bool {
+	return c.anyOrigin || c.origins[origin]
+}
+
+// Middleware is usable with Chain: httpserver.Chain(h, cors.Middleware).
+func (c *CORS) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		header := w.Header()
+		header.Add("Vary", "Origin")
+
+		if origin == "" || !c.allowed(origin) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if c.anyOrigin && !c.credentials {
+			header.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			header.Set("Access-Control-Allow-Origin", origin)
+		}
+		if c.credentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+			header.Set("Access-Control-Allow-Methods", c.methods)
+			header.Set("Access-Control-Allow-Headers", c.headers)
+			if c.maxAge > 0 {
+				header.Set("Access-Control-Max-Age", strconv.Itoa(int(c.maxAge.Seconds())))
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		if c.expose != "" {
+			header.Set("Access-Control-Expose-Headers", c.expose)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,195 @@
+package httpserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultMaxBodySize bounds request bodies read by DecodeJSON.
+const DefaultMaxBodySize = 1 << 20
+
+// Problem is an RFC 7807 problem details body. It is also an error, so
+// handlers can return it and have RespondProblem send it as is.
+type Problem struct {
+	Type     string
+	Title    string
+	Status   int
+	Detail   string
+	Instance string
+	// Extensions are extra members serialized next to the standard ones,
+	// such as a list of invalid fields.
+	Extensions map[string]interface{}
+}
+
+// NewProblem uses the standard status text as title.
+func NewProblem(status int, detail string) *Problem {
+	return &Problem{Status: status, Title: http.StatusText(status), Detail: detail}
+}
+
+func (p *Problem) Error() string {
+	if p.Detail == "" {
+		return fmt.Sprintf("%d %s", p.Status, p.Title)
+	}
+	return fmt.Sprintf("%d %s: %s", p.Status, p.Title, p.Detail)
+}
+
+// With adds an extension member.
+func (p *Problem) With(key string, value interface{}) *Problem {
+	if p.Extensions == nil {
+		p.Extensions = make(map[string]interface{})
+	}
+	p.Extensions[key] = value
+	return p
+}
+
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	body := make(map[string]interface{}, len(p.Extensions)+5)
+	for key, value := range p.Extensions {
+		body[key] = value
+	}
+	body["type"] = p.Type
+	if p.Type == "" {
+		body["type"] = "about:blank"
+	}
+	body["title"] = p.Title
+	body["status"] = p.Status
+	if p.Detail != "" {
+		body["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		body["instance"] = p.Instance
+	}
+	return json.Marshal(body)
+}
+
+func (p *Problem) UnmarshalJSON(data []byte) error {
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(data, &body); err != nil {
+		return err
+	}
+	standard := map[string]interface{}{
+		"type": &p.Type, "title": &p.Title, "status": &p.Status,
+		"detail": &p.Detail, "instance": &p.Instance,
+	}
+	for key, raw := range body {
+		if dst, ok := standard[key]; ok {
+			if err := json.Unmarshal(raw, dst); err != nil {
+				return errors.Wrapf(err, "problem %s", key)
+			}
+			continue
+		}
+		var value interface{}
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return err
+		}
+		p.With(key, value)
+	}
+	return nil
+}
+
+// DecodeJSON reads a JSON body of at most DefaultMaxBodySize into v.
+func DecodeJSON(r *http.Request, v interface{}) error {
+	return DecodeJSONLimit(r, v, DefaultMaxBodySize)
+}
+
+// DecodeJSONLimit reads a single JSON value into v, rejecting unknown
+// fields, trailing data and bodies over limit bytes. Failures are
+// *Problem errors with status 400, 413 or 415, ready for RespondProblem.
+func DecodeJSONLimit(r *http.Request, v interface{}, limit int64) error {
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || (mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json")) {
+			return NewProblem(http.StatusUnsupportedMediaType, "expected a JSON body")
+		}
+	}
+	if r.Body == nil {
+		return NewProblem(http.StatusBadRequest, "request body is empty")
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, limit+1))
+	if err != nil {
+		return NewProblem(http.StatusBadRequest, "reading request body failed")
+	}
+	if int64(len(body)) > limit {
+		return NewProblem(http.StatusRequestEntityTooLarge, fmt.Sprintf("request body exceeds %d bytes", limit))
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return NewProblem(http.StatusBadRequest, decodeDetail(err))
+	}
+	if err := decoder.Decode(&struct{}{}); err != io.EOF {
+		return NewProblem(http.StatusBadRequest, "request body must hold a single JSON value")
+	}
+	return nil
+}
+
+// decodeDetail describes err without echoing Go type names.
+func decodeDetail(err error) string {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case err == io.EOF:
+		return "request body is empty"
+	case errors.As(err, &syntaxErr):
+		return fmt.Sprintf("malformed JSON at offset %d", syntaxErr.Offset)
+	case errors.As(err, &typeErr):
+		if typeErr.Field != "" {
+			return fmt.Sprintf("invalid value for field %q", typeErr.Field)
+		}
+		return fmt.Sprintf("invalid value at offset %d", typeErr.Offset)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		return "unknown field " + strings.TrimPrefix(err.Error(), "json: unknown field ")
+	case err == io.ErrUnexpectedEOF:
+		return "malformed JSON: unexpected end of body"
+	}
+	return "malformed JSON"
+}
+
+// RespondJSON writes v as JSON with the given status.
+func RespondJSON(w http.ResponseWriter, status int, v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return errors.Wrap(err, "json.Marshal")
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_, err = w.Write(append(body, '\n'))
+	return err
+}
+
+// RespondProblem writes err as an application/problem+json body. A
+// *Problem anywhere in the chain is sent as is, with a missing status and
+// title filled in on a copy; any other error becomes a 500 whose detail is
+// withheld, as it may expose internals.
+func RespondProblem(w http.ResponseWriter, err error) error {
+	var found *Problem
+	if !errors.As(err, &found) {
+		found = NewProblem(http.StatusInternalServerError, "")
+	}
+	problem := *found
+	if problem.Status == 0 {
+		problem.Status = http.StatusInternalServerError
+	}
+	if problem.Title == "" {
+		problem.Title = http.StatusText(problem.Status)
+	}
+
+	body, err := json.Marshal(&problem)
+	if err != nil {
+		return errors.Wrap(err, "json.Marshal")
+	}
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(problem.Status)
+	_, err = w.Write(append(body, '\n'))
+	return err
+}
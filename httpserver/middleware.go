@@ -0,0 +1,175 @@
+package httpserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"time"
+	"utils/log"
+	"utils/requestid"
+)
+
+// Middleware wraps a handler; it works with any router built on
+// http.Handler.
+type Middleware func(http.Handler) http.Handler
+
+// Chain wraps h so the first middleware is the outermost.
+func Chain(h http.Handler, middlewares ...Middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
+
+// Recorder is a ResponseWriter that remembers the status and size of the
+// response.
+type Recorder struct {
+	http.ResponseWriter
+	Status int
+	Bytes  int64
+}
+
+func NewRecorder(w http.ResponseWriter) *Recorder {
+	if rec, ok := w.(*Recorder); ok {
+		return rec
+	}
+	return &Recorder{ResponseWriter: w}
+}
+
+func (r *Recorder) WriteHeader(status int) {
+	if r.Status == 0 {
+		r.Status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *Recorder) Write(p []byte) (int, error) {
+	if r.Status == 0 {
+		r.Status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(p)
+	r.Bytes += int64(n)
+	return n, err
+}
+
+func (r *Recorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (r *Recorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// Logging logs every request with its status, size and duration; server
+// errors are logged at error level and client errors at warn.
+func Logging(logger *log.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := NewRecorder(w)
+			next.ServeHTTP(rec, r)
+
+			status := rec.Status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			fields := []log.Field{
+				log.String("method", r.Method),
+				log.String("path", r.URL.Path),
+				log.Int("status", status),
+				log.Int64("bytes", rec.Bytes),
+				log.Duration("elapsed", time.Since(start)),
+				log.String("remote", r.RemoteAddr),
+			}
+			if requestID := RequestIDFrom(r.Context()); requestID != "" {
+				fields = append(fields, log.String("request_id", requestID))
+			}
+
+			switch {
+			case status >= 500:
+				logger.Error("request", fields...)
+			case status >= 400:
+				logger.Warn("request", fields...)
+			default:
+				logger.Info("request", fields...)
+			}
+		})
+	}
+}
+
+// Recover turns a panic in the handler into a 500 response, logging the
+// panic value and stack. http.ErrAbortHandler is re-raised, as net/http
+// uses it to abort a response silently.
+func Recover(logger *log.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+				logger.Error("panic serving request",
+					log.String("method", r.Method),
+					log.String("path", r.URL.Path),
+					log.String("panic", fmt.Sprint(v)),
+					log.String("stack", string(debug.Stack())),
+				)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequestIDHeader is the default header carrying the request ID.
+const RequestIDHeader = requestid.DefaultHeader
+
+// WithRequestID returns a copy of ctx carrying requestID.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return requestid.NewContext(ctx, requestID)
+}
+
+// RequestIDFrom returns the request ID stored by the RequestID middleware,
+// or "" when there is none.
+func RequestIDFrom(ctx context.Context) string {
+	return requestid.FromContext(ctx)
+}
+
+// RequestID reuses the ID sent in header, or generates one when it is
+// missing or implausible, and echoes it in the response. The request
+// context carries the ID, which the package's HTTP client forwards on
+// outgoing calls, and a logger from log.FromContext tagged with it. An
+// empty header means RequestIDHeader.
+func RequestID(header string) Middleware {
+	if header == "" {
+		header = RequestIDHeader
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := r.Header.Get(header)
+			if !requestid.Valid(requestID) {
+				requestID = requestid.New()
+			}
+			w.Header().Set(header, requestID)
+
+			ctx := requestid.NewContext(r.Context(), requestID)
+			ctx = log.NewContext(ctx, requestid.Logger(ctx, log.FromContext(ctx)))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Timeout cancels the request context after d and answers 503 if the
+// handler has not written a response by then.
+func Timeout(d time.Duration) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.TimeoutHandler(next, d, http.StatusText(http.StatusServiceUnavailable))
+	}
+}
@@ -0,0 +1,108 @@
+package httpserver
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+	"utils/log"
+
+	"github.com/pkg/errors"
+)
+
+// Server is an http.Server with sensible timeouts that shuts down
+// gracefully when its context is cancelled. Its zero value is not usable;
+// create it with New.
+type Server struct {
+	http            *http.Server
+	shutdownTimeout time.Duration
+	logger          *log.Logger
+}
+
+func New(addr string, handler http.Handler) *Server {
+	return &Server{
+		http: &http.Server{
+			Addr:              addr,
+			Handler:           handler,
+			ReadHeaderTimeout: 10 * time.Second,
+			ReadTimeout:       30 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+		},
+		shutdownTimeout: 15 * time.Second,
+		logger:          log.Default().Named("httpserver"),
+	}
+}
+
+// ReadTimeout bounds reading a whole request, headers included.
+func (s *Server) ReadTimeout(d time.Duration) *Server {
+	s.http.ReadTimeout = d
+	return s
+}
+
+// WriteTimeout bounds writing a response; zero disables it, as streaming
+// endpoints need.
+func (s *Server) WriteTimeout(d time.Duration) *Server {
+	s.http.WriteTimeout = d
+	return s
+}
+
+func (s *Server) IdleTimeout(d time.Duration) *Server {
+	s.http.IdleTimeout = d
+	return s
+}
+
+// ShutdownTimeout is how long in-flight requests get to finish after the
+// context is cancelled before connections are closed.
+func (s *Server) ShutdownTimeout(d time.Duration) *Server {
+	s.shutdownTimeout = d
+	return s
+}
+
+func (s *Server) Logger(logger *log.Logger) *Server {
+	s.logger = logger
+	return s
+}
+
+// Run listens on the address and serves until ctx is cancelled, then shuts
+// down gracefully. It returns nil after a clean shutdown. Request contexts
+// do not derive from ctx, so in-flight requests are not cancelled with it.
+func (s *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.http.Addr)
+	if err != nil {
+		return errors.Wrap(err, "net.Listen")
+	}
+	return s.Serve(ctx, listener)
+}
+
+// Serve is Run on an existing listener, such as one on port 0 in tests.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	failed := make(chan error, 1)
+	go func() {
+		if err := s.http.Serve(listener); err != nil && err != http.ErrServerClosed {
+			failed <- err
+		}
+		close(failed)
+	}()
+	s.logger.Info("listening", log.String("addr", listener.Addr().String()))
+
+	select {
+	case err := <-failed:
+		return errors.Wrap(err, "http.Serve")
+	case <-ctx.Done():
+	}
+
+	s.logger.Info("shutting down", log.Duration("timeout", s.shutdownTimeout))
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+	if err := s.http.Shutdown(shutdownCtx); err != nil {
+		s.http.Close()
+		return errors.Wrap(err, "http.Shutdown")
+	}
+	return nil
+}
+
+// Run serves handler on addr until ctx is cancelled, with default timeouts.
+func Run(ctx context.Context, addr string, handler http.Handler) error {
+	return New(addr, handler).Run(ctx)
+}
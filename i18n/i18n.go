@@ -0,0 +1,328 @@
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Plural categories, as named by CLDR.
+const (
+	Zero  = "zero"
+	One   = "one"
+	Two   = "two"
+	Few   = "few"
+	Many  = "many"
+	Other = "other"
+)
+
+// PluralRule returns the category of count in a language.
+type PluralRule func(count float64) string
+
+var (
+	pluralRulesMu sync.RWMutex
+	pluralRules   = map[string]PluralRule{
+		"en": func(n float64) string {
+			if n == 1 {
+				return One
+			}
+			return Other
+		},
+		// Brazilian Portuguese treats zero as singular: "0 item".
+		"pt": func(n float64) string {
+			if n >= 0 && n < 2 && n == float64(int64(n)) {
+				return One
+			}
+			return Other
+		},
+		"pt-PT": func(n float64) string {
+			if n == 1 {
+				return One
+			}
+			return Other
+		},
+	}
+)
+
+// message is either a plain string or plural forms by category.
+type message struct {
+	text   string
+	plural map[string]string
+}
+
+// Bundle holds message catalogs by locale. It is safe for concurrent use.
+type Bundle struct {
+	mu            sync.RWMutex
+	catalogs      map[string]map[string]message
+	fallbacks     map[string][]string
+	defaultLocale string
+}
+
+// NewBundle falls back to defaultLocale when a message is missing from
+// the requested locale and its base language.
+func NewBundle(defaultLocale string) *Bundle {
+	return &Bundle{
+		catalogs:      make(map[string]map[string]message),
+		fallbacks:     make(map[string][]string),
+		defaultLocale: Normalize(defaultLocale),
+	}
+}
+
+// Normalize formats a locale as language-REGION, so "pt_br" and "PT-BR"
+// become "pt-BR".
+func Normalize(locale string) string {
+	lang, region, found := strings.Cut(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"), "-")
+	if !found {
+		return strings.ToLower(lang)
+	}
+	return strings.ToLower(lang) + "-" + strings.ToUpper(region)
+}
+
+// Fallback sets the locales tried, in order, after locale itself and
+// before the default locale, replacing the implicit base language.
+func (b *Bundle) Fallback(locale string, chain ...string) *Bundle {
+	normalized := make([]string, len(chain))
+	for i, fallback := range chain {
+		normalized[i] = Normalize(fallback)
+	}
+	b.mu.Lock()
+	b.fallbacks[Normalize(locale)] = normalized
+	b.mu.Unlock()
+	return b
+}
+
+// Add merges messages into the catalog of locale. Values are strings or,
+// for plurals, objects keyed by category ("one", "other"...); other
+// objects nest keys with dots, so {"mail": {"subject": "..."}} defines
+// "mail.subject".
+func (b *Bundle) Add(locale string, messages map[string]interface{}) error {
+	flat := make(map[string]message)
+	if err := flatten("", messages, flat); err != nil {
+		return errors.Wrapf(err, "i18n: locale %s", locale)
+	}
+
+	locale = Normalize(locale)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	catalog := b.catalogs[locale]
+	if catalog == nil {
+		catalog = make(map[string]message)
+		b.catalogs[locale] = catalog
+	}
+	for key, msg := range flat {
+		catalog[key] = msg
+	}
+	return nil
+}
+
+func flatten(prefix string, messages map[string]interface{}, out map[string]message) error {
+	for key, value := range messages {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		switch value := value.(type) {
+		case string:
+			out[key] = message{text: value}
+		case map[string]interface{}:
+			if forms, ok := pluralForms(value); ok {
+				out[key] = message{plural: forms}
+				continue
+			}
+			if err := flatten(key, value, out); err != nil {
+				return err
+			}
+		default:
+			return errors.Errorf("key %s: unsupported value %T", key, value)
+		}
+	}
+	return nil
+}
+
+func pluralForms(value map[string]interface{}) (map[string]string, bool) {
+	forms := make(map[string]string, len(value))
+	for category, form := range value {
+		switch category {
+		case Zero, One, Two, Few, Many, Other:
+		default:
+			return nil, false
+		}
+		text, ok := form.(string)
+		if !ok {
+			return nil, false
+		}
+		forms[category] = text
+	}
+	_, hasOther := forms[Other]
+	return forms, hasOther
+}
+
+// LoadJSON merges a JSON catalog into locale.
+func (b *Bundle) LoadJSON(locale string, data []byte) error {
+	var messages map[string]interface{}
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return errors.Wrapf(err, "i18n: decoding %s catalog", locale)
+	}
+	return b.Add(locale, messages)
+}
+
+// LoadFS loads every <locale>.json file in dir of fsys, such as an
+// embed.FS with locales/en.json and locales/pt-BR.json.
+func (b *Bundle) LoadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return errors.Wrap(err, "fs.ReadDir")
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return errors.Wrap(err, "fs.ReadFile")
+		}
+		if err := b.LoadJSON(strings.TrimSuffix(entry.Name(), ".json"), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadDir loads every <locale>.json file in a directory on disk.
+func (b *Bundle) LoadDir(dir string) error {
+	return b.LoadFS(os.DirFS(dir), ".")
+}
+
+// chain lists the locales tried for locale: itself, its explicit
+// fallbacks or else its base language, then the default locale.
+func (b *Bundle) chain(locale string) []string {
+	chain := []string{locale}
+	if fallbacks, ok := b.fallbacks[locale]; ok {
+		chain = append(chain, fallbacks...)
+	} else if lang, _, found := strings.Cut(locale, "-"); found {
+		chain = append(chain, lang)
+	}
+	return append(chain, b.defaultLocale)
+}
+
+func (b *Bundle) lookup(locale, key string) (message, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, candidate := range b.chain(Normalize(locale)) {
+		if msg, ok := b.catalogs[candidate][key]; ok {
+			return msg, true
+		}
+	}
+	return message{}, false
+}
+
+// Has reports whether key resolves for locale, fallbacks included.
+func (b *Bundle) Has(locale, key string) bool {
+	_, ok := b.lookup(locale, key)
+	return ok
+}
+
+// T returns the message key in locale formatted with args as by
+// fmt.Sprintf. For plural messages the first numeric argument selects the
+// form by the rule of locale, with an explicit "zero" form taking
+// precedence for 0. Missing keys return the key itself, so gaps show up
+// without breaking output.
+func (b *Bundle) T(locale, key string, args ...interface{}) string {
+	msg, ok := b.lookup(locale, key)
+	if !ok {
+		return key
+	}
+
+	text := msg.text
+	if msg.plural != nil {
+		text = msg.plural[Other]
+		if count, ok := firstNumber(args); ok {
+			if form, ok := msg.plural[category(Normalize(locale), count)]; ok {
+				text = form
+			}
+			if form, ok := msg.plural[Zero]; ok && count == 0 {
+				text = form
+			}
+		}
+	}
+	if len(args) == 0 {
+		return text
+	}
+	// Forms such as "no items" use fewer arguments than the call passes;
+	// drop the complaint fmt appends for them.
+	out := fmt.Sprintf(text, args...)
+	if i := strings.Index(out, "%!(EXTRA "); i >= 0 && strings.HasSuffix(out, ")") {
+		out = out[:i]
+	}
+	return out
+}
+
+func category(locale string, count float64) string {
+	pluralRulesMu.RLock()
+	rule, ok := pluralRules[locale]
+	if !ok {
+		lang, _, _ := strings.Cut(locale, "-")
+		if rule, ok = pluralRules[lang]; !ok {
+			rule = pluralRules["en"]
+		}
+	}
+	pluralRulesMu.RUnlock()
+	return rule(count)
+}
+
+func firstNumber(args []interface{}) (float64, bool) {
+	for _, arg := range args {
+		switch n := arg.(type) {
+		case int:
+			return float64(n), true
+		case int8:
+			return float64(n), true
+		case int16:
+			return float64(n), true
+		case int32:
+			return float64(n), true
+		case int64:
+			return float64(n), true
+		case uint:
+			return float64(n), true
+		case uint8:
+			return float64(n), true
+		case uint16:
+			return float64(n), true
+		case uint32:
+			return float64(n), true
+		case uint64:
+			return float64(n), true
+		case float32:
+			return float64(n), true
+		case float64:
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+// RegisterPluralRule adds or replaces the rule of a language ("fr") or
+// locale ("pt-PT").
+func RegisterPluralRule(locale string, rule PluralRule) {
+	pluralRulesMu.Lock()
+	pluralRules[Normalize(locale)] = rule
+	pluralRulesMu.Unlock()
+}
+
+var defaultBundle = NewBundle("en")
+
+// Default returns the bundle used by the package-level T.
+func Default() *Bundle {
+	return defaultBundle
+}
+
+// T translates key with the default bundle.
+func T(locale, key string, args ...interface{}) string {
+	return defaultBundle.T(locale, key, args...)
+}
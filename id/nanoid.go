@@ -0,0 +1,67 @@
+package id
+
+import (
+	"crypto/rand"
+	"math/bits"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	NanoAlphabet = "_-0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	NanoSize     = 21
+)
+
+// NanoID returns a 21-character URL-safe ID with about the same collision
+// resistance as a UUIDv4.
+func NanoID() (string, error) {
+	return CustomNanoID(NanoAlphabet, NanoSize)
+}
+
+// CustomNanoID draws size characters uniformly from alphabet, discarding
+// random bytes outside the alphabet's bit mask to avoid modulo bias.
+func CustomNanoID(alphabet string, size int) (string, error) {
+	if len(alphabet) < 2 || len(alphabet) > 255 {
+		return "", errors.New("id: alphabet must have between 2 and 255 characters")
+	}
+	if size < 1 {
+		return "", errors.New("id: size must be positive")
+	}
+
+	mask := byte(1<<bits.Len(uint(len(alphabet)-1)) - 1)
+	step := 1.6 * float64(mask) * float64(size) / float64(len(alphabet))
+
+	out := make([]byte, 0, size)
+	buf := make([]byte, int(step)+1)
+	for {
+		if _, err := rand.Read(buf); err != nil {
+			return "", errors.Wrap(err, "rand.Read")
+		}
+		for _, b := range buf {
+			if idx := int(b & mask); idx < len(alphabet) {
+				out = append(out, alphabet[idx])
+				if len(out) == size {
+					return string(out), nil
+				}
+			}
+		}
+	}
+}
+
+// ValidNanoID reports whether s has the given size and only characters
+// from alphabet.
+func ValidNanoID(s, alphabet string, size int) bool {
+	if len(s) != size {
+		return false
+	}
+	allowed := [256]bool{}
+	for i := 0; i < len(alphabet); i++ {
+		allowed[alphabet[i]] = true
+	}
+	for i := 0; i < len(s); i++ {
+		if !allowed[s[i]] {
+			return false
+		}
+	}
+	return true
+}
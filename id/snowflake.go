@@ -0,0 +1,106 @@
+package id
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	snowflakeNodeBits     = 10
+	snowflakeSequenceBits = 12
+	snowflakeMaxNode      = 1<<snowflakeNodeBits - 1
+	snowflakeMaxSequence  = 1<<snowflakeSequenceBits - 1
+	snowflakeMaxMillis    = 1<<41 - 1
+)
+
+// DefaultEpoch is 2020-01-01 UTC, which leaves room for about 69 years.
+var DefaultEpoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Snowflake generates 64-bit time-ordered IDs made of 41 bits of
+// milliseconds since the epoch, 10 bits of node ID and a 12-bit sequence.
+//
+// If the wall clock moves backwards, the generator keeps issuing IDs from
+// the last timestamp it saw, borrowing future milliseconds when the
+// sequence wraps, so IDs never decrease.
+type Snowflake struct {
+	mu         sync.Mutex
+	epoch      time.Time
+	node       int64
+	lastMillis int64
+	sequence   int64
+}
+
+type SnowflakeParts struct {
+	Time     time.Time
+	Node     int64
+	Sequence int64
+}
+
+func NewSnowflake(node int64) (*Snowflake, error) {
+	if node < 0 || node > snowflakeMaxNode {
+		return nil, errors.Errorf("id: snowflake node must be between 0 and %d", snowflakeMaxNode)
+	}
+	return &Snowflake{epoch: DefaultEpoch, node: node}, nil
+}
+
+func (s *Snowflake) Epoch(epoch time.Time) *Snowflake {
+	s.mu.Lock()
+	s.epoch = epoch
+	s.mu.Unlock()
+	return s
+}
+
+func (s *Snowflake) Next() (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	millis := time.Since(s.epoch).Milliseconds()
+	if millis < 0 {
+		return 0, errors.New("id: clock is before the snowflake epoch")
+	}
+
+	if millis <= s.lastMillis {
+		s.sequence = (s.sequence + 1) & snowflakeMaxSequence
+		if s.sequence == 0 {
+			s.lastMillis++
+		}
+		millis = s.lastMillis
+	} else {
+		s.sequence = 0
+		s.lastMillis = millis
+	}
+
+	if millis > snowflakeMaxMillis {
+		return 0, errors.New("id: snowflake timestamp overflow")
+	}
+
+	return millis<<(snowflakeNodeBits+snowflakeSequenceBits) |
+		s.node<<snowflakeSequenceBits |
+		s.sequence, nil
+}
+
+func (s *Snowflake) MustNext() int64 {
+	id, err := s.Next()
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+func (s *Snowflake) Decode(id int64) SnowflakeParts {
+	s.mu.Lock()
+	epoch := s.epoch
+	s.mu.Unlock()
+	return DecodeSnowflake(id, epoch)
+}
+
+func DecodeSnowflake(id int64, epoch time.Time) SnowflakeParts {
+	millis := id >> (snowflakeNodeBits + snowflakeSequenceBits)
+	return SnowflakeParts{
+		Time:     epoch.Add(time.Duration(millis) * time.Millisecond),
+		Node:     (id >> snowflakeSequenceBits) & snowflakeMaxNode,
+		Sequence: id & snowflakeMaxSequence,
+	}
+}
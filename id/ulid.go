@@ -0,0 +1,159 @@
+package id
+
+import (
+	"crypto/rand"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ULID is a 128-bit identifier with a 48-bit millisecond timestamp and 80
+// random bits, encoded as 26 Crockford base32 characters that sort
+// lexicographically by time.
+type ULID [16]byte
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+var (
+	ErrInvalidULID = errors.New("id: invalid ULID")
+
+	crockfordIndex [256]byte
+
+	ulidState struct {
+		sync.Mutex
+		lastMillis uint64
+		last       ULID
+	}
+)
+
+func init() {
+	for i := range crockfordIndex {
+		crockfordIndex[i] = 0xff
+	}
+	for i := 0; i < len(crockford); i++ {
+		crockfordIndex[crockford[i]] = byte(i)
+		crockfordIndex[strings.ToLower(crockford)[i]] = byte(i)
+	}
+	// Crockford aliases
+	for alias, value := range map[byte]byte{'O': 0, 'o': 0, 'I': 1, 'i': 1, 'L': 1, 'l': 1} {
+		crockfordIndex[alias] = value
+	}
+}
+
+// NewULID is monotonic: IDs created in the same millisecond increment the
+// random part of the previous one instead of drawing new randomness.
+func NewULID() (ULID, error) {
+	ulidState.Lock()
+	defer ulidState.Unlock()
+
+	millis := uint64(time.Now().UnixMilli())
+	if millis <= ulidState.lastMillis {
+		next := ulidState.last
+		for i := 15; i >= 6; i-- {
+			next[i]++
+			if next[i] != 0 {
+				break
+			}
+			if i == 6 {
+				return ULID{}, errors.New("id: ULID random part overflow")
+			}
+		}
+		ulidState.last = next
+		return next, nil
+	}
+
+	var u ULID
+	u[0] = byte(millis >> 40)
+	u[1] = byte(millis >> 32)
+	u[2] = byte(millis >> 24)
+	u[3] = byte(millis >> 16)
+	u[4] = byte(millis >> 8)
+	u[5] = byte(millis)
+	if _, err := io.ReadFull(rand.Reader, u[6:]); err != nil {
+		return ULID{}, errors.Wrap(err, "rand.Read")
+	}
+
+	ulidState.lastMillis = millis
+	ulidState.last = u
+	return u, nil
+}
+
+func MustULID() ULID {
+	u, err := NewULID()
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+func (u ULID) Time() time.Time {
+	millis := int64(u[0])<<40 | int64(u[1])<<32 | int64(u[2])<<24 | int64(u[3])<<16 | int64(u[4])<<8 | int64(u[5])
+	return time.UnixMilli(millis)
+}
+
+func (u ULID) String() string {
+	// 128 bits in 26 characters: the first character carries only 3 bits
+	var out [26]byte
+	var carry uint
+	var bits uint
+	pos := 25
+	for i := 15; i >= 0; i-- {
+		carry |= uint(u[i]) << bits
+		bits += 8
+		for bits >= 5 {
+			out[pos] = crockford[carry&0x1f]
+			pos--
+			carry >>= 5
+			bits -= 5
+		}
+	}
+	out[0] = crockford[carry&0x1f]
+	return string(out[:])
+}
+
+func ParseULID(s string) (ULID, error) {
+	if len(s) != 26 || crockfordIndex[s[0]] > 7 {
+		return ULID{}, ErrInvalidULID
+	}
+
+	var u ULID
+	var acc uint
+	var bits uint
+	pos := 15
+	for i := 25; i >= 0; i-- {
+		v := crockfordIndex[s[i]]
+		if v == 0xff {
+			return ULID{}, ErrInvalidULID
+		}
+		acc |= uint(v) << bits
+		bits += 5
+		for bits >= 8 && pos >= 0 {
+			u[pos] = byte(acc)
+			pos--
+			acc >>= 8
+			bits -= 8
+		}
+	}
+	return u, nil
+}
+
+func ValidULID(s string) bool {
+	_, err := ParseULID(s)
+	return err == nil
+}
+
+func (u ULID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+func (u *ULID) UnmarshalText(text []byte) error {
+	parsed, err := ParseULID(string(text))
+	if err != nil {
+		return err
+	}
+	*u = parsed
+	return nil
+}
@@ -0,0 +1,162 @@
+package id
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type UUID [16]byte
+
+var (
+	Nil            UUID
+	ErrInvalidUUID = errors.New("id: invalid UUID")
+)
+
+func NewV4() (UUID, error) {
+	var u UUID
+	if _, err := io.ReadFull(rand.Reader, u[:]); err != nil {
+		return Nil, errors.Wrap(err, "rand.Read")
+	}
+	u[6] = u[6]&0x0f | 0x40
+	u[8] = u[8]&0x3f | 0x80
+	return u, nil
+}
+
+var v7 struct {
+	sync.Mutex
+	lastMillis uint64
+	seq        uint16
+}
+
+// NewV7 returns a time-ordered UUID (RFC 9562): 48 bits of Unix milliseconds
+// followed by random bits. Within the same millisecond the 12-bit rand_a
+// field acts as a counter so IDs from one process stay strictly increasing.
+func NewV7() (UUID, error) {
+	var u UUID
+	if _, err := io.ReadFull(rand.Reader, u[:]); err != nil {
+		return Nil, errors.Wrap(err, "rand.Read")
+	}
+
+	v7.Lock()
+	millis := uint64(time.Now().UnixMilli())
+	if millis <= v7.lastMillis {
+		v7.seq++
+		if v7.seq > 0x0fff {
+			v7.lastMillis++
+			v7.seq = 0
+		}
+		millis = v7.lastMillis
+	} else {
+		v7.lastMillis = millis
+		v7.seq = binary.BigEndian.Uint16(u[6:8]) & 0x07ff
+	}
+	seq := v7.seq
+	v7.Unlock()
+
+	u[0] = byte(millis >> 40)
+	u[1] = byte(millis >> 32)
+	u[2] = byte(millis >> 24)
+	u[3] = byte(millis >> 16)
+	u[4] = byte(millis >> 8)
+	u[5] = byte(millis)
+	u[6] = 0x70 | byte(seq>>8)
+	u[7] = byte(seq)
+	u[8] = u[8]&0x3f | 0x80
+	return u, nil
+}
+
+func MustV4() UUID {
+	u, err := NewV4()
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+func MustV7() UUID {
+	u, err := NewV7()
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+// ParseUUID accepts the canonical form, optionally braced, urn:uuid:
+// prefixed or without hyphens.
+func ParseUUID(s string) (UUID, error) {
+	s = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(s)), "urn:uuid:")
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "{"), "}")
+
+	switch len(s) {
+	case 36:
+		if s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+			return Nil, ErrInvalidUUID
+		}
+		s = s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+	case 32:
+	default:
+		return Nil, ErrInvalidUUID
+	}
+
+	var u UUID
+	if _, err := hex.Decode(u[:], []byte(s)); err != nil {
+		return Nil, ErrInvalidUUID
+	}
+	return u, nil
+}
+
+func ValidUUID(s string) bool {
+	_, err := ParseUUID(s)
+	return err == nil
+}
+
+func (u UUID) Version() int {
+	return int(u[6] >> 4)
+}
+
+// Time returns the timestamp embedded in a version 7 UUID.
+func (u UUID) Time() (time.Time, bool) {
+	if u.Version() != 7 {
+		return time.Time{}, false
+	}
+	millis := int64(u[0])<<40 | int64(u[1])<<32 | int64(u[2])<<24 | int64(u[3])<<16 | int64(u[4])<<8 | int64(u[5])
+	return time.UnixMilli(millis), true
+}
+
+func (u UUID) IsNil() bool {
+	return u == Nil
+}
+
+func (u UUID) String() string {
+	var buf [36]byte
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf[:])
+}
+
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+func (u *UUID) UnmarshalText(text []byte) error {
+	parsed, err := ParseUUID(string(text))
+	if err != nil {
+		return err
+	}
+	*u = parsed
+	return nil
+}
@@ -0,0 +1,180 @@
+package img
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+
+	"github.com/pkg/errors"
+)
+
+// Orientation returns the EXIF orientation (1 to 8) of JPEG data, or 1
+// when there is none.
+func Orientation(data []byte) int {
+	for _, seg := range jpegSegments(data) {
+		if seg.marker != 0xe1 || !bytes.HasPrefix(seg.payload, []byte("Exif\x00\x00")) {
+			continue
+		}
+		if o := tiffOrientation(seg.payload[6:]); o >= 1 && o <= 8 {
+			return o
+		}
+	}
+	return 1
+}
+
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+	offset := int(order.Uint32(tiff[4:8]))
+	if offset+2 > len(tiff) {
+		return 0
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	for i := 0; i < count; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			return int(order.Uint16(tiff[entry+8:]))
+		}
+	}
+	return 0
+}
+
+type segment struct {
+	marker  byte
+	start   int // offset of the 0xff marker
+	end     int
+	payload []byte
+}
+
+// jpegSegments lists the marker segments before the image data.
+func jpegSegments(data []byte) []segment {
+	if !bytes.HasPrefix(data, []byte{0xff, 0xd8}) {
+		return nil
+	}
+	var out []segment
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xff {
+			break
+		}
+		marker := data[i+1]
+		if marker == 0xff {
+			i++ // fill byte
+			continue
+		}
+		if marker == 0xda || marker == 0xd9 {
+			break // start of scan or end of image
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		end := i + 2 + length
+		if length < 2 || end > len(data) {
+			break
+		}
+		out = append(out, segment{marker: marker, start: i, end: end, payload: data[i+4 : end]})
+		i = end
+	}
+	return out
+}
+
+// Orient rotates and flips m to undo an EXIF orientation.
+func Orient(m image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return m
+	}
+	src := toRGBA(m)
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = w-1-x, y
+			case 3:
+				sx, sy = w-1-x, h-1-y
+			case 4:
+				sx, sy = x, h-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, h-1-x
+			case 7:
+				sx, sy = w-1-y, h-1-x
+			case 8:
+				sx, sy = w-1-y, x
+			}
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], src.Pix[src.PixOffset(sx, sy):])
+		}
+	}
+	return dst
+}
+
+// StripMetadata removes EXIF, XMP, IPTC and comments from JPEG data and
+// the text, time and EXIF chunks from PNG data, without re-encoding.
+// Color profiles are kept. The EXIF orientation is lost with the rest,
+// so photos that rely on it should go through Decode and Encode instead.
+func StripMetadata(data []byte) ([]byte, error) {
+	format, _ := Sniff(data)
+	switch format {
+	case JPEG:
+		return stripJPEG(data), nil
+	case PNG:
+		return stripPNG(data)
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+func stripJPEG(data []byte) []byte {
+	segments := jpegSegments(data)
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:2]...)
+	rest := 2
+	for _, seg := range segments {
+		rest = seg.end
+		switch seg.marker {
+		case 0xe1, 0xed, 0xfe: // APP1 (EXIF, XMP), APP13 (IPTC), COM
+			continue
+		}
+		out = append(out, data[seg.start:seg.end]...)
+	}
+	return append(out, data[rest:]...)
+}
+
+var pngMetadata = map[string]bool{"eXIf": true, "tEXt": true, "zTXt": true, "iTXt": true, "tIME": true}
+
+func stripPNG(data []byte) ([]byte, error) {
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:8]...)
+	for i := 8; i < len(data); {
+		if i+8 > len(data) {
+			return nil, errors.New("img: truncated PNG chunk")
+		}
+		length := int(binary.BigEndian.Uint32(data[i:]))
+		end := i + 12 + length
+		if length < 0 || end > len(data) {
+			return nil, errors.New("img: truncated PNG chunk")
+		}
+		if !pngMetadata[string(data[i+4:i+8])] {
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	return out, nil
+}
@@ -0,0 +1,123 @@
+package img
+
+import (
+	"bytes"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+type Format string
+
+const (
+	JPEG Format = "jpeg"
+	PNG  Format = "png"
+	GIF  Format = "gif"
+	WebP Format = "webp"
+)
+
+// DefaultMaxPixels bounds the images Decode accepts, rejecting
+// decompression bombs before their pixels are allocated.
+const DefaultMaxPixels = 50_000_000
+
+// DefaultMaxBytes bounds the encoded size Decode reads into memory.
+const DefaultMaxBytes = 32 << 20
+
+// DefaultQuality is the JPEG quality used when none is given.
+const DefaultQuality = 85
+
+var (
+	ErrUnsupportedFormat = errors.New("img: unsupported format")
+	ErrTooLarge          = errors.New("img: image exceeds size limit")
+)
+
+// Sniff detects the format from the first bytes of data.
+func Sniff(data []byte) (Format, bool) {
+	switch {
+	case bytes.HasPrefix(data, []byte("\xff\xd8\xff")):
+		return JPEG, true
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return PNG, true
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		return GIF, true
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return WebP, true
+	}
+	return "", false
+}
+
+// Decode reads a JPEG, PNG, GIF or still WebP image of up to
+// DefaultMaxBytes and DefaultMaxPixels, rotated and flipped as its EXIF
+// orientation says. Importing this package registers the WebP decoder
+// with the image package, so image.Decode accepts WebP everywhere in the
+// program, not just through Decode.
+func Decode(r io.Reader) (image.Image, Format, error) {
+	return DecodeLimit(r, DefaultMaxBytes, DefaultMaxPixels)
+}
+
+// DecodeLimit is Decode with explicit limits on the encoded size and the
+// pixel count; exceeding either returns ErrTooLarge.
+func DecodeLimit(r io.Reader, maxBytes int64, maxPixels int) (image.Image, Format, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxBytes+1))
+	if err != nil {
+		return nil, "", errors.Wrap(err, "io.ReadAll")
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, "", errors.Wrapf(ErrTooLarge, "more than %d bytes", maxBytes)
+	}
+	format, ok := Sniff(data)
+	if !ok {
+		return nil, "", ErrUnsupportedFormat
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, format, errors.Wrap(err, "image.DecodeConfig")
+	}
+	if config.Width*config.Height > maxPixels {
+		return nil, format, errors.Wrapf(ErrTooLarge, "%dx%d", config.Width, config.Height)
+	}
+
+	m, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, format, errors.Wrap(err, "image.Decode")
+	}
+	if format == JPEG {
+		m = Orient(m, Orientation(data))
+	}
+	return m, format, nil
+}
+
+// Encode writes m as JPEG, PNG or GIF. quality applies to JPEG only and
+// defaults to DefaultQuality when 0. None of the encoders write EXIF or
+// other metadata.
+func Encode(w io.Writer, m image.Image, format Format, quality int) error {
+	switch format {
+	case JPEG:
+		if quality <= 0 {
+			quality = DefaultQuality
+		}
+		return errors.Wrap(jpeg.Encode(w, m, &jpeg.Options{Quality: quality}), "jpeg.Encode")
+	case PNG:
+		e := png.Encoder{CompressionLevel: png.BestCompression}
+		return errors.Wrap(e.Encode(w, m), "png.Encode")
+	case GIF:
+		return errors.Wrap(gif.Encode(w, m, nil), "gif.Encode")
+	}
+	return errors.Wrapf(ErrUnsupportedFormat, "cannot encode %s", format)
+}
+
+// Convert decodes an image, fits it within maxWidth x maxHeight without
+// enlarging it and encodes it in format. Orientation is applied and
+// metadata dropped, which is what uploads of photos usually need.
+func Convert(w io.Writer, r io.Reader, format Format, maxWidth, maxHeight, quality int) error {
+	m, _, err := Decode(r)
+	if err != nil {
+		return err
+	}
+	return Encode(w, Fit(m, maxWidth, maxHeight), format, quality)
+}
@@ -0,0 +1,230 @@
+package img
+
+import (
+	"image"
+	"image/draw"
+	"math"
+)
+
+// Filter is a resampling kernel with its support radius in source pixels
+// (scaled up when shrinking, so every source pixel contributes).
+type Filter struct {
+	Support float64
+	Kernel  func(x float64) float64
+}
+
+var (
+	// Linear is a triangle filter: bilinear when enlarging, an area
+	// average when shrinking.
+	Linear = Filter{Support: 1, Kernel: func(x float64) float64 {
+		if x = math.Abs(x); x < 1 {
+			return 1 - x
+		}
+		return 0
+	}}
+
+	// CatmullRom is a sharper cubic filter and the default.
+	CatmullRom = Filter{Support: 2, Kernel: func(x float64) float64 {
+		x = math.Abs(x)
+		switch {
+		case x < 1:
+			return (1.5*x-2.5)*x*x + 1
+		case x < 2:
+			return ((-0.5*x+2.5)*x-4)*x + 2
+		}
+		return 0
+	}}
+)
+
+// Resize scales m to width x height with CatmullRom. A zero width or
+// height is computed from the other, preserving the aspect ratio.
+func Resize(m image.Image, width, height int) *image.RGBA {
+	return ResizeWith(m, width, height, CatmullRom)
+}
+
+func ResizeWith(m image.Image, width, height int, f Filter) *image.RGBA {
+	b := m.Bounds()
+	width, height = dimensions(b.Dx(), b.Dy(), width, height)
+	src := toRGBA(m)
+	if width == b.Dx() && height == b.Dy() {
+		return src
+	}
+	return resample(src, width, height, f)
+}
+
+// Fit scales m down to fit within maxWidth x maxHeight, preserving the
+// aspect ratio. Images that already fit are returned as they are; a zero
+// bound is unlimited.
+func Fit(m image.Image, maxWidth, maxHeight int) image.Image {
+	b := m.Bounds()
+	scale := 1.0
+	if maxWidth > 0 && b.Dx() > maxWidth {
+		scale = float64(maxWidth) / float64(b.Dx())
+	}
+	if maxHeight > 0 && b.Dy() > maxHeight {
+		scale = math.Min(scale, float64(maxHeight)/float64(b.Dy()))
+	}
+	if scale == 1 {
+		return m
+	}
+	return Resize(m, atLeastOne(float64(b.Dx())*scale), atLeastOne(float64(b.Dy())*scale))
+}
+
+// Fill scales m to cover width x height and crops the center, giving an
+// image of exactly that size.
+func Fill(m image.Image, width, height int) *image.RGBA {
+	b := m.Bounds()
+	scale := math.Max(float64(width)/float64(b.Dx()), float64(height)/float64(b.Dy()))
+	scaled := Resize(m, atLeastOne(float64(b.Dx())*scale), atLeastOne(float64(b.Dy())*scale))
+
+	sb := scaled.Bounds()
+	x := (sb.Dx() - width) / 2
+	y := (sb.Dy() - height) / 2
+	return Crop(scaled, image.Rect(x, y, x+width, y+height))
+}
+
+// Thumbnail returns a size x size square of the center of m, as used for
+// avatars.
+func Thumbnail(m image.Image, size int) *image.RGBA {
+	return Fill(m, size, size)
+}
+
+// Crop copies the part of m inside r, relative to m's bounds.
+func Crop(m image.Image, r image.Rectangle) *image.RGBA {
+	r = r.Add(m.Bounds().Min).Intersect(m.Bounds())
+	dst := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(dst, dst.Bounds(), m, r.Min, draw.Src)
+	return dst
+}
+
+func dimensions(srcW, srcH, width, height int) (int, int) {
+	switch {
+	case width <= 0 && height <= 0:
+		return srcW, srcH
+	case width <= 0:
+		return atLeastOne(float64(srcW) * float64(height) / float64(srcH)), height
+	case height <= 0:
+		return width, atLeastOne(float64(srcH) * float64(width) / float64(srcW))
+	}
+	return width, height
+}
+
+func atLeastOne(f float64) int {
+	if n := int(math.Round(f)); n > 1 {
+		return n
+	}
+	return 1
+}
+
+// toRGBA returns m as premultiplied RGBA with bounds at the origin.
+func toRGBA(m image.Image) *image.RGBA {
+	if rgba, ok := m.(*image.RGBA); ok && rgba.Rect.Min == (image.Point{}) {
+		return rgba
+	}
+	b := m.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), m, b.Min, draw.Src)
+	return dst
+}
+
+type contribution struct {
+	first   int
+	weights []float64
+}
+
+// contributions computes, for every destination coordinate, the source
+// pixels that contribute to it and their normalized weights.
+func contributions(srcLen, dstLen int, f Filter) []contribution {
+	scale := float64(srcLen) / float64(dstLen)
+	stretch := math.Max(scale, 1)
+	support := f.Support * stretch
+
+	out := make([]contribution, dstLen)
+	for i := range out {
+		center := (float64(i)+0.5)*scale - 0.5
+		first := int(math.Ceil(center - support))
+		last := int(math.Floor(center + support))
+
+		weights := make([]float64, 0, last-first+1)
+		total := 0.0
+		for j := first; j <= last; j++ {
+			w := f.Kernel((float64(j) - center) / stretch)
+			weights = append(weights, w)
+			total += w
+		}
+		if total != 0 {
+			for k := range weights {
+				weights[k] /= total
+			}
+		}
+		out[i] = contribution{first: first, weights: weights}
+	}
+	return out
+}
+
+// resample scales src in two separable passes, horizontally then
+// vertically, on premultiplied values.
+func resample(src *image.RGBA, width, height int, f Filter) *image.RGBA {
+	srcW, srcH := src.Rect.Dx(), src.Rect.Dy()
+
+	cols := contributions(srcW, width, f)
+	tmp := make([]float64, width*srcH*4)
+	for y := 0; y < srcH; y++ {
+		row := src.Pix[y*src.Stride:]
+		for x, c := range cols {
+			var px [4]float64
+			for k, w := range c.weights {
+				sx := clamp(c.first+k, srcW-1)
+				for ch := 0; ch < 4; ch++ {
+					px[ch] += w * float64(row[sx*4+ch])
+				}
+			}
+			copy(tmp[(y*width+x)*4:], px[:])
+		}
+	}
+
+	rows := contributions(srcH, height, f)
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y, c := range rows {
+		for x := 0; x < width; x++ {
+			var px [4]float64
+			for k, w := range c.weights {
+				sy := clamp(c.first+k, srcH-1)
+				for ch := 0; ch < 4; ch++ {
+					px[ch] += w * tmp[(sy*width+x)*4+ch]
+				}
+			}
+			alpha := toByte(px[3])
+			o := dst.PixOffset(x, y)
+			for ch := 0; ch < 3; ch++ {
+				v := toByte(px[ch])
+				if v > alpha {
+					v = alpha
+				}
+				dst.Pix[o+ch] = v
+			}
+			dst.Pix[o+3] = alpha
+		}
+	}
+	return dst
+}
+
+func clamp(i, max int) int {
+	switch {
+	case i < 0:
+		return 0
+	case i > max:
+		return max
+	}
+	return i
+}
+
+func toByte(v float64) uint8 {
+	switch {
+	case v <= 0:
+		return 0
+	case v >= 255:
+		return 255
+	}
+	return uint8(v + 0.5)
+}
@@ -0,0 +1,549 @@
+package img
+
+import (
+	"encoding/binary"
+	"image"
+
+	"github.com/pkg/errors"
+)
+
+// Macroblock luma and chroma prediction modes.
+const (
+	mbDC = iota
+	mbV
+	mbH
+	mbTM
+	mbB
+)
+
+// Subblock prediction modes, numbered as in RFC 6386.
+const (
+	bDC = iota
+	bTM
+	bVE
+	bHE
+	bLD
+	bRD
+	bVR
+	bVL
+	bHD
+	bHU
+)
+
+// boolDecoder is the boolean entropy decoder of RFC 6386, section 7.
+type boolDecoder struct {
+	data  []byte
+	value uint32
+	rng   uint32
+	count int
+}
+
+func newBoolDecoder(data []byte) *boolDecoder {
+	d := &boolDecoder{data: data, rng: 255}
+	d.value = uint32(d.next())<<8 | uint32(d.next())
+	return d
+}
+
+// next returns zero past the end: the decoder reads ahead of the last
+// symbol, and truncated partitions decode as garbage rather than panic.
+func (d *boolDecoder) next() byte {
+	if len(d.data) == 0 {
+		return 0
+	}
+	b := d.data[0]
+	d.data = d.data[1:]
+	return b
+}
+
+// bool reads a bit that is zero with probability prob/256.
+func (d *boolDecoder) bool(prob uint8) bool {
+	split := 1 + ((d.rng-1)*uint32(prob))>>8
+	bit := d.value >= split<<8
+	if bit {
+		d.rng -= split
+		d.value -= split << 8
+	} else {
+		d.rng = split
+	}
+	for d.rng < 128 {
+		d.value <<= 1
+		d.rng <<= 1
+		if d.count++; d.count == 8 {
+			d.count = 0
+			d.value |= uint32(d.next())
+		}
+	}
+	return bit
+}
+
+// literal reads an n-bit unsigned value, most significant bit first.
+func (d *boolDecoder) literal(n int) int {
+	v := 0
+	for ; n > 0; n-- {
+		v = v<<1 | b2i(d.bool(128))
+	}
+	return v
+}
+
+// optional reads a flag and, when it is set, an n-bit magnitude followed
+// by a sign.
+func (d *boolDecoder) optional(n int) int {
+	if !d.bool(128) {
+		return 0
+	}
+	v := d.literal(n)
+	if d.bool(128) {
+		return -v
+	}
+	return v
+}
+
+// tree reads a value coded with t, whose leaves are negated values.
+func (d *boolDecoder) tree(t []int, probs []uint8) int {
+	i := 0
+	for {
+		i = t[i+b2i(d.bool(probs[i>>1]))]
+		if i <= 0 {
+			return -i
+		}
+	}
+}
+
+func b2i(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// vp8Filter holds the loop filter thresholds of a macroblock; a zero
+// limit disables filtering.
+type vp8Filter struct {
+	limit    int
+	interior int
+	hev      int
+}
+
+func newVP8Filter(level, sharpness int) vp8Filter {
+	if level <= 0 {
+		return vp8Filter{}
+	}
+	if level > 63 {
+		level = 63
+	}
+	interior := level
+	if sharpness > 0 {
+		if sharpness > 4 {
+			interior >>= 2
+		} else {
+			interior >>= 1
+		}
+		if interior > 9-sharpness {
+			interior = 9 - sharpness
+		}
+	}
+	if interior < 1 {
+		interior = 1
+	}
+	f := vp8Filter{limit: 2*level + interior, interior: interior}
+	if level >= 40 {
+		f.hev = 2
+	} else if level >= 15 {
+		f.hev = 1
+	}
+	return f
+}
+
+type vp8Segment struct {
+	// quant holds the DC and AC factors of the Y, Y2 and chroma blocks.
+	quant [3][2]int
+	// filter applies to macroblocks without and with subblock modes.
+	filter [2]vp8Filter
+}
+
+// vp8Context holds whether the blocks bordering a macroblock had
+// non-zero coefficients.
+type vp8Context struct {
+	y    [4]bool
+	u, v [2]bool
+	y2   bool
+}
+
+type vp8Macroblock struct {
+	filter vp8Filter
+	inner  bool
+}
+
+type vp8Decoder struct {
+	width, height int
+	mbw, mbh      int
+
+	first *boolDecoder
+	parts []*boolDecoder
+
+	segments     [4]vp8Segment
+	updateMap    bool
+	segmentProbs [3]uint8
+	filtering    bool
+	simple       bool
+	probs        [4][8][3][11]uint8
+	skipProb     int
+
+	upModes   []uint8
+	upCtx     []vp8Context
+	leftModes [4]uint8
+	leftCtx   vp8Context
+	coeffs    [25][16]int32
+
+	mbs []vp8Macroblock
+	img *image.YCbCr
+}
+
+// vp8Size parses the frame header of a VP8 key frame.
+func vp8Size(data []byte) (int, int, error) {
+	if len(data) < 10 {
+		return 0, 0, errors.Wrap(errWebP, "short VP8 header")
+	}
+	tag := uint32(data[0]) | uint32(data[1])<<8 | uint32(data[2])<<16
+	switch {
+	case tag&1 != 0:
+		return 0, 0, errors.Wrap(errWebP, "VP8 frame is not a key frame")
+	case tag>>1&7 > 3:
+		return 0, 0, errors.Wrap(errWebP, "unknown VP8 version")
+	case tag>>4&1 == 0:
+		return 0, 0, errors.Wrap(errWebP, "VP8 frame is not shown")
+	case data[3] != 0x9d || data[4] != 0x01 || data[5] != 0x2a:
+		return 0, 0, errors.Wrap(errWebP, "missing VP8 start code")
+	}
+	width := int(binary.LittleEndian.Uint16(data[6:]) & 0x3fff)
+	height := int(binary.LittleEndian.Uint16(data[8:]) & 0x3fff)
+	if width == 0 || height == 0 {
+		return 0, 0, errors.Wrap(errWebP, "empty VP8 frame")
+	}
+	return width, height, nil
+}
+
+// decodeVP8 decodes a lossy WebP bitstream, a single VP8 key frame.
+func decodeVP8(data []byte) (*image.YCbCr, error) {
+	width, height, err := vp8Size(data)
+	if err != nil {
+		return nil, err
+	}
+	firstSize := int(uint32(data[0])|uint32(data[1])<<8|uint32(data[2])<<16) >> 5
+	data = data[10:]
+	if firstSize > len(data) {
+		return nil, errors.Wrap(errWebP, "truncated VP8 partition")
+	}
+
+	d := &vp8Decoder{
+		width:    width,
+		height:   height,
+		mbw:      (width + 15) / 16,
+		mbh:      (height + 15) / 16,
+		first:    newBoolDecoder(data[:firstSize]),
+		skipProb: -1,
+	}
+	if err := d.parseHeader(data[firstSize:]); err != nil {
+		return nil, err
+	}
+
+	d.upModes = make([]uint8, 4*d.mbw)
+	d.upCtx = make([]vp8Context, d.mbw)
+	d.mbs = make([]vp8Macroblock, d.mbw*d.mbh)
+	d.img = image.NewYCbCr(image.Rect(0, 0, 16*d.mbw, 16*d.mbh), image.YCbCrSubsampleRatio420)
+	for mby := 0; mby < d.mbh; mby++ {
+		d.leftModes = [4]uint8{}
+		d.leftCtx = vp8Context{}
+		part := d.parts[mby%len(d.parts)]
+		for mbx := 0; mbx < d.mbw; mbx++ {
+			d.decodeMacroblock(part, mbx, mby)
+		}
+	}
+	d.loopFilter()
+	return d.img.SubImage(image.Rect(0, 0, width, height)).(*image.YCbCr), nil
+}
+
+func (d *vp8Decoder) parseHeader(rest []byte) error {
+	br := d.first
+	br.literal(2) // color space and clamping type
+
+	var quant, level [4]int
+	segmented, absolute := br.bool(128), false
+	if segmented {
+		d.updateMap = br.bool(128)
+		if br.bool(128) {
+			absolute = br.bool(128)
+			for i := range quant {
+				quant[i] = br.optional(7)
+			}
+			for i := range level {
+				level[i] = br.optional(6)
+			}
+		}
+		if d.updateMap {
+			for i := range d.segmentProbs {
+				d.segmentProbs[i] = 255
+				if br.bool(128) {
+					d.segmentProbs[i] = uint8(br.literal(8))
+				}
+			}
+		}
+	}
+
+	d.simple = br.bool(128)
+	baseLevel := br.literal(6)
+	sharpness := br.literal(3)
+	d.filtering = baseLevel > 0
+	var refDelta, modeDelta int
+	if br.bool(128) && br.bool(128) {
+		// Key frames only use the deltas of intra prediction and of
+		// subblock modes, the first of each list.
+		for i := 0; i < 4; i++ {
+			if v := br.optional(6); i == 0 {
+				refDelta = v
+			}
+		}
+		for i := 0; i < 4; i++ {
+			if v := br.optional(6); i == 0 {
+				modeDelta = v
+			}
+		}
+	}
+
+	if err := d.parsePartitions(br.literal(2), rest); err != nil {
+		return err
+	}
+
+	baseQuant := br.literal(7)
+	yDC, y2DC, y2AC, uvDC, uvAC := br.optional(4), br.optional(4), br.optional(4), br.optional(4), br.optional(4)
+	for i := range d.segments {
+		q, l := baseQuant, baseLevel
+		if segmented {
+			q, l = quant[i], level[i]
+			if !absolute {
+				q += baseQuant
+				l += baseLevel
+			}
+		}
+
+		s := &d.segments[i]
+		s.quant[0] = [2]int{vp8Quant(&vp8DCTable, q+yDC, 127), vp8Quant(&vp8ACTable, q, 127)}
+		s.quant[1] = [2]int{2 * vp8Quant(&vp8DCTable, q+y2DC, 127), vp8Quant(&vp8ACTable, q+y2AC, 127) * 155 / 100}
+		if s.quant[1][1] < 8 {
+			s.quant[1][1] = 8
+		}
+		s.quant[2] = [2]int{vp8Quant(&vp8DCTable, q+uvDC, 117), vp8Quant(&vp8ACTable, q+uvAC, 127)}
+		s.filter[0] = newVP8Filter(l+refDelta, sharpness)
+		s.filter[1] = newVP8Filter(l+refDelta+modeDelta, sharpness)
+	}
+
+	br.literal(1) // refresh entropy probabilities
+	d.probs = vp8CoeffProbs
+	for i := range d.probs {
+		for j := range d.probs[i] {
+			for k := range d.probs[i][j] {
+				for l := range d.probs[i][j][k] {
+					if br.bool(vp8CoeffUpdateProbs[i][j][k][l]) {
+						d.probs[i][j][k][l] = uint8(br.literal(8))
+					}
+				}
+			}
+		}
+	}
+	if br.bool(128) {
+		d.skipProb = br.literal(8)
+	}
+	return nil
+}
+
+func vp8Quant(table *[128]int, q, max int) int {
+	if q < 0 {
+		q = 0
+	} else if q > max {
+		q = max
+	}
+	return table[q]
+}
+
+// parsePartitions splits the DCT token partitions that follow the
+// first partition, each prefixed by its 3-byte size except the last.
+func (d *vp8Decoder) parsePartitions(log2 int, data []byte) error {
+	n := 1 << log2
+	sizes := 3 * (n - 1)
+	if len(data) < sizes {
+		return errors.Wrap(errWebP, "truncated VP8 partition sizes")
+	}
+	sizeData, data := data[:sizes], data[sizes:]
+	d.parts = make([]*boolDecoder, n)
+	for i := 0; i < n-1; i++ {
+		size := int(sizeData[3*i]) | int(sizeData[3*i+1])<<8 | int(sizeData[3*i+2])<<16
+		if size > len(data) {
+			return errors.Wrap(errWebP, "truncated VP8 partition")
+		}
+		d.parts[i] = newBoolDecoder(data[:size])
+		data = data[size:]
+	}
+	d.parts[n-1] = newBoolDecoder(data)
+	return nil
+}
+
+func (d *vp8Decoder) decodeMacroblock(part *boolDecoder, mbx, mby int) {
+	br := d.first
+	segment := 0
+	if d.updateMap {
+		if !br.bool(d.segmentProbs[0]) {
+			segment = b2i(br.bool(d.segmentProbs[1]))
+		} else {
+			segment = 2 + b2i(br.bool(d.segmentProbs[2]))
+		}
+	}
+	skip := d.skipProb >= 0 && br.bool(uint8(d.skipProb))
+	ymode, bmodes, uvmode := d.parseModes(mbx)
+
+	seg := &d.segments[segment]
+	up, left := &d.upCtx[mbx], &d.leftCtx
+	d.coeffs = [25][16]int32{}
+	if skip {
+		up.y, left.y = [4]bool{}, [4]bool{}
+		up.u, up.v, left.u, left.v = [2]bool{}, [2]bool{}, [2]bool{}, [2]bool{}
+		if ymode != mbB {
+			up.y2, left.y2 = false, false
+		}
+	} else {
+		d.parseResiduals(part, ymode == mbB, seg, up, left)
+		if ymode != mbB {
+			d.inverseWHT()
+		}
+	}
+
+	nonzero := d.reconstruct(mbx, mby, ymode, &bmodes, uvmode)
+	isB := ymode == mbB
+	d.mbs[mby*d.mbw+mbx] = vp8Macroblock{filter: seg.filter[b2i(isB)], inner: isB || nonzero}
+}
+
+// parseModes reads the prediction modes of a key frame macroblock,
+// keeping the subblock modes around it as context.
+func (d *vp8Decoder) parseModes(mbx int) (ymode int, bmodes [16]uint8, uvmode int) {
+	br := d.first
+	up := d.upModes[4*mbx : 4*mbx+4]
+	if !br.bool(145) {
+		ymode = mbB
+		for y := 0; y < 4; y++ {
+			for x := 0; x < 4; x++ {
+				m := uint8(br.tree(vp8BModeTree[:], vp8BModeProbs[up[x]][d.leftModes[y]][:]))
+				bmodes[4*y+x], up[x], d.leftModes[y] = m, m, m
+			}
+		}
+	} else {
+		var implied uint8
+		switch {
+		case !br.bool(156):
+			if !br.bool(163) {
+				ymode, implied = mbDC, bDC
+			} else {
+				ymode, implied = mbV, bVE
+			}
+		case !br.bool(128):
+			ymode, implied = mbH, bHE
+		default:
+			ymode, implied = mbTM, bTM
+		}
+		for i := range up {
+			up[i], d.leftModes[i] = implied, implied
+		}
+	}
+
+	switch {
+	case !br.bool(142):
+		uvmode = mbDC
+	case !br.bool(114):
+		uvmode = mbV
+	case !br.bool(183):
+		uvmode = mbH
+	default:
+		uvmode = mbTM
+	}
+	return ymode, bmodes, uvmode
+}
+
+func (d *vp8Decoder) parseResiduals(br *boolDecoder, isB bool, seg *vp8Segment, up, left *vp8Context) {
+	first, typ := 0, 3
+	if !isB {
+		n := d.readCoeffs(br, 1, b2i(up.y2)+b2i(left.y2), seg.quant[1], 0, &d.coeffs[24])
+		up.y2, left.y2 = n > 0, n > 0
+		first, typ = 1, 0
+	}
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			n := d.readCoeffs(br, typ, b2i(up.y[x])+b2i(left.y[y]), seg.quant[0], first, &d.coeffs[4*y+x])
+			up.y[x], left.y[y] = n > first, n > first
+		}
+	}
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 2; x++ {
+			n := d.readCoeffs(br, 2, b2i(up.u[x])+b2i(left.u[y]), seg.quant[2], 0, &d.coeffs[16+2*y+x])
+			up.u[x], left.u[y] = n > 0, n > 0
+		}
+	}
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 2; x++ {
+			n := d.readCoeffs(br, 2, b2i(up.v[x])+b2i(left.v[y]), seg.quant[2], 0, &d.coeffs[20+2*y+x])
+			up.v[x], left.v[y] = n > 0, n > 0
+		}
+	}
+}
+
+// readCoeffs reads the dequantized coefficients of a block from position
+// n on and returns the position after the last one coded.
+func (d *vp8Decoder) readCoeffs(br *boolDecoder, typ, ctx int, quant [2]int, n int, out *[16]int32) int {
+	probs := &d.probs[typ]
+	p := &probs[vp8Bands[n]][ctx]
+	for ; n < 16; n++ {
+		if !br.bool(p[0]) {
+			return n
+		}
+		// No end of block can follow a zero.
+		for !br.bool(p[1]) {
+			if n++; n == 16 {
+				return 16
+			}
+			p = &probs[vp8Bands[n]][0]
+		}
+
+		v, next := 1, 1
+		if br.bool(p[2]) {
+			v, next = vp8LargeValue(br, p), 2
+		}
+		if br.bool(128) {
+			v = -v
+		}
+		out[vp8Zigzag[n]] = int32(v * quant[b2i(n > 0)])
+		p = &probs[vp8Bands[n+1]][next]
+	}
+	return 16
+}
+
+// vp8LargeValue reads a coefficient magnitude of 2 or more.
+func vp8LargeValue(br *boolDecoder, p *[11]uint8) int {
+	if !br.bool(p[3]) {
+		if !br.bool(p[4]) {
+			return 2
+		}
+		return 3 + b2i(br.bool(p[5]))
+	}
+	if !br.bool(p[6]) {
+		if !br.bool(p[7]) {
+			return 5 + b2i(br.bool(159))
+		}
+		v := 7 + 2*b2i(br.bool(165))
+		return v + b2i(br.bool(145))
+	}
+
+	high := b2i(br.bool(p[8]))
+	cat := 2*high + b2i(br.bool(p[9+high]))
+	v := 0
+	for _, prob := range [...][]uint8{vp8Cat3, vp8Cat4, vp8Cat5, vp8Cat6}[cat] {
+		v = 2*v + b2i(br.bool(prob))
+	}
+	return v + 3 + 8<<cat
+}
@@ -0,0 +1,514 @@
+package img
+
+// Intra prediction, inverse transforms and the loop filter of RFC 6386.
+// Prediction reads unfiltered pixels, so the whole frame is reconstructed
+// before it is filtered.
+
+// reconstruct predicts a macroblock and adds its residue, reporting
+// whether any coefficient was non-zero.
+func (d *vp8Decoder) reconstruct(mbx, mby, ymode int, bmodes *[16]uint8, uvmode int) bool {
+	m := d.img
+	nonzero := false
+	if ymode != mbB {
+		predictBlock(m.Y, m.YStride, 16*mbx, 16*mby, 16, ymode)
+	}
+	for i := 0; i < 16; i++ {
+		x, y := 16*mbx+4*(i&3), 16*mby+4*(i>>2)
+		if ymode == mbB {
+			d.predictSubblock(mbx, mby, i, bmodes[i])
+		}
+		nonzero = addResidue(m.Y, m.YStride, y*m.YStride+x, &d.coeffs[i]) || nonzero
+	}
+
+	predictBlock(m.Cb, m.CStride, 8*mbx, 8*mby, 8, uvmode)
+	predictBlock(m.Cr, m.CStride, 8*mbx, 8*mby, 8, uvmode)
+	for i := 0; i < 4; i++ {
+		off := (8*mby+4*(i>>1))*m.CStride + 8*mbx + 4*(i&1)
+		nonzero = addResidue(m.Cb, m.CStride, off, &d.coeffs[16+i]) || nonzero
+		nonzero = addResidue(m.Cr, m.CStride, off, &d.coeffs[20+i]) || nonzero
+	}
+	return nonzero
+}
+
+// predictBlock fills a size x size block at x0, y0 of p. Pixels above the
+// frame read as 127 and pixels left of it as 129, except for DC
+// prediction which averages the available edges only.
+func predictBlock(p []byte, stride, x0, y0, size, mode int) {
+	off := y0*stride + x0
+	switch mode {
+	case mbDC:
+		sum, n := 0, 0
+		if y0 > 0 {
+			for x := 0; x < size; x++ {
+				sum += int(p[off-stride+x])
+			}
+			n += size
+		}
+		if x0 > 0 {
+			for y := 0; y < size; y++ {
+				sum += int(p[off+y*stride-1])
+			}
+			n += size
+		}
+		dc := byte(128)
+		if n > 0 {
+			dc = byte((sum + n/2) / n)
+		}
+		for y := 0; y < size; y++ {
+			row := p[off+y*stride : off+y*stride+size]
+			for x := range row {
+				row[x] = dc
+			}
+		}
+	case mbV:
+		for y := 0; y < size; y++ {
+			row := p[off+y*stride : off+y*stride+size]
+			for x := range row {
+				row[x] = 127
+				if y0 > 0 {
+					row[x] = p[off-stride+x]
+				}
+			}
+		}
+	case mbH:
+		for y := 0; y < size; y++ {
+			v := byte(129)
+			if x0 > 0 {
+				v = p[off+y*stride-1]
+			}
+			row := p[off+y*stride : off+y*stride+size]
+			for x := range row {
+				row[x] = v
+			}
+		}
+	case mbTM:
+		corner := 127
+		if y0 > 0 {
+			corner = 129
+			if x0 > 0 {
+				corner = int(p[off-stride-1])
+			}
+		}
+		for y := 0; y < size; y++ {
+			left := 129
+			if x0 > 0 {
+				left = int(p[off+y*stride-1])
+			}
+			row := p[off+y*stride : off+y*stride+size]
+			for x := range row {
+				top := 127
+				if y0 > 0 {
+					top = int(p[off-stride+x])
+				}
+				row[x] = clamp255(left + top - corner)
+			}
+		}
+	}
+}
+
+// predictSubblock predicts the i-th 4x4 luma block of a macroblock. The
+// blocks on the right column take their above-right pixels from the
+// macroblock row above, like the top right block.
+func (d *vp8Decoder) predictSubblock(mbx, mby, i int, mode uint8) {
+	p, stride := d.img.Y, d.img.YStride
+	sx, sy := i&3, i>>2
+	x0, y0 := 16*mbx+4*sx, 16*mby+4*sy
+	off := y0*stride + x0
+
+	// top holds the above-left pixel, the four above and the four
+	// above-right.
+	var top [9]int
+	var left [4]int
+	if y0 == 0 {
+		for k := range top {
+			top[k] = 127
+		}
+	} else {
+		top[0] = 129
+		if x0 > 0 {
+			top[0] = int(p[off-stride-1])
+		}
+		for k := 0; k < 4; k++ {
+			top[1+k] = int(p[off-stride+k])
+		}
+		for k := 0; k < 4; k++ {
+			switch {
+			case sx < 3:
+				top[5+k] = int(p[off-stride+4+k])
+			case mby == 0:
+				top[5+k] = 127
+			case mbx == d.mbw-1:
+				top[5+k] = int(p[(16*mby-1)*stride+16*mbx+15])
+			default:
+				top[5+k] = int(p[(16*mby-1)*stride+16*mbx+16+k])
+			}
+		}
+	}
+	for k := range left {
+		left[k] = 129
+		if x0 > 0 {
+			left[k] = int(p[off+k*stride-1])
+		}
+	}
+
+	b := predict4(mode, &top, &left)
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			p[off+y*stride+x] = byte(b[y][x])
+		}
+	}
+}
+
+func predict4(mode uint8, top *[9]int, left *[4]int) (b [4][4]int) {
+	X, A, B, C, D, E, F, G, H := top[0], top[1], top[2], top[3], top[4], top[5], top[6], top[7], top[8]
+	I, J, K, L := left[0], left[1], left[2], left[3]
+	switch mode {
+	case bDC:
+		dc := 4
+		for k := 0; k < 4; k++ {
+			dc += top[1+k] + left[k]
+		}
+		fill4(&b, dc>>3)
+	case bTM:
+		for y := 0; y < 4; y++ {
+			for x := 0; x < 4; x++ {
+				b[y][x] = int(clamp255(left[y] + top[1+x] - X))
+			}
+		}
+	case bVE:
+		for x := 0; x < 4; x++ {
+			v := avg3(top[x], top[1+x], top[2+x])
+			for y := 0; y < 4; y++ {
+				b[y][x] = v
+			}
+		}
+	case bHE:
+		rows := [4]int{avg3(X, I, J), avg3(I, J, K), avg3(J, K, L), avg3(K, L, L)}
+		for y := 0; y < 4; y++ {
+			for x := 0; x < 4; x++ {
+				b[y][x] = rows[y]
+			}
+		}
+	case bLD:
+		for y := 0; y < 4; y++ {
+			for x := 0; x < 4; x++ {
+				if k := x + y; k < 6 {
+					b[y][x] = avg3(top[1+k], top[2+k], top[3+k])
+				} else {
+					b[y][x] = avg3(G, H, H)
+				}
+			}
+		}
+	case bRD:
+		edge := [9]int{L, K, J, I, X, A, B, C, D}
+		for y := 0; y < 4; y++ {
+			for x := 0; x < 4; x++ {
+				b[y][x] = avg3(edge[3-y+x], edge[4-y+x], edge[5-y+x])
+			}
+		}
+	case bVR:
+		b[0][0], b[2][1] = avg2(X, A), avg2(X, A)
+		b[0][1], b[2][2] = avg2(A, B), avg2(A, B)
+		b[0][2], b[2][3] = avg2(B, C), avg2(B, C)
+		b[0][3] = avg2(C, D)
+		b[3][0] = avg3(K, J, I)
+		b[2][0] = avg3(J, I, X)
+		b[1][0], b[3][1] = avg3(I, X, A), avg3(I, X, A)
+		b[1][1], b[3][2] = avg3(X, A, B), avg3(X, A, B)
+		b[1][2], b[3][3] = avg3(A, B, C), avg3(A, B, C)
+		b[1][3] = avg3(B, C, D)
+	case bVL:
+		b[0][0] = avg2(A, B)
+		b[0][1], b[2][0] = avg2(B, C), avg2(B, C)
+		b[0][2], b[2][1] = avg2(C, D), avg2(C, D)
+		b[0][3], b[2][2] = avg2(D, E), avg2(D, E)
+		b[1][0] = avg3(A, B, C)
+		b[1][1], b[3][0] = avg3(B, C, D), avg3(B, C, D)
+		b[1][2], b[3][1] = avg3(C, D, E), avg3(C, D, E)
+		b[1][3], b[3][2] = avg3(D, E, F), avg3(D, E, F)
+		b[2][3] = avg3(E, F, G)
+		b[3][3] = avg3(F, G, H)
+	case bHD:
+		b[0][0], b[1][2] = avg2(I, X), avg2(I, X)
+		b[1][0], b[2][2] = avg2(J, I), avg2(J, I)
+		b[2][0], b[3][2] = avg2(K, J), avg2(K, J)
+		b[3][0] = avg2(L, K)
+		b[0][3] = avg3(A, B, C)
+		b[0][2] = avg3(X, A, B)
+		b[0][1], b[1][3] = avg3(I, X, A), avg3(I, X, A)
+		b[1][1], b[2][3] = avg3(J, I, X), avg3(J, I, X)
+		b[2][1], b[3][3] = avg3(K, J, I), avg3(K, J, I)
+		b[3][1] = avg3(L, K, J)
+	case bHU:
+		b[0][0] = avg2(I, J)
+		b[0][2], b[1][0] = avg2(J, K), avg2(J, K)
+		b[1][2], b[2][0] = avg2(K, L), avg2(K, L)
+		b[0][1] = avg3(I, J, K)
+		b[0][3], b[1][1] = avg3(J, K, L), avg3(J, K, L)
+		b[1][3], b[2][1] = avg3(K, L, L), avg3(K, L, L)
+		b[2][2], b[2][3] = L, L
+		b[3] = [4]int{L, L, L, L}
+	}
+	return b
+}
+
+func fill4(b *[4][4]int, v int) {
+	for y := range b {
+		for x := range b[y] {
+			b[y][x] = v
+		}
+	}
+}
+
+func avg2(a, b int) int {
+	return (a + b + 1) >> 1
+}
+
+func avg3(a, b, c int) int {
+	return (a + 2*b + c + 2) >> 2
+}
+
+func clamp255(v int) byte {
+	if v < 0 {
+		return 0
+	}
+	if v > 255 {
+		return 255
+	}
+	return byte(v)
+}
+
+// inverseWHT spreads the Y2 block over the DC coefficients of the 16
+// luma blocks.
+func (d *vp8Decoder) inverseWHT() {
+	in := &d.coeffs[24]
+	var tmp [16]int
+	for i := 0; i < 4; i++ {
+		a0 := int(in[i]) + int(in[12+i])
+		a1 := int(in[4+i]) + int(in[8+i])
+		a2 := int(in[4+i]) - int(in[8+i])
+		a3 := int(in[i]) - int(in[12+i])
+		tmp[i] = a0 + a1
+		tmp[8+i] = a0 - a1
+		tmp[4+i] = a3 + a2
+		tmp[12+i] = a3 - a2
+	}
+	for i := 0; i < 4; i++ {
+		dc := tmp[4*i] + 3
+		a0 := dc + tmp[4*i+3]
+		a1 := tmp[4*i+1] + tmp[4*i+2]
+		a2 := tmp[4*i+1] - tmp[4*i+2]
+		a3 := dc - tmp[4*i+3]
+		d.coeffs[4*i][0] = int32((a0 + a1) >> 3)
+		d.coeffs[4*i+1][0] = int32((a3 + a2) >> 3)
+		d.coeffs[4*i+2][0] = int32((a0 - a1) >> 3)
+		d.coeffs[4*i+3][0] = int32((a3 - a2) >> 3)
+	}
+}
+
+// addResidue adds the inverse DCT of c to the 4x4 block at off,
+// reporting whether c had any non-zero coefficient.
+func addResidue(p []byte, stride, off int, c *[16]int32) bool {
+	if *c == ([16]int32{}) {
+		return false
+	}
+	var tmp [16]int
+	for i := 0; i < 4; i++ {
+		a := int(c[i]) + int(c[8+i])
+		b := int(c[i]) - int(c[8+i])
+		x := idctMul2(int(c[4+i])) - idctMul1(int(c[12+i]))
+		y := idctMul1(int(c[4+i])) + idctMul2(int(c[12+i]))
+		tmp[4*i] = a + y
+		tmp[4*i+1] = b + x
+		tmp[4*i+2] = b - x
+		tmp[4*i+3] = a - y
+	}
+	for i := 0; i < 4; i++ {
+		dc := tmp[i] + 4
+		a := dc + tmp[8+i]
+		b := dc - tmp[8+i]
+		x := idctMul2(tmp[4+i]) - idctMul1(tmp[12+i])
+		y := idctMul1(tmp[4+i]) + idctMul2(tmp[12+i])
+		row := p[off+i*stride : off+i*stride+4]
+		row[0] = clamp255(int(row[0]) + (a+y)>>3)
+		row[1] = clamp255(int(row[1]) + (b+x)>>3)
+		row[2] = clamp255(int(row[2]) + (b-x)>>3)
+		row[3] = clamp255(int(row[3]) + (a-y)>>3)
+	}
+	return true
+}
+
+func idctMul1(a int) int {
+	return (a*20091)>>16 + a
+}
+
+func idctMul2(a int) int {
+	return (a * 35468) >> 16
+}
+
+// loopFilter smooths the macroblock and, unless a macroblock had no
+// residue, subblock edges, in raster order.
+func (d *vp8Decoder) loopFilter() {
+	if !d.filtering {
+		return
+	}
+	m := d.img
+	ys, cs := m.YStride, m.CStride
+	for mby := 0; mby < d.mbh; mby++ {
+		for mbx := 0; mbx < d.mbw; mbx++ {
+			mb := &d.mbs[mby*d.mbw+mbx]
+			f := &mb.filter
+			if f.limit == 0 {
+				continue
+			}
+			yi := 16*mby*ys + 16*mbx
+			ci := 8*mby*cs + 8*mbx
+
+			if d.simple {
+				if mbx > 0 {
+					simpleEdge(m.Y, yi, 1, ys, 16, f.limit+4)
+				}
+				if mb.inner {
+					for k := 4; k < 16; k += 4 {
+						simpleEdge(m.Y, yi+k, 1, ys, 16, f.limit)
+					}
+				}
+				if mby > 0 {
+					simpleEdge(m.Y, yi, ys, 1, 16, f.limit+4)
+				}
+				if mb.inner {
+					for k := 4; k < 16; k += 4 {
+						simpleEdge(m.Y, yi+k*ys, ys, 1, 16, f.limit)
+					}
+				}
+				continue
+			}
+
+			if mbx > 0 {
+				macroblockEdge(m.Y, yi, 1, ys, 16, f)
+				macroblockEdge(m.Cb, ci, 1, cs, 8, f)
+				macroblockEdge(m.Cr, ci, 1, cs, 8, f)
+			}
+			if mb.inner {
+				for k := 4; k < 16; k += 4 {
+					subblockEdge(m.Y, yi+k, 1, ys, 16, f)
+				}
+				subblockEdge(m.Cb, ci+4, 1, cs, 8, f)
+				subblockEdge(m.Cr, ci+4, 1, cs, 8, f)
+			}
+			if mby > 0 {
+				macroblockEdge(m.Y, yi, ys, 1, 16, f)
+				macroblockEdge(m.Cb, ci, cs, 1, 8, f)
+				macroblockEdge(m.Cr, ci, cs, 1, 8, f)
+			}
+			if mb.inner {
+				for k := 4; k < 16; k += 4 {
+					subblockEdge(m.Y, yi+k*ys, ys, 1, 16, f)
+				}
+				subblockEdge(m.Cb, ci+4*cs, cs, 1, 8, f)
+				subblockEdge(m.Cr, ci+4*cs, cs, 1, 8, f)
+			}
+		}
+	}
+}
+
+// The edge filters run along n pixels from off, the first pixel past
+// the edge, stepping by along; across steps over the edge.
+
+func simpleEdge(p []byte, off, across, along, n, limit int) {
+	for ; n > 0; n, off = n-1, off+along {
+		if edgeDiff(p, off, across) <= limit {
+			filterCommon(p, off, across, true)
+		}
+	}
+}
+
+func subblockEdge(p []byte, off, across, along, n int, f *vp8Filter) {
+	for ; n > 0; n, off = n-1, off+along {
+		if !filterNeeded(p, off, across, f.limit, f.interior) {
+			continue
+		}
+		hev := highEdgeVariance(p, off, across, f.hev)
+		a := (filterCommon(p, off, across, hev) + 1) >> 1
+		if !hev {
+			p[off+across] = s2u(int(p[off+across]) - 128 - a)
+			p[off-2*across] = s2u(int(p[off-2*across]) - 128 + a)
+		}
+	}
+}
+
+func macroblockEdge(p []byte, off, across, along, n int, f *vp8Filter) {
+	for ; n > 0; n, off = n-1, off+along {
+		if !filterNeeded(p, off, across, f.limit+4, f.interior) {
+			continue
+		}
+		if highEdgeVariance(p, off, across, f.hev) {
+			filterCommon(p, off, across, true)
+			continue
+		}
+		p2, p1, p0 := int(p[off-3*across])-128, int(p[off-2*across])-128, int(p[off-across])-128
+		q0, q1, q2 := int(p[off])-128, int(p[off+across])-128, int(p[off+2*across])-128
+		w := clampS8(clampS8(p1-q1) + 3*(q0-p0))
+		a := clampS8((27*w + 63) >> 7)
+		p[off], p[off-across] = s2u(q0-a), s2u(p0+a)
+		a = clampS8((18*w + 63) >> 7)
+		p[off+across], p[off-2*across] = s2u(q1-a), s2u(p1+a)
+		a = clampS8((9*w + 63) >> 7)
+		p[off+2*across], p[off-3*across] = s2u(q2-a), s2u(p2+a)
+	}
+}
+
+// filterCommon adjusts the two pixels next to the edge and returns the
+// adjustment of the one past it.
+func filterCommon(p []byte, off, across int, outer bool) int {
+	p1, p0 := int(p[off-2*across])-128, int(p[off-across])-128
+	q0, q1 := int(p[off])-128, int(p[off+across])-128
+	a := 0
+	if outer {
+		a = clampS8(p1 - q1)
+	}
+	a = clampS8(a + 3*(q0-p0))
+	b := clampS8(a+3) >> 3
+	a = clampS8(a+4) >> 3
+	p[off] = s2u(q0 - a)
+	p[off-across] = s2u(p0 + b)
+	return a
+}
+
+func edgeDiff(p []byte, off, across int) int {
+	return 2*absDiff(p[off-across], p[off]) + absDiff(p[off-2*across], p[off+across])>>1
+}
+
+func filterNeeded(p []byte, off, across, limit, interior int) bool {
+	p3, p2, p1, p0 := p[off-4*across], p[off-3*across], p[off-2*across], p[off-across]
+	q0, q1, q2, q3 := p[off], p[off+across], p[off+2*across], p[off+3*across]
+	return edgeDiff(p, off, across) <= limit &&
+		absDiff(p3, p2) <= interior && absDiff(p2, p1) <= interior && absDiff(p1, p0) <= interior &&
+		absDiff(q3, q2) <= interior && absDiff(q2, q1) <= interior && absDiff(q1, q0) <= interior
+}
+
+func highEdgeVariance(p []byte, off, across, threshold int) bool {
+	return absDiff(p[off-2*across], p[off-across]) > threshold || absDiff(p[off+across], p[off]) > threshold
+}
+
+func absDiff(a, b byte) int {
+	if a > b {
+		return int(a - b)
+	}
+	return int(b - a)
+}
+
+func clampS8(v int) int {
+	if v < -128 {
+		return -128
+	}
+	if v > 127 {
+		return 127
+	}
+	return v
+}
+
+// s2u converts a signed filter value back to a pixel.
+func s2u(v int) byte {
+	return byte(clampS8(v) + 128)
+}
@@ -0,0 +1,616 @@
+package img
+
+import (
+	"encoding/binary"
+	"image"
+	"math/bits"
+
+	"github.com/pkg/errors"
+)
+
+// Lossless WebP, as specified in RFC 9649.
+
+const (
+	transformPredictor = iota
+	transformColor
+	transformSubtractGreen
+	transformColorIndexing
+)
+
+// vp8lReader reads bits least significant first.
+type vp8lReader struct {
+	data []byte
+	bits uint64
+	n    uint
+	eof  bool
+}
+
+// fill buffers at least n bits when the data has them.
+func (r *vp8lReader) fill(n uint) bool {
+	for r.n < n && len(r.data) > 0 {
+		r.bits |= uint64(r.data[0]) << r.n
+		r.data = r.data[1:]
+		r.n += 8
+	}
+	return r.n >= n
+}
+
+func (r *vp8lReader) read(n uint) uint32 {
+	if !r.fill(n) {
+		r.eof = true
+		r.n = n
+	}
+	v := uint32(r.bits & (1<<n - 1))
+	r.bits >>= n
+	r.n -= n
+	return v
+}
+
+const huffmanTableBits = 8
+
+// huffman decodes a canonical prefix code, looking up codes of up to
+// huffmanTableBits at once.
+type huffman struct {
+	// table holds symbol<<4 | length for the short codes, indexed by the
+	// next bits, and zero where a longer code starts.
+	table   [1 << huffmanTableBits]uint16
+	counts  [16]uint16
+	symbols []uint16
+	// single is the only symbol of a code that takes no bits, or -1.
+	single int
+}
+
+func newHuffman(lengths []uint8) (*huffman, error) {
+	h := &huffman{single: -1}
+	used, last := 0, 0
+	for s, l := range lengths {
+		if l > 0 {
+			h.counts[l]++
+			used, last = used+1, s
+		}
+	}
+	switch used {
+	case 0:
+		return nil, errors.Wrap(errWebP, "empty prefix code")
+	case 1:
+		h.single = last
+		return h, nil
+	}
+
+	left := 1
+	var offsets [16]int
+	for l := 1; l < 16; l++ {
+		left = left<<1 - int(h.counts[l])
+		if left < 0 {
+			return nil, errors.Wrap(errWebP, "over-subscribed prefix code")
+		}
+		if l < 15 {
+			offsets[l+1] = offsets[l] + int(h.counts[l])
+		}
+	}
+	if left != 0 {
+		return nil, errors.Wrap(errWebP, "incomplete prefix code")
+	}
+
+	h.symbols = make([]uint16, used)
+	code := 0
+	var next [16]int
+	for l := 1; l < 16; l++ {
+		next[l] = code
+		code = (code + int(h.counts[l])) << 1
+	}
+	for s, l := range lengths {
+		if l == 0 {
+			continue
+		}
+		h.symbols[offsets[l]] = uint16(s)
+		offsets[l]++
+		if l <= huffmanTableBits {
+			reversed := int(bits.Reverse16(uint16(next[l])) >> (16 - l))
+			for i := reversed; i < len(h.table); i += 1 << l {
+				h.table[i] = uint16(s)<<4 | uint16(l)
+			}
+		}
+		next[l]++
+	}
+	return h, nil
+}
+
+func (h *huffman) decode(r *vp8lReader) int {
+	if h.single >= 0 {
+		return h.single
+	}
+	if r.fill(huffmanTableBits) {
+		if e := h.table[r.bits&(1<<huffmanTableBits-1)]; e != 0 {
+			r.read(uint(e & 15))
+			return int(e >> 4)
+		}
+	}
+
+	code, first, index := 0, 0, 0
+	for l := 1; l < 16; l++ {
+		code |= int(r.read(1))
+		count := int(h.counts[l])
+		if code-first < count {
+			return int(h.symbols[index+code-first])
+		}
+		index += count
+		first = (first + count) << 1
+		code <<= 1
+	}
+	return 0
+}
+
+var codeLengthOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// vp8lGroup holds the codes for green and lengths, red, blue, alpha and
+// distances.
+type vp8lGroup [5]*huffman
+
+type vp8lTransform struct {
+	kind int
+	bits int
+	// width is that of the image the transform applies to.
+	width int
+	data  []uint32
+}
+
+type vp8lDecoder struct {
+	r *vp8lReader
+}
+
+// vp8lSize parses the header of a lossless bitstream.
+func vp8lSize(data []byte) (int, int, bool, error) {
+	if len(data) < 5 || data[0] != 0x2f {
+		return 0, 0, false, errors.Wrap(errWebP, "missing VP8L signature")
+	}
+	v := binary.LittleEndian.Uint32(data[1:])
+	if v>>29 != 0 {
+		return 0, 0, false, errors.Wrap(errWebP, "unknown VP8L version")
+	}
+	return int(v&0x3fff) + 1, int(v>>14&0x3fff) + 1, v>>28&1 == 1, nil
+}
+
+// decodeVP8L decodes a lossless WebP bitstream.
+func decodeVP8L(data []byte) (*image.NRGBA, error) {
+	width, height, _, err := vp8lSize(data)
+	if err != nil {
+		return nil, err
+	}
+	pix, err := decodeVP8LImage(data[5:], width, height)
+	if err != nil {
+		return nil, err
+	}
+
+	m := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for i, p := range pix {
+		m.Pix[4*i] = uint8(p >> 16)
+		m.Pix[4*i+1] = uint8(p >> 8)
+		m.Pix[4*i+2] = uint8(p)
+		m.Pix[4*i+3] = uint8(p >> 24)
+	}
+	return m, nil
+}
+
+// decodeVP8LImage decodes a headerless image stream into ARGB pixels; the
+// alpha chunk of lossy images stores one too.
+func decodeVP8LImage(data []byte, width, height int) ([]uint32, error) {
+	d := &vp8lDecoder{r: &vp8lReader{data: data}}
+	return d.decodeImage(width, height, true)
+}
+
+func (d *vp8lDecoder) decodeImage(width, height int, main bool) ([]uint32, error) {
+	var transforms []vp8lTransform
+	if main {
+		seen := 0
+		for d.r.read(1) == 1 {
+			kind := int(d.r.read(2))
+			if seen&(1<<kind) != 0 {
+				return nil, errors.Wrap(errWebP, "repeated VP8L transform")
+			}
+			seen |= 1 << kind
+
+			t := vp8lTransform{kind: kind, width: width}
+			var err error
+			switch kind {
+			case transformPredictor, transformColor:
+				t.bits = int(d.r.read(3)) + 2
+				t.data, err = d.decodeImage(subSampleSize(width, t.bits), subSampleSize(height, t.bits), false)
+			case transformColorIndexing:
+				t.data, t.bits, err = d.decodePalette()
+				width = subSampleSize(width, t.bits)
+			}
+			if err != nil {
+				return nil, err
+			}
+			transforms = append(transforms, t)
+		}
+	}
+
+	cacheBits := 0
+	if d.r.read(1) == 1 {
+		cacheBits = int(d.r.read(4))
+		if cacheBits < 1 || cacheBits > 11 {
+			return nil, errors.Wrap(errWebP, "invalid VP8L color cache size")
+		}
+	}
+
+	var meta []uint32
+	metaBits, groups := 0, 1
+	if main && d.r.read(1) == 1 {
+		metaBits = int(d.r.read(3)) + 2
+		var err error
+		meta, err = d.decodeImage(subSampleSize(width, metaBits), subSampleSize(height, metaBits), false)
+		if err != nil {
+			return nil, err
+		}
+		for i, p := range meta {
+			meta[i] = p >> 8 & 0xffff
+			if int(meta[i]) >= groups {
+				groups = int(meta[i]) + 1
+			}
+		}
+	}
+
+	codes := make([]vp8lGroup, groups)
+	for i := range codes {
+		for j, size := range [5]int{256 + 24 + cacheSize(cacheBits), 256, 256, 256, 40} {
+			h, err := d.readCode(size)
+			if err != nil {
+				return nil, err
+			}
+			codes[i][j] = h
+		}
+	}
+
+	pix, err := d.decodePixels(width, height, codes, meta, metaBits, cacheBits)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(transforms) - 1; i >= 0; i-- {
+		pix = transforms[i].inverse(pix, height)
+	}
+	return pix, nil
+}
+
+func cacheSize(bits int) int {
+	if bits == 0 {
+		return 0
+	}
+	return 1 << bits
+}
+
+func subSampleSize(size, bits int) int {
+	return (size + 1<<bits - 1) >> bits
+}
+
+// decodePalette reads the color table of the color indexing transform
+// and returns it with the number of bits packing indexes into pixels.
+func (d *vp8lDecoder) decodePalette() ([]uint32, int, error) {
+	n := int(d.r.read(8)) + 1
+	colors, err := d.decodeImage(n, 1, false)
+	if err != nil {
+		return nil, 0, err
+	}
+	// Indexes past the table are transparent black.
+	palette := make([]uint32, 256)
+	copy(palette, colors)
+	for i := 1; i < n; i++ {
+		palette[i] = addPixels(palette[i], palette[i-1])
+	}
+
+	bits := 0
+	switch {
+	case n <= 2:
+		bits = 3
+	case n <= 4:
+		bits = 2
+	case n <= 16:
+		bits = 1
+	}
+	return palette, bits, nil
+}
+
+func (d *vp8lDecoder) readCode(size int) (*huffman, error) {
+	r := d.r
+	lengths := make([]uint8, size)
+	if r.read(1) == 1 {
+		symbols := int(r.read(1)) + 1
+		first := int(r.read(1 + 7*uint(r.read(1))))
+		if first >= size {
+			return nil, errors.Wrap(errWebP, "invalid VP8L prefix code")
+		}
+		lengths[first] = 1
+		if symbols == 2 {
+			second := int(r.read(8))
+			if second >= size {
+				return nil, errors.Wrap(errWebP, "invalid VP8L prefix code")
+			}
+			lengths[second] = 1
+		}
+		return newHuffman(lengths)
+	}
+
+	var codeLengths [19]uint8
+	for i, n := 0, int(r.read(4))+4; i < n; i++ {
+		codeLengths[codeLengthOrder[i]] = uint8(r.read(3))
+	}
+	lengthCode, err := newHuffman(codeLengths[:])
+	if err != nil {
+		return nil, err
+	}
+
+	max := size
+	if r.read(1) == 1 {
+		max = 2 + int(r.read(2+2*uint(r.read(3))))
+		if max > size {
+			return nil, errors.Wrap(errWebP, "invalid VP8L prefix code")
+		}
+	}
+
+	prev := uint8(8)
+	for s := 0; s < size && max > 0; max-- {
+		c := lengthCode.decode(r)
+		if c < 16 {
+			lengths[s] = uint8(c)
+			s++
+			if c != 0 {
+				prev = uint8(c)
+			}
+			continue
+		}
+
+		var repeat int
+		v := uint8(0)
+		switch c {
+		case 16:
+			repeat, v = 3+int(r.read(2)), prev
+		case 17:
+			repeat = 3 + int(r.read(3))
+		default:
+			repeat = 11 + int(r.read(7))
+		}
+		if s+repeat > size {
+			return nil, errors.Wrap(errWebP, "invalid VP8L prefix code")
+		}
+		for ; repeat > 0; repeat-- {
+			lengths[s] = v
+			s++
+		}
+	}
+	if r.eof {
+		return nil, errors.Wrap(errWebP, "truncated VP8L data")
+	}
+	return newHuffman(lengths)
+}
+
+func (d *vp8lDecoder) decodePixels(width, height int, codes []vp8lGroup, meta []uint32, metaBits, cacheBits int) ([]uint32, error) {
+	r := d.r
+	pix := make([]uint32, width*height)
+	var cache []uint32
+	if cacheBits > 0 {
+		cache = make([]uint32, 1<<cacheBits)
+	}
+	metaWidth := subSampleSize(width, metaBits)
+
+	for i := 0; i < len(pix); {
+		g := &codes[0]
+		if meta != nil {
+			x, y := i%width, i/width
+			g = &codes[meta[(y>>metaBits)*metaWidth+x>>metaBits]]
+		}
+
+		start := i
+		switch s := g[0].decode(r); {
+		case s < 256:
+			red, blue, alpha := g[1].decode(r), g[2].decode(r), g[3].decode(r)
+			pix[i] = uint32(alpha)<<24 | uint32(red)<<16 | uint32(s)<<8 | uint32(blue)
+			i++
+		case s < 256+24:
+			length := lz77Value(r, s-256)
+			dist := lz77Distance(width, lz77Value(r, g[4].decode(r)))
+			if dist > i || length > len(pix)-i {
+				return nil, errors.Wrap(errWebP, "invalid VP8L backward reference")
+			}
+			for ; length > 0; length-- {
+				pix[i] = pix[i-dist]
+				i++
+			}
+		default:
+			pix[i] = cache[s-256-24]
+			i++
+		}
+
+		if r.eof {
+			return nil, errors.Wrap(errWebP, "truncated VP8L data")
+		}
+		if cache != nil {
+			for _, p := range pix[start:i] {
+				cache[(0x1e35a7bd*p)>>(32-cacheBits)] = p
+			}
+		}
+	}
+	return pix, nil
+}
+
+// lz77Value decodes a length or distance from its prefix symbol and
+// extra bits.
+func lz77Value(r *vp8lReader, prefix int) int {
+	if prefix < 4 {
+		return prefix + 1
+	}
+	extra := uint(prefix-2) >> 1
+	offset := (2 + prefix&1) << extra
+	return offset + int(r.read(extra)) + 1
+}
+
+// lz77Distance maps a distance code to a pixel distance; the first 120
+// codes address the neighbourhood of the pixel as (dx, dy) pairs.
+func lz77Distance(width, code int) int {
+	if code > 120 {
+		return code - 120
+	}
+	c := distanceCodes[code-1]
+	if dist := int(c[0]) + int(c[1])*width; dist >= 1 {
+		return dist
+	}
+	return 1
+}
+
+var distanceCodes = [120][2]int8{
+	{0, 1}, {1, 0}, {1, 1}, {-1, 1}, {0, 2}, {2, 0}, {1, 2},
+	{-1, 2}, {2, 1}, {-2, 1}, {2, 2}, {-2, 2}, {0, 3}, {3, 0},
+	{1, 3}, {-1, 3}, {3, 1}, {-3, 1}, {2, 3}, {-2, 3}, {3, 2},
+	{-3, 2}, {0, 4}, {4, 0}, {1, 4}, {-1, 4}, {4, 1}, {-4, 1},
+	{3, 3}, {-3, 3}, {2, 4}, {-2, 4}, {4, 2}, {-4, 2}, {0, 5},
+	{3, 4}, {-3, 4}, {4, 3}, {-4, 3}, {5, 0}, {1, 5}, {-1, 5},
+	{5, 1}, {-5, 1}, {2, 5}, {-2, 5}, {5, 2}, {-5, 2}, {4, 4},
+	{-4, 4}, {3, 5}, {-3, 5}, {5, 3}, {-5, 3}, {0, 6}, {6, 0},
+	{1, 6}, {-1, 6}, {6, 1}, {-6, 1}, {2, 6}, {-2, 6}, {6, 2},
+	{-6, 2}, {4, 5}, {-4, 5}, {5, 4}, {-5, 4}, {3, 6}, {-3, 6},
+	{6, 3}, {-6, 3}, {0, 7}, {7, 0}, {1, 7}, {-1, 7}, {5, 5},
+	{-5, 5}, {7, 1}, {-7, 1}, {4, 6}, {-4, 6}, {6, 4}, {-6, 4},
+	{2, 7}, {-2, 7}, {7, 2}, {-7, 2}, {3, 7}, {-3, 7}, {7, 3},
+	{-7, 3}, {5, 6}, {-5, 6}, {6, 5}, {-6, 5}, {8, 0}, {4, 7},
+	{-4, 7}, {7, 4}, {-7, 4}, {8, 1}, {8, 2}, {6, 6}, {-6, 6},
+	{8, 3}, {5, 7}, {-5, 7}, {7, 5}, {-7, 5}, {8, 4}, {6, 7},
+	{-6, 7}, {7, 6}, {-7, 6}, {8, 5}, {7, 7}, {-7, 7}, {8, 6},
+	{8, 7},
+}
+
+// inverse undoes the transform on pix, returning the pixels of an image
+// t.width wide.
+func (t *vp8lTransform) inverse(pix []uint32, height int) []uint32 {
+	w := t.width
+	switch t.kind {
+	case transformPredictor:
+		blocks := subSampleSize(w, t.bits)
+		for y := 0; y < height; y++ {
+			for x := 0; x < w; x++ {
+				i := y*w + x
+				var pred uint32
+				switch {
+				case i == 0:
+					pred = 0xff000000
+				case y == 0:
+					pred = pix[i-1]
+				case x == 0:
+					pred = pix[i-w]
+				default:
+					mode := t.data[(y>>t.bits)*blocks+x>>t.bits] >> 8 & 0xf
+					// On the right column, the top right pixel wraps to
+					// the first of the current row, as the spec says.
+					pred = predictPixel(mode, pix[i-1], pix[i-w], pix[i-w-1], pix[i-w+1])
+				}
+				pix[i] = addPixels(pix[i], pred)
+			}
+		}
+	case transformColor:
+		blocks := subSampleSize(w, t.bits)
+		for y := 0; y < height; y++ {
+			for x := 0; x < w; x++ {
+				i := y*w + x
+				e := t.data[(y>>t.bits)*blocks+x>>t.bits]
+				p := pix[i]
+				green := int(int8(p >> 8))
+				red := int(p>>16&0xff) + int(int8(e))*green>>5
+				blue := int(p&0xff) + int(int8(e>>8))*green>>5
+				blue += int(int8(e>>16)) * int(int8(red)) >> 5
+				pix[i] = p&0xff00ff00 | uint32(red&0xff)<<16 | uint32(blue&0xff)
+			}
+		}
+	case transformSubtractGreen:
+		for i, p := range pix {
+			g := p >> 8 & 0xff
+			pix[i] = p&0xff00ff00 | (p&0x00ff00ff+(g<<16|g))&0x00ff00ff
+		}
+	case transformColorIndexing:
+		packed := subSampleSize(w, t.bits)
+		perPixel, size := 1<<t.bits, 8>>t.bits
+		mask := uint32(1)<<size - 1
+		out := make([]uint32, w*height)
+		for y := 0; y < height; y++ {
+			for x := 0; x < w; x++ {
+				p := pix[y*packed+x>>t.bits] >> 8
+				out[y*w+x] = t.data[p>>((x&(perPixel-1))*size)&mask]
+			}
+		}
+		return out
+	}
+	return pix
+}
+
+func predictPixel(mode uint32, l, t, tl, tr uint32) uint32 {
+	switch mode {
+	case 1:
+		return l
+	case 2:
+		return t
+	case 3:
+		return tr
+	case 4:
+		return tl
+	case 5:
+		return average2(average2(l, tr), t)
+	case 6:
+		return average2(l, tl)
+	case 7:
+		return average2(l, t)
+	case 8:
+		return average2(tl, t)
+	case 9:
+		return average2(t, tr)
+	case 10:
+		return average2(average2(l, tl), average2(t, tr))
+	case 11:
+		return selectPixel(l, t, tl)
+	case 12:
+		return mapChannels(l, t, tl, func(a, b, c int) int { return a + b - c })
+	case 13:
+		avg := average2(l, t)
+		return mapChannels(avg, tl, 0, func(a, b, _ int) int { return a + (a-b)/2 })
+	}
+	return 0xff000000
+}
+
+// addPixels adds a and b channel by channel, modulo 256.
+func addPixels(a, b uint32) uint32 {
+	return (a&0xff00ff00+b&0xff00ff00)&0xff00ff00 | (a&0x00ff00ff+b&0x00ff00ff)&0x00ff00ff
+}
+
+func average2(a, b uint32) uint32 {
+	return (a^b)&0xfefefefe>>1 + a&b
+}
+
+// selectPixel returns whichever of l and t is closer to the gradient
+// estimate l + t - tl.
+func selectPixel(l, t, tl uint32) uint32 {
+	pl, pt := 0, 0
+	for shift := 0; shift < 32; shift += 8 {
+		cl, ct, ctl := int(l>>shift&0xff), int(t>>shift&0xff), int(tl>>shift&0xff)
+		pl += abs(ct - ctl)
+		pt += abs(cl - ctl)
+	}
+	if pl < pt {
+		return l
+	}
+	return t
+}
+
+// mapChannels applies f to each channel of a, b and c, clamping the
+// results to a byte.
+func mapChannels(a, b, c uint32, f func(a, b, c int) int) uint32 {
+	var out uint32
+	for shift := 0; shift < 32; shift += 8 {
+		v := f(int(a>>shift&0xff), int(b>>shift&0xff), int(c>>shift&0xff))
+		out |= uint32(clamp255(v)) << shift
+	}
+	return out
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
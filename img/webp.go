@@ -0,0 +1,201 @@
+package img
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+var errWebP = errors.New("img: malformed WebP")
+
+// init registers WebP with image.RegisterFormat. The registry is global,
+// so importing this package also makes image.Decode and
+// image.DecodeConfig read WebP in every other package of the program.
+func init() {
+	image.RegisterFormat("webp", "RIFF????WEBP", decodeWebP, decodeWebPConfig)
+}
+
+// webpFile holds the chunks of a still WebP image.
+type webpFile struct {
+	// data is the VP8 or, when lossless, the VP8L bitstream.
+	data     []byte
+	lossless bool
+	// alpha is the ALPH chunk of a lossy image with transparency.
+	alpha []byte
+	// width and height are the canvas size of the VP8X chunk, if any.
+	width, height int
+}
+
+// readWebP reads the RIFF container up to the image bitstream, skipping
+// metadata chunks. Animations are not supported.
+func readWebP(r io.Reader) (*webpFile, error) {
+	var header [12]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, errors.Wrap(err, "io.ReadFull")
+	}
+	if string(header[:4]) != "RIFF" || string(header[8:]) != "WEBP" {
+		return nil, errors.Wrap(errWebP, "missing RIFF header")
+	}
+	r = io.LimitReader(r, int64(binary.LittleEndian.Uint32(header[4:]))-4)
+
+	f := &webpFile{}
+	for {
+		fourCC, chunk, err := readChunk(r)
+		if err != nil {
+			return nil, err
+		}
+		switch fourCC {
+		case "VP8 ", "VP8L":
+			f.data, f.lossless = chunk, fourCC == "VP8L"
+			return f, nil
+		case "VP8X":
+			if len(chunk) < 10 {
+				return nil, errors.Wrap(errWebP, "short VP8X chunk")
+			}
+			if chunk[0]&0x02 != 0 {
+				return nil, errors.Wrap(ErrUnsupportedFormat, "animated WebP")
+			}
+			f.width = int(chunk[4]) | int(chunk[5])<<8 | int(chunk[6])<<16 + 1
+			f.height = int(chunk[7]) | int(chunk[8])<<8 | int(chunk[9])<<16 + 1
+		case "ALPH":
+			f.alpha = chunk
+		}
+	}
+}
+
+func readChunk(r io.Reader) (string, []byte, error) {
+	var header [8]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if err == io.EOF {
+			return "", nil, errors.Wrap(errWebP, "no image data")
+		}
+		return "", nil, errors.Wrap(err, "io.ReadFull")
+	}
+
+	fourCC, size := string(header[:4]), int64(binary.LittleEndian.Uint32(header[4:]))
+	data, err := io.ReadAll(io.LimitReader(r, size))
+	if err != nil {
+		return "", nil, errors.Wrap(err, "io.ReadAll")
+	}
+	if int64(len(data)) < size {
+		return "", nil, errors.Wrap(errWebP, "truncated chunk")
+	}
+	if size&1 == 1 {
+		// Chunks are padded to an even size; the last pad may be missing.
+		io.ReadFull(r, header[:1])
+	}
+	return fourCC, data, nil
+}
+
+func (f *webpFile) config() (image.Config, error) {
+	var c image.Config
+	var err error
+	switch {
+	case f.lossless:
+		c.ColorModel = color.NRGBAModel
+		c.Width, c.Height, _, err = vp8lSize(f.data)
+	case f.alpha != nil:
+		c.ColorModel = color.NYCbCrAModel
+		c.Width, c.Height, err = vp8Size(f.data)
+	default:
+		c.ColorModel = color.YCbCrModel
+		c.Width, c.Height, err = vp8Size(f.data)
+	}
+	if err != nil {
+		return image.Config{}, err
+	}
+	if f.width != 0 && (c.Width != f.width || c.Height != f.height) {
+		return image.Config{}, errors.Wrap(errWebP, "canvas and frame sizes differ")
+	}
+	return c, nil
+}
+
+func decodeWebPConfig(r io.Reader) (image.Config, error) {
+	f, err := readWebP(r)
+	if err != nil {
+		return image.Config{}, err
+	}
+	return f.config()
+}
+
+func decodeWebP(r io.Reader) (image.Image, error) {
+	f, err := readWebP(r)
+	if err != nil {
+		return nil, err
+	}
+	c, err := f.config()
+	if err != nil {
+		return nil, err
+	}
+	if f.lossless {
+		return decodeVP8L(f.data)
+	}
+
+	m, err := decodeVP8(f.data)
+	if err != nil {
+		return nil, err
+	}
+	if f.alpha == nil {
+		return m, nil
+	}
+	alpha, err := decodeAlpha(f.alpha, c.Width, c.Height)
+	if err != nil {
+		return nil, err
+	}
+	return &image.NYCbCrA{YCbCr: *m, A: alpha, AStride: c.Width}, nil
+}
+
+// decodeAlpha decodes an ALPH chunk, raw or lossless and optionally
+// filtered, into a width x height plane.
+func decodeAlpha(chunk []byte, width, height int) ([]byte, error) {
+	if len(chunk) == 0 {
+		return nil, errors.Wrap(errWebP, "empty ALPH chunk")
+	}
+	alpha := make([]byte, width*height)
+	switch chunk[0] & 3 {
+	case 0:
+		if len(chunk)-1 < len(alpha) {
+			return nil, errors.Wrap(errWebP, "truncated ALPH chunk")
+		}
+		copy(alpha, chunk[1:])
+	case 1:
+		pix, err := decodeVP8LImage(chunk[1:], width, height)
+		if err != nil {
+			return nil, err
+		}
+		for i, p := range pix {
+			alpha[i] = uint8(p >> 8)
+		}
+	default:
+		return nil, errors.Wrap(errWebP, "unknown alpha compression")
+	}
+
+	filter := chunk[0] >> 2 & 3
+	if filter == 0 {
+		return alpha, nil
+	}
+	// The top row is always predicted from the left and the left column
+	// from above; other pixels by the filter of the header.
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := y*width + x
+			switch {
+			case i == 0:
+			case y == 0:
+				alpha[i] += alpha[i-1]
+			case x == 0:
+				alpha[i] += alpha[i-width]
+			case filter == 1:
+				alpha[i] += alpha[i-1]
+			case filter == 2:
+				alpha[i] += alpha[i-width]
+			default:
+				alpha[i] += clamp255(int(alpha[i-1]) + int(alpha[i-width]) - int(alpha[i-width-1]))
+			}
+		}
+	}
+	return alpha, nil
+}
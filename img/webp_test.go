@@ -0,0 +1,122 @@
+package img
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// The references were decoded by libwebp. Lossy ones hold the planes the
+// codec produces, before any upsampling to RGB, as a gray image: the Y
+// rows, then for each chroma row the Cb row followed by the Cr row, then
+// the A rows when there is alpha.
+func TestDecodeWebP(t *testing.T) {
+	for _, name := range []string{
+		"gradient.lossy.webp",
+		"gradient.lossy-with-alpha.webp",
+		"gradient.lossless.webp",
+	} {
+		t.Run(name, func(t *testing.T) {
+			m := decodeFile(t, name)
+			switch m := m.(type) {
+			case *image.YCbCr:
+				comparePlanes(t, readReference(t, name+".ycbcr.png"), m, nil)
+			case *image.NYCbCrA:
+				comparePlanes(t, readReference(t, name+".nycbcra.png"), &m.YCbCr, m.A)
+			default:
+				want := readReference(t, name+".png")
+				if m.Bounds() != want.Bounds() {
+					t.Fatalf("bounds %v, want %v", m.Bounds(), want.Bounds())
+				}
+				for y := m.Bounds().Min.Y; y < m.Bounds().Max.Y; y++ {
+					for x := m.Bounds().Min.X; x < m.Bounds().Max.X; x++ {
+						got := color.NRGBAModel.Convert(m.At(x, y))
+						if w := color.NRGBAModel.Convert(want.At(x, y)); got != w {
+							t.Fatalf("pixel (%d, %d) = %v, want %v", x, y, got, w)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func decodeFile(t *testing.T, name string) image.Image {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, format, err := Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if format != WebP {
+		t.Fatalf("format %q, want %q", format, WebP)
+	}
+	return m
+}
+
+func readReference(t *testing.T, name string) image.Image {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	m, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func comparePlanes(t *testing.T, ref image.Image, m *image.YCbCr, alpha []byte) {
+	t.Helper()
+	gray, ok := ref.(*image.Gray)
+	if !ok {
+		t.Fatalf("reference is %T, want *image.Gray", ref)
+	}
+	width, height := m.Rect.Dx(), m.Rect.Dy()
+	cw, ch := (width+1)/2, (height+1)/2
+	row := func(y int) []byte {
+		return gray.Pix[y*gray.Stride : y*gray.Stride+gray.Rect.Dx()]
+	}
+
+	for y := 0; y < height; y++ {
+		compareRow(t, "Y", y, m.Y[y*m.YStride:y*m.YStride+width], row(y)[:width])
+	}
+	for y := 0; y < ch; y++ {
+		r := row(height + y)
+		compareRow(t, "Cb", y, m.Cb[y*m.CStride:y*m.CStride+cw], r[:cw])
+		compareRow(t, "Cr", y, m.Cr[y*m.CStride:y*m.CStride+cw], r[cw:2*cw])
+	}
+	for y := 0; alpha != nil && y < height; y++ {
+		compareRow(t, "A", y, alpha[y*width:(y+1)*width], row(height + ch + y)[:width])
+	}
+}
+
+func compareRow(t *testing.T, plane string, y int, got, want []byte) {
+	t.Helper()
+	if !bytes.Equal(got, want) {
+		t.Fatalf("%s row %d = %v, want %v", plane, y, got, want)
+	}
+}
+
+func FuzzDecode(f *testing.F) {
+	files, _ := filepath.Glob(filepath.Join("testdata", "*.webp"))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		DecodeLimit(bytes.NewReader(data), 1<<20, 1<<20)
+	})
+}
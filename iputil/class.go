@@ -0,0 +1,105 @@
+package iputil
+
+import "net"
+
+type Class string
+
+const (
+	Public      Class = "public"
+	Private     Class = "private"
+	Loopback    Class = "loopback"
+	LinkLocal   Class = "link-local"
+	Multicast   Class = "multicast"
+	Unspecified Class = "unspecified"
+	// Reserved covers shared (CGNAT), documentation, benchmarking and
+	// other special-purpose ranges that are neither private nor routable.
+	Reserved Class = "reserved"
+	// Invalid is a nil or malformed net.IP, neither 4 nor 16 bytes long.
+	Invalid Class = "invalid"
+)
+
+var (
+	nat64     = mustParseCIDRs("64:ff9b::/96")[0]
+	sixToFour = mustParseCIDRs("2002::/16")[0]
+)
+
+var reservedNetworks = mustParseCIDRs(
+	"0.0.0.0/8",
+	"100.64.0.0/10",
+	"192.0.0.0/24",
+	"192.0.2.0/24",
+	"198.18.0.0/15",
+	"198.51.100.0/24",
+	"203.0.113.0/24",
+	"240.0.0.0/4",
+	"255.255.255.255/32",
+	"64:ff9b::/96",
+	"100::/64",
+	"2002::/16",
+	"2001::/23",
+	"2001:db8::/32",
+)
+
+func mustParseCIDRs(values ...string) []*net.IPNet {
+	networks, err := ParseCIDRs(values...)
+	if err != nil {
+		panic(err)
+	}
+	return networks
+}
+
+// Classify returns the class of ip; SSRF protection should only allow
+// Public destinations. NAT64 (64:ff9b::/96) and 6to4 (2002::/16) addresses
+// take the class of the IPv4 address they embed, or Reserved when that is
+// public. A nil or malformed ip is Invalid.
+func Classify(ip net.IP) Class {
+	if len(ip) != net.IPv4len && len(ip) != net.IPv6len {
+		return Invalid
+	}
+	if v4 := embeddedIPv4(ip); v4 != nil {
+		if class := Classify(v4); class != Public {
+			return class
+		}
+		return Reserved
+	}
+	switch {
+	case ip.IsUnspecified():
+		return Unspecified
+	case ip.IsLoopback():
+		return Loopback
+	case ip.IsLinkLocalUnicast(), ip.IsLinkLocalMulticast():
+		return LinkLocal
+	case ip.IsMulticast():
+		return Multicast
+	case ip.IsPrivate():
+		return Private
+	case ContainsAny(reservedNetworks, ip):
+		return Reserved
+	}
+	return Public
+}
+
+// IsPrivate reports RFC 1918 and IPv6 unique local addresses.
+func IsPrivate(ip net.IP) bool {
+	return Classify(ip) == Private
+}
+
+// IsPublic reports addresses routable on the internet.
+func IsPublic(ip net.IP) bool {
+	return ip != nil && Classify(ip) == Public
+}
+
+// embeddedIPv4 returns the IPv4 address carried by a NAT64 or 6to4
+// address.
+func embeddedIPv4(ip net.IP) net.IP {
+	if ip.To4() != nil || len(ip) != net.IPv6len {
+		return nil
+	}
+	switch {
+	case nat64.Contains(ip):
+		return net.IPv4(ip[12], ip[13], ip[14], ip[15])
+	case sixToFour.Contains(ip):
+		return net.IPv4(ip[2], ip[3], ip[4], ip[5])
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package iputil
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ClientIPResolver finds the address of the client behind reverse
+// proxies. Forwarding headers are only honoured when the direct peer is a
+// trusted proxy, so clients cannot spoof their address.
+type ClientIPResolver struct {
+	trusted []*net.IPNet
+	headers []string
+}
+
+func NewClientIPResolver() *ClientIPResolver {
+	return &ClientIPResolver{headers: []string{"X-Forwarded-For", "X-Real-IP"}}
+}
+
+// TrustProxies sets the proxy networks allowed to set forwarding headers;
+// build them with ParseCIDRs.
+func (r *ClientIPResolver) TrustProxies(proxies ...*net.IPNet) *ClientIPResolver {
+	r.trusted = proxies
+	return r
+}
+
+// Headers replaces the forwarding headers checked, in order, e.g.
+// "CF-Connecting-IP" behind Cloudflare.
+func (r *ClientIPResolver) Headers(headers ...string) *ClientIPResolver {
+	r.headers = headers
+	return r
+}
+
+// Resolve returns the client IP of req. X-Forwarded-For is read right to
+// left, skipping trusted proxies, so the first untrusted hop wins.
+func (r *ClientIPResolver) Resolve(req *http.Request) net.IP {
+	remote := parseHost(req.RemoteAddr)
+	if remote == nil || !ContainsAny(r.trusted, remote) {
+		return remote
+	}
+
+	for _, header := range r.headers {
+		values := req.Header.Values(header)
+		if len(values) == 0 {
+			continue
+		}
+		hops := strings.Split(strings.Join(values, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := parseHost(strings.TrimSpace(hops[i]))
+			if ip == nil {
+				break
+			}
+			if !ContainsAny(r.trusted, ip) || i == 0 {
+				return ip
+			}
+		}
+	}
+	return remote
+}
+
+// parseHost parses "ip", "ip:port" and "[ipv6]:port".
+func parseHost(s string) net.IP {
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+	return net.ParseIP(strings.Trim(s, "[]"))
+}
+
+// ClientIP resolves the client IP trusting only loopback and private
+// proxies, a fit for apps behind a load balancer in the same network.
+func ClientIP(req *http.Request) net.IP {
+	return defaultResolver.Resolve(req)
+}
+
+var defaultResolver = NewClientIPResolver().TrustProxies(mustParseCIDRs(
+	"127.0.0.0/8", "::1/128", "10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "fc00::/7",
+)...)
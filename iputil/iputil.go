@@ -0,0 +1,146 @@
+package iputil
+
+import (
+	"math/big"
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ParseCIDR accepts a CIDR or a bare IP, which becomes a /32 or /128.
+func ParseCIDR(s string) (*net.IPNet, error) {
+	s = strings.TrimSpace(s)
+	if !strings.Contains(s, "/") {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, errors.Errorf("iputil: invalid IP %q", s)
+		}
+		bits := 128
+		if ip4 := ip.To4(); ip4 != nil {
+			ip, bits = ip4, 32
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+	_, network, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, errors.Wrap(err, "net.ParseCIDR")
+	}
+	return network, nil
+}
+
+// ParseCIDRs parses a list such as a trusted proxies setting.
+func ParseCIDRs(values ...string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(values))
+	for _, v := range values {
+		network, err := ParseCIDR(v)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// Contains reports whether ip belongs to cidr.
+func Contains(cidr, ip string) (bool, error) {
+	network, err := ParseCIDR(cidr)
+	if err != nil {
+		return false, err
+	}
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	if parsed == nil {
+		return false, errors.Errorf("iputil: invalid IP %q", ip)
+	}
+	return network.Contains(parsed), nil
+}
+
+// ContainsAny reports whether ip belongs to any of networks.
+func ContainsAny(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Overlaps reports whether two CIDRs share any address.
+func Overlaps(a, b string) (bool, error) {
+	na, err := ParseCIDR(a)
+	if err != nil {
+		return false, err
+	}
+	nb, err := ParseCIDR(b)
+	if err != nil {
+		return false, err
+	}
+	return na.Contains(nb.IP) || nb.Contains(na.IP), nil
+}
+
+// RangeToCIDRs returns the smallest list of CIDRs covering exactly the
+// addresses from start to end, inclusive.
+func RangeToCIDRs(start, end string) ([]*net.IPNet, error) {
+	first, last := net.ParseIP(start), net.ParseIP(end)
+	if first == nil || last == nil {
+		return nil, errors.Errorf("iputil: invalid range %q - %q", start, end)
+	}
+	bits := 128
+	if first.To4() != nil && last.To4() != nil {
+		first, last, bits = first.To4(), last.To4(), 32
+	} else if first.To4() != nil || last.To4() != nil {
+		return nil, errors.New("iputil: range mixes IPv4 and IPv6")
+	}
+
+	lo, hi := new(big.Int).SetBytes(first), new(big.Int).SetBytes(last)
+	if lo.Cmp(hi) > 0 {
+		return nil, errors.Errorf("iputil: range start %s is after end %s", start, end)
+	}
+
+	var out []*net.IPNet
+	one := big.NewInt(1)
+	for lo.Cmp(hi) <= 0 {
+		// The largest block aligned at lo that does not pass hi.
+		size := 0
+		for size < bits {
+			block := new(big.Int).Lsh(one, uint(size+1))
+			if new(big.Int).Mod(lo, block).Sign() != 0 {
+				break
+			}
+			blockEnd := new(big.Int).Add(lo, block)
+			if blockEnd.Sub(blockEnd, one).Cmp(hi) > 0 {
+				break
+			}
+			size++
+		}
+		out = append(out, &net.IPNet{IP: toIP(lo, bits/8), Mask: net.CIDRMask(bits-size, bits)})
+		lo.Add(lo, new(big.Int).Lsh(one, uint(size)))
+	}
+	return out, nil
+}
+
+func toIP(n *big.Int, length int) net.IP {
+	b := n.Bytes()
+	ip := make(net.IP, length)
+	copy(ip[length-len(b):], b)
+	return ip
+}
+
+// Anonymize zeroes the host part of ip for privacy-preserving logs and
+// analytics: the last octet of IPv4 and the last 80 bits of IPv6.
+func Anonymize(ip net.IP) net.IP {
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4.Mask(net.CIDRMask(24, 32))
+	}
+	return ip.Mask(net.CIDRMask(48, 128))
+}
+
+// AnonymizeString is Anonymize for textual addresses; invalid input is
+// returned as is.
+func AnonymizeString(s string) string {
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return s
+	}
+	return Anonymize(ip).String()
+}
@@ -0,0 +1,119 @@
+package jsonutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Op string
+
+const (
+	Added   Op = "added"
+	Removed Op = "removed"
+	Changed Op = "changed"
+)
+
+// Change describes a difference at Path, a JSON pointer (RFC 6901).
+type Change struct {
+	Path string      `json:"path"`
+	Op   Op          `json:"op"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+func (c Change) String() string {
+	switch c.Op {
+	case Added:
+		return fmt.Sprintf("+ %s: %s", c.Path, compact(c.New))
+	case Removed:
+		return fmt.Sprintf("- %s: %s", c.Path, compact(c.Old))
+	}
+	return fmt.Sprintf("~ %s: %s -> %s", c.Path, compact(c.Old), compact(c.New))
+}
+
+// Diff compares two JSON documents semantically, ignoring formatting and key
+// order, and returns the changes sorted by path. Numbers are compared by
+// their textual value, so 1 and 1.0 are reported as changed.
+func Diff(a, b []byte) ([]Change, error) {
+	left, err := decode(a)
+	if err != nil {
+		return nil, err
+	}
+	right, err := decode(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+	diff("", left, right, &changes)
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// Equal reports whether two JSON documents are semantically identical.
+func Equal(a, b []byte) (bool, error) {
+	changes, err := Diff(a, b)
+	return len(changes) == 0, err
+}
+
+func diff(path string, a, b interface{}, changes *[]Change) {
+	switch left := a.(type) {
+	case map[string]interface{}:
+		right, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+		for key, lv := range left {
+			rv, exists := right[key]
+			if !exists {
+				*changes = append(*changes, Change{Path: path + "/" + escape(key), Op: Removed, Old: lv})
+				continue
+			}
+			diff(path+"/"+escape(key), lv, rv, changes)
+		}
+		for key, rv := range right {
+			if _, exists := left[key]; !exists {
+				*changes = append(*changes, Change{Path: path + "/" + escape(key), Op: Added, New: rv})
+			}
+		}
+		return
+
+	case []interface{}:
+		right, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+		for i := 0; i < len(left) || i < len(right); i++ {
+			p := path + "/" + strconv.Itoa(i)
+			switch {
+			case i >= len(right):
+				*changes = append(*changes, Change{Path: p, Op: Removed, Old: left[i]})
+			case i >= len(left):
+				*changes = append(*changes, Change{Path: p, Op: Added, New: right[i]})
+			default:
+				diff(p, left[i], right[i], changes)
+			}
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		*changes = append(*changes, Change{Path: path, Op: Changed, Old: a, New: b})
+	}
+}
+
+func escape(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+func compact(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
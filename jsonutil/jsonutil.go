@@ -0,0 +1,51 @@
+package jsonutil
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+func Pretty(data []byte) ([]byte, error) {
+	return PrettyIndent(data, "  ")
+}
+
+func PrettyIndent(data []byte, indent string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", indent); err != nil {
+		return nil, errors.Wrap(err, "json.Indent")
+	}
+	return buf.Bytes(), nil
+}
+
+// PrettyValue marshals v with indentation, without escaping HTML characters.
+func PrettyValue(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return nil, errors.Wrap(err, "json.Encoder.Encode")
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+func Minify(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return nil, errors.Wrap(err, "json.Compact")
+	}
+	return buf.Bytes(), nil
+}
+
+func decode(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, errors.Wrap(err, "json.Decoder.Decode")
+	}
+	return v, nil
+}
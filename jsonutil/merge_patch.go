@@ -0,0 +1,50 @@
+package jsonutil
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// MergePatch applies an RFC 7386 JSON merge patch to target.
+func MergePatch(target, patch []byte) ([]byte, error) {
+	var doc interface{}
+	if len(target) > 0 {
+		var err error
+		if doc, err = decode(target); err != nil {
+			return nil, err
+		}
+	}
+
+	p, err := decode(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := json.Marshal(mergePatch(doc, p))
+	if err != nil {
+		return nil, errors.Wrap(err, "json.Marshal")
+	}
+	return out, nil
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = map[string]interface{}{}
+	}
+
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+			continue
+		}
+		targetObj[key] = mergePatch(targetObj[key], value)
+	}
+	return targetObj
+}
@@ -0,0 +1,187 @@
+package kv
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"utils/clock"
+	"utils/fsutil"
+
+	"github.com/pkg/errors"
+)
+
+type entry struct {
+	Value     json.RawMessage `json:"v"`
+	ExpiresAt *time.Time      `json:"e,omitempty"`
+}
+
+// Store is a small persistent key-value store kept in memory and saved
+// to a single JSON file after every change, through an atomic rename so
+// a crash never leaves a torn file. It suits tokens, sync cursors and
+// similar state of command-line tools, not large or write-heavy data.
+// It is safe for concurrent use within one process.
+type Store struct {
+	mu    sync.RWMutex
+	path  string
+	data  map[string]entry
+	clock clock.Clock
+}
+
+// Open loads the store at path, starting empty when the file does not
+// exist yet.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path, data: make(map[string]entry), clock: clock.Real}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, errors.Wrap(err, "os.ReadFile")
+	}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &s.data); err != nil {
+			return nil, errors.Wrapf(err, "kv: corrupt store %s", path)
+		}
+	}
+	return s, nil
+}
+
+// Clock sets the time source used for expiration, for deterministic tests.
+func (s *Store) Clock(clk clock.Clock) *Store {
+	s.mu.Lock()
+	s.clock = clock.Or(clk)
+	s.mu.Unlock()
+	return s
+}
+
+func (s *Store) Set(key string, value interface{}) error {
+	return s.SetTTL(key, value, 0)
+}
+
+// SetTTL stores value as JSON, expiring after ttl; zero never expires.
+func (s *Store) SetTTL(key string, value interface{}, ttl time.Duration) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return errors.Wrap(err, "json.Marshal")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := entry{Value: raw}
+	if ttl > 0 {
+		expiresAt := s.clock.Now().Add(ttl)
+		e.ExpiresAt = &expiresAt
+	}
+	s.data[key] = e
+	return s.save()
+}
+
+func (s *Store) live(key string) (entry, bool) {
+	e, ok := s.data[key]
+	if !ok || (e.ExpiresAt != nil && !s.clock.Now().Before(*e.ExpiresAt)) {
+		return entry{}, false
+	}
+	return e, true
+}
+
+// Get decodes the value of key into dst and reports whether it was found.
+func (s *Store) Get(key string, dst interface{}) (bool, error) {
+	s.mu.RLock()
+	e, ok := s.live(key)
+	s.mu.RUnlock()
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(e.Value, dst); err != nil {
+		return true, errors.Wrapf(err, "kv: decode %s", key)
+	}
+	return true, nil
+}
+
+func (s *Store) Has(key string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.live(key)
+	return ok
+}
+
+func (s *Store) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.data[key]; !ok {
+		return nil
+	}
+	delete(s.data, key)
+	return s.save()
+}
+
+// DeletePrefix removes every key starting with prefix.
+func (s *Store) DeletePrefix(prefix string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key := range s.data {
+		if strings.HasPrefix(key, prefix) {
+			delete(s.data, key)
+		}
+	}
+	return s.save()
+}
+
+// Keys returns the live keys starting with prefix, sorted.
+func (s *Store) Keys(prefix string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var keys []string
+	for key := range s.data {
+		if _, ok := s.live(key); ok && strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Scan calls fn with the raw JSON of each live key starting with prefix,
+// in key order, until fn returns false.
+func (s *Store) Scan(prefix string, fn func(key string, value json.RawMessage) bool) {
+	keys := s.Keys(prefix)
+	for _, key := range keys {
+		s.mu.RLock()
+		e, ok := s.live(key)
+		s.mu.RUnlock()
+		if ok && !fn(key, e.Value) {
+			return
+		}
+	}
+}
+
+func (s *Store) Len() int {
+	return len(s.Keys(""))
+}
+
+// save drops expired entries and rewrites the file; callers hold the
+// write lock.
+func (s *Store) save() error {
+	now := s.clock.Now()
+	for key, e := range s.data {
+		if e.ExpiresAt != nil && !now.Before(*e.ExpiresAt) {
+			delete(s.data, key)
+		}
+	}
+	raw, err := json.MarshalIndent(s.data, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "json.MarshalIndent")
+	}
+	// Stores often hold credentials: create the file private so
+	// AtomicWrite, which keeps existing permissions, keeps it that way.
+	if _, err := os.Stat(s.path); os.IsNotExist(err) {
+		if err := os.WriteFile(s.path, nil, 0o600); err != nil {
+			return errors.Wrap(err, "os.WriteFile")
+		}
+	}
+	return fsutil.AtomicWrite(s.path, raw)
+}
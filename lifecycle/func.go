@@ -0,0 +1,80 @@
+package lifecycle
+
+import (
+	"context"
+	"time"
+)
+
+// Hook is a Component built from functions; either may be nil.
+type Hook struct {
+	name    string
+	start   func(ctx context.Context) error
+	stop    func(ctx context.Context) error
+	timeout time.Duration
+}
+
+func NewHook(name string, start, stop func(ctx context.Context) error) *Hook {
+	return &Hook{name: name, start: start, stop: stop}
+}
+
+// Timeout overrides DefaultStopTimeout for this hook.
+func (h *Hook) Timeout(timeout time.Duration) *Hook {
+	h.timeout = timeout
+	return h
+}
+
+func (h *Hook) Name() string {
+	return h.name
+}
+
+func (h *Hook) StopTimeout() time.Duration {
+	return h.timeout
+}
+
+func (h *Hook) Start(ctx context.Context) error {
+	if h.start == nil {
+		return nil
+	}
+	return h.start(ctx)
+}
+
+func (h *Hook) Stop(ctx context.Context) error {
+	if h.stop == nil {
+		return nil
+	}
+	return h.stop(ctx)
+}
+
+// Service adapts a blocking run function, such as http.Server's
+// ListenAndServe, into a Component. run is started in the background; if
+// it returns an error before shutdown the whole service is stopped.
+type Service struct {
+	Hook
+	run    func(ctx context.Context) error
+	failed chan error
+}
+
+func NewService(name string, run, stop func(ctx context.Context) error) *Service {
+	s := &Service{run: run, failed: make(chan error, 1)}
+	s.Hook = Hook{name: name, stop: stop}
+	s.Hook.start = s.begin
+	return s
+}
+
+func (s *Service) Timeout(timeout time.Duration) *Service {
+	s.Hook.Timeout(timeout)
+	return s
+}
+
+func (s *Service) begin(ctx context.Context) error {
+	go func() {
+		if err := s.run(ctx); err != nil && ctx.Err() == nil {
+			s.failed <- err
+		}
+	}()
+	return nil
+}
+
+func (s *Service) Failed() <-chan error {
+	return s.failed
+}
@@ -0,0 +1,190 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+	"utils/log"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultStopTimeout bounds each component's Stop unless it provides its
+// own through StopTimeout.
+const DefaultStopTimeout = 10 * time.Second
+
+// Component is a long-lived part of a service. Start must return once the
+// component is running; background work should watch the context it
+// receives, which is canceled when shutdown begins.
+type Component interface {
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+}
+
+// Optional interfaces a Component may implement.
+type (
+	// Namer names the component in logs and errors.
+	Namer interface {
+		Name() string
+	}
+	// StopTimeouter overrides DefaultStopTimeout.
+	StopTimeouter interface {
+		StopTimeout() time.Duration
+	}
+	// Failer reports a fatal error after a successful Start, triggering
+	// shutdown of the whole service.
+	Failer interface {
+		Failed() <-chan error
+	}
+)
+
+// StopError lists every component that failed to stop cleanly.
+type StopError []error
+
+func (e StopError) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return "lifecycle: shutdown: " + strings.Join(msgs, "; ")
+}
+
+// Run starts the components in order, waits for SIGINT, SIGTERM, ctx
+// cancellation or a component failure, then stops the started components
+// in reverse order, each with its own timeout. It returns nil after a
+// clean signal- or context-triggered shutdown.
+func Run(ctx context.Context, components ...Component) error {
+	return NewRunner().Run(ctx, components...)
+}
+
+type Runner struct {
+	signals []os.Signal
+	logger  *log.Logger
+}
+
+func NewRunner() *Runner {
+	return &Runner{
+		signals: []os.Signal{os.Interrupt, syscall.SIGTERM},
+		logger:  log.Default().Named("lifecycle"),
+	}
+}
+
+// Signals replaces the signals that trigger shutdown.
+func (r *Runner) Signals(signals ...os.Signal) *Runner {
+	r.signals = signals
+	return r
+}
+
+func (r *Runner) Logger(logger *log.Logger) *Runner {
+	r.logger = logger
+	return r
+}
+
+func (r *Runner) Run(ctx context.Context, components ...Component) error {
+	ctx, stopSignals := signal.NotifyContext(ctx, r.signals...)
+	defer stopSignals()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	failed := make(chan error, len(components))
+	var started []Component
+	var cause error
+
+	for i, c := range components {
+		name := nameOf(c, i)
+		if err := c.Start(ctx); err != nil {
+			cause = errors.Wrapf(err, "lifecycle: start %s", name)
+			break
+		}
+		r.logger.Debug("started", log.String("component", name))
+		started = append(started, c)
+
+		if f, ok := c.(Failer); ok {
+			go func(name string, ch <-chan error) {
+				select {
+				case err, ok := <-ch:
+					if ok && err != nil {
+						failed <- errors.Wrapf(err, "lifecycle: %s failed", name)
+					}
+				case <-ctx.Done():
+				}
+			}(name, f.Failed())
+		}
+	}
+
+	if cause == nil {
+		select {
+		case <-ctx.Done():
+			r.logger.Info("shutting down")
+		case cause = <-failed:
+			r.logger.Error("component failed, shutting down", log.Err(cause))
+		}
+	}
+	cancel()
+
+	stopErr := r.stop(started)
+	switch {
+	case cause != nil && stopErr != nil:
+		return errors.Wrap(cause, stopErr.Error())
+	case cause != nil:
+		return cause
+	case stopErr != nil:
+		return stopErr
+	}
+	return nil
+}
+
+func (r *Runner) stop(started []Component) error {
+	var errs StopError
+	for i := len(started) - 1; i >= 0; i-- {
+		c := started[i]
+		name := nameOf(c, i)
+
+		timeout := DefaultStopTimeout
+		if t, ok := c.(StopTimeouter); ok && t.StopTimeout() > 0 {
+			timeout = t.StopTimeout()
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err := stopWithin(ctx, c)
+		cancel()
+
+		if err != nil {
+			r.logger.Error("stop failed", log.String("component", name), log.Err(err))
+			errs = append(errs, errors.Wrapf(err, "stop %s", name))
+			continue
+		}
+		r.logger.Debug("stopped", log.String("component", name))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// stopWithin returns when Stop does or the timeout expires, whichever is
+// first, so a component ignoring its context cannot hang shutdown.
+func stopWithin(ctx context.Context, c Component) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Stop(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "timed out")
+	}
+}
+
+func nameOf(c Component, index int) string {
+	if n, ok := c.(Namer); ok {
+		return n.Name()
+	}
+	return fmt.Sprintf("#%d (%T)", index, c)
+}
@@ -0,0 +1,19 @@
+package log
+
+import "context"
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying l, typically a logger with
+// request-scoped fields.
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger stored by NewContext, or Default.
+func FromContext(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(contextKey{}).(*Logger); ok {
+		return l
+	}
+	return Default()
+}
@@ -0,0 +1,130 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Entry struct {
+	Time    time.Time
+	Level   Level
+	Logger  string
+	Message string
+	Fields  []Field
+}
+
+type Encoder interface {
+	Encode(w io.Writer, entry Entry) error
+}
+
+type JSONEncoder struct {
+	TimeFormat string
+}
+
+func (e JSONEncoder) Encode(w io.Writer, entry Entry) error {
+	timeFormat := e.TimeFormat
+	if timeFormat == "" {
+		timeFormat = time.RFC3339Nano
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	writeJSONPair(&buf, "time", entry.Time.Format(timeFormat))
+	buf.WriteByte(',')
+	writeJSONPair(&buf, "level", entry.Level.String())
+	if entry.Logger != "" {
+		buf.WriteByte(',')
+		writeJSONPair(&buf, "logger", entry.Logger)
+	}
+	buf.WriteByte(',')
+	writeJSONPair(&buf, "msg", entry.Message)
+	for _, field := range entry.Fields {
+		buf.WriteByte(',')
+		writeJSONPair(&buf, field.Key, jsonValue(field.Value))
+	}
+	buf.WriteString("}\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func writeJSONPair(buf *bytes.Buffer, key string, value interface{}) {
+	k, _ := json.Marshal(key)
+	buf.Write(k)
+	buf.WriteByte(':')
+
+	v, err := json.Marshal(value)
+	if err != nil {
+		v, _ = json.Marshal(fmt.Sprintf("%v", value))
+	}
+	buf.Write(v)
+}
+
+func jsonValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case time.Duration:
+		return v.String()
+	case error:
+		return v.Error()
+	}
+	return value
+}
+
+type ConsoleEncoder struct {
+	TimeFormat string
+}
+
+func (e ConsoleEncoder) Encode(w io.Writer, entry Entry) error {
+	timeFormat := e.TimeFormat
+	if timeFormat == "" {
+		timeFormat = "2006-01-02 15:04:05.000"
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(entry.Time.Format(timeFormat))
+	buf.WriteByte(' ')
+	buf.WriteString(fmt.Sprintf("%-5s", strings.ToUpper(entry.Level.String())))
+	if entry.Logger != "" {
+		buf.WriteString(" [")
+		buf.WriteString(entry.Logger)
+		buf.WriteByte(']')
+	}
+	buf.WriteByte(' ')
+	buf.WriteString(entry.Message)
+	for _, field := range entry.Fields {
+		buf.WriteByte(' ')
+		buf.WriteString(field.Key)
+		buf.WriteByte('=')
+		buf.WriteString(consoleValue(field.Value))
+	}
+	buf.WriteByte('\n')
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func consoleValue(value interface{}) string {
+	var s string
+	switch v := value.(type) {
+	case nil:
+		return "<nil>"
+	case string:
+		s = v
+	case error:
+		s = v.Error()
+	case time.Time:
+		return v.Format(time.RFC3339)
+	default:
+		s = fmt.Sprintf("%v", v)
+	}
+
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
@@ -0,0 +1,174 @@
+package log
+
+import (
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// core is shared by a logger and every child derived from it through Named
+// and With, so output, encoder and level changes apply to all of them.
+type core struct {
+	mu        sync.RWMutex
+	out       io.Writer
+	encoder   Encoder
+	level     Level
+	overrides map[string]Level
+}
+
+type Logger struct {
+	core   *core
+	name   string
+	fields []Field
+}
+
+func New(out io.Writer) *Logger {
+	return &Logger{
+		core: &core{
+			out:       out,
+			encoder:   ConsoleEncoder{},
+			level:     InfoLevel,
+			overrides: make(map[string]Level),
+		},
+	}
+}
+
+func (l *Logger) Output(out io.Writer) *Logger {
+	l.core.mu.Lock()
+	l.core.out = out
+	l.core.mu.Unlock()
+	return l
+}
+
+func (l *Logger) Encoder(encoder Encoder) *Logger {
+	l.core.mu.Lock()
+	l.core.encoder = encoder
+	l.core.mu.Unlock()
+	return l
+}
+
+func (l *Logger) Level(level Level) *Logger {
+	l.core.mu.Lock()
+	l.core.level = level
+	l.core.mu.Unlock()
+	return l
+}
+
+// LevelFor overrides the level of the named logger and its descendants,
+// e.g. LevelFor("http", DebugLevel) also affects "http.client".
+func (l *Logger) LevelFor(name string, level Level) *Logger {
+	l.core.mu.Lock()
+	l.core.overrides[name] = level
+	l.core.mu.Unlock()
+	return l
+}
+
+func (l *Logger) Named(name string) *Logger {
+	if l.name != "" {
+		name = l.name + "." + name
+	}
+	return &Logger{core: l.core, name: name, fields: l.fields}
+}
+
+func (l *Logger) With(fields ...Field) *Logger {
+	merged := make([]Field, 0, len(l.fields)+len(fields))
+	merged = append(merged, l.fields...)
+	merged = append(merged, fields...)
+	return &Logger{core: l.core, name: l.name, fields: merged}
+}
+
+func (l *Logger) Enabled(level Level) bool {
+	l.core.mu.RLock()
+	defer l.core.mu.RUnlock()
+	return level >= l.core.levelOf(l.name) && level < Disabled
+}
+
+func (l *Logger) Debug(msg string, fields ...Field) {
+	l.log(DebugLevel, msg, fields)
+}
+
+func (l *Logger) Info(msg string, fields ...Field) {
+	l.log(InfoLevel, msg, fields)
+}
+
+func (l *Logger) Warn(msg string, fields ...Field) {
+	l.log(WarnLevel, msg, fields)
+}
+
+func (l *Logger) Error(msg string, fields ...Field) {
+	l.log(ErrorLevel, msg, fields)
+}
+
+func (l *Logger) log(level Level, msg string, fields []Field) {
+	if !l.Enabled(level) {
+		return
+	}
+
+	all := l.fields
+	if len(fields) > 0 {
+		all = make([]Field, 0, len(l.fields)+len(fields))
+		all = append(all, l.fields...)
+		all = append(all, fields...)
+	}
+
+	entry := Entry{
+		Time:    time.Now(),
+		Level:   level,
+		Logger:  l.name,
+		Message: msg,
+		Fields:  all,
+	}
+
+	l.core.mu.Lock()
+	defer l.core.mu.Unlock()
+	_ = l.core.encoder.Encode(l.core.out, entry)
+}
+
+func (c *core) levelOf(name string) Level {
+	for name != "" {
+		if level, ok := c.overrides[name]; ok {
+			return level
+		}
+		i := strings.LastIndex(name, ".")
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return c.level
+}
+
+var (
+	defaultMu     sync.RWMutex
+	defaultLogger = New(os.Stderr)
+)
+
+func Default() *Logger {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultLogger
+}
+
+func SetDefault(l *Logger) {
+	defaultMu.Lock()
+	defaultLogger = l
+	defaultMu.Unlock()
+}
+
+func Debug(msg string, fields ...Field) {
+	Default().log(DebugLevel, msg, fields)
+}
+
+func Info(msg string, fields ...Field) {
+	Default().log(InfoLevel, msg, fields)
+}
+
+func Warn(msg string, fields ...Field) {
+	Default().log(WarnLevel, msg, fields)
+}
+
+func Error(msg string, fields ...Field) {
+	Default().log(ErrorLevel, msg, fields)
+}
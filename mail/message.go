@@ -0,0 +1,434 @@
+package mail
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	netmail "net/mail"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"utils/id"
+	"utils/tmpl"
+
+	"github.com/pkg/errors"
+)
+
+// Attachment is a file carried by a Message. Inline attachments are
+// referenced from the HTML body as cid:<ContentID>.
+type Attachment struct {
+	Filename    string
+	ContentType string
+	ContentID   string
+	Data        []byte
+	Inline      bool
+}
+
+// Message builds a MIME email with optional text and HTML alternatives,
+// inline images and attachments.
+type Message struct {
+	from        string
+	replyTo     string
+	to          []string
+	cc          []string
+	bcc         []string
+	subject     string
+	text        string
+	html        string
+	header      map[string]string
+	attachments []Attachment
+}
+
+func NewMessage() *Message {
+	return &Message{header: make(map[string]string)}
+}
+
+func (m *Message) From(address string) *Message {
+	m.from = address
+	return m
+}
+
+func (m *Message) ReplyTo(address string) *Message {
+	m.replyTo = address
+	return m
+}
+
+func (m *Message) To(addresses ...string) *Message {
+	m.to = append(m.to, addresses...)
+	return m
+}
+
+func (m *Message) Cc(addresses ...string) *Message {
+	m.cc = append(m.cc, addresses...)
+	return m
+}
+
+func (m *Message) Bcc(addresses ...string) *Message {
+	m.bcc = append(m.bcc, addresses...)
+	return m
+}
+
+func (m *Message) Subject(subject string) *Message {
+	m.subject = subject
+	return m
+}
+
+func (m *Message) Text(body string) *Message {
+	m.text = body
+	return m
+}
+
+func (m *Message) HTML(body string) *Message {
+	m.html = body
+	return m
+}
+
+// Template renders the text and HTML bodies with the tmpl package; either
+// source may be empty to skip that alternative.
+func (m *Message) Template(name, textSource, htmlSource string, data interface{}) error {
+	if textSource != "" {
+		body, err := tmpl.Render(name+".txt", textSource, data)
+		if err != nil {
+			return err
+		}
+		m.text = body
+	}
+	if htmlSource != "" {
+		body, err := tmpl.RenderHTML(name+".html", htmlSource, data)
+		if err != nil {
+			return err
+		}
+		m.html = body
+	}
+	return nil
+}
+
+// Header sets an extra header. Names and values with line breaks, which
+// could inject other headers, make Bytes fail.
+func (m *Message) Header(name, value string) *Message {
+	m.header[textproto.CanonicalMIMEHeaderKey(name)] = value
+	return m
+}
+
+// Attach adds a regular attachment. An empty contentType is guessed from
+// the filename extension.
+func (m *Message) Attach(filename, contentType string, data []byte) *Message {
+	m.attachments = append(m.attachments, Attachment{
+		Filename:    filename,
+		ContentType: contentTypeFor(filename, contentType),
+		Data:        data,
+	})
+	return m
+}
+
+func (m *Message) AttachFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err, "os.ReadFile")
+	}
+	m.Attach(filepath.Base(path), "", data)
+	return nil
+}
+
+// Embed adds an inline attachment, typically an image referenced from the
+// HTML body as <img src="cid:contentID">.
+func (m *Message) Embed(contentID, filename, contentType string, data []byte) *Message {
+	m.attachments = append(m.attachments, Attachment{
+		Filename:    filename,
+		ContentType: contentTypeFor(filename, contentType),
+		ContentID:   contentID,
+		Data:        data,
+		Inline:      true,
+	})
+	return m
+}
+
+// Recipients returns every envelope recipient (To, Cc and Bcc) as bare
+// addresses.
+func (m *Message) Recipients() ([]string, error) {
+	var out []string
+	for _, list := range [][]string{m.to, m.cc, m.bcc} {
+		for _, raw := range list {
+			addr, err := netmail.ParseAddress(raw)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid recipient %q", raw)
+			}
+			out = append(out, addr.Address)
+		}
+	}
+	return out, nil
+}
+
+// Sender returns the bare envelope sender address.
+func (m *Message) Sender() (string, error) {
+	addr, err := netmail.ParseAddress(m.from)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid sender %q", m.from)
+	}
+	return addr.Address, nil
+}
+
+// Bytes renders the message in RFC 5322 format. Bcc recipients are never
+// written to the headers.
+func (m *Message) Bytes() ([]byte, error) {
+	from, err := m.Sender()
+	if err != nil {
+		return nil, err
+	}
+	if len(m.to)+len(m.cc)+len(m.bcc) == 0 {
+		return nil, errors.New("message has no recipients")
+	}
+	if err := m.validateHeaders(); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	header := map[string]string{
+		"From":         formatAddress(m.from),
+		"Subject":      mime.QEncoding.Encode("utf-8", m.subject),
+		"Date":         time.Now().Format(time.RFC1123Z),
+		"Message-Id":   fmt.Sprintf("<%s@%s>", id.MustV4(), domainOf(from)),
+		"Mime-Version": "1.0",
+	}
+	if len(m.to) > 0 {
+		header["To"] = formatAddresses(m.to)
+	}
+	if len(m.cc) > 0 {
+		header["Cc"] = formatAddresses(m.cc)
+	}
+	if m.replyTo != "" {
+		header["Reply-To"] = formatAddress(m.replyTo)
+	}
+	for name, value := range m.header {
+		header[name] = value
+	}
+
+	for _, name := range []string{"From", "To", "Cc", "Reply-To", "Subject", "Date", "Message-Id", "Mime-Version"} {
+		if value, ok := header[name]; ok {
+			fmt.Fprintf(&buf, "%s: %s\r\n", name, value)
+			delete(header, name)
+		}
+	}
+	for name, value := range header {
+		fmt.Fprintf(&buf, "%s: %s\r\n", name, value)
+	}
+
+	if err := m.writeBody(topLevel(&buf)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// validateHeaders rejects CR and LF in every field written to the header,
+// which would let a value start a header of its own, such as a Bcc.
+func (m *Message) validateHeaders() error {
+	fields := map[string][]string{
+		"From":     {m.from},
+		"Reply-To": {m.replyTo},
+		"To":       m.to,
+		"Cc":       m.cc,
+		"Bcc":      m.bcc,
+		"Subject":  {m.subject},
+	}
+	for name, values := range fields {
+		for _, value := range values {
+			if strings.ContainsAny(value, "\r\n") {
+				return errors.Errorf("line break in %s header", name)
+			}
+		}
+	}
+	for name, value := range m.header {
+		if name == "" || strings.ContainsAny(name, "\r\n: ") {
+			return errors.Errorf("invalid header name %q", name)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return errors.Errorf("line break in %s header", name)
+		}
+	}
+	return nil
+}
+
+// partFunc opens a MIME part with the given header, either at the top of
+// the message or nested inside a parent multipart.
+type partFunc func(header textproto.MIMEHeader) (io.Writer, error)
+
+func topLevel(w io.Writer) partFunc {
+	return func(header textproto.MIMEHeader) (io.Writer, error) {
+		for name, values := range header {
+			for _, value := range values {
+				fmt.Fprintf(w, "%s: %s\r\n", name, value)
+			}
+		}
+		_, err := io.WriteString(w, "\r\n")
+		return w, err
+	}
+}
+
+func (m *Message) writeBody(create partFunc) error {
+	var inline, attached []Attachment
+	for _, a := range m.attachments {
+		if a.Inline {
+			inline = append(inline, a)
+		} else {
+			attached = append(attached, a)
+		}
+	}
+
+	if len(attached) == 0 {
+		return m.writeRelated(create, inline)
+	}
+
+	return writeMultipart(create, "mixed", func(mw *multipart.Writer) error {
+		if err := m.writeRelated(mw.CreatePart, inline); err != nil {
+			return err
+		}
+		for _, a := range attached {
+			if err := writeAttachment(mw, a); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// writeRelated wraps the alternative bodies in multipart/related when there
+// are inline attachments to reference.
+func (m *Message) writeRelated(create partFunc, inline []Attachment) error {
+	if len(inline) == 0 {
+		return m.writeAlternative(create)
+	}
+
+	return writeMultipart(create, "related", func(mw *multipart.Writer) error {
+		if err := m.writeAlternative(mw.CreatePart); err != nil {
+			return err
+		}
+		for _, a := range inline {
+			if err := writeAttachment(mw, a); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (m *Message) writeAlternative(create partFunc) error {
+	switch {
+	case m.html == "":
+		return writeText(create, "text/plain", m.text)
+	case m.text == "":
+		return writeText(create, "text/html", m.html)
+	}
+
+	return writeMultipart(create, "alternative", func(mw *multipart.Writer) error {
+		if err := writeText(mw.CreatePart, "text/plain", m.text); err != nil {
+			return err
+		}
+		return writeText(mw.CreatePart, "text/html", m.html)
+	})
+}
+
+func writeMultipart(create partFunc, subtype string, fill func(mw *multipart.Writer) error) error {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	if err := fill(mw); err != nil {
+		return err
+	}
+	if err := mw.Close(); err != nil {
+		return errors.Wrap(err, "multipart.Close")
+	}
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", mime.FormatMediaType("multipart/"+subtype, map[string]string{"boundary": mw.Boundary()}))
+	w, err := create(header)
+	if err != nil {
+		return errors.Wrap(err, "multipart.CreatePart")
+	}
+	_, err = buf.WriteTo(w)
+	return errors.Wrap(err, "multipart.Write")
+}
+
+func writeText(create partFunc, contentType, content string) error {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", contentType+"; charset=utf-8")
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+	w, err := create(header)
+	if err != nil {
+		return errors.Wrap(err, "multipart.CreatePart")
+	}
+
+	qp := quotedprintable.NewWriter(w)
+	if _, err := io.WriteString(qp, content); err != nil {
+		return errors.Wrap(err, "quotedprintable.Write")
+	}
+	return qp.Close()
+}
+
+func writeAttachment(mw *multipart.Writer, a Attachment) error {
+	disposition := "attachment"
+	if a.Inline {
+		disposition = "inline"
+	}
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", a.ContentType)
+	header.Set("Content-Transfer-Encoding", "base64")
+	header.Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": a.Filename}))
+	if a.ContentID != "" {
+		header.Set("Content-Id", "<"+a.ContentID+">")
+	}
+
+	part, err := mw.CreatePart(header)
+	if err != nil {
+		return errors.Wrap(err, "multipart.CreatePart")
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(a.Data)
+	for len(encoded) > 76 {
+		if _, err := io.WriteString(part, encoded[:76]+"\r\n"); err != nil {
+			return errors.Wrap(err, "part.Write")
+		}
+		encoded = encoded[76:]
+	}
+	_, err = io.WriteString(part, encoded+"\r\n")
+	return errors.Wrap(err, "part.Write")
+}
+
+func contentTypeFor(filename, contentType string) string {
+	if contentType != "" {
+		return contentType
+	}
+	if guessed := mime.TypeByExtension(filepath.Ext(filename)); guessed != "" {
+		return guessed
+	}
+	return "application/octet-stream"
+}
+
+func formatAddress(raw string) string {
+	addr, err := netmail.ParseAddress(raw)
+	if err != nil {
+		return raw
+	}
+	return addr.String()
+}
+
+func formatAddresses(list []string) string {
+	out := make([]string, len(list))
+	for i, raw := range list {
+		out[i] = formatAddress(raw)
+	}
+	return strings.Join(out, ", ")
+}
+
+func domainOf(address string) string {
+	if i := strings.LastIndexByte(address, '@'); i >= 0 {
+		return address[i+1:]
+	}
+	return "localhost"
+}
@@ -0,0 +1,277 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/smtp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Sender delivers messages over SMTP, keeping up to PoolSize idle
+// connections open between sends. Port 465 uses implicit TLS; any other
+// port upgrades with STARTTLS when the server offers it.
+type Sender struct {
+	host          string
+	port          int
+	username      string
+	password      string
+	tlsConfig     *tls.Config
+	timeout       time.Duration
+	retryAttempts int
+	retryDelay    time.Duration
+
+	mu     sync.Mutex
+	idle   []*conn
+	size   int
+	closed bool
+}
+
+func NewSender(host string, port int) *Sender {
+	return &Sender{
+		host:      host,
+		port:      port,
+		tlsConfig: &tls.Config{ServerName: host},
+		timeout:   30 * time.Second,
+		size:      2,
+	}
+}
+
+func (s *Sender) Auth(username, password string) *Sender {
+	s.username = username
+	s.password = password
+	return s
+}
+
+func (s *Sender) TLSConfig(config *tls.Config) *Sender {
+	s.tlsConfig = config
+	return s
+}
+
+func (s *Sender) Timeout(timeout time.Duration) *Sender {
+	s.timeout = timeout
+	return s
+}
+
+// PoolSize sets how many idle connections are kept; zero disables pooling.
+func (s *Sender) PoolSize(size int) *Sender {
+	s.size = size
+	return s
+}
+
+// Retry resends after a failed attempt up to attempts more times, waiting
+// delay between tries. Retries stop when the context of Send is done.
+func (s *Sender) Retry(attempts int, delay time.Duration) *Sender {
+	s.retryAttempts = attempts
+	s.retryDelay = delay
+	return s
+}
+
+func (s *Sender) Send(ctx context.Context, msg *Message) error {
+	from, err := msg.Sender()
+	if err != nil {
+		return err
+	}
+	to, err := msg.Recipients()
+	if err != nil {
+		return err
+	}
+	data, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+
+	for attempt := 0; ; attempt++ {
+		if ctx.Err() != nil {
+			return errors.Wrap(ctx.Err(), "mail.Send")
+		}
+		err = s.send(ctx, from, to, data)
+		if err == nil || attempt >= s.retryAttempts {
+			return err
+		}
+
+		timer := time.NewTimer(s.retryDelay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Wrap(ctx.Err(), "mail.Send")
+		case <-timer.C:
+		}
+	}
+}
+
+// conn is a pooled SMTP client with the connection it runs on, kept to
+// set deadlines.
+type conn struct {
+	*smtp.Client
+	net net.Conn
+}
+
+// watch bounds the exchanges on c by the timeout and ctx: the deadline is
+// the earlier of both, and cancelling ctx interrupts blocked reads and
+// writes. The returned function stops watching.
+func (s *Sender) watch(ctx context.Context, c net.Conn) func() {
+	deadline := time.Now().Add(s.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	c.SetDeadline(deadline)
+
+	done, exited := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(exited)
+		select {
+		case <-ctx.Done():
+			c.SetDeadline(time.Unix(1, 0))
+		case <-done:
+		}
+	}()
+	return func() {
+		close(done)
+		<-exited
+	}
+}
+
+func (s *Sender) send(ctx context.Context, from string, to []string, data []byte) error {
+	c, err := s.acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	stop := s.watch(ctx, c.net)
+	err = deliver(c.Client, from, to, data)
+	stop()
+	if err != nil {
+		c.Close()
+		return err
+	}
+
+	s.release(c)
+	return nil
+}
+
+func deliver(client *smtp.Client, from string, to []string, data []byte) error {
+	if err := client.Mail(from); err != nil {
+		return errors.Wrap(err, "smtp.Mail")
+	}
+	for _, rcpt := range to {
+		if err := client.Rcpt(rcpt); err != nil {
+			return errors.Wrapf(err, "smtp.Rcpt %s", rcpt)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return errors.Wrap(err, "smtp.Data")
+	}
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return errors.Wrap(err, "smtp.Write")
+	}
+	return errors.Wrap(w.Close(), "smtp.Close")
+}
+
+// acquire reuses an idle connection when it still answers a RSET, and
+// dials a new one otherwise.
+func (s *Sender) acquire(ctx context.Context) (*conn, error) {
+	for {
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			return nil, errors.New("mail sender is closed")
+		}
+		if len(s.idle) == 0 {
+			s.mu.Unlock()
+			break
+		}
+		c := s.idle[len(s.idle)-1]
+		s.idle = s.idle[:len(s.idle)-1]
+		s.mu.Unlock()
+
+		stop := s.watch(ctx, c.net)
+		err := c.Reset()
+		stop()
+		if err == nil {
+			return c, nil
+		}
+		c.Close()
+	}
+
+	return s.dial(ctx)
+}
+
+func (s *Sender) release(c *conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed || len(s.idle) >= s.size {
+		c.net.SetDeadline(time.Now().Add(s.timeout))
+		c.Quit()
+		return
+	}
+	s.idle = append(s.idle, c)
+}
+
+func (s *Sender) dial(ctx context.Context) (*conn, error) {
+	addr := net.JoinHostPort(s.host, strconv.Itoa(s.port))
+	dialer := &net.Dialer{Timeout: s.timeout}
+
+	var netConn net.Conn
+	var err error
+	if s.port == 465 {
+		netConn, err = (&tls.Dialer{NetDialer: dialer, Config: s.tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		netConn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "net.Dial")
+	}
+	stop := s.watch(ctx, netConn)
+	defer stop()
+
+	client, err := smtp.NewClient(netConn, s.host)
+	if err != nil {
+		netConn.Close()
+		return nil, errors.Wrap(err, "smtp.NewClient")
+	}
+
+	if s.port != 465 {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(s.tlsConfig); err != nil {
+				client.Close()
+				return nil, errors.Wrap(err, "smtp.StartTLS")
+			}
+		}
+	}
+
+	if s.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.username, s.password, s.host)); err != nil {
+			client.Close()
+			return nil, errors.Wrap(err, "smtp.Auth")
+		}
+	}
+
+	return &conn{Client: client, net: netConn}, nil
+}
+
+// Close quits every idle connection; subsequent sends fail.
+func (s *Sender) Close() error {
+	s.mu.Lock()
+	idle := s.idle
+	s.idle = nil
+	s.closed = true
+	s.mu.Unlock()
+
+	var firstErr error
+	for _, c := range idle {
+		c.net.SetDeadline(time.Now().Add(s.timeout))
+		if err := c.Quit(); err != nil && firstErr == nil {
+			firstErr = errors.Wrap(err, "smtp.Quit")
+		}
+	}
+	return firstErr
+}
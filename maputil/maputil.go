@@ -0,0 +1,124 @@
+package maputil
+
+import "sort"
+
+type Ordered interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64 | ~string
+}
+
+func Keys[K comparable, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func Values[K comparable, V any](m map[K]V) []V {
+	values := make([]V, 0, len(m))
+	for _, v := range m {
+		values = append(values, v)
+	}
+	return values
+}
+
+func SortedKeys[K Ordered, V any](m map[K]V) []K {
+	keys := Keys(m)
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+// Range calls fn for every entry in ascending key order until fn returns
+// false, giving deterministic iteration for output and tests.
+func Range[K Ordered, V any](m map[K]V, fn func(K, V) bool) {
+	for _, k := range SortedKeys(m) {
+		if !fn(k, m[k]) {
+			return
+		}
+	}
+}
+
+// Resolver decides the value kept when a key is present in more than one map.
+type Resolver[K comparable, V any] func(key K, existing, incoming V) V
+
+func KeepFirst[K comparable, V any](_ K, existing, _ V) V {
+	return existing
+}
+
+func KeepLast[K comparable, V any](_ K, _, incoming V) V {
+	return incoming
+}
+
+// Merge combines maps into a new one. Conflicts are settled by resolve, or
+// by the last map when resolve is nil.
+func Merge[K comparable, V any](resolve Resolver[K, V], maps ...map[K]V) map[K]V {
+	size := 0
+	for _, m := range maps {
+		size += len(m)
+	}
+
+	out := make(map[K]V, size)
+	for _, m := range maps {
+		for k, v := range m {
+			if existing, ok := out[k]; ok && resolve != nil {
+				v = resolve(k, existing, v)
+			}
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// Invert swaps keys and values. When values repeat, an arbitrary key wins.
+func Invert[K, V comparable](m map[K]V) map[V]K {
+	out := make(map[V]K, len(m))
+	for k, v := range m {
+		out[v] = k
+	}
+	return out
+}
+
+func Pick[K comparable, V any](m map[K]V, keys ...K) map[K]V {
+	out := make(map[K]V, len(keys))
+	for _, k := range keys {
+		if v, ok := m[k]; ok {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func Omit[K comparable, V any](m map[K]V, keys ...K) map[K]V {
+	exclude := make(map[K]struct{}, len(keys))
+	for _, k := range keys {
+		exclude[k] = struct{}{}
+	}
+
+	out := make(map[K]V, len(m))
+	for k, v := range m {
+		if _, ok := exclude[k]; !ok {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func FilterMap[K comparable, V any](m map[K]V, keep func(K, V) bool) map[K]V {
+	out := make(map[K]V)
+	for k, v := range m {
+		if keep(k, v) {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func MapValues[K comparable, V, U any](m map[K]V, fn func(V) U) map[K]U {
+	out := make(map[K]U, len(m))
+	for k, v := range m {
+		out[k] = fn(v)
+	}
+	return out
+}
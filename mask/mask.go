@@ -0,0 +1,178 @@
+package mask
+
+import (
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// Char is the rune that replaces hidden characters.
+const Char = '*'
+
+// Middle keeps the first keepStart and last keepEnd runes of s and hides
+// the rest. When s is too short to hide anything, every rune is hidden so
+// short secrets don't leak.
+func Middle(s string, keepStart, keepEnd int) string {
+	runes := []rune(s)
+	if keepStart < 0 {
+		keepStart = 0
+	}
+	if keepEnd < 0 {
+		keepEnd = 0
+	}
+	if keepStart+keepEnd >= len(runes) {
+		return strings.Repeat(string(Char), len(runes))
+	}
+	for i := keepStart; i < len(runes)-keepEnd; i++ {
+		runes[i] = Char
+	}
+	return string(runes)
+}
+
+// Digits hides every digit of s except the last keepEnd, preserving
+// formatting characters: Digits("(11) 91234-5678", 4) returns
+// "(**) *****-5678".
+func Digits(s string, keepEnd int) string {
+	total := 0
+	for _, r := range s {
+		if unicode.IsDigit(r) {
+			total++
+		}
+	}
+	seen := 0
+	return strings.Map(func(r rune) rune {
+		if !unicode.IsDigit(r) {
+			return r
+		}
+		seen++
+		if seen > total-keepEnd {
+			return r
+		}
+		return Char
+	}, s)
+}
+
+// onlyDigits keeps the digits of s. mask does not use br.Digits because
+// br depends on the HTTP client, which masks its debug logs with this
+// package.
+func onlyDigits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+// CPF returns 123.***.***-09; invalid input is fully hidden.
+func CPF(cpf string) string {
+	d := onlyDigits(cpf)
+	if len(d) != 11 {
+		return Middle(cpf, 0, 0)
+	}
+	return d[:3] + ".***.***-" + d[9:]
+}
+
+// CNPJ returns 12.***.***/0001-95; invalid input is fully hidden.
+func CNPJ(cnpj string) string {
+	d := onlyDigits(cnpj)
+	if len(d) != 14 {
+		return Middle(cnpj, 0, 0)
+	}
+	return d[:2] + ".***.***/" + d[8:12] + "-" + d[12:]
+}
+
+// Email keeps the first character of the local part and the domain:
+// "joao.silva@example.com" returns "j*********@example.com".
+func Email(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return Middle(email, 0, 0)
+	}
+	return Middle(email[:at], 1, 0) + email[at:]
+}
+
+// CreditCard keeps the last four digits in groups of four:
+// "4111 1111 1111 1234" returns "**** **** **** 1234".
+func CreditCard(number string) string {
+	var digits []rune
+	for _, r := range number {
+		if unicode.IsDigit(r) {
+			digits = append(digits, r)
+		}
+	}
+	masked := []rune(Middle(string(digits), 0, 4))
+
+	var b strings.Builder
+	for i, r := range masked {
+		if i > 0 && i%4 == 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Phone keeps the last four digits and the formatting:
+// "+55 11 91234-5678" returns "+** ** *****-5678".
+func Phone(phone string) string {
+	return Digits(phone, 4)
+}
+
+// Secret hides a credential entirely with a fixed-length mask, keeping
+// two leading characters of long values to help tell keys apart.
+func Secret(s string) string {
+	if len([]rune(s)) < 12 {
+		return "****"
+	}
+	return string([]rune(s)[:2]) + "****"
+}
+
+var sensitiveKeys = []string{
+	"password", "passwd", "secret", "token", "apikey", "api_key", "api-key",
+	"authorization", "auth", "signature", "sig", "key", "cpf", "cnpj", "card",
+	"cvv", "session",
+}
+
+// IsSensitive reports whether a parameter or header name usually carries
+// credentials or personal data.
+func IsSensitive(name string) bool {
+	name = strings.ToLower(name)
+	for _, key := range sensitiveKeys {
+		if name == key || (len(key) > 3 && strings.Contains(name, key)) {
+			return true
+		}
+	}
+	return false
+}
+
+// URL hides the password in the user info and the values of sensitive
+// query parameters, for logging requests. A URL that does not parse is
+// hidden entirely, as its secrets cannot be located.
+func URL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "****"
+	}
+	if u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), "****")
+		}
+	}
+	query := u.Query()
+	changed := false
+	for name, values := range query {
+		if !IsSensitive(name) {
+			continue
+		}
+		for i, value := range values {
+			values[i] = Secret(value)
+		}
+		changed = true
+	}
+	if changed {
+		u.RawQuery = query.Encode()
+	}
+	// Keep the mask readable instead of percent-encoded.
+	return strings.ReplaceAll(u.String(), "%2A", "*")
+}
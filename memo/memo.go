@@ -0,0 +1,162 @@
+package memo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+	"utils/cache"
+	"utils/clock"
+	"utils/dedupe"
+)
+
+// DefaultMaxEntries is used when Func receives a non-positive maxEntries.
+const DefaultMaxEntries = 1024
+
+// Memo caches the results of a function per key. Concurrent calls for a
+// key that is not cached share a single execution, and errors are never
+// cached.
+type Memo[K comparable, V any] struct {
+	fn    func(K) (V, error)
+	cache *cache.LRU[K, V]
+	group *dedupe.Group[K, V]
+	ttl   time.Duration
+
+	backend cache.Backend
+	prefix  string
+
+	mu         sync.Mutex
+	generation uint64
+}
+
+// Func memoizes fn, keeping at most maxEntries results (least recently
+// used are evicted first) for ttl each; a zero ttl keeps results until
+// evicted or invalidated.
+func Func[K comparable, V any](fn func(K) (V, error), ttl time.Duration, maxEntries int) *Memo[K, V] {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+	return &Memo[K, V]{
+		fn:    fn,
+		cache: cache.NewLRU[K, V](maxEntries).TTL(ttl),
+		group: dedupe.NewGroup[K, V](),
+		ttl:   ttl,
+	}
+}
+
+// Backend adds a shared second tier, so results computed by one instance
+// of a service are reused by the others. Values are stored as JSON under
+// prefix followed by the key formatted with %v. Backend failures are
+// treated as misses.
+func (m *Memo[K, V]) Backend(backend cache.Backend, prefix string) *Memo[K, V] {
+	m.backend = backend
+	m.prefix = prefix
+	return m
+}
+
+// Clock sets the time source used for expiration, for deterministic tests.
+func (m *Memo[K, V]) Clock(clk clock.Clock) *Memo[K, V] {
+	m.cache.Clock(clk)
+	return m
+}
+
+// Get returns the cached value for key, calling the wrapped function on a
+// miss.
+func (m *Memo[K, V]) Get(key K) (V, error) {
+	if value, ok := m.cache.Get(key); ok {
+		return value, nil
+	}
+
+	m.mu.Lock()
+	generation := m.generation
+	m.mu.Unlock()
+
+	value, _, err := m.group.Do(key, func() (V, error) {
+		if value, ttl, ok := m.load(key); ok {
+			m.store(key, value, ttl, generation)
+			return value, nil
+		}
+		value, err := m.fn(key)
+		if err != nil {
+			return value, err
+		}
+		if m.store(key, value, m.ttl, generation) && m.backend != nil {
+			if raw, err := json.Marshal(value); err == nil {
+				m.backend.Set(context.Background(), m.backendKey(key), raw, m.ttl)
+			}
+		}
+		return value, nil
+	})
+	return value, err
+}
+
+// store caches value locally for ttl unless an Invalidate or Purge
+// happened since generation, as the value may then be stale.
+func (m *Memo[K, V]) store(key K, value V, ttl time.Duration, generation uint64) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.generation != generation {
+		return false
+	}
+	m.cache.SetWithTTL(key, value, ttl)
+	return true
+}
+
+// load reads key from the backend along with the time it has left there,
+// so a local copy does not outlive the shared one.
+func (m *Memo[K, V]) load(key K) (V, time.Duration, bool) {
+	var value V
+	if m.backend == nil {
+		return value, 0, false
+	}
+	ctx := context.Background()
+	raw, found, err := m.backend.Get(ctx, m.backendKey(key))
+	if err != nil || !found || json.Unmarshal(raw, &value) != nil {
+		return value, 0, false
+	}
+
+	ttl, found, err := m.backend.TTL(ctx, m.backendKey(key))
+	switch {
+	case err != nil, !found, ttl < 0:
+		return value, 0, false
+	case ttl == 0, m.ttl > 0 && ttl > m.ttl:
+		// Never expiring in the backend, or set by an instance with a
+		// longer ttl: keep to ours.
+		ttl = m.ttl
+	}
+	return value, ttl, true
+}
+
+func (m *Memo[K, V]) backendKey(key K) string {
+	return m.prefix + fmt.Sprint(key)
+}
+
+// Invalidate drops the cached value for key, from the backend as well.
+func (m *Memo[K, V]) Invalidate(key K) {
+	m.mu.Lock()
+	m.generation++
+	m.cache.Delete(key)
+	m.group.Forget(key)
+	m.mu.Unlock()
+	if m.backend != nil {
+		m.backend.Delete(context.Background(), m.backendKey(key))
+	}
+}
+
+// Purge drops every locally cached value; values in the backend stay
+// until they expire.
+func (m *Memo[K, V]) Purge() {
+	m.mu.Lock()
+	m.generation++
+	m.cache.Purge()
+	m.mu.Unlock()
+}
+
+func (m *Memo[K, V]) Len() int {
+	return m.cache.Len()
+}
+
+func (m *Memo[K, V]) Stats() cache.Stats {
+	return m.cache.Stats()
+}
@@ -0,0 +1,383 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"utils/log"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrLocked   = errors.New("migrate: another runner holds the lock")
+	ErrNoDown   = errors.New("migrate: migration has no down script")
+	identifier  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	filePattern = regexp.MustCompile(`^(\d+)_(.+?)(?:\.(up|down))?\.sql$`)
+)
+
+// Placeholder is the bind parameter style of the database driver.
+type Placeholder int
+
+const (
+	// Question is used by SQLite and MySQL: ?, ?, ?.
+	Question Placeholder = iota
+	// Dollar is used by PostgreSQL: $1, $2, $3.
+	Dollar
+)
+
+// Migration is one version found in the migrations directory.
+type Migration struct {
+	Version   int64
+	Name      string
+	Up        string
+	Down      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Migrator applies SQL files named like 0001_create_users.up.sql and
+// 0001_create_users.down.sql (a plain .sql file is an up script) in
+// version order, recording applied versions in a table. Each migration
+// runs in its own transaction together with its bookkeeping, as a single
+// Exec: MySQL needs multiStatements=true in the DSN for multi-statement
+// files.
+type Migrator struct {
+	db          *sql.DB
+	fsys        fs.FS
+	dir         string
+	table       string
+	placeholder Placeholder
+	dryRun      bool
+	lockTimeout time.Duration
+	staleLock   time.Duration
+	logger      *log.Logger
+}
+
+func NewMigrator(db *sql.DB, fsys fs.FS) *Migrator {
+	return &Migrator{
+		db:          db,
+		fsys:        fsys,
+		dir:         ".",
+		table:       "schema_migrations",
+		lockTimeout: time.Minute,
+		staleLock:   time.Hour,
+		logger:      log.Default().Named("migrate"),
+	}
+}
+
+// Dir sets the directory of fsys holding the files, e.g. "migrations"
+// for an embed.FS.
+func (m *Migrator) Dir(dir string) *Migrator {
+	m.dir = dir
+	return m
+}
+
+// Table sets the version table; the lock table is named after it with a
+// "_lock" suffix.
+func (m *Migrator) Table(table string) *Migrator {
+	m.table = table
+	return m
+}
+
+func (m *Migrator) Placeholder(placeholder Placeholder) *Migrator {
+	m.placeholder = placeholder
+	return m
+}
+
+// DryRun logs the migrations that would run without executing them or
+// touching the database otherwise: the bookkeeping tables are neither
+// created nor locked.
+func (m *Migrator) DryRun(dryRun bool) *Migrator {
+	m.dryRun = dryRun
+	return m
+}
+
+// LockTimeout is how long to wait for a concurrent runner to finish.
+func (m *Migrator) LockTimeout(d time.Duration) *Migrator {
+	m.lockTimeout = d
+	return m
+}
+
+// StaleLock is the age after which a lock is assumed to be left over by a
+// crashed runner and broken. It must exceed the longest migration run.
+func (m *Migrator) StaleLock(d time.Duration) *Migrator {
+	m.staleLock = d
+	return m
+}
+
+func (m *Migrator) Logger(logger *log.Logger) *Migrator {
+	m.logger = logger
+	return m
+}
+
+func (m *Migrator) bind(query string) string {
+	if m.placeholder != Dollar {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Migrations lists every migration with its applied state.
+func (m *Migrator) Migrations(ctx context.Context) ([]*Migration, error) {
+	if !identifier.MatchString(m.table) {
+		return nil, errors.Errorf("migrate: invalid table name %q", m.table)
+	}
+	migrations, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+	if !m.dryRun {
+		if err := m.ensureTables(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := m.db.QueryContext(ctx, fmt.Sprintf(`SELECT version, applied_at FROM %s`, m.table))
+	if err != nil && m.dryRun {
+		// The version table is only created by a real run; without it
+		// nothing has been applied yet.
+		m.logger.Debug("reading applied versions failed", log.Err(err))
+		return migrations, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "db.QueryContext")
+	}
+	defer rows.Close()
+
+	byVersion := make(map[int64]*Migration, len(migrations))
+	for _, mig := range migrations {
+		byVersion[mig.Version] = mig
+	}
+	for rows.Next() {
+		var version, appliedAt int64
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, errors.Wrap(err, "rows.Scan")
+		}
+		mig, ok := byVersion[version]
+		if !ok {
+			return nil, errors.Errorf("migrate: version %d is applied but its file is missing", version)
+		}
+		mig.Applied, mig.AppliedAt = true, time.Unix(appliedAt, 0)
+	}
+	return migrations, errors.Wrap(rows.Err(), "rows.Err")
+}
+
+func (m *Migrator) load() ([]*Migration, error) {
+	entries, err := fs.ReadDir(m.fsys, m.dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "fs.ReadDir")
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		match := filePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		body, err := fs.ReadFile(m.fsys, path.Join(m.dir, entry.Name()))
+		if err != nil {
+			return nil, errors.Wrap(err, "fs.ReadFile")
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = mig
+		} else if mig.Name != match[2] {
+			return nil, errors.Errorf("migrate: version %d used by %q and %q", version, mig.Name, match[2])
+		}
+		if match[3] == "down" {
+			mig.Down = string(body)
+		} else {
+			if mig.Up != "" {
+				return nil, errors.Errorf("migrate: duplicate up script for version %d", version)
+			}
+			mig.Up = string(body)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		migrations = append(migrations, mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+func (m *Migrator) ensureTables(ctx context.Context) error {
+	for _, stmt := range []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (version BIGINT PRIMARY KEY, name VARCHAR(255) NOT NULL, applied_at BIGINT NOT NULL)`, m.table),
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s_lock (id INTEGER PRIMARY KEY, locked_at BIGINT NOT NULL)`, m.table),
+	} {
+		if _, err := m.db.ExecContext(ctx, stmt); err != nil {
+			return errors.Wrap(err, "db.ExecContext")
+		}
+	}
+	return nil
+}
+
+// lock inserts the single row of the lock table, which fails while
+// another runner holds it. Locks older than staleLock are assumed to be
+// left over by a crashed runner and are broken.
+func (m *Migrator) lock(ctx context.Context) (func(), error) {
+	deadline := time.Now().Add(m.lockTimeout)
+	for {
+		now := time.Now().Unix()
+		_, err := m.db.ExecContext(ctx, m.bind(fmt.Sprintf(`INSERT INTO %s_lock (id, locked_at) VALUES (1, ?)`, m.table)), now)
+		if err == nil {
+			return func() {
+				m.db.ExecContext(context.Background(), fmt.Sprintf(`DELETE FROM %s_lock WHERE id = 1`, m.table))
+			}, nil
+		}
+
+		m.db.ExecContext(ctx, m.bind(fmt.Sprintf(`DELETE FROM %s_lock WHERE id = 1 AND locked_at < ?`, m.table)),
+			now-int64(m.staleLock/time.Second))
+
+		if time.Now().After(deadline) {
+			return nil, ErrLocked
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+// Up applies every pending migration and returns the versions applied,
+// or that would be applied in dry-run mode.
+func (m *Migrator) Up(ctx context.Context) ([]int64, error) {
+	return m.UpTo(ctx, 0)
+}
+
+// UpTo applies pending migrations up to and including version; zero
+// means all of them.
+func (m *Migrator) UpTo(ctx context.Context, version int64) ([]int64, error) {
+	unlock, migrations, err := m.prepare(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	var applied []int64
+	for _, mig := range migrations {
+		if mig.Applied || (version > 0 && mig.Version > version) {
+			continue
+		}
+		if mig.Up == "" {
+			return applied, errors.Errorf("migrate: version %d has only a down script", mig.Version)
+		}
+		if err := m.apply(ctx, mig, mig.Up, true); err != nil {
+			return applied, err
+		}
+		applied = append(applied, mig.Version)
+	}
+	return applied, nil
+}
+
+// Down reverts the last steps applied migrations, newest first.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]int64, error) {
+	unlock, migrations, err := m.prepare(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	var reverted []int64
+	for i := len(migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+		mig := migrations[i]
+		if !mig.Applied {
+			continue
+		}
+		if mig.Down == "" {
+			return reverted, errors.Wrapf(ErrNoDown, "version %d", mig.Version)
+		}
+		if err := m.apply(ctx, mig, mig.Down, false); err != nil {
+			return reverted, err
+		}
+		reverted = append(reverted, mig.Version)
+	}
+	return reverted, nil
+}
+
+func (m *Migrator) prepare(ctx context.Context) (func(), []*Migration, error) {
+	if _, err := m.Migrations(ctx); err != nil {
+		return nil, nil, err
+	}
+	unlock := func() {}
+	if !m.dryRun {
+		var err error
+		if unlock, err = m.lock(ctx); err != nil {
+			return nil, nil, err
+		}
+	}
+	// Reload under the lock, a concurrent runner may have applied some.
+	migrations, err := m.Migrations(ctx)
+	if err != nil {
+		unlock()
+		return nil, nil, err
+	}
+	return unlock, migrations, nil
+}
+
+func (m *Migrator) apply(ctx context.Context, mig *Migration, script string, up bool) error {
+	direction := "down"
+	if up {
+		direction = "up"
+	}
+	fields := []log.Field{log.Int64("version", mig.Version), log.String("name", mig.Name), log.String("direction", direction)}
+	if m.dryRun {
+		m.logger.Info("would apply migration", fields...)
+		return nil
+	}
+
+	start := time.Now()
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "db.BeginTx")
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return errors.Wrapf(err, "migrate: %s %d_%s", direction, mig.Version, mig.Name)
+	}
+	if up {
+		_, err = tx.ExecContext(ctx, m.bind(fmt.Sprintf(`INSERT INTO %s (version, name, applied_at) VALUES (?, ?, ?)`, m.table)),
+			mig.Version, mig.Name, time.Now().Unix())
+	} else {
+		_, err = tx.ExecContext(ctx, m.bind(fmt.Sprintf(`DELETE FROM %s WHERE version = ?`, m.table)), mig.Version)
+	}
+	if err != nil {
+		return errors.Wrap(err, "tx.ExecContext")
+	}
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "tx.Commit")
+	}
+	m.logger.Info("applied migration", append(fields, log.Duration("elapsed", time.Since(start)))...)
+	return nil
+}
+
+// Run applies every pending migration found at the root of fsys.
+func Run(db *sql.DB, fsys fs.FS) error {
+	_, err := NewMigrator(db, fsys).Up(context.Background())
+	return err
+}
@@ -0,0 +1,292 @@
+package mime
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	stdmime "mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	OctetStream = "application/octet-stream"
+	PlainText   = "text/plain"
+	Zip         = "application/zip"
+)
+
+// sniffLen is how much content Detect reads; tar headers need 262 bytes
+// and zip entry names may sit a little further in.
+const sniffLen = 3072
+
+var ErrMismatch = errors.New("mime: content does not match the file extension")
+
+type signature struct {
+	offset int
+	magic  []byte
+	mime   string
+}
+
+// signatures complement http.DetectContentType, which only knows the
+// types browsers care about.
+var signatures = []signature{
+	{0, []byte("7z\xbc\xaf\x27\x1c"), "application/x-7z-compressed"},
+	{0, []byte("Rar!\x1a\x07"), "application/vnd.rar"},
+	{0, []byte("BZh"), "application/x-bzip2"},
+	{0, []byte("\xfd7zXZ\x00"), "application/x-xz"},
+	{0, []byte("\x28\xb5\x2f\xfd"), "application/zstd"},
+	{257, []byte("ustar"), "application/x-tar"},
+	{0, []byte("SQLite format 3\x00"), "application/vnd.sqlite3"},
+	{0, []byte("PAR1"), "application/vnd.apache.parquet"},
+	{0, []byte("\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1"), "application/x-ole-storage"},
+	{4, []byte("ftypheic"), "image/heic"},
+	{4, []byte("ftypavif"), "image/avif"},
+	{0, []byte("II*\x00"), "image/tiff"},
+	{0, []byte("MM\x00*"), "image/tiff"},
+	{0, []byte("OFXHEADER"), "application/x-ofx"},
+}
+
+// zipFormats tells zip-based document formats apart by the entries
+// stored near the start of the archive.
+var zipFormats = []struct {
+	entry string
+	mime  string
+}{
+	{"word/", "application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+	{"xl/", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"},
+	{"ppt/", "application/vnd.openxmlformats-officedocument.presentationml.presentation"},
+	{"mimetypeapplication/epub+zip", "application/epub+zip"},
+	{"mimetypeapplication/vnd.oasis.opendocument.text", "application/vnd.oasis.opendocument.text"},
+	{"mimetypeapplication/vnd.oasis.opendocument.spreadsheet", "application/vnd.oasis.opendocument.spreadsheet"},
+	{"META-INF/MANIFEST.MF", "application/java-archive"},
+}
+
+// DetectBytes sniffs the media type of data from its leading bytes,
+// without parameters such as charset. Unknown binary data is
+// OctetStream.
+func DetectBytes(data []byte) string {
+	if len(data) > sniffLen {
+		data = data[:sniffLen]
+	}
+	for _, sig := range signatures {
+		if len(data) >= sig.offset+len(sig.magic) && bytes.Equal(data[sig.offset:sig.offset+len(sig.magic)], sig.magic) {
+			return sig.mime
+		}
+	}
+
+	detected := mediaType(http.DetectContentType(data))
+	switch detected {
+	case Zip:
+		for _, format := range zipFormats {
+			if bytes.Contains(data, []byte(format.entry)) {
+				return format.mime
+			}
+		}
+	case PlainText:
+		if isJSON(data, len(data) == sniffLen) {
+			return "application/json"
+		}
+	}
+	return detected
+}
+
+// isJSON reports whether data holds a JSON object or array. A truncated
+// prefix only needs to be free of syntax errors up to where it stops.
+func isJSON(data []byte, truncated bool) bool {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return false
+	}
+	if !truncated {
+		return json.Valid(trimmed)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(trimmed))
+	for {
+		_, err := dec.Token()
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return true
+		}
+		if err != nil {
+			return false
+		}
+	}
+}
+
+// Detect sniffs the media type from the first bytes of r.
+func Detect(r io.Reader) (string, error) {
+	buf := make([]byte, sniffLen)
+	n, err := io.ReadFull(r, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", errors.Wrap(err, "io.ReadFull")
+	}
+	return DetectBytes(buf[:n]), nil
+}
+
+// Peek is like Detect but also returns a reader that yields the full
+// content of r, including the sniffed bytes, for streaming uploads.
+func Peek(r io.Reader) (string, io.Reader, error) {
+	buf := make([]byte, sniffLen)
+	n, err := io.ReadFull(r, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", nil, errors.Wrap(err, "io.ReadFull")
+	}
+	return DetectBytes(buf[:n]), io.MultiReader(bytes.NewReader(buf[:n]), r), nil
+}
+
+var extensions = map[string]string{
+	".7z":      "application/x-7z-compressed",
+	".avif":    "image/avif",
+	".bz2":     "application/x-bzip2",
+	".csv":     "text/csv",
+	".doc":     "application/msword",
+	".docx":    "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".epub":    "application/epub+zip",
+	".gif":     "image/gif",
+	".gz":      "application/gzip",
+	".heic":    "image/heic",
+	".htm":     "text/html",
+	".html":    "text/html",
+	".ico":     "image/x-icon",
+	".jar":     "application/java-archive",
+	".jpeg":    "image/jpeg",
+	".jpg":     "image/jpeg",
+	".json":    "application/json",
+	".md":      "text/markdown",
+	".mp3":     "audio/mpeg",
+	".mp4":     "video/mp4",
+	".ods":     "application/vnd.oasis.opendocument.spreadsheet",
+	".odt":     "application/vnd.oasis.opendocument.text",
+	".ofx":     "application/x-ofx",
+	".parquet": "application/vnd.apache.parquet",
+	".pdf":     "application/pdf",
+	".png":     "image/png",
+	".pptx":    "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+	".rar":     "application/vnd.rar",
+	".sqlite":  "application/vnd.sqlite3",
+	".svg":     "image/svg+xml",
+	".tar":     "application/x-tar",
+	".tgz":     "application/gzip",
+	".tif":     "image/tiff",
+	".tiff":    "image/tiff",
+	".txt":     "text/plain",
+	".wasm":    "application/wasm",
+	".webm":    "video/webm",
+	".webp":    "image/webp",
+	".xls":     "application/vnd.ms-excel",
+	".xlsx":    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".xml":     "text/xml",
+	".xz":      "application/x-xz",
+	".yaml":    "application/yaml",
+	".yml":     "application/yaml",
+	".zip":     "application/zip",
+	".zst":     "application/zstd",
+}
+
+// ByExtension returns the media type for the extension of name, or ""
+// when unknown. A built-in table is checked before the system one so
+// results don't vary between machines.
+func ByExtension(name string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	if ext == "" {
+		return ""
+	}
+	if t, ok := extensions[ext]; ok {
+		return t
+	}
+	return mediaType(stdmime.TypeByExtension(ext))
+}
+
+// BestGuess combines content and name: a specific sniffed type wins, and
+// the extension refines generic results such as text/plain for a .csv or
+// zip for a format the sniffer could not tell apart.
+func BestGuess(name string, data []byte) string {
+	detected := DetectBytes(data)
+	byExt := ByExtension(name)
+	if byExt == "" || !isGeneric(detected) {
+		return detected
+	}
+	if Compatible(byExt, detected) {
+		return byExt
+	}
+	return detected
+}
+
+// Verify returns ErrMismatch when the content of a file named name cannot
+// be of the type its extension claims, e.g. an executable renamed to .pdf.
+func Verify(name string, data []byte) error {
+	byExt := ByExtension(name)
+	if byExt == "" {
+		return nil
+	}
+	if detected := DetectBytes(data); !Compatible(byExt, detected) {
+		return errors.Wrapf(ErrMismatch, "%s looks like %s, not %s", filepath.Base(name), detected, byExt)
+	}
+	return nil
+}
+
+// Compatible reports whether content sniffed as detected may be a file
+// of type declared.
+func Compatible(declared, detected string) bool {
+	if declared == detected {
+		return true
+	}
+	switch detected {
+	case PlainText:
+		return isText(declared)
+	case "text/xml":
+		return declared == "image/svg+xml" || declared == "text/xml" || declared == "application/xml"
+	case Zip:
+		return strings.Contains(declared, "zip") || strings.Contains(declared, "openxmlformats") ||
+			strings.Contains(declared, "opendocument") || declared == "application/java-archive"
+	case "application/x-ole-storage":
+		return declared == "application/msword" || declared == "application/vnd.ms-excel"
+	case "application/x-gzip":
+		return declared == "application/gzip"
+	case OctetStream:
+		// The sniffer cannot recognise every binary format, but it does
+		// recognise the ones with a known signature.
+		return !isText(declared) && !sniffable[declared]
+	}
+	return false
+}
+
+// sniffable holds the types DetectBytes recognises by signature.
+var sniffable = map[string]bool{
+	"application/pdf":  true,
+	"application/gzip": true,
+	"image/png":        true,
+	"image/jpeg":       true,
+	"image/gif":        true,
+	"image/webp":       true,
+	"image/bmp":        true,
+}
+
+func init() {
+	for _, sig := range signatures {
+		sniffable[sig.mime] = true
+	}
+	for _, format := range zipFormats {
+		sniffable[format.mime] = true
+	}
+	sniffable[Zip] = true
+}
+
+func isGeneric(t string) bool {
+	return t == PlainText || t == OctetStream || t == Zip || t == "text/xml" || t == "application/x-ole-storage"
+}
+
+func isText(t string) bool {
+	return strings.HasPrefix(t, "text/") || t == "application/json" || t == "application/yaml" ||
+		t == "image/svg+xml" || t == "application/x-ofx"
+}
+
+func mediaType(t string) string {
+	if i := strings.IndexByte(t, ';'); i >= 0 {
+		t = t[:i]
+	}
+	return strings.TrimSpace(t)
+}
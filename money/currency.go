@@ -0,0 +1,63 @@
+package money
+
+import (
+	"strings"
+	"sync"
+	"utils/format"
+
+	"github.com/pkg/errors"
+)
+
+type Currency struct {
+	Code      string
+	Symbol    string
+	Decimals  int
+	Decimal   string
+	Thousands string
+	// SymbolAfter places the symbol after the amount, as in 1.234,56 €.
+	SymbolAfter bool
+	// SymbolSpace separates symbol and amount, as in R$ 1.234,56.
+	SymbolSpace bool
+}
+
+var (
+	currenciesMu sync.RWMutex
+	currencies   = map[string]Currency{
+		"BRL": {Code: "BRL", Symbol: "R$", Decimals: 2, Decimal: ",", Thousands: ".", SymbolSpace: true},
+		"USD": {Code: "USD", Symbol: "$", Decimals: 2, Decimal: ".", Thousands: ","},
+		"EUR": {Code: "EUR", Symbol: "€", Decimals: 2, Decimal: ",", Thousands: ".", SymbolAfter: true, SymbolSpace: true},
+		"GBP": {Code: "GBP", Symbol: "£", Decimals: 2, Decimal: ".", Thousands: ","},
+		"JPY": {Code: "JPY", Symbol: "¥", Decimals: 0, Decimal: ".", Thousands: ","},
+	}
+)
+
+var ErrUnknownCurrency = errors.New("money: unknown currency")
+
+// RegisterCurrency adds or replaces a currency. Its symbol and decimals are
+// also registered with the format package, so Money.Format knows it too.
+func RegisterCurrency(c Currency) {
+	currenciesMu.Lock()
+	currencies[strings.ToUpper(c.Code)] = c
+	currenciesMu.Unlock()
+
+	format.RegisterCurrency(format.CurrencyInfo{Code: strings.ToUpper(c.Code), Symbol: c.Symbol, Decimals: c.Decimals})
+}
+
+func LookupCurrency(code string) (Currency, error) {
+	currenciesMu.RLock()
+	defer currenciesMu.RUnlock()
+
+	c, ok := currencies[strings.ToUpper(code)]
+	if !ok {
+		return Currency{}, errors.Wrap(ErrUnknownCurrency, code)
+	}
+	return c, nil
+}
+
+func mustCurrency(code string) Currency {
+	c, err := LookupCurrency(code)
+	if err != nil {
+		return Currency{Code: strings.ToUpper(code), Symbol: strings.ToUpper(code), Decimals: 2, Decimal: ".", Thousands: ",", SymbolSpace: true}
+	}
+	return c
+}
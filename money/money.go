@@ -0,0 +1,322 @@
+package money
+
+import (
+	"encoding/json"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+	"utils/format"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrCurrencyMismatch = errors.New("money: currency mismatch")
+	ErrOverflow         = errors.New("money: overflow")
+)
+
+// Money is an amount in the currency's minor unit (centavos, cents), so
+// arithmetic never goes through floating point.
+type Money struct {
+	amount   int64
+	currency string
+}
+
+func New(minor int64, currency string) Money {
+	return Money{amount: minor, currency: strings.ToUpper(currency)}
+}
+
+func BRL(minor int64) Money { return New(minor, "BRL") }
+func USD(minor int64) Money { return New(minor, "USD") }
+func EUR(minor int64) Money { return New(minor, "EUR") }
+
+// Parse reads a decimal amount such as "1234.56", "1.234,56" or "-10",
+// treating the last '.' or ',' followed by up to the currency's decimals as
+// the decimal separator. Unknown currencies return ErrUnknownCurrency.
+func Parse(s string, currency string) (Money, error) {
+	c, err := LookupCurrency(currency)
+	if err != nil {
+		return Money{}, err
+	}
+	s = strings.TrimSpace(s)
+
+	sign := ""
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		sign, s = s[:1], s[1:]
+	}
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		return Money{}, errors.Errorf("money: invalid amount %q", sign+s)
+	}
+
+	whole, frac := s, ""
+	if i := strings.LastIndexAny(s, ".,"); i >= 0 && len(s)-i-1 <= c.Decimals && len(s)-i-1 > 0 {
+		whole, frac = s[:i], s[i+1:]
+	}
+	whole = strings.NewReplacer(".", "", ",", "", " ", "", "_", "").Replace(whole)
+	if whole == "" {
+		whole = "0"
+	}
+	frac += strings.Repeat("0", c.Decimals-len(frac))
+
+	// The sign goes to ParseInt so that the minimum int64 still parses.
+	minor, err := strconv.ParseInt(sign+whole+frac, 10, 64)
+	if err != nil {
+		return Money{}, errors.Wrapf(err, "money: invalid amount %q", sign+s)
+	}
+	return New(minor, c.Code), nil
+}
+
+func (m Money) Amount() int64 {
+	return m.amount
+}
+
+func (m Money) Currency() string {
+	return m.currency
+}
+
+// Float returns the amount in major units, for display or interop only.
+func (m Money) Float() float64 {
+	return float64(m.amount) / math.Pow10(mustCurrency(m.currency).Decimals)
+}
+
+func (m Money) IsZero() bool     { return m.amount == 0 }
+func (m Money) IsNegative() bool { return m.amount < 0 }
+func (m Money) IsPositive() bool { return m.amount > 0 }
+
+// Negate returns -m. The minimum int64 has no positive counterpart and
+// is returned unchanged; Sub reports it as ErrOverflow.
+func (m Money) Negate() Money {
+	return Money{amount: -m.amount, currency: m.currency}
+}
+
+// Abs returns |m|, with the same caveat as Negate for the minimum int64.
+func (m Money) Abs() Money {
+	if m.amount < 0 {
+		return m.Negate()
+	}
+	return m
+}
+
+func (m Money) SameCurrency(o Money) bool {
+	return m.currency == o.currency
+}
+
+func (m Money) Add(o Money) (Money, error) {
+	if !m.SameCurrency(o) {
+		return Money{}, ErrCurrencyMismatch
+	}
+	sum := m.amount + o.amount
+	if (o.amount > 0 && sum < m.amount) || (o.amount < 0 && sum > m.amount) {
+		return Money{}, ErrOverflow
+	}
+	return Money{amount: sum, currency: m.currency}, nil
+}
+
+func (m Money) Sub(o Money) (Money, error) {
+	if !m.SameCurrency(o) {
+		return Money{}, ErrCurrencyMismatch
+	}
+	diff := m.amount - o.amount
+	if (o.amount > 0 && diff > m.amount) || (o.amount < 0 && diff < m.amount) {
+		return Money{}, ErrOverflow
+	}
+	return Money{amount: diff, currency: m.currency}, nil
+}
+
+func (m Money) Mul(n int64) (Money, error) {
+	if m.amount != 0 && n != 0 {
+		product := m.amount * n
+		// product/n does not catch MinInt64 * -1, which wraps to itself.
+		if product/n != m.amount || (n == -1 && m.amount == math.MinInt64) {
+			return Money{}, ErrOverflow
+		}
+		return Money{amount: product, currency: m.currency}, nil
+	}
+	return Money{amount: 0, currency: m.currency}, nil
+}
+
+// MulRate multiplies by a decimal rate such as a tax or discount, rounding
+// half away from zero to the nearest minor unit.
+func (m Money) MulRate(rate float64) Money {
+	return Money{amount: int64(math.Round(float64(m.amount) * rate)), currency: m.currency}
+}
+
+// Allocate splits m proportionally to ratios without losing minor units;
+// the remainder is distributed one unit at a time to the first parts.
+func (m Money) Allocate(ratios ...int) ([]Money, error) {
+	var total int64
+	for _, r := range ratios {
+		if r < 0 {
+			return nil, errors.New("money: negative ratio")
+		}
+		if total > math.MaxInt64-int64(r) {
+			return nil, errors.Wrap(ErrOverflow, "ratios sum")
+		}
+		total += int64(r)
+	}
+	if total == 0 {
+		return nil, errors.New("money: ratios sum to zero")
+	}
+
+	parts := make([]Money, len(ratios))
+	remainder := m.amount
+	for i, r := range ratios {
+		share := mulDiv(m.amount, int64(r), total)
+		parts[i] = Money{amount: share, currency: m.currency}
+		remainder -= share
+	}
+
+	unit := int64(1)
+	if remainder < 0 {
+		unit = -1
+	}
+	for i := 0; remainder != 0; i = (i + 1) % len(parts) {
+		if ratios[i] == 0 {
+			continue
+		}
+		parts[i].amount += unit
+		remainder -= unit
+	}
+	return parts, nil
+}
+
+// mulDiv returns a*b/c truncated toward zero for 0 <= b <= c, without
+// overflowing in the intermediate product.
+func mulDiv(a, b, c int64) int64 {
+	abs := uint64(a)
+	if a < 0 {
+		abs = -abs
+	}
+	hi, lo := bits.Mul64(abs, uint64(b))
+	q, _ := bits.Div64(hi, lo, uint64(c))
+	if a < 0 {
+		return -int64(q)
+	}
+	return int64(q)
+}
+
+// Split divides m into n parts that differ by at most one minor unit.
+func (m Money) Split(n int) ([]Money, error) {
+	if n < 1 {
+		return nil, errors.New("money: split into less than one part")
+	}
+	ratios := make([]int, n)
+	for i := range ratios {
+		ratios[i] = 1
+	}
+	return m.Allocate(ratios...)
+}
+
+func (m Money) Compare(o Money) (int, error) {
+	if !m.SameCurrency(o) {
+		return 0, ErrCurrencyMismatch
+	}
+	switch {
+	case m.amount < o.amount:
+		return -1, nil
+	case m.amount > o.amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (m Money) Equal(o Money) bool {
+	return m.currency == o.currency && m.amount == o.amount
+}
+
+func (m Money) GreaterThan(o Money) bool {
+	c, err := m.Compare(o)
+	return err == nil && c > 0
+}
+
+func (m Money) LessThan(o Money) bool {
+	c, err := m.Compare(o)
+	return err == nil && c < 0
+}
+
+// String formats with the currency's default conventions, e.g. R$ 1.234,56.
+func (m Money) String() string {
+	c := mustCurrency(m.currency)
+
+	number := formatMinor(magnitude(m.amount), c.Decimals, c.Decimal, c.Thousands)
+	sign := ""
+	if m.amount < 0 {
+		sign = "-"
+	}
+
+	space := ""
+	if c.SymbolSpace {
+		space = " "
+	}
+
+	if c.SymbolAfter {
+		return sign + number + space + c.Symbol
+	}
+	return sign + c.Symbol + space + number
+}
+
+// Format uses the separators and symbol placement of locale, e.g.
+// BRL(123456).Format("en-US") returns R$1,234.56.
+func (m Money) Format(locale string) string {
+	return format.CurrencyDecimal(m.Decimal(), m.currency, locale)
+}
+
+// Decimal formats the amount as a plain decimal number, e.g. -1234.56.
+func (m Money) Decimal() string {
+	c := mustCurrency(m.currency)
+	s := formatMinor(magnitude(m.amount), c.Decimals, ".", "")
+	if m.amount < 0 {
+		return "-" + s
+	}
+	return s
+}
+
+// magnitude returns |amount| as a uint64, which also holds the minimum
+// int64.
+func magnitude(amount int64) uint64 {
+	if amount < 0 {
+		return uint64(-amount)
+	}
+	return uint64(amount)
+}
+
+func formatMinor(minor uint64, decimals int, decimal, thousands string) string {
+	digits := strconv.FormatUint(minor, 10)
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+
+	whole, frac := digits[:len(digits)-decimals], digits[len(digits)-decimals:]
+
+	var b strings.Builder
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(thousands)
+		}
+		b.WriteRune(r)
+	}
+	if decimals > 0 {
+		b.WriteString(decimal)
+		b.WriteString(frac)
+	}
+	return b.String()
+}
+
+type moneyJSON struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{Amount: m.amount, Currency: m.currency})
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var v moneyJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return errors.Wrap(err, "json.Unmarshal")
+	}
+	*m = New(v.Amount, v.Currency)
+	return nil
+}
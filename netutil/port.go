@@ -0,0 +1,80 @@
+package netutil
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// FreePort asks the kernel for a free TCP port on localhost. The port is
+// released before returning, so another process may grab it first; use it
+// for tests, not for production binding.
+func FreePort() (int, error) {
+	ports, err := FreePorts(1)
+	if err != nil {
+		return 0, err
+	}
+	return ports[0], nil
+}
+
+// FreePorts returns n distinct free TCP ports.
+func FreePorts(n int) ([]int, error) {
+	listeners := make([]net.Listener, 0, n)
+	defer func() {
+		for _, l := range listeners {
+			l.Close()
+		}
+	}()
+
+	ports := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return nil, errors.Wrap(err, "net.Listen")
+		}
+		listeners = append(listeners, l)
+		ports = append(ports, l.Addr().(*net.TCPAddr).Port)
+	}
+	return ports, nil
+}
+
+// IsPortOpen reports whether a TCP connection to host:port succeeds within
+// timeout.
+func IsPortOpen(host string, port int, timeout time.Duration) bool {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(host, strconv.Itoa(port)), timeout)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// WaitForPort polls addr ("host:port") until it accepts TCP connections or
+// ctx is done, e.g. to wait for a database container at startup.
+func WaitForPort(ctx context.Context, addr string) error {
+	return WaitForPortInterval(ctx, addr, 250*time.Millisecond)
+}
+
+// WaitForPortInterval is WaitForPort with a custom polling interval.
+func WaitForPortInterval(ctx context.Context, addr string, interval time.Duration) error {
+	dialer := net.Dialer{Timeout: interval}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
+		if err == nil {
+			conn.Close()
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(ctx.Err(), "waiting for %s", addr)
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package netutil
+
+import (
+	"context"
+	"net"
+	"time"
+	"utils/waitgroup"
+
+	"github.com/pkg/errors"
+)
+
+// ProbeTCP measures how long a TCP handshake with addr ("host:port")
+// takes. It stands in for ICMP ping, which needs raw socket privileges
+// and is often blocked where TCP to the service port is not.
+func ProbeTCP(addr string, timeout time.Duration) (time.Duration, error) {
+	return probe(context.Background(), addr, timeout)
+}
+
+func probe(ctx context.Context, addr string, timeout time.Duration) (time.Duration, error) {
+	dialer := net.Dialer{Timeout: timeout}
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	latency := time.Since(start)
+	if err != nil {
+		return latency, errors.Wrapf(err, "probe %s", addr)
+	}
+	conn.Close()
+	return latency, nil
+}
+
+type ProbeResult struct {
+	Addr    string
+	Latency time.Duration
+	Err     error
+}
+
+func (r ProbeResult) OK() bool {
+	return r.Err == nil
+}
+
+// ProbeAll probes every target with at most concurrency probes in flight
+// and returns the results in the order of targets.
+func ProbeAll(ctx context.Context, targets []string, timeout time.Duration, concurrency int) []ProbeResult {
+	results := make([]ProbeResult, len(targets))
+	group := waitgroup.New().Limit(concurrency)
+	for i, addr := range targets {
+		i, addr := i, addr
+		group.Go(func() error {
+			latency, err := probe(ctx, addr, timeout)
+			results[i] = ProbeResult{Addr: addr, Latency: latency, Err: err}
+			return nil
+		})
+	}
+	group.Wait()
+	return results
+}
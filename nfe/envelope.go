@@ -0,0 +1,178 @@
+package nfe
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// NFeProc is a distributed NF-e: the document and its authorization
+// protocol.
+type NFeProc struct {
+	XMLName xml.Name `xml:"http://www.portalfiscal.inf.br/nfe nfeProc"`
+	Version string   `xml:"versao,attr"`
+	NFe     NFe      `xml:"NFe"`
+	ProtNFe ProtNFe  `xml:"protNFe"`
+}
+
+func (p *NFeProc) Signed() *Signature {
+	return p.NFe.Signature
+}
+
+func (p *NFeProc) SignedID() string {
+	return p.NFe.InfNFe.ID
+}
+
+// Authorized reports whether the protocol authorizes the document, either
+// on time (100) or late (150). The protocol is not covered by the NF-e
+// signature, so this is unauthenticated: anyone holding a signed NF-e can
+// attach a protNFe saying it was authorized. Confirm the status with
+// ConsSitNFe before relying on it.
+func (p *NFeProc) Authorized() bool {
+	return p.ProtNFe.InfProt.Authorized()
+}
+
+type ProtNFe struct {
+	Version string  `xml:"versao,attr"`
+	InfProt InfProt `xml:"infProt"`
+}
+
+// InfProt is the result of processing one document.
+type InfProt struct {
+	ID       string   `xml:"Id,attr,omitempty"`
+	TpAmb    int      `xml:"tpAmb"`
+	VerAplic string   `xml:"verAplic"`
+	ChNFe    string   `xml:"chNFe"`
+	DhRecbto DateTime `xml:"dhRecbto"`
+	NProt    string   `xml:"nProt,omitempty"`
+	DigVal   string   `xml:"digVal,omitempty"`
+	CStat    int      `xml:"cStat"`
+	XMotivo  string   `xml:"xMotivo"`
+}
+
+func (p InfProt) Authorized() bool {
+	return p.CStat == 100 || p.CStat == 150
+}
+
+// EnviNFe is a batch sent to the authorization service.
+type EnviNFe struct {
+	XMLName xml.Name `xml:"http://www.portalfiscal.inf.br/nfe enviNFe"`
+	Version string   `xml:"versao,attr"`
+	IdLote  string   `xml:"idLote"`
+	IndSinc int      `xml:"indSinc"`
+	NFe     []NFe    `xml:"NFe"`
+}
+
+// RetEnviNFe is the answer to EnviNFe; synchronous batches carry the
+// protocol, asynchronous ones a receipt number.
+type RetEnviNFe struct {
+	XMLName  xml.Name `xml:"http://www.portalfiscal.inf.br/nfe retEnviNFe"`
+	Version  string   `xml:"versao,attr"`
+	TpAmb    int      `xml:"tpAmb"`
+	VerAplic string   `xml:"verAplic"`
+	CStat    int      `xml:"cStat"`
+	XMotivo  string   `xml:"xMotivo"`
+	CUF      int      `xml:"cUF"`
+	DhRecbto DateTime `xml:"dhRecbto"`
+	NRec     string   `xml:"infRec>nRec,omitempty"`
+	ProtNFe  *ProtNFe `xml:"protNFe,omitempty"`
+}
+
+// ConsSitNFe queries the status of a document by its key.
+type ConsSitNFe struct {
+	XMLName xml.Name `xml:"http://www.portalfiscal.inf.br/nfe consSitNFe"`
+	Version string   `xml:"versao,attr"`
+	TpAmb   int      `xml:"tpAmb"`
+	XServ   string   `xml:"xServ"`
+	ChNFe   string   `xml:"chNFe"`
+}
+
+func NewConsSitNFe(key string, env int) *ConsSitNFe {
+	return &ConsSitNFe{Version: Version, TpAmb: env, XServ: "CONSULTAR", ChNFe: key}
+}
+
+type RetConsSitNFe struct {
+	XMLName  xml.Name `xml:"http://www.portalfiscal.inf.br/nfe retConsSitNFe"`
+	Version  string   `xml:"versao,attr"`
+	TpAmb    int      `xml:"tpAmb"`
+	VerAplic string   `xml:"verAplic"`
+	CStat    int      `xml:"cStat"`
+	XMotivo  string   `xml:"xMotivo"`
+	CUF      int      `xml:"cUF"`
+	DhRecbto DateTime `xml:"dhRecbto"`
+	ChNFe    string   `xml:"chNFe"`
+	ProtNFe  *ProtNFe `xml:"protNFe,omitempty"`
+	Events   []RawXML `xml:"procEventoNFe,omitempty"`
+}
+
+// Web services of NF-e 4.00, named as in their WSDL namespaces.
+const (
+	ServiceAuthorization = "NFeAutorizacao4"
+	ServiceReceipt       = "NFeRetAutorizacao4"
+	ServiceQuery         = "NFeConsultaProtocolo4"
+	ServiceStatus        = "NFeStatusServico4"
+	ServiceEvent         = "NFeRecepcaoEvento4"
+)
+
+const (
+	soapNamespace = "http://www.w3.org/2003/05/soap-envelope"
+	wsdlNamespace = "http://www.portalfiscal.inf.br/nfe/wsdl/"
+
+	// SOAPContentType is the Content-Type of SOAP 1.2 requests.
+	SOAPContentType = "application/soap+xml; charset=utf-8"
+)
+
+type soapEnvelope struct {
+	XMLName xml.Name `xml:"http://www.w3.org/2003/05/soap-envelope Envelope"`
+	Body    soapBody `xml:"Body"`
+}
+
+type soapBody struct {
+	Content []byte     `xml:",innerxml"`
+	Fault   *soapFault `xml:"Fault"`
+}
+
+type soapFault struct {
+	Code   string `xml:"Code>Value"`
+	Reason string `xml:"Reason>Text"`
+}
+
+// SOAPRequest wraps msg in the SOAP 1.2 envelope and nfeDadosMsg element
+// of service, such as ServiceAuthorization.
+func SOAPRequest(service string, msg interface{}) ([]byte, error) {
+	body, err := xml.Marshal(msg)
+	if err != nil {
+		return nil, errors.Wrap(err, "xml.Marshal")
+	}
+	envelope := fmt.Sprintf(`%s<soap12:Envelope xmlns:soap12="%s"><soap12:Body><nfeDadosMsg xmlns="%s%s">%s</nfeDadosMsg></soap12:Body></soap12:Envelope>`,
+		strings.TrimSpace(xml.Header), soapNamespace, wsdlNamespace, service, body)
+	return []byte(envelope), nil
+}
+
+// SOAPResponse unmarshals the message inside the nfeResultMsg element of
+// a SOAP response into v. A SOAP fault is returned as an error.
+func SOAPResponse(data []byte, v interface{}) error {
+	return NewDecoder().SOAPResponse(data, v)
+}
+
+// SOAPResponse is like the package-level SOAPResponse, verifying the
+// signature of the message as Decode does.
+func (d *Decoder) SOAPResponse(data []byte, v interface{}) error {
+	var envelope soapEnvelope
+	if err := xml.Unmarshal(data, &envelope); err != nil {
+		return errors.Wrap(err, "xml.Unmarshal")
+	}
+	if f := envelope.Body.Fault; f != nil {
+		return errors.Errorf("nfe: SOAP fault %s: %s", f.Code, f.Reason)
+	}
+
+	var result struct {
+		Content []byte `xml:",innerxml"`
+	}
+	if err := xml.Unmarshal(envelope.Body.Content, &result); err != nil {
+		return errors.Wrap(err, "xml.Unmarshal")
+	}
+	return d.Decode(result.Content, v)
+}
@@ -0,0 +1,127 @@
+package nfe
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"utils/br"
+
+	"github.com/pkg/errors"
+)
+
+var ErrInvalidKey = errors.New("nfe: invalid access key")
+
+// Key is the 44-character access key (chave de acesso) of an NF-e or
+// NFC-e. The issuer's CNPJ may be alphanumeric.
+type Key struct {
+	State    int // IBGE code of the issuer's UF
+	Month    time.Time
+	Issuer   string // CNPJ, or CPF padded to 14 digits
+	Model    int    // 55 for NF-e, 65 for NFC-e
+	Series   int
+	Number   int
+	Emission int // tpEmis
+	Code     int // cNF, the random code chosen by the issuer
+}
+
+// String returns the key with its check digit; call Validate first for
+// keys that were not parsed.
+func (k Key) String() string {
+	base := fmt.Sprintf("%02d%s%014s%02d%03d%09d%d%08d",
+		k.State, k.Month.Format("0601"), br.NormalizeCNPJ(k.Issuer), k.Model, k.Series, k.Number, k.Emission, k.Code)
+	return base + strconv.Itoa(keyDigit(base))
+}
+
+// ID returns the key as used in the Id attribute of infNFe.
+func (k Key) ID() string {
+	return "NFe" + k.String()
+}
+
+// ParseKey accepts a key with or without the "NFe" prefix and spaces.
+func ParseKey(s string) (Key, error) {
+	s = strings.TrimPrefix(strings.ToUpper(strings.Join(strings.Fields(s), "")), "NFE")
+	if len(s) != 44 {
+		return Key{}, errors.Wrapf(ErrInvalidKey, "%d characters", len(s))
+	}
+	for i := 0; i < 44; i++ {
+		c := s[i]
+		digit := c >= '0' && c <= '9'
+		if !digit && !(i >= 6 && i < 18 && c >= 'A' && c <= 'Z') {
+			return Key{}, errors.Wrapf(ErrInvalidKey, "character %q at %d", c, i+1)
+		}
+	}
+	if want := strconv.Itoa(keyDigit(s[:43])); s[43:] != want {
+		return Key{}, errors.Wrapf(ErrInvalidKey, "check digit %s, expected %s", s[43:], want)
+	}
+
+	month, err := time.Parse("0601", s[2:6])
+	if err != nil {
+		return Key{}, errors.Wrap(ErrInvalidKey, "month")
+	}
+	atoi := func(from, to int) int {
+		n, _ := strconv.Atoi(s[from:to])
+		return n
+	}
+	k := Key{
+		State:    atoi(0, 2),
+		Month:    month,
+		Issuer:   s[6:20],
+		Model:    atoi(20, 22),
+		Series:   atoi(22, 25),
+		Number:   atoi(25, 34),
+		Emission: atoi(34, 35),
+		Code:     atoi(35, 43),
+	}
+	if err := k.Validate(); err != nil {
+		return Key{}, err
+	}
+	return k, nil
+}
+
+// states are the IBGE codes of the 27 UFs.
+var states = map[int]bool{
+	11: true, 12: true, 13: true, 14: true, 15: true, 16: true, 17: true,
+	21: true, 22: true, 23: true, 24: true, 25: true, 26: true, 27: true, 28: true, 29: true,
+	31: true, 32: true, 33: true, 35: true,
+	41: true, 42: true, 43: true,
+	50: true, 51: true, 52: true, 53: true,
+}
+
+// Validate checks the fields String cannot represent: an unknown UF, a
+// month outside 2000-2099 (the key holds a two-digit year) or a model
+// other than 55 and 65.
+func (k Key) Validate() error {
+	if !states[k.State] {
+		return errors.Wrapf(ErrInvalidKey, "unknown UF code %d", k.State)
+	}
+	if y := k.Month.Year(); y < 2000 || y > 2099 {
+		return errors.Wrapf(ErrInvalidKey, "month %s", k.Month.Format("2006-01"))
+	}
+	if k.Model != 55 && k.Model != 65 {
+		return errors.Wrapf(ErrInvalidKey, "model %d", k.Model)
+	}
+	return nil
+}
+
+func ValidKey(s string) bool {
+	_, err := ParseKey(s)
+	return err == nil
+}
+
+// keyDigit is the modulo 11 check digit with weights 2 to 9 from the
+// right; letters are valued by their ASCII code minus 48, as in the
+// alphanumeric CNPJ.
+func keyDigit(base string) int {
+	sum, weight := 0, 2
+	for i := len(base) - 1; i >= 0; i-- {
+		sum += int(base[i]-'0') * weight
+		if weight++; weight > 9 {
+			weight = 2
+		}
+	}
+	if rest := sum % 11; rest > 1 {
+		return 11 - rest
+	}
+	return 0
+}
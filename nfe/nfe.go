@@ -0,0 +1,229 @@
+package nfe
+
+import (
+	"encoding/xml"
+)
+
+const (
+	Namespace = "http://www.portalfiscal.inf.br/nfe"
+	Version   = "4.00"
+)
+
+// Environment codes of tpAmb.
+const (
+	Production   = 1
+	Homologation = 2
+)
+
+// NFe is a signed NF-e or NFC-e document.
+type NFe struct {
+	XMLName   xml.Name   `xml:"http://www.portalfiscal.inf.br/nfe NFe"`
+	InfNFe    InfNFe     `xml:"infNFe"`
+	Supl      *Supl      `xml:"infNFeSupl,omitempty"`
+	Signature *Signature `xml:"http://www.w3.org/2000/09/xmldsig# Signature,omitempty"`
+}
+
+// Key parses the access key from the Id attribute.
+func (n *NFe) Key() (Key, error) {
+	return ParseKey(n.InfNFe.ID)
+}
+
+type InfNFe struct {
+	ID      string   `xml:"Id,attr"`
+	Version string   `xml:"versao,attr"`
+	Ide     Ide      `xml:"ide"`
+	Emit    Emit     `xml:"emit"`
+	Dest    *Dest    `xml:"dest,omitempty"`
+	Det     []Det    `xml:"det"`
+	Total   Total    `xml:"total"`
+	Transp  Transp   `xml:"transp"`
+	Cobr    *RawXML  `xml:"cobr,omitempty"`
+	Pag     Pag      `xml:"pag"`
+	InfAdic *InfAdic `xml:"infAdic,omitempty"`
+	Resp    *RawXML  `xml:"infRespTec,omitempty"`
+}
+
+// Ide identifies the document.
+type Ide struct {
+	CUF         int       `xml:"cUF"`
+	CNF         string    `xml:"cNF"`
+	NatOp       string    `xml:"natOp"`
+	Mod         int       `xml:"mod"`
+	Serie       int       `xml:"serie"`
+	NNF         int       `xml:"nNF"`
+	DhEmi       DateTime  `xml:"dhEmi"`
+	DhSaiEnt    *DateTime `xml:"dhSaiEnt,omitempty"`
+	TpNF        int       `xml:"tpNF"`
+	IdDest      int       `xml:"idDest"`
+	CMunFG      string    `xml:"cMunFG"`
+	TpImp       int       `xml:"tpImp"`
+	TpEmis      int       `xml:"tpEmis"`
+	CDV         int       `xml:"cDV"`
+	TpAmb       int       `xml:"tpAmb"`
+	FinNFe      int       `xml:"finNFe"`
+	IndFinal    int       `xml:"indFinal"`
+	IndPres     int       `xml:"indPres"`
+	IndIntermed string    `xml:"indIntermed,omitempty"`
+	ProcEmi     int       `xml:"procEmi"`
+	VerProc     string    `xml:"verProc"`
+	NFref       []RawXML  `xml:"NFref,omitempty"`
+}
+
+type Address struct {
+	XLgr    string `xml:"xLgr"`
+	Nro     string `xml:"nro"`
+	XCpl    string `xml:"xCpl,omitempty"`
+	XBairro string `xml:"xBairro"`
+	CMun    string `xml:"cMun"`
+	XMun    string `xml:"xMun"`
+	UF      string `xml:"UF"`
+	CEP     string `xml:"CEP,omitempty"`
+	CPais   string `xml:"cPais,omitempty"`
+	XPais   string `xml:"xPais,omitempty"`
+	Fone    string `xml:"fone,omitempty"`
+}
+
+// Emit is the issuer.
+type Emit struct {
+	CNPJ      string  `xml:"CNPJ,omitempty"`
+	CPF       string  `xml:"CPF,omitempty"`
+	XNome     string  `xml:"xNome"`
+	XFant     string  `xml:"xFant,omitempty"`
+	EnderEmit Address `xml:"enderEmit"`
+	IE        string  `xml:"IE"`
+	IM        string  `xml:"IM,omitempty"`
+	CNAE      string  `xml:"CNAE,omitempty"`
+	CRT       int     `xml:"CRT"`
+}
+
+// Dest is the recipient.
+type Dest struct {
+	CNPJ          string   `xml:"CNPJ,omitempty"`
+	CPF           string   `xml:"CPF,omitempty"`
+	IDEstrangeiro string   `xml:"idEstrangeiro,omitempty"`
+	XNome         string   `xml:"xNome,omitempty"`
+	EnderDest     *Address `xml:"enderDest,omitempty"`
+	IndIEDest     int      `xml:"indIEDest"`
+	IE            string   `xml:"IE,omitempty"`
+	Email         string   `xml:"email,omitempty"`
+}
+
+// Det is an item of the document.
+type Det struct {
+	NItem     int    `xml:"nItem,attr"`
+	Prod      Prod   `xml:"prod"`
+	Imposto   RawXML `xml:"imposto"`
+	InfAdProd string `xml:"infAdProd,omitempty"`
+}
+
+// Prod describes the goods of an item. Quantities and unit values keep
+// the precision written by the issuer (up to 4 and 10 decimals), so they
+// are strings.
+type Prod struct {
+	CProd    string  `xml:"cProd"`
+	CEAN     string  `xml:"cEAN"`
+	XProd    string  `xml:"xProd"`
+	NCM      string  `xml:"NCM"`
+	CEST     string  `xml:"CEST,omitempty"`
+	CFOP     string  `xml:"CFOP"`
+	UCom     string  `xml:"uCom"`
+	QCom     string  `xml:"qCom"`
+	VUnCom   string  `xml:"vUnCom"`
+	VProd    Amount  `xml:"vProd"`
+	CEANTrib string  `xml:"cEANTrib"`
+	UTrib    string  `xml:"uTrib"`
+	QTrib    string  `xml:"qTrib"`
+	VUnTrib  string  `xml:"vUnTrib"`
+	VFrete   *Amount `xml:"vFrete,omitempty"`
+	VSeg     *Amount `xml:"vSeg,omitempty"`
+	VDesc    *Amount `xml:"vDesc,omitempty"`
+	VOutro   *Amount `xml:"vOutro,omitempty"`
+	IndTot   int     `xml:"indTot"`
+	XPed     string  `xml:"xPed,omitempty"`
+}
+
+type Total struct {
+	ICMSTot ICMSTot `xml:"ICMSTot"`
+}
+
+type ICMSTot struct {
+	VBC        Amount  `xml:"vBC"`
+	VICMS      Amount  `xml:"vICMS"`
+	VICMSDeson Amount  `xml:"vICMSDeson"`
+	VFCP       Amount  `xml:"vFCP"`
+	VBCST      Amount  `xml:"vBCST"`
+	VST        Amount  `xml:"vST"`
+	VFCPST     Amount  `xml:"vFCPST"`
+	VFCPSTRet  Amount  `xml:"vFCPSTRet"`
+	VProd      Amount  `xml:"vProd"`
+	VFrete     Amount  `xml:"vFrete"`
+	VSeg       Amount  `xml:"vSeg"`
+	VDesc      Amount  `xml:"vDesc"`
+	VII        Amount  `xml:"vII"`
+	VIPI       Amount  `xml:"vIPI"`
+	VIPIDevol  Amount  `xml:"vIPIDevol"`
+	VPIS       Amount  `xml:"vPIS"`
+	VCOFINS    Amount  `xml:"vCOFINS"`
+	VOutro     Amount  `xml:"vOutro"`
+	VNF        Amount  `xml:"vNF"`
+	VTotTrib   *Amount `xml:"vTotTrib,omitempty"`
+}
+
+type Transp struct {
+	ModFrete int      `xml:"modFrete"`
+	Rest     []RawXML `xml:",any"`
+}
+
+type Pag struct {
+	DetPag []DetPag `xml:"detPag"`
+	VTroco *Amount  `xml:"vTroco,omitempty"`
+}
+
+type DetPag struct {
+	IndPag string  `xml:"indPag,omitempty"`
+	TPag   string  `xml:"tPag"`
+	XPag   string  `xml:"xPag,omitempty"`
+	VPag   Amount  `xml:"vPag"`
+	Card   *RawXML `xml:"card,omitempty"`
+}
+
+type InfAdic struct {
+	InfAdFisco string `xml:"infAdFisco,omitempty"`
+	InfCpl     string `xml:"infCpl,omitempty"`
+}
+
+// Supl holds the NFC-e QR code data.
+type Supl struct {
+	QRCode   string `xml:"qrCode"`
+	URLChave string `xml:"urlChave"`
+}
+
+// RawXML keeps an element without typed fields. Content is the inner
+// XML, written back unchanged.
+type RawXML struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Content string     `xml:",innerxml"`
+}
+
+// UnmarshalXML drops the namespace of the element and its declarations,
+// which the enclosing document already carries, so marshalling does not
+// repeat them on every raw element.
+func (r *RawXML) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var inner struct {
+		Content string `xml:",innerxml"`
+	}
+	if err := d.DecodeElement(&inner, &start); err != nil {
+		return err
+	}
+	r.XMLName = xml.Name{Local: start.Name.Local}
+	r.Attrs = nil
+	for _, attr := range start.Attr {
+		if attr.Name.Space == "xmlns" || (attr.Name.Space == "" && attr.Name.Local == "xmlns") {
+			continue
+		}
+		r.Attrs = append(r.Attrs, attr)
+	}
+	r.Content = inner.Content
+	return nil
+}
@@ -0,0 +1,106 @@
+package nfe
+
+import "encoding/xml"
+
+// NFSeNamespace is the namespace of the national NFS-e standard. Cities
+// still on their own (ABRASF-based) layouts are not covered.
+const NFSeNamespace = "http://www.sped.fazenda.gov.br/nfse"
+
+// NFSe is a service invoice of the national standard, as returned by the
+// Sefin Nacional API, embedding the DPS sent by the provider.
+type NFSe struct {
+	XMLName   xml.Name   `xml:"http://www.sped.fazenda.gov.br/nfse NFSe"`
+	Version   string     `xml:"versao,attr"`
+	InfNFSe   InfNFSe    `xml:"infNFSe"`
+	Signature *Signature `xml:"http://www.w3.org/2000/09/xmldsig# Signature,omitempty"`
+}
+
+func (n *NFSe) Signed() *Signature {
+	return n.Signature
+}
+
+func (n *NFSe) SignedID() string {
+	return n.InfNFSe.ID
+}
+
+type InfNFSe struct {
+	ID            string      `xml:"Id,attr"`
+	XLocEmi       string      `xml:"xLocEmi"`
+	XLocPrestacao string      `xml:"xLocPrestacao"`
+	NNFSe         string      `xml:"nNFSe"`
+	CLocIncid     string      `xml:"cLocIncid,omitempty"`
+	XLocIncid     string      `xml:"xLocIncid,omitempty"`
+	XTribNac      string      `xml:"xTribNac"`
+	XTribMun      string      `xml:"xTribMun,omitempty"`
+	XNBS          string      `xml:"xNBS,omitempty"`
+	VerAplic      string      `xml:"verAplic"`
+	AmbGer        int         `xml:"ambGer"`
+	TpEmis        int         `xml:"tpEmis"`
+	ProcEmi       int         `xml:"procEmi"`
+	CStat         int         `xml:"cStat"`
+	DhProc        DateTime    `xml:"dhProc"`
+	NDFSe         string      `xml:"nDFSe"`
+	Emit          Party       `xml:"emit"`
+	Valores       NFSeValores `xml:"valores"`
+	DPS           DPS         `xml:"DPS"`
+}
+
+// NFSeValores are the amounts computed by the tax authority.
+type NFSeValores struct {
+	VCalcDR    *Amount `xml:"vCalcDR,omitempty"`
+	VBC        *Amount `xml:"vBC,omitempty"`
+	PAliqAplic string  `xml:"pAliqAplic,omitempty"`
+	VISSQN     *Amount `xml:"vISSQN,omitempty"`
+	VTotalRet  *Amount `xml:"vTotalRet,omitempty"`
+	VLiq       Amount  `xml:"vLiq"`
+}
+
+// DPS is the service declaration (Declaração de Prestação de Serviço)
+// signed by the provider and sent to generate an NFS-e.
+type DPS struct {
+	XMLName   xml.Name   `xml:"http://www.sped.fazenda.gov.br/nfse DPS"`
+	Version   string     `xml:"versao,attr"`
+	InfDPS    InfDPS     `xml:"infDPS"`
+	Signature *Signature `xml:"http://www.w3.org/2000/09/xmldsig# Signature,omitempty"`
+}
+
+func (d *DPS) Signed() *Signature {
+	return d.Signature
+}
+
+func (d *DPS) SignedID() string {
+	return d.InfDPS.ID
+}
+
+type InfDPS struct {
+	ID       string   `xml:"Id,attr"`
+	TpAmb    int      `xml:"tpAmb"`
+	DhEmi    DateTime `xml:"dhEmi"`
+	VerAplic string   `xml:"verAplic"`
+	Serie    string   `xml:"serie"`
+	NDPS     string   `xml:"nDPS"`
+	DCompet  Date     `xml:"dCompet"`
+	TpEmit   int      `xml:"tpEmit"`
+	CLocEmi  string   `xml:"cLocEmi"`
+	Subst    *RawXML  `xml:"subst,omitempty"`
+	Prest    Party    `xml:"prest"`
+	Toma     *Party   `xml:"toma,omitempty"`
+	Interm   *Party   `xml:"interm,omitempty"`
+	Serv     RawXML   `xml:"serv"`
+	Valores  RawXML   `xml:"valores"`
+}
+
+// Party is the provider, taker or intermediary of a service.
+type Party struct {
+	CNPJ     string  `xml:"CNPJ,omitempty"`
+	CPF      string  `xml:"CPF,omitempty"`
+	NIF      string  `xml:"NIF,omitempty"`
+	CAEPF    string  `xml:"CAEPF,omitempty"`
+	IM       string  `xml:"IM,omitempty"`
+	XNome    string  `xml:"xNome,omitempty"`
+	End      *RawXML `xml:"end,omitempty"`
+	EnderNac *RawXML `xml:"enderNac,omitempty"`
+	Fone     string  `xml:"fone,omitempty"`
+	Email    string  `xml:"email,omitempty"`
+	RegTrib  *RawXML `xml:"regTrib,omitempty"`
+}
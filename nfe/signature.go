@@ -0,0 +1,176 @@
+package nfe
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/xml"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const SignatureNamespace = "http://www.w3.org/2000/09/xmldsig#"
+
+var ErrUnsigned = errors.New("nfe: document is not signed")
+
+// Signature is an enveloped XML signature (xmldsig) as used by NF-e,
+// its events and the web service responses.
+type Signature struct {
+	SignedInfo     SignedInfo `xml:"SignedInfo"`
+	SignatureValue string     `xml:"SignatureValue"`
+	KeyInfo        KeyInfo    `xml:"KeyInfo"`
+}
+
+type SignedInfo struct {
+	CanonicalizationMethod Algorithm `xml:"CanonicalizationMethod"`
+	SignatureMethod        Algorithm `xml:"SignatureMethod"`
+	Reference              Reference `xml:"Reference"`
+}
+
+type Algorithm struct {
+	Algorithm           string               `xml:"Algorithm,attr"`
+	InclusiveNamespaces *InclusiveNamespaces `xml:"http://www.w3.org/2001/10/xml-exc-c14n# InclusiveNamespaces,omitempty"`
+}
+
+// InclusiveNamespaces is the PrefixList parameter of exclusive
+// canonicalization.
+type InclusiveNamespaces struct {
+	PrefixList string `xml:"PrefixList,attr"`
+}
+
+// prefixes returns the PrefixList entries, if any.
+func (a Algorithm) prefixes() []string {
+	if a.InclusiveNamespaces == nil {
+		return nil
+	}
+	return strings.Fields(a.InclusiveNamespaces.PrefixList)
+}
+
+type Reference struct {
+	URI          string      `xml:"URI,attr"`
+	Transforms   []Algorithm `xml:"Transforms>Transform"`
+	DigestMethod Algorithm   `xml:"DigestMethod"`
+	DigestValue  string      `xml:"DigestValue"`
+}
+
+type KeyInfo struct {
+	X509Certificate string `xml:"X509Data>X509Certificate"`
+}
+
+// Certificate parses the signer's certificate from KeyInfo.
+func (s *Signature) Certificate() (*x509.Certificate, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(s.KeyInfo.X509Certificate), ""))
+	if err != nil {
+		return nil, errors.Wrap(err, "nfe: X509Certificate")
+	}
+	cert, err := x509.ParseCertificate(raw)
+	if err != nil {
+		return nil, errors.Wrap(err, "x509.ParseCertificate")
+	}
+	return cert, nil
+}
+
+// ReferenceID returns the Id of the signed element, the reference URI
+// without its leading '#'.
+func (s *Signature) ReferenceID() string {
+	return strings.TrimPrefix(s.SignedInfo.Reference.URI, "#")
+}
+
+// Verifier checks a signature against the document it was read from.
+// doc holds the original bytes, since digests are computed over the
+// canonical form of the signed element and cannot be reproduced from a
+// re-marshalled value.
+type Verifier interface {
+	Verify(doc []byte, sig *Signature) error
+}
+
+// VerifierFunc adapts a function to Verifier.
+type VerifierFunc func(doc []byte, sig *Signature) error
+
+func (f VerifierFunc) Verify(doc []byte, sig *Signature) error {
+	return f(doc, sig)
+}
+
+// Signed is implemented by documents that carry a signature. SignedID is
+// the Id of the decoded element the signature must cover.
+type Signed interface {
+	Signed() *Signature
+	SignedID() string
+}
+
+func (n *NFe) Signed() *Signature {
+	return n.Signature
+}
+
+func (n *NFe) SignedID() string {
+	return n.InfNFe.ID
+}
+
+// Decoder unmarshals fiscal documents and optionally verifies their
+// signature.
+type Decoder struct {
+	verifier Verifier
+	required bool
+}
+
+func NewDecoder() *Decoder {
+	return &Decoder{}
+}
+
+// Verifier sets the check run on documents implementing Signed.
+// Unsigned documents are accepted unless required is true, in which case
+// they fail with ErrUnsigned.
+func (d *Decoder) Verifier(v Verifier, required bool) *Decoder {
+	d.verifier = v
+	d.required = required
+	return d
+}
+
+// Decode unmarshals data into v and then verifies the signature, which
+// must cover the element v was decoded from.
+func (d *Decoder) Decode(data []byte, v interface{}) error {
+	if err := xml.Unmarshal(data, v); err != nil {
+		return errors.Wrap(err, "xml.Unmarshal")
+	}
+	if d.verifier == nil {
+		return nil
+	}
+	signed, ok := v.(Signed)
+	if !ok {
+		return nil
+	}
+	sig := signed.Signed()
+	if sig == nil {
+		if d.required {
+			return ErrUnsigned
+		}
+		return nil
+	}
+	if err := d.verifier.Verify(data, sig); err != nil {
+		return err
+	}
+	// The signature may cover an element other than the one decoded into
+	// v, e.g. when a forged copy sits next to the signed one.
+	if id := signed.SignedID(); sig.ReferenceID() != id {
+		return errors.Wrapf(ErrInvalidSignature, "signature covers %q, decoded %q", sig.ReferenceID(), id)
+	}
+	return nil
+}
+
+// Unmarshal decodes data into v without verifying signatures.
+func Unmarshal(data []byte, v interface{}) error {
+	return NewDecoder().Decode(data, v)
+}
+
+// Marshal returns the compact XML of v, without whitespace between
+// elements as the web services require, prefixed with the XML
+// declaration.
+func Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header[:len(xml.Header)-1])
+	if err := xml.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, errors.Wrap(err, "xml.Encoder.Encode")
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,104 @@
+package nfe
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+	"utils/xmlutil"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	genuineInfNFe = `<infNFe Id="NFeB" versao="4.00"><emit><xNome>Genuine</xNome></emit></infNFe>`
+	signatureSlot = `<!--signature-->`
+)
+
+// signNFe returns an NFe holding genuineInfNFe and an enveloped signature
+// of it; extra is inserted before the signature.
+func signNFe(t *testing.T, extra string) []byte {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc := `<NFe xmlns="http://www.portalfiscal.inf.br/nfe">` + genuineInfNFe + signatureSlot + `</NFe>`
+	tree, err := xmlutil.ParseBytes([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256(xmlutil.Canonicalize(tree.ByID("NFeB")))
+
+	signature := `<Signature xmlns="http://www.w3.org/2000/09/xmldsig#"><SignedInfo>` +
+		`<CanonicalizationMethod Algorithm="` + xmlutil.C14N + `"></CanonicalizationMethod>` +
+		`<SignatureMethod Algorithm="http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"></SignatureMethod>` +
+		`<Reference URI="#NFeB"><Transforms>` +
+		`<Transform Algorithm="` + envelopedSignature + `"></Transform>` +
+		`<Transform Algorithm="` + xmlutil.C14N + `"></Transform>` +
+		`</Transforms><DigestMethod Algorithm="http://www.w3.org/2001/04/xmlenc#sha256"></DigestMethod>` +
+		`<DigestValue>` + base64.StdEncoding.EncodeToString(digest[:]) + `</DigestValue></Reference>` +
+		`</SignedInfo><SignatureValue></SignatureValue><KeyInfo><X509Data><X509Certificate>` +
+		base64.StdEncoding.EncodeToString(cert) + `</X509Certificate></X509Data></KeyInfo></Signature>`
+
+	tree, err = xmlutil.ParseBytes([]byte(strings.Replace(doc, signatureSlot, signature, 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	signedInfo, _ := xmlutil.Select(tree, "//SignedInfo")
+	hashed := sha256.Sum256(xmlutil.Canonicalize(signedInfo[0]))
+	value, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hashed[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signature = strings.Replace(signature, `<SignatureValue></SignatureValue>`,
+		`<SignatureValue>`+base64.StdEncoding.EncodeToString(value)+`</SignatureValue>`, 1)
+	return []byte(strings.Replace(doc, signatureSlot, extra+signature, 1))
+}
+
+func TestDecodeVerifiesSignature(t *testing.T) {
+	var n NFe
+	if err := NewDecoder().Verifier(XMLDSig, true).Decode(signNFe(t, ""), &n); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if n.InfNFe.ID != "NFeB" || n.InfNFe.Emit.XNome != "Genuine" {
+		t.Fatalf("decoded %q by %q", n.InfNFe.ID, n.InfNFe.Emit.XNome)
+	}
+}
+
+// A forged infNFe next to the signed one is merged by encoding/xml into
+// the decoded document while the signature still checks out.
+func TestDecodeRejectsWrappedInfNFe(t *testing.T) {
+	for name, forged := range map[string]string{
+		"other id": `<infNFe Id="NFeA" versao="4.00"><emit><xNome>Forged</xNome></emit></infNFe>`,
+		"no id":    `<infNFe versao="4.00"><emit><xNome>Forged</xNome></emit></infNFe>`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var n NFe
+			err := NewDecoder().Verifier(XMLDSig, true).Decode(signNFe(t, forged), &n)
+			if !errors.Is(err, ErrInvalidSignature) {
+				t.Fatalf("Decode = %v, decoded %q by %q; want ErrInvalidSignature", err, n.InfNFe.ID, n.InfNFe.Emit.XNome)
+			}
+		})
+	}
+}
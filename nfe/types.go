@@ -0,0 +1,89 @@
+package nfe
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Amount is a monetary value in centavos, written with two decimals as
+// in vProd or vNF.
+type Amount int64
+
+func (a Amount) MarshalText() ([]byte, error) {
+	sign := ""
+	if a < 0 {
+		sign, a = "-", -a
+	}
+	return []byte(sign + strconv.FormatInt(int64(a)/100, 10) + "." + twoDigits(int64(a)%100)), nil
+}
+
+func (a *Amount) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	whole, frac, _ := strings.Cut(s, ".")
+	if strings.TrimLeft(whole, "+-") == "" {
+		return errors.Errorf("nfe: invalid amount %q", s)
+	}
+	if len(frac) > 2 {
+		if strings.Trim(frac[2:], "0") != "" {
+			return errors.Errorf("nfe: amount %q has more than two decimals", s)
+		}
+		frac = frac[:2]
+	}
+	frac += strings.Repeat("0", 2-len(frac))
+	n, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return errors.Wrapf(err, "nfe: amount %q", s)
+	}
+	*a = Amount(n)
+	return nil
+}
+
+func twoDigits(n int64) string {
+	if n < 10 {
+		return "0" + strconv.FormatInt(n, 10)
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+// DateTimeLayout is the UTC offset format required in dhEmi and other
+// date-time fields; SEFAZ rejects the "Z" suffix.
+const DateTimeLayout = "2006-01-02T15:04:05-07:00"
+
+// DateTime is a time written with DateTimeLayout.
+type DateTime struct {
+	time.Time
+}
+
+func (d DateTime) MarshalText() ([]byte, error) {
+	return []byte(d.Format(DateTimeLayout)), nil
+}
+
+func (d *DateTime) UnmarshalText(text []byte) error {
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(string(text)))
+	if err != nil {
+		return errors.Wrap(err, "nfe: date-time")
+	}
+	d.Time = t
+	return nil
+}
+
+// Date is a time written as 2006-01-02, as in dVenc.
+type Date struct {
+	time.Time
+}
+
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.Format("2006-01-02")), nil
+}
+
+func (d *Date) UnmarshalText(text []byte) error {
+	t, err := time.Parse("2006-01-02", strings.TrimSpace(string(text)))
+	if err != nil {
+		return errors.Wrap(err, "nfe: date")
+	}
+	d.Time = t
+	return nil
+}
@@ -0,0 +1,246 @@
+package nfe
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/xml"
+	"strings"
+	"utils/xmlutil"
+
+	"github.com/pkg/errors"
+)
+
+var ErrInvalidSignature = errors.New("nfe: invalid signature")
+
+const envelopedSignature = "http://www.w3.org/2000/09/xmldsig#enveloped-signature"
+
+var digestMethods = map[string]crypto.Hash{
+	"http://www.w3.org/2000/09/xmldsig#sha1":  crypto.SHA1,
+	"http://www.w3.org/2001/04/xmlenc#sha256": crypto.SHA256,
+	"http://www.w3.org/2001/04/xmlenc#sha512": crypto.SHA512,
+}
+
+var signatureMethods = map[string]crypto.Hash{
+	"http://www.w3.org/2000/09/xmldsig#rsa-sha1":        crypto.SHA1,
+	"http://www.w3.org/2001/04/xmldsig-more#rsa-sha256": crypto.SHA256,
+	"http://www.w3.org/2001/04/xmldsig-more#rsa-sha512": crypto.SHA512,
+}
+
+// XMLDSig verifies enveloped RSA signatures with VerifySignature.
+var XMLDSig Verifier = VerifierFunc(VerifySignature)
+
+// VerifySignature checks the digest of the element referenced by sig and
+// the signature of SignedInfo with the certificate in KeyInfo. Every value
+// is read from the one Signature element of doc that references the
+// signed element, which must be unique, sit inside it or next to it and
+// match sig; documents with several such signatures, duplicate Ids or a
+// sibling of the same name next to the signed element or any of its
+// ancestors are rejected, so a signature cannot be wrapped around forged
+// content that encoding/xml would merge into the same field. It
+// does not validate the certificate chain or who the signer is; wrap it
+// to check the ICP-Brasil issuer or the CNPJ of the certificate.
+func VerifySignature(doc []byte, sig *Signature) error {
+	tree, err := xmlutil.ParseBytes(doc)
+	if err != nil {
+		return err
+	}
+	node, err := findSignature(tree, sig.SignedInfo.Reference.URI)
+	if err != nil {
+		return err
+	}
+	var signed Signature
+	if err := xml.Unmarshal(xmlutil.Canonicalize(node), &signed); err != nil {
+		return errors.Wrap(err, "xml.Unmarshal")
+	}
+	if compact(signed.SignatureValue) != compact(sig.SignatureValue) ||
+		compact(signed.SignedInfo.Reference.DigestValue) != compact(sig.SignedInfo.Reference.DigestValue) {
+		return errors.Wrap(ErrInvalidSignature, "Signature does not match the document")
+	}
+	sig = &signed
+
+	referenced := tree.Root()
+	if id := sig.ReferenceID(); id != "" {
+		found := withID(tree, id)
+		if len(found) != 1 {
+			return errors.Wrapf(ErrInvalidSignature, "%d elements with Id %q", len(found), id)
+		}
+		referenced = found[0]
+	}
+	if node.Parent != referenced && node.Parent != referenced.Parent {
+		return errors.Wrap(ErrInvalidSignature, "Signature is not enveloped by the signed element")
+	}
+	if err := unique(referenced); err != nil {
+		return err
+	}
+
+	canonical, err := transform(referenced, node, sig.SignedInfo.Reference.Transforms)
+	if err != nil {
+		return err
+	}
+	hash, ok := digestMethods[sig.SignedInfo.Reference.DigestMethod.Algorithm]
+	if !ok {
+		return errors.Errorf("nfe: unsupported digest method %q", sig.SignedInfo.Reference.DigestMethod.Algorithm)
+	}
+	digest := sum(hash, canonical)
+	want, err := base64.StdEncoding.DecodeString(compact(sig.SignedInfo.Reference.DigestValue))
+	if err != nil || !bytes.Equal(digest, want) {
+		return errors.Wrap(ErrInvalidSignature, "digest mismatch")
+	}
+
+	signedInfo, _ := xmlutil.Select(node, "SignedInfo")
+	if len(signedInfo) != 1 {
+		return errors.Wrap(ErrInvalidSignature, "SignedInfo not found")
+	}
+	c, err := canonicalizer(sig.SignedInfo.CanonicalizationMethod)
+	if err != nil {
+		return err
+	}
+	hash, ok = signatureMethods[sig.SignedInfo.SignatureMethod.Algorithm]
+	if !ok {
+		return errors.Errorf("nfe: unsupported signature method %q", sig.SignedInfo.SignatureMethod.Algorithm)
+	}
+
+	cert, err := sig.Certificate()
+	if err != nil {
+		return err
+	}
+	key, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return errors.Errorf("nfe: unsupported public key %T", cert.PublicKey)
+	}
+	value, err := base64.StdEncoding.DecodeString(compact(sig.SignatureValue))
+	if err != nil {
+		return errors.Wrap(err, "nfe: SignatureValue")
+	}
+	if err := rsa.VerifyPKCS1v15(key, hash, sum(hash, c.Canonicalize(signedInfo[0])), value); err != nil {
+		return errors.Wrap(ErrInvalidSignature, err.Error())
+	}
+	return nil
+}
+
+// findSignature returns the only Signature element that references uri.
+// It must have a single SignedInfo with a single Reference and no other
+// Signature next to it.
+func findSignature(tree *xmlutil.Node, uri string) (*xmlutil.Node, error) {
+	nodes, _ := xmlutil.Select(tree, "//Signature")
+	var found []*xmlutil.Node
+	for _, n := range nodes {
+		if n.Namespace() != SignatureNamespace {
+			continue
+		}
+		refs, _ := xmlutil.Find(n, "SignedInfo/Reference@URI")
+		for _, ref := range refs {
+			if ref == uri {
+				found = append(found, n)
+				break
+			}
+		}
+	}
+	if len(found) != 1 {
+		return nil, errors.Wrapf(ErrInvalidSignature, "%d Signature elements reference %q", len(found), uri)
+	}
+	n := found[0]
+	if refs, _ := xmlutil.Select(n, "SignedInfo/Reference"); len(refs) != 1 {
+		return nil, errors.Wrap(ErrInvalidSignature, "Signature must have exactly one Reference")
+	}
+	if infos, _ := xmlutil.Select(n, "SignedInfo"); len(infos) != 1 {
+		return nil, errors.Wrap(ErrInvalidSignature, "Signature must have exactly one SignedInfo")
+	}
+	for _, sibling := range n.Parent.Elements() {
+		if sibling != n && sibling.Name == "Signature" && sibling.Namespace() == SignatureNamespace {
+			return nil, errors.Wrap(ErrInvalidSignature, "several Signature elements")
+		}
+	}
+	return n, nil
+}
+
+// unique fails when n or one of its ancestors has a sibling of the same
+// name, such as a second infNFe next to the signed one.
+func unique(n *xmlutil.Node) error {
+	for ; n.Parent != nil && n.Parent.Kind == xmlutil.ElementNode; n = n.Parent {
+		for _, sibling := range n.Parent.Elements() {
+			if sibling != n && sibling.Name == n.Name && sibling.Namespace() == n.Namespace() {
+				return errors.Wrapf(ErrInvalidSignature, "several %s elements", n.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// withID returns every element whose Id, ID or id attribute is id.
+func withID(n *xmlutil.Node, id string) []*xmlutil.Node {
+	var out []*xmlutil.Node
+	if n.Kind == xmlutil.ElementNode {
+		for _, a := range n.Attrs {
+			if a.Prefix == "" && (a.Name == "Id" || a.Name == "ID" || a.Name == "id") && a.Value == id {
+				out = append(out, n)
+				break
+			}
+		}
+	}
+	for _, c := range n.Children {
+		out = append(out, withID(c, id)...)
+	}
+	return out
+}
+
+// canonicalizer returns the canonicalizer of a CanonicalizationMethod or
+// Transform, with its InclusiveNamespaces PrefixList.
+func canonicalizer(a Algorithm) (*xmlutil.Canonicalizer, error) {
+	c, err := xmlutil.NewCanonicalizer(a.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	if prefixes := a.prefixes(); len(prefixes) > 0 {
+		if a.Algorithm != xmlutil.ExcC14N && a.Algorithm != xmlutil.ExcC14NWithComments {
+			return nil, errors.Errorf("nfe: InclusiveNamespaces on %q", a.Algorithm)
+		}
+		c.InclusivePrefixes(prefixes...)
+	}
+	return c, nil
+}
+
+// transform applies the reference transforms; without a canonicalization
+// transform the element is canonicalized with C14N, as xmldsig requires.
+func transform(referenced, signature *xmlutil.Node, transforms []Algorithm) ([]byte, error) {
+	c, _ := xmlutil.NewCanonicalizer(xmlutil.C14N)
+	enveloped := false
+	for _, t := range transforms {
+		if t.Algorithm == envelopedSignature {
+			enveloped = true
+			continue
+		}
+		next, err := canonicalizer(t)
+		if err != nil {
+			return nil, errors.Errorf("nfe: unsupported transform %q", t.Algorithm)
+		}
+		c = next
+	}
+	if enveloped {
+		c.Exclude(signature)
+	}
+	return c.Canonicalize(referenced), nil
+}
+
+func compact(s string) string {
+	return strings.Join(strings.Fields(s), "")
+}
+
+func sum(hash crypto.Hash, data []byte) []byte {
+	switch hash {
+	case crypto.SHA1:
+		s := sha1.Sum(data)
+		return s[:]
+	case crypto.SHA512:
+		s := sha512.Sum512(data)
+		return s[:]
+	default:
+		s := sha256.Sum256(data)
+		return s[:]
+	}
+}
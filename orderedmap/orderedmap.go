@@ -0,0 +1,239 @@
+package orderedmap
+
+import (
+	"bytes"
+	"container/list"
+	"encoding"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+type Pair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+// Map keeps keys in insertion order; updating an existing key keeps its
+// position. It is not safe for concurrent use.
+type Map[K comparable, V any] struct {
+	items map[K]*list.Element
+	order *list.List
+}
+
+func New[K comparable, V any]() *Map[K, V] {
+	return &Map[K, V]{
+		items: make(map[K]*list.Element),
+		order: list.New(),
+	}
+}
+
+func (m *Map[K, V]) init() {
+	if m.items == nil {
+		m.items = make(map[K]*list.Element)
+		m.order = list.New()
+	}
+}
+
+func (m *Map[K, V]) Set(key K, value V) {
+	m.init()
+	if elem, ok := m.items[key]; ok {
+		elem.Value.(*Pair[K, V]).Value = value
+		return
+	}
+	m.items[key] = m.order.PushBack(&Pair[K, V]{Key: key, Value: value})
+}
+
+func (m *Map[K, V]) Get(key K) (V, bool) {
+	if elem, ok := m.items[key]; ok {
+		return elem.Value.(*Pair[K, V]).Value, true
+	}
+	var zero V
+	return zero, false
+}
+
+func (m *Map[K, V]) Has(key K) bool {
+	_, ok := m.items[key]
+	return ok
+}
+
+func (m *Map[K, V]) Delete(key K) bool {
+	elem, ok := m.items[key]
+	if !ok {
+		return false
+	}
+	m.order.Remove(elem)
+	delete(m.items, key)
+	return true
+}
+
+func (m *Map[K, V]) Len() int {
+	return len(m.items)
+}
+
+// MoveToBack marks key as most recently inserted.
+func (m *Map[K, V]) MoveToBack(key K) bool {
+	elem, ok := m.items[key]
+	if ok {
+		m.order.MoveToBack(elem)
+	}
+	return ok
+}
+
+func (m *Map[K, V]) Oldest() (Pair[K, V], bool) {
+	if m.order == nil || m.order.Len() == 0 {
+		return Pair[K, V]{}, false
+	}
+	return *m.order.Front().Value.(*Pair[K, V]), true
+}
+
+func (m *Map[K, V]) Newest() (Pair[K, V], bool) {
+	if m.order == nil || m.order.Len() == 0 {
+		return Pair[K, V]{}, false
+	}
+	return *m.order.Back().Value.(*Pair[K, V]), true
+}
+
+// Range calls fn in insertion order until it returns false. fn must not
+// modify the map.
+func (m *Map[K, V]) Range(fn func(K, V) bool) {
+	if m.order == nil {
+		return
+	}
+	for elem := m.order.Front(); elem != nil; elem = elem.Next() {
+		pair := elem.Value.(*Pair[K, V])
+		if !fn(pair.Key, pair.Value) {
+			return
+		}
+	}
+}
+
+func (m *Map[K, V]) Keys() []K {
+	keys := make([]K, 0, m.Len())
+	m.Range(func(k K, _ V) bool {
+		keys = append(keys, k)
+		return true
+	})
+	return keys
+}
+
+func (m *Map[K, V]) Values() []V {
+	values := make([]V, 0, m.Len())
+	m.Range(func(_ K, v V) bool {
+		values = append(values, v)
+		return true
+	})
+	return values
+}
+
+func (m *Map[K, V]) Pairs() []Pair[K, V] {
+	pairs := make([]Pair[K, V], 0, m.Len())
+	m.Range(func(k K, v V) bool {
+		pairs = append(pairs, Pair[K, V]{Key: k, Value: v})
+		return true
+	})
+	return pairs
+}
+
+func (m *Map[K, V]) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+
+	var err error
+	first := true
+	m.Range(func(k K, v V) bool {
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+
+		var key, value []byte
+		if key, err = marshalKey(k); err != nil {
+			return false
+		}
+		if value, err = json.Marshal(v); err != nil {
+			err = errors.Wrap(err, "json.Marshal")
+			return false
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (m *Map[K, V]) UnmarshalJSON(data []byte) error {
+	m.items = make(map[K]*list.Element)
+	m.order = list.New()
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return errors.Wrap(err, "json.Decoder.Token")
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return errors.New("orderedmap: expected JSON object")
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return errors.Wrap(err, "json.Decoder.Token")
+		}
+
+		key, err := unmarshalKey[K](tok.(string))
+		if err != nil {
+			return err
+		}
+
+		var value V
+		if err := dec.Decode(&value); err != nil {
+			return errors.Wrap(err, "json.Decoder.Decode")
+		}
+		m.Set(key, value)
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return errors.Wrap(err, "json.Decoder.Token")
+	}
+	return nil
+}
+
+func marshalKey[K comparable](k K) ([]byte, error) {
+	switch key := any(k).(type) {
+	case string:
+		return json.Marshal(key)
+	case encoding.TextMarshaler:
+		text, err := key.MarshalText()
+		if err != nil {
+			return nil, errors.Wrap(err, "MarshalText")
+		}
+		return json.Marshal(string(text))
+	}
+	return json.Marshal(fmt.Sprint(k))
+}
+
+func unmarshalKey[K comparable](raw string) (K, error) {
+	var key K
+	switch target := any(&key).(type) {
+	case *string:
+		*target = raw
+		return key, nil
+	case encoding.TextUnmarshaler:
+		err := target.UnmarshalText([]byte(raw))
+		return key, errors.Wrap(err, "UnmarshalText")
+	}
+
+	// numeric and boolean keys are quoted in JSON objects
+	if err := json.Unmarshal([]byte(raw), &key); err != nil {
+		return key, errors.Wrapf(err, "orderedmap: invalid key %q", raw)
+	}
+	return key, nil
+}
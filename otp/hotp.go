@@ -0,0 +1,83 @@
+package otp
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// HOTP generates counter-based codes as defined by RFC 4226.
+type HOTP struct {
+	secret    string
+	digits    int
+	algorithm Algorithm
+	lookAhead int
+}
+
+func NewHOTP(secret string) *HOTP {
+	return &HOTP{secret: secret, digits: 6, algorithm: SHA1}
+}
+
+func (h *HOTP) Digits(digits int) *HOTP {
+	h.digits = digits
+	return h
+}
+
+func (h *HOTP) Algorithm(algorithm Algorithm) *HOTP {
+	h.algorithm = algorithm
+	return h
+}
+
+// LookAhead accepts codes up to n counters ahead, to resynchronize with
+// tokens that were used without reaching the server.
+func (h *HOTP) LookAhead(n int) *HOTP {
+	h.lookAhead = n
+	return h
+}
+
+func (h *HOTP) Code(counter uint64) (string, error) {
+	if err := checkDigits(h.digits); err != nil {
+		return "", err
+	}
+	key, err := decodeSecret(h.secret)
+	if err != nil {
+		return "", err
+	}
+	return generate(key, counter, h.digits, h.algorithm), nil
+}
+
+// Validate checks code against counter and the look-ahead window. On success
+// it returns the counter to store for the next validation.
+func (h *HOTP) Validate(code string, counter uint64) (bool, uint64, error) {
+	if err := checkDigits(h.digits); err != nil {
+		return false, counter, err
+	}
+	key, err := decodeSecret(h.secret)
+	if err != nil {
+		return false, counter, err
+	}
+
+	for i := 0; i <= h.lookAhead; i++ {
+		if equal(generate(key, counter+uint64(i), h.digits, h.algorithm), code) {
+			return true, counter + uint64(i) + 1, nil
+		}
+	}
+	return false, counter, nil
+}
+
+func (h *HOTP) ProvisioningURI(issuer, account string, counter uint64) string {
+	params := url.Values{}
+	params.Set("secret", h.secret)
+	params.Set("algorithm", string(h.algorithm))
+	params.Set("digits", fmt.Sprint(h.digits))
+	params.Set("counter", fmt.Sprint(counter))
+	return provisioningURI("hotp", issuer, account, params)
+}
+
+func provisioningURI(kind, issuer, account string, params url.Values) string {
+	label := url.PathEscape(account)
+	if issuer != "" {
+		label = url.PathEscape(issuer) + ":" + label
+		params.Set("issuer", issuer)
+	}
+	return "otpauth://" + kind + "/" + label + "?" + params.Encode()
+}
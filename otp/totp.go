@@ -0,0 +1,104 @@
+package otp
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TOTP generates time-based codes as defined by RFC 6238.
+type TOTP struct {
+	secret    string
+	digits    int
+	period    time.Duration
+	algorithm Algorithm
+	window    int
+}
+
+func NewTOTP(secret string) *TOTP {
+	return &TOTP{secret: secret, digits: 6, period: 30 * time.Second, algorithm: SHA1, window: 1}
+}
+
+func (t *TOTP) Digits(digits int) *TOTP {
+	t.digits = digits
+	return t
+}
+
+func (t *TOTP) Period(period time.Duration) *TOTP {
+	t.period = period
+	return t
+}
+
+func (t *TOTP) Algorithm(algorithm Algorithm) *TOTP {
+	t.algorithm = algorithm
+	return t
+}
+
+// Window accepts codes from n periods before or after the current one to
+// tolerate clock drift; the default is 1.
+func (t *TOTP) Window(n int) *TOTP {
+	t.window = n
+	return t
+}
+
+// check rejects settings that cannot produce codes: periods under a
+// second and digit counts outside 6 to 8.
+func (t *TOTP) check() error {
+	if t.period < time.Second {
+		return errors.Errorf("otp: period must be at least 1s, got %s", t.period)
+	}
+	return checkDigits(t.digits)
+}
+
+func (t *TOTP) counter(at time.Time) uint64 {
+	return uint64(at.Unix()) / uint64(t.period/time.Second)
+}
+
+func (t *TOTP) Code(at time.Time) (string, error) {
+	if err := t.check(); err != nil {
+		return "", err
+	}
+	key, err := decodeSecret(t.secret)
+	if err != nil {
+		return "", err
+	}
+	return generate(key, t.counter(at), t.digits, t.algorithm), nil
+}
+
+func (t *TOTP) Now() (string, error) {
+	return t.Code(time.Now())
+}
+
+func (t *TOTP) Validate(code string, at time.Time) (bool, error) {
+	if err := t.check(); err != nil {
+		return false, err
+	}
+	key, err := decodeSecret(t.secret)
+	if err != nil {
+		return false, err
+	}
+
+	counter := t.counter(at)
+	for i := -t.window; i <= t.window; i++ {
+		if int64(counter)+int64(i) < 0 {
+			continue
+		}
+		if equal(generate(key, uint64(int64(counter)+int64(i)), t.digits, t.algorithm), code) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ProvisioningURI returns the otpauth:// URI to be rendered as a QR code by
+// authenticator apps.
+func (t *TOTP) ProvisioningURI(issuer, account string) string {
+	params := url.Values{}
+	params.Set("secret", t.secret)
+	params.Set("algorithm", string(t.algorithm))
+	params.Set("digits", fmt.Sprint(t.digits))
+	params.Set("period", fmt.Sprint(int(t.period/time.Second)))
+	return provisioningURI("totp", issuer, account, params)
+}
@@ -0,0 +1,65 @@
+package phone
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var brAreaCodes = map[string]bool{}
+
+func init() {
+	for _, ddd := range strings.Fields(`11 12 13 14 15 16 17 18 19 21 22 24 27 28
+		31 32 33 34 35 37 38 41 42 43 44 45 46 47 48 49 51 53 54 55
+		61 62 63 64 65 66 67 68 69 71 73 74 75 77 79
+		81 82 83 84 85 86 87 88 89 91 92 93 94 95 96 97 98 99`) {
+		brAreaCodes[ddd] = true
+	}
+}
+
+func ValidAreaCode(ddd string) bool {
+	return brAreaCodes[ddd]
+}
+
+// ParseBR parses a Brazilian number with or without the 55 country code,
+// trunk prefix 0 and carrier selection code (0 XX DDD ...). Legacy 8-digit
+// mobile numbers gain the ninth digit.
+func ParseBR(s string) (*Number, error) {
+	d := digits(s)
+
+	switch {
+	case strings.HasPrefix(d, "55") && (len(d) == 12 || len(d) == 13):
+		d = d[2:]
+	case strings.HasPrefix(d, "0") && (len(d) == 13 || len(d) == 14):
+		// 0 + carrier code + DDD + number
+		d = d[3:]
+	case strings.HasPrefix(d, "0") && (len(d) == 11 || len(d) == 12):
+		d = d[1:]
+	}
+
+	if len(d) != 10 && len(d) != 11 {
+		return nil, errors.Wrap(ErrInvalid, "invalid length")
+	}
+
+	ddd, subscriber := d[:2], d[2:]
+	if !ValidAreaCode(ddd) {
+		return nil, errors.Wrapf(ErrInvalid, "unknown area code %s", ddd)
+	}
+
+	switch {
+	case len(subscriber) == 9 && subscriber[0] == '9':
+		return &Number{CountryCode: "55", AreaCode: ddd, Subscriber: subscriber, Type: Mobile}, nil
+	case len(subscriber) == 8 && subscriber[0] >= '2' && subscriber[0] <= '5':
+		return &Number{CountryCode: "55", AreaCode: ddd, Subscriber: subscriber, Type: Landline}, nil
+	case len(subscriber) == 8 && subscriber[0] >= '6':
+		return &Number{CountryCode: "55", AreaCode: ddd, Subscriber: "9" + subscriber, Type: Mobile}, nil
+	}
+	return nil, errors.Wrap(ErrInvalid, "invalid subscriber number")
+}
+
+func splitSubscriber(s string) string {
+	if len(s) < 8 {
+		return s
+	}
+	return s[:len(s)-4] + "-" + s[len(s)-4:]
+}
@@ -0,0 +1,27 @@
+package phone
+
+import "strings"
+
+// callingCodes covers the most common country calling codes; it is enough
+// to split the country code from the national number for formatting.
+var callingCodes = map[string]bool{}
+
+func init() {
+	for _, cc := range strings.Fields(`1 7 20 27 30 31 32 33 34 36 39 40 41 43 44 45 46 47 48 49
+		51 52 53 54 55 56 57 58 60 61 62 63 64 65 66 81 82 84 86 90 91 92 93 94 95 98
+		212 213 216 218 220 221 225 233 234 244 249 251 254 255 256 258 260 263
+		351 352 353 354 356 357 358 359 370 371 372 380 381 385 386 387 420 421
+		502 503 504 505 506 507 509 591 593 595 597 598
+		852 853 855 856 880 886 960 961 962 963 964 965 966 967 968 970 971 972 973 974 975 976 977`) {
+		callingCodes[cc] = true
+	}
+}
+
+func countryCode(d string) string {
+	for size := 1; size <= 3 && size < len(d); size++ {
+		if callingCodes[d[:size]] {
+			return d[:size]
+		}
+	}
+	return ""
+}
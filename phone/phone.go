@@ -0,0 +1,126 @@
+package phone
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type Type string
+
+const (
+	Mobile   Type = "mobile"
+	Landline Type = "landline"
+	Unknown  Type = "unknown"
+)
+
+var ErrInvalid = errors.New("phone: invalid number")
+
+type Number struct {
+	CountryCode string
+	// AreaCode is the DDD for Brazilian numbers and empty otherwise.
+	AreaCode string
+	// Subscriber is the national number without the area code for Brazilian
+	// numbers, or the whole national significant number otherwise.
+	Subscriber string
+	Type       Type
+}
+
+func (n Number) E164() string {
+	return "+" + n.CountryCode + n.AreaCode + n.Subscriber
+}
+
+func (n Number) String() string {
+	return n.International()
+}
+
+// National formats Brazilian numbers as (11) 98765-4321 and others by
+// grouping digits.
+func (n Number) National() string {
+	if n.CountryCode == "55" {
+		return "(" + n.AreaCode + ") " + splitSubscriber(n.Subscriber)
+	}
+	return group(n.Subscriber)
+}
+
+// International formats as +55 11 98765-4321 or +44 791 112 3456.
+func (n Number) International() string {
+	if n.CountryCode == "55" {
+		return "+55 " + n.AreaCode + " " + splitSubscriber(n.Subscriber)
+	}
+	return "+" + n.CountryCode + " " + group(n.Subscriber)
+}
+
+// Parse accepts numbers in E.164 form or, when they lack a country code, as
+// national numbers of defaultCountry, a calling code such as "55".
+func Parse(s string, defaultCountry string) (*Number, error) {
+	raw := strings.TrimSpace(s)
+	d := digits(raw)
+	if d == "" {
+		return nil, ErrInvalid
+	}
+
+	switch {
+	case strings.HasPrefix(raw, "+"):
+	case strings.HasPrefix(d, "00"):
+		d = d[2:]
+	case defaultCountry == "55":
+		return ParseBR(d)
+	default:
+		d = defaultCountry + strings.TrimLeft(d, "0")
+	}
+
+	if strings.HasPrefix(d, "55") {
+		return ParseBR(d[2:])
+	}
+
+	cc := countryCode(d)
+	if cc == "" {
+		return nil, errors.Wrap(ErrInvalid, "unknown country code")
+	}
+	national := d[len(cc):]
+	if len(national) < 4 || len(d) > 15 {
+		return nil, errors.Wrap(ErrInvalid, "invalid length")
+	}
+	return &Number{CountryCode: cc, Subscriber: national, Type: Unknown}, nil
+}
+
+// Normalize returns the E.164 form of s, assuming Brazilian numbers when no
+// country code is present.
+func Normalize(s string) (string, error) {
+	n, err := Parse(s, "55")
+	if err != nil {
+		return "", err
+	}
+	return n.E164(), nil
+}
+
+func Valid(s string, defaultCountry string) bool {
+	_, err := Parse(s, defaultCountry)
+	return err == nil
+}
+
+func digits(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// group splits a national number into blocks of three or four digits, a
+// best-effort layout for countries without specific rules.
+func group(d string) string {
+	var parts []string
+	for len(d) > 4 {
+		size := 3
+		if len(d) == 8 {
+			size = 4
+		}
+		parts = append(parts, d[:size])
+		d = d[size:]
+	}
+	return strings.Join(append(parts, d), " ")
+}
@@ -0,0 +1,246 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Policy decides how a pipeline reacts to a stage error.
+type Policy int
+
+const (
+	// FailFast cancels the whole pipeline on the first error.
+	FailFast Policy = iota
+	// Collect drops the failing item, keeps processing and reports every
+	// error at the end.
+	Collect
+)
+
+// StageError wraps a failure with the stage and item that caused it.
+type StageError struct {
+	Stage string
+	Item  interface{}
+	Err   error
+}
+
+func (e *StageError) Error() string {
+	return fmt.Sprintf("stage %s: %v", e.Stage, e.Err)
+}
+
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+// Errors aggregates the failures of a pipeline run with the Collect
+// policy.
+type Errors []*StageError
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Stats counts the items through a stage. Busy is the summed time spent
+// in the stage function across workers.
+type Stats struct {
+	Name    string
+	Workers int
+	In      uint64
+	Out     uint64
+	Errors  uint64
+	Busy    time.Duration
+}
+
+type stageStats struct {
+	name    string
+	workers int
+	in      uint64
+	out     uint64
+	errors  uint64
+	busy    int64
+}
+
+// Pipeline owns the shared context, error handling and statistics of a
+// set of connected stages.
+type Pipeline struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	policy Policy
+	wg     sync.WaitGroup
+
+	mu     sync.Mutex
+	first  error
+	errs   Errors
+	stages []*stageStats
+}
+
+func New(ctx context.Context) *Pipeline {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Pipeline{ctx: ctx, cancel: cancel}
+}
+
+func (p *Pipeline) Policy(policy Policy) *Pipeline {
+	p.policy = policy
+	return p
+}
+
+// Context is canceled when the pipeline fails fast or is canceled.
+func (p *Pipeline) Context() context.Context {
+	return p.ctx
+}
+
+func (p *Pipeline) Cancel() {
+	p.cancel()
+}
+
+func (p *Pipeline) fail(err *StageError) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.policy == Collect {
+		p.errs = append(p.errs, err)
+		return
+	}
+	if p.first == nil {
+		p.first = err
+		p.cancel()
+	}
+}
+
+// Wait blocks until every stage has finished and returns the first error
+// (FailFast), the aggregated Errors (Collect), or the context error if the
+// pipeline was canceled from outside.
+func (p *Pipeline) Wait() error {
+	p.wg.Wait()
+	canceled := p.ctx.Err()
+	p.cancel()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch {
+	case p.first != nil:
+		return p.first
+	case len(p.errs) > 0:
+		return p.errs
+	}
+	return canceled
+}
+
+func (p *Pipeline) Stats() []Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	out := make([]Stats, len(p.stages))
+	for i, s := range p.stages {
+		out[i] = Stats{
+			Name:    s.name,
+			Workers: s.workers,
+			In:      atomic.LoadUint64(&s.in),
+			Out:     atomic.LoadUint64(&s.out),
+			Errors:  atomic.LoadUint64(&s.errors),
+			Busy:    time.Duration(atomic.LoadInt64(&s.busy)),
+		}
+	}
+	return out
+}
+
+func (p *Pipeline) register(name string, workers int) *stageStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s := &stageStats{name: name, workers: workers}
+	p.stages = append(p.stages, s)
+	return s
+}
+
+func (p *Pipeline) spawn(f func()) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		f()
+	}()
+}
+
+// Flow is the stream of values between two stages.
+type Flow[T any] struct {
+	p  *Pipeline
+	ch <-chan T
+}
+
+// From feeds items into the pipeline.
+func From[T any](p *Pipeline, items []T) *Flow[T] {
+	ch := make(chan T)
+	p.spawn(func() {
+		defer close(ch)
+		for _, item := range items {
+			select {
+			case ch <- item:
+			case <-p.ctx.Done():
+				return
+			}
+		}
+	})
+	return &Flow[T]{p: p, ch: ch}
+}
+
+// FromChan feeds the values of in into the pipeline until it is closed.
+func FromChan[T any](p *Pipeline, in <-chan T) *Flow[T] {
+	ch := make(chan T)
+	p.spawn(func() {
+		defer close(ch)
+		for {
+			select {
+			case item, ok := <-in:
+				if !ok {
+					return
+				}
+				select {
+				case ch <- item:
+				case <-p.ctx.Done():
+					return
+				}
+			case <-p.ctx.Done():
+				return
+			}
+		}
+	})
+	return &Flow[T]{p: p, ch: ch}
+}
+
+// Chan exposes the flow for custom consumers; they must read it to the
+// end and then call Wait.
+func (f *Flow[T]) Chan() <-chan T {
+	return f.ch
+}
+
+// Collect gathers every output and waits for the pipeline. With workers
+// above one, output order is not preserved.
+func (f *Flow[T]) Collect() ([]T, error) {
+	var out []T
+	for v := range f.ch {
+		out = append(out, v)
+	}
+	return out, f.p.Wait()
+}
+
+// Each calls fn for every output in the caller's goroutine; an error from
+// fn is handled like a stage error named "sink".
+func (f *Flow[T]) Each(fn func(T) error) error {
+	for v := range f.ch {
+		if f.p.ctx.Err() != nil && f.p.policy == FailFast {
+			continue
+		}
+		if err := fn(v); err != nil {
+			f.p.fail(&StageError{Stage: "sink", Item: v, Err: err})
+		}
+	}
+	return f.p.Wait()
+}
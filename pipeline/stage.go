@@ -0,0 +1,119 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Stage transforms values of In into Out with a pool of workers.
+type Stage[In, Out any] struct {
+	name    string
+	fn      func(ctx context.Context, in In) (Out, error)
+	workers int
+	buffer  int
+}
+
+func NewStage[In, Out any](name string, fn func(ctx context.Context, in In) (Out, error)) *Stage[In, Out] {
+	return &Stage[In, Out]{name: name, fn: fn, workers: 1}
+}
+
+// Workers sets how many items the stage processes concurrently.
+func (s *Stage[In, Out]) Workers(n int) *Stage[In, Out] {
+	if n < 1 {
+		n = 1
+	}
+	s.workers = n
+	return s
+}
+
+// Buffer sets how many outputs may wait for the next stage before the
+// workers block.
+func (s *Stage[In, Out]) Buffer(n int) *Stage[In, Out] {
+	s.buffer = n
+	return s
+}
+
+// Through connects in to stage and returns the stage's output flow.
+// Panics inside the stage function are recovered as errors.
+func Through[In, Out any](in *Flow[In], stage *Stage[In, Out]) *Flow[Out] {
+	p := in.p
+	stats := p.register(stage.name, stage.workers)
+	out := make(chan Out, stage.buffer)
+
+	var workers sync.WaitGroup
+	workers.Add(stage.workers)
+	for i := 0; i < stage.workers; i++ {
+		p.spawn(func() {
+			defer workers.Done()
+			for {
+				var item In
+				var ok bool
+				select {
+				case item, ok = <-in.ch:
+				case <-p.ctx.Done():
+					return
+				}
+				if !ok {
+					return
+				}
+				atomic.AddUint64(&stats.in, 1)
+
+				start := time.Now()
+				result, err := call(p.ctx, stage.fn, item)
+				atomic.AddInt64(&stats.busy, int64(time.Since(start)))
+
+				if err != nil {
+					atomic.AddUint64(&stats.errors, 1)
+					p.fail(&StageError{Stage: stage.name, Item: item, Err: err})
+					continue
+				}
+
+				select {
+				case out <- result:
+					atomic.AddUint64(&stats.out, 1)
+				case <-p.ctx.Done():
+					return
+				}
+			}
+		})
+	}
+	p.spawn(func() {
+		workers.Wait()
+		close(out)
+	})
+
+	return &Flow[Out]{p: p, ch: out}
+}
+
+// Filter keeps the values for which keep returns true.
+func Filter[T any](in *Flow[T], keep func(T) bool) *Flow[T] {
+	out := make(chan T)
+	p := in.p
+	p.spawn(func() {
+		defer close(out)
+		for item := range in.ch {
+			if !keep(item) {
+				continue
+			}
+			select {
+			case out <- item:
+			case <-p.ctx.Done():
+				return
+			}
+		}
+	})
+	return &Flow[T]{p: p, ch: out}
+}
+
+func call[In, Out any](ctx context.Context, fn func(context.Context, In) (Out, error), item In) (out Out, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("panic: %v", r)
+		}
+	}()
+	return fn(ctx, item)
+}
@@ -0,0 +1,20 @@
+package pix
+
+import "fmt"
+
+// crc16 implements CRC-16/CCITT-FALSE (polynomial 0x1021, initial value
+// 0xFFFF) as required by the BR Code specification.
+func crc16(data string) string {
+	crc := uint16(0xFFFF)
+	for i := 0; i < len(data); i++ {
+		crc ^= uint16(data[i]) << 8
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return fmt.Sprintf("%04X", crc)
+}
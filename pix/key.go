@@ -0,0 +1,85 @@
+package pix
+
+import (
+	"regexp"
+	"strings"
+	"utils/br"
+
+	"github.com/pkg/errors"
+)
+
+type KeyType string
+
+const (
+	KeyCPF   KeyType = "cpf"
+	KeyCNPJ  KeyType = "cnpj"
+	KeyEmail KeyType = "email"
+	KeyPhone KeyType = "phone"
+	KeyEVP   KeyType = "evp"
+)
+
+var (
+	ErrInvalidKey = errors.New("pix: invalid key")
+
+	evpPattern   = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+	phonePattern = regexp.MustCompile(`^\+55[1-9][0-9]9?[0-9]{8}$`)
+	emailPattern = regexp.MustCompile(`^[a-z0-9.!#$%&'*+/=?^_{|}~-]+@[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?(?:\.[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?)+$`)
+)
+
+// DetectKeyType identifies the kind of a PIX key as registered in DICT:
+// 11-digit CPF, 14-character CNPJ, lowercase email up to 77 characters,
+// phone in +55 E.164 form or a random key (EVP) UUID.
+func DetectKeyType(key string) (KeyType, error) {
+	switch {
+	case evpPattern.MatchString(key):
+		return KeyEVP, nil
+	case phonePattern.MatchString(key):
+		return KeyPhone, nil
+	case strings.Contains(key, "@"):
+		if len(key) <= 77 && emailPattern.MatchString(key) {
+			return KeyEmail, nil
+		}
+	case len(key) == 11 && br.Digits(key) == key:
+		if br.ValidCPF(key) {
+			return KeyCPF, nil
+		}
+	case len(key) == 14 && br.NormalizeCNPJ(key) == key:
+		if br.ValidCNPJ(key) {
+			return KeyCNPJ, nil
+		}
+	}
+	return "", ErrInvalidKey
+}
+
+func ValidKey(key string) bool {
+	_, err := DetectKeyType(key)
+	return err == nil
+}
+
+// NormalizeKey converts common human-entered forms into the DICT format:
+// formatted CPF/CNPJ lose punctuation, emails and EVPs are lowercased and
+// Brazilian phone numbers gain the +55 prefix.
+func NormalizeKey(key string) string {
+	key = strings.TrimSpace(key)
+
+	if strings.Contains(key, "@") {
+		return strings.ToLower(key)
+	}
+	if evpPattern.MatchString(strings.ToLower(key)) {
+		return strings.ToLower(key)
+	}
+	if strings.HasPrefix(key, "+") {
+		return "+" + br.Digits(key)
+	}
+
+	digits := br.Digits(key)
+	switch {
+	case len(digits) == 11 && br.ValidCPF(digits):
+		return digits
+	case len(br.NormalizeCNPJ(key)) == 14 && br.ValidCNPJ(key):
+		return br.NormalizeCNPJ(key)
+	case len(digits) == 10 || len(digits) == 11:
+		return "+55" + digits
+	}
+	return key
+}
@@ -0,0 +1,235 @@
+package pix
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	idPayloadFormat       = "00"
+	idPointOfInitiation   = "01"
+	idMerchantAccount     = "26"
+	idMerchantCategory    = "52"
+	idTransactionCurrency = "53"
+	idTransactionAmount   = "54"
+	idCountryCode         = "58"
+	idMerchantName        = "59"
+	idMerchantCity        = "60"
+	idPostalCode          = "61"
+	idAdditionalData      = "62"
+	idCRC                 = "63"
+
+	idGUI         = "00"
+	idKey         = "01"
+	idDescription = "02"
+	idURL         = "25"
+	idTxID        = "05"
+
+	gui = "br.gov.bcb.pix"
+)
+
+var ErrInvalidCRC = errors.New("pix: invalid CRC")
+
+// Payload holds the fields of a BR Code "copia e cola" string. Amount is in
+// centavos; zero leaves the amount open for the payer to fill in. Static
+// payloads carry a Key, dynamic ones a URL to the PSP location.
+type Payload struct {
+	Key          string
+	Description  string
+	URL          string
+	MerchantName string
+	MerchantCity string
+	PostalCode   string
+	Amount       int64
+	TxID         string
+	Unique       bool
+}
+
+func (p Payload) Validate() error {
+	switch {
+	case p.Key == "" && p.URL == "":
+		return errors.New("pix: key or URL is required")
+	case p.Key != "" && !ValidKey(p.Key):
+		return ErrInvalidKey
+	case p.MerchantName == "" || len(p.MerchantName) > 25:
+		return errors.New("pix: merchant name must have 1 to 25 characters")
+	case p.MerchantCity == "" || len(p.MerchantCity) > 15:
+		return errors.New("pix: merchant city must have 1 to 15 characters")
+	case len(p.TxID) > 25:
+		return errors.New("pix: txid must have at most 25 characters")
+	case strings.Trim(p.TxID, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789") != "" && p.TxID != "***":
+		return errors.New("pix: txid must be alphanumeric")
+	case p.Amount < 0:
+		return errors.New("pix: amount must not be negative")
+	}
+	return nil
+}
+
+// Encode builds the payload string, including its trailing CRC16.
+func (p Payload) Encode() (string, error) {
+	if err := p.Validate(); err != nil {
+		return "", err
+	}
+
+	var account emv
+	account.add(idGUI, gui)
+	if p.Key != "" {
+		account.add(idKey, p.Key)
+	}
+	if p.Description != "" {
+		account.add(idDescription, p.Description)
+	}
+	if p.URL != "" {
+		account.add(idURL, p.URL)
+	}
+
+	txid := p.TxID
+	if txid == "" {
+		txid = "***"
+	}
+	var additional emv
+	additional.add(idTxID, txid)
+
+	var b emv
+	b.add(idPayloadFormat, "01")
+	if p.Unique {
+		b.add(idPointOfInitiation, "12")
+	}
+	b.add(idMerchantAccount, account.String())
+	b.add(idMerchantCategory, "0000")
+	b.add(idTransactionCurrency, "986")
+	if p.Amount > 0 {
+		b.add(idTransactionAmount, fmt.Sprintf("%d.%02d", p.Amount/100, p.Amount%100))
+	}
+	b.add(idCountryCode, "BR")
+	b.add(idMerchantName, p.MerchantName)
+	b.add(idMerchantCity, p.MerchantCity)
+	if p.PostalCode != "" {
+		b.add(idPostalCode, p.PostalCode)
+	}
+	b.add(idAdditionalData, additional.String())
+	for _, f := range []*emv{&account, &additional, &b} {
+		if f.err != nil {
+			return "", f.err
+		}
+	}
+	b.WriteString(idCRC + "04")
+
+	payload := b.String()
+	return payload + crc16(payload), nil
+}
+
+// Parse decodes a payload string, verifying its CRC16.
+func Parse(s string) (*Payload, error) {
+	s = strings.TrimSpace(s)
+	if len(s) < 8 || s[len(s)-8:len(s)-4] != idCRC+"04" {
+		return nil, errors.New("pix: missing CRC field")
+	}
+	if !strings.EqualFold(crc16(s[:len(s)-4]), s[len(s)-4:]) {
+		return nil, ErrInvalidCRC
+	}
+
+	fields, err := parseFields(s[:len(s)-8])
+	if err != nil {
+		return nil, err
+	}
+	if fields[idPayloadFormat] != "01" {
+		return nil, errors.New("pix: unsupported payload format")
+	}
+
+	account, err := parseFields(fields[idMerchantAccount])
+	if err != nil {
+		return nil, errors.Wrap(err, "merchant account")
+	}
+	if !strings.EqualFold(account[idGUI], gui) {
+		return nil, errors.New("pix: merchant account is not a PIX account")
+	}
+
+	p := &Payload{
+		Key:          account[idKey],
+		Description:  account[idDescription],
+		URL:          account[idURL],
+		MerchantName: fields[idMerchantName],
+		MerchantCity: fields[idMerchantCity],
+		PostalCode:   fields[idPostalCode],
+		Unique:       fields[idPointOfInitiation] == "12",
+	}
+
+	if amount := fields[idTransactionAmount]; amount != "" {
+		if p.Amount, err = parseAmount(amount); err != nil {
+			return nil, err
+		}
+	}
+
+	if data := fields[idAdditionalData]; data != "" {
+		additional, err := parseFields(data)
+		if err != nil {
+			return nil, errors.Wrap(err, "additional data")
+		}
+		if txid := additional[idTxID]; txid != "***" {
+			p.TxID = txid
+		}
+	}
+
+	return p, nil
+}
+
+// emv writes EMV fields, remembering the first value too long for the
+// two-digit length.
+type emv struct {
+	strings.Builder
+	err error
+}
+
+func (f *emv) add(id, value string) {
+	if len(value) > 99 && f.err == nil {
+		f.err = errors.Errorf("pix: field %s has %d bytes, at most 99 fit", id, len(value))
+	}
+	fmt.Fprintf(f, "%s%02d%s", id, len(value), value)
+}
+
+func parseFields(s string) (map[string]string, error) {
+	fields := make(map[string]string)
+	for len(s) > 0 {
+		if len(s) < 4 {
+			return nil, errors.New("pix: truncated field header")
+		}
+		size, err := strconv.Atoi(s[2:4])
+		if err != nil {
+			return nil, errors.Errorf("pix: invalid length for field %s", s[:2])
+		}
+		if len(s) < 4+size {
+			return nil, errors.Errorf("pix: truncated field %s", s[:2])
+		}
+		fields[s[:2]] = s[4 : 4+size]
+		s = s[4+size:]
+	}
+	return fields, nil
+}
+
+func parseAmount(s string) (int64, error) {
+	if s == "" || strings.Trim(s, "0123456789.") != "" {
+		return 0, errors.Errorf("pix: invalid amount %q", s)
+	}
+	whole, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, frac = s[:i], s[i+1:]
+	}
+	if len(frac) > 2 {
+		return 0, errors.Errorf("pix: invalid amount %q", s)
+	}
+	frac += strings.Repeat("0", 2-len(frac))
+
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return 0, errors.Errorf("pix: invalid amount %q", s)
+	}
+	cents, err := strconv.ParseInt(frac, 10, 64)
+	if err != nil {
+		return 0, errors.Errorf("pix: invalid amount %q", s)
+	}
+	return units*100 + cents, nil
+}
@@ -0,0 +1,180 @@
+package probabilistic
+
+import (
+	"encoding/binary"
+	"math"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+var ErrIncompatible = errors.New("incompatible filters")
+
+// Bloom is a Bloom filter: Test never returns false for an added item and
+// returns true for a missing one with roughly the configured probability.
+// It is safe for concurrent use.
+type Bloom struct {
+	mu   sync.RWMutex
+	bits []uint64
+	m    uint64
+	k    uint64
+	n    uint64
+}
+
+// NewBloom sizes a filter for expected items at the given false positive
+// rate, e.g. NewBloom(1_000_000, 0.01) takes about 1.2MB.
+func NewBloom(expected uint64, fpr float64) *Bloom {
+	if expected == 0 {
+		expected = 1
+	}
+	if fpr <= 0 || fpr >= 1 {
+		fpr = 0.01
+	}
+	m := uint64(math.Ceil(-float64(expected) * math.Log(fpr) / (math.Ln2 * math.Ln2)))
+	k := uint64(math.Round(float64(m) / float64(expected) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	return newBloom(m, k)
+}
+
+func newBloom(m, k uint64) *Bloom {
+	words := (m + 63) / 64
+	return &Bloom{bits: make([]uint64, words), m: words * 64, k: k}
+}
+
+// locations derives the k bit positions by double hashing.
+func (b *Bloom) locations(data []byte, fn func(i uint64) bool) bool {
+	h := hash64(data)
+	h1, h2 := h&0xffffffff, h>>32|1
+	for i := uint64(0); i < b.k; i++ {
+		if !fn((h1 + i*h2) % b.m) {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *Bloom) Add(data []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.locations(data, func(i uint64) bool {
+		b.bits[i/64] |= 1 << (i % 64)
+		return true
+	})
+	b.n++
+}
+
+func (b *Bloom) AddString(s string) {
+	b.Add([]byte(s))
+}
+
+// Test reports whether data may have been added.
+func (b *Bloom) Test(data []byte) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.locations(data, func(i uint64) bool {
+		return b.bits[i/64]&(1<<(i%64)) != 0
+	})
+}
+
+func (b *Bloom) TestString(s string) bool {
+	return b.Test([]byte(s))
+}
+
+// TestAndAdd reports whether data may have been added before and adds it,
+// the usual step when deduplicating a stream.
+func (b *Bloom) TestAndAdd(data []byte) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	present := true
+	b.locations(data, func(i uint64) bool {
+		mask := uint64(1) << (i % 64)
+		if b.bits[i/64]&mask == 0 {
+			present = false
+			b.bits[i/64] |= mask
+		}
+		return true
+	})
+	b.n++
+	return present
+}
+
+// Count returns the number of Add calls, including duplicates.
+func (b *Bloom) Count() uint64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.n
+}
+
+// EstimatedFPR returns the false positive rate expected at the current
+// fill level.
+func (b *Bloom) EstimatedFPR() float64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return math.Pow(1-math.Exp(-float64(b.k)*float64(b.n)/float64(b.m)), float64(b.k))
+}
+
+// Merge ORs other into b; both must have the same size and hash count.
+func (b *Bloom) Merge(other *Bloom) error {
+	// Copy other under its own lock first, so merging a filter into
+	// itself or two filters into each other cannot deadlock.
+	other.mu.RLock()
+	m, k, n := other.m, other.k, other.n
+	bits := append([]uint64(nil), other.bits...)
+	other.mu.RUnlock()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.m != m || b.k != k {
+		return ErrIncompatible
+	}
+	for i := range b.bits {
+		b.bits[i] |= bits[i]
+	}
+	b.n += n
+	return nil
+}
+
+func (b *Bloom) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.bits = make([]uint64, len(b.bits))
+	b.n = 0
+}
+
+// MarshalBinary encodes the filter as m, k and n followed by the bit set,
+// all little endian.
+func (b *Bloom) MarshalBinary() ([]byte, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	out := make([]byte, 24+8*len(b.bits))
+	binary.LittleEndian.PutUint64(out[0:], b.m)
+	binary.LittleEndian.PutUint64(out[8:], b.k)
+	binary.LittleEndian.PutUint64(out[16:], b.n)
+	for i, word := range b.bits {
+		binary.LittleEndian.PutUint64(out[24+8*i:], word)
+	}
+	return out, nil
+}
+
+func (b *Bloom) UnmarshalBinary(data []byte) error {
+	if len(data) < 24 {
+		return errors.New("bloom: data too short")
+	}
+	m := binary.LittleEndian.Uint64(data[0:])
+	k := binary.LittleEndian.Uint64(data[8:])
+	if m == 0 || m%64 != 0 || k == 0 || uint64(len(data)-24) != m/8 {
+		return errors.New("bloom: invalid header")
+	}
+	bits := make([]uint64, m/64)
+	for i := range bits {
+		bits[i] = binary.LittleEndian.Uint64(data[24+8*i:])
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.m, b.k, b.bits = m, k, bits
+	b.n = binary.LittleEndian.Uint64(data[16:])
+	return nil
+}
@@ -0,0 +1,17 @@
+package probabilistic
+
+import "hash/fnv"
+
+// hash64 returns a well-mixed 64-bit hash of data: FNV-1a followed by the
+// splitmix64 finalizer, which spreads FNV's weak low bits.
+func hash64(data []byte) uint64 {
+	h := fnv.New64a()
+	h.Write(data)
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,128 @@
+package probabilistic
+
+import (
+	"math"
+	"math/bits"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// HyperLogLog estimates the number of distinct items using 2^precision
+// one-byte registers, with a standard error of about 1.04/sqrt(2^precision)
+// (0.8% at the default precision 14, in 16KB). It is safe for concurrent
+// use.
+type HyperLogLog struct {
+	mu        sync.RWMutex
+	precision uint8
+	registers []uint8
+}
+
+const DefaultPrecision = 14
+
+// NewHyperLogLog creates an estimator; precision is clamped to [4, 18].
+func NewHyperLogLog(precision uint8) *HyperLogLog {
+	if precision < 4 {
+		precision = 4
+	}
+	if precision > 18 {
+		precision = 18
+	}
+	return &HyperLogLog{precision: precision, registers: make([]uint8, 1<<precision)}
+}
+
+func (h *HyperLogLog) Add(data []byte) {
+	x := hash64(data)
+	index := x >> (64 - h.precision)
+	rank := uint8(bits.LeadingZeros64(x<<h.precision|1<<(h.precision-1))) + 1
+
+	h.mu.Lock()
+	if rank > h.registers[index] {
+		h.registers[index] = rank
+	}
+	h.mu.Unlock()
+}
+
+func (h *HyperLogLog) AddString(s string) {
+	h.Add([]byte(s))
+}
+
+// Count returns the estimated number of distinct items added.
+func (h *HyperLogLog) Count() uint64 {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	m := float64(len(h.registers))
+	sum := 0.0
+	zeros := 0
+	for _, r := range h.registers {
+		sum += 1 / float64(uint64(1)<<r)
+		if r == 0 {
+			zeros++
+		}
+	}
+
+	var alpha float64
+	switch len(h.registers) {
+	case 16:
+		alpha = 0.673
+	case 32:
+		alpha = 0.697
+	case 64:
+		alpha = 0.709
+	default:
+		alpha = 0.7213 / (1 + 1.079/m)
+	}
+
+	estimate := alpha * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(estimate + 0.5)
+}
+
+// Merge folds other into h so h estimates the union of both streams.
+func (h *HyperLogLog) Merge(other *HyperLogLog) error {
+	// Copy other under its own lock first, so merging a sketch into
+	// itself or two sketches into each other cannot deadlock.
+	other.mu.RLock()
+	precision := other.precision
+	registers := append([]uint8(nil), other.registers...)
+	other.mu.RUnlock()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.precision != precision {
+		return ErrIncompatible
+	}
+	for i, r := range registers {
+		if r > h.registers[i] {
+			h.registers[i] = r
+		}
+	}
+	return nil
+}
+
+func (h *HyperLogLog) Reset() {
+	h.mu.Lock()
+	h.registers = make([]uint8, len(h.registers))
+	h.mu.Unlock()
+}
+
+// MarshalBinary encodes the precision byte followed by the registers.
+func (h *HyperLogLog) MarshalBinary() ([]byte, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return append([]byte{h.precision}, h.registers...), nil
+}
+
+func (h *HyperLogLog) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 || data[0] < 4 || data[0] > 18 || len(data)-1 != 1<<data[0] {
+		return errors.New("hyperloglog: invalid data")
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.precision = data[0]
+	h.registers = append([]uint8(nil), data[1:]...)
+	return nil
+}
@@ -0,0 +1,321 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"utils/clock"
+	"utils/log"
+
+	"github.com/pkg/errors"
+)
+
+// upstream is a target with passive health: after a connect failure it is
+// skipped until the cooldown passes.
+type upstream struct {
+	url       *url.URL
+	downUntil time.Time
+}
+
+// Proxy is a reverse proxy balancing requests across upstreams in round
+// robin. Its zero value is not usable; create it with New.
+type Proxy struct {
+	mu        sync.Mutex
+	upstreams []*upstream
+	next      int
+	cooldown  time.Duration
+
+	attempts   int
+	retryDelay time.Duration
+	maxBuffer  int64
+
+	rewritePath     func(path string) string
+	requestHeaders  map[string]string
+	removeHeaders   []string
+	responseHeaders map[string]string
+
+	transport http.RoundTripper
+	clock     clock.Clock
+	logger    *log.Logger
+	handler   *httputil.ReverseProxy
+}
+
+// New proxies to targets such as "http://localhost:8081"; a target path
+// is prefixed to every request path.
+func New(targets ...string) (*Proxy, error) {
+	if len(targets) == 0 {
+		return nil, errors.New("proxy: no targets")
+	}
+	p := &Proxy{
+		cooldown:        10 * time.Second,
+		maxBuffer:       1 << 20,
+		requestHeaders:  make(map[string]string),
+		responseHeaders: make(map[string]string),
+		transport:       http.DefaultTransport,
+		clock:           clock.Real,
+		logger:          log.Default().Named("proxy"),
+	}
+	for _, target := range targets {
+		u, err := url.Parse(target)
+		if err != nil {
+			return nil, errors.Wrapf(err, "proxy: target %q", target)
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return nil, errors.Errorf("proxy: target %q needs a scheme and host", target)
+		}
+		p.upstreams = append(p.upstreams, &upstream{url: u})
+	}
+	p.handler = &httputil.ReverseProxy{
+		Director:       p.direct,
+		Transport:      roundTripper{p},
+		ModifyResponse: p.modifyResponse,
+		ErrorHandler:   p.handleError,
+	}
+	return p, nil
+}
+
+// Retry retries idempotent requests that could not connect up to attempts
+// more times on the next upstreams, waiting delay in between, like the
+// HTTP client's Retry. Requests with bodies over 1 MiB are not retried.
+func (p *Proxy) Retry(attempts int, delay time.Duration) *Proxy {
+	p.attempts = attempts
+	p.retryDelay = delay
+	return p
+}
+
+// Cooldown is how long an upstream that refused a connection is skipped.
+func (p *Proxy) Cooldown(d time.Duration) *Proxy {
+	p.cooldown = d
+	return p
+}
+
+// StripPrefix removes prefix from request paths, for mounting the proxy
+// under a path such as /api/.
+func (p *Proxy) StripPrefix(prefix string) *Proxy {
+	previous := p.rewritePath
+	return p.RewritePath(func(path string) string {
+		if previous != nil {
+			path = previous(path)
+		}
+		path = strings.TrimPrefix(path, prefix)
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+		return path
+	})
+}
+
+// RewritePath maps request paths before the target path is prefixed.
+func (p *Proxy) RewritePath(rewrite func(path string) string) *Proxy {
+	p.rewritePath = rewrite
+	return p
+}
+
+// SetHeader sets a request header sent upstream.
+func (p *Proxy) SetHeader(name, value string) *Proxy {
+	p.requestHeaders[name] = value
+	return p
+}
+
+// RemoveHeader drops a request header, such as Cookie, before proxying.
+func (p *Proxy) RemoveHeader(name string) *Proxy {
+	p.removeHeaders = append(p.removeHeaders, name)
+	return p
+}
+
+// ResponseHeader sets a header on every proxied response.
+func (p *Proxy) ResponseHeader(name, value string) *Proxy {
+	p.responseHeaders[name] = value
+	return p
+}
+
+func (p *Proxy) Transport(transport http.RoundTripper) *Proxy {
+	p.transport = transport
+	return p
+}
+
+// Clock sets the time source used for cooldowns and retry delays.
+func (p *Proxy) Clock(clk clock.Clock) *Proxy {
+	p.clock = clock.Or(clk)
+	return p
+}
+
+func (p *Proxy) Logger(logger *log.Logger) *Proxy {
+	p.logger = logger
+	return p
+}
+
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.handler.ServeHTTP(w, r)
+}
+
+// pick returns the next upstream in round robin, preferring those not
+// cooling down and skipping exclude.
+func (p *Proxy) pick(exclude map[*upstream]bool) *upstream {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.clock.Now()
+	var fallback *upstream
+	for i := 0; i < len(p.upstreams); i++ {
+		candidate := p.upstreams[(p.next+i)%len(p.upstreams)]
+		if exclude[candidate] {
+			continue
+		}
+		if now.Before(candidate.downUntil) {
+			if fallback == nil {
+				fallback = candidate
+			}
+			continue
+		}
+		p.next = (p.next + i + 1) % len(p.upstreams)
+		return candidate
+	}
+	return fallback
+}
+
+func (p *Proxy) markDown(u *upstream) {
+	p.mu.Lock()
+	u.downUntil = p.clock.Now().Add(p.cooldown)
+	p.mu.Unlock()
+}
+
+func (p *Proxy) direct(r *http.Request) {
+	if p.rewritePath != nil {
+		r.URL.Path = p.rewritePath(r.URL.Path)
+		r.URL.RawPath = ""
+	}
+	if _, ok := r.Header["User-Agent"]; !ok {
+		// Keep net/http from adding its own User-Agent.
+		r.Header.Set("User-Agent", "")
+	}
+	for _, name := range p.removeHeaders {
+		r.Header.Del(name)
+	}
+	for name, value := range p.requestHeaders {
+		r.Header.Set(name, value)
+	}
+	r.Header.Set("X-Forwarded-Host", r.Host)
+	if r.TLS != nil {
+		r.Header.Set("X-Forwarded-Proto", "https")
+	} else {
+		r.Header.Set("X-Forwarded-Proto", "http")
+	}
+}
+
+// target points r at u, joining the upstream path with the request path.
+func target(r *http.Request, u *upstream, path string) {
+	r.URL.Scheme = u.url.Scheme
+	r.URL.Host = u.url.Host
+	r.URL.Path = strings.TrimSuffix(u.url.Path, "/") + path
+	r.Host = u.url.Host
+	if u.url.RawQuery != "" {
+		if r.URL.RawQuery == "" {
+			r.URL.RawQuery = u.url.RawQuery
+		} else {
+			r.URL.RawQuery = u.url.RawQuery + "&" + r.URL.RawQuery
+		}
+	}
+}
+
+func (p *Proxy) modifyResponse(res *http.Response) error {
+	for name, value := range p.responseHeaders {
+		res.Header.Set(name, value)
+	}
+	return nil
+}
+
+func (p *Proxy) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	p.logger.Error("proxy request failed", log.String("method", r.Method), log.String("path", r.URL.Path), log.Err(err))
+	w.WriteHeader(http.StatusBadGateway)
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// connectFailure reports errors raised before the request reached the
+// upstream, which are safe to retry elsewhere.
+func connectFailure(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// roundTripper chooses upstreams and retries connect failures.
+type roundTripper struct {
+	p *Proxy
+}
+
+func (t roundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	p := t.p
+	path := r.URL.Path
+
+	var body []byte
+	retry := p.attempts > 0 && idempotent(r.Method)
+	if retry && r.Body != nil && r.Body != http.NoBody {
+		buffered, err := io.ReadAll(io.LimitReader(r.Body, p.maxBuffer+1))
+		if err != nil {
+			return nil, errors.Wrap(err, "proxy: reading request body")
+		}
+		if int64(len(buffered)) <= p.maxBuffer {
+			r.Body.Close()
+			body = buffered
+		} else {
+			// Too large to replay; send what was read followed by the rest.
+			retry = false
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(buffered), r.Body), r.Body}
+		}
+	}
+
+	tried := make(map[*upstream]bool)
+	for attempt := 0; ; attempt++ {
+		u := p.pick(tried)
+		if u == nil {
+			return nil, errors.New("proxy: no upstream available")
+		}
+		tried[u] = true
+
+		out := r.Clone(r.Context())
+		target(out, u, path)
+		if body != nil {
+			out.Body = io.NopCloser(bytes.NewReader(body))
+			out.ContentLength = int64(len(body))
+		}
+
+		res, err := p.transport.RoundTrip(out)
+		if err == nil || !connectFailure(err) {
+			return res, err
+		}
+		p.markDown(u)
+		p.logger.Warn("upstream unreachable", log.String("upstream", u.url.Host), log.Err(err))
+
+		if !retry || attempt >= p.attempts {
+			return nil, err
+		}
+		if len(tried) == len(p.upstreams) {
+			tried = make(map[*upstream]bool)
+		}
+		select {
+		case <-r.Context().Done():
+			return nil, r.Context().Err()
+		case <-p.clock.After(p.retryDelay):
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package qrcode
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Level is the error correction level, the share of the symbol that can
+// be damaged or covered and still be read: about 7%, 15%, 25% and 30%.
+type Level int
+
+const (
+	Low Level = iota
+	Medium
+	Quartile
+	High
+)
+
+// DefaultLevel is used by PNG and SVG; use Encode for another level.
+const DefaultLevel = Medium
+
+var ErrTooLong = errors.New("qrcode: data too long")
+
+// Code is an encoded QR symbol.
+type Code struct {
+	Version int
+	Level   Level
+	size    int
+	modules []bool
+}
+
+// Size is the width and height in modules, without the quiet zone.
+func (c *Code) Size() int {
+	return c.size
+}
+
+// Black reports whether the module at column x and row y is dark.
+// Coordinates outside the symbol are light, as in the quiet zone.
+func (c *Code) Black(x, y int) bool {
+	if x < 0 || y < 0 || x >= c.size || y >= c.size {
+		return false
+	}
+	return c.modules[y*c.size+x]
+}
+
+// Encode encodes data at the given level.
+func Encode(data string, level Level) (*Code, error) {
+	if level < Low || level > High {
+		return nil, errors.Errorf("qrcode: invalid level %d", level)
+	}
+	m := modeFor(data)
+	for version := 1; version <= 40; version++ {
+		bits := m.length(data, version)
+		capacity := dataCodewords(version, level) * 8
+		if bits > capacity {
+			continue
+		}
+		codewords := m.encode(data, version, capacity)
+		return newCode(version, level, interleave(codewords, version, level)), nil
+	}
+	return nil, errors.Wrapf(ErrTooLong, "%d bytes at level %d", len(data), level)
+}
+
+// mode is a data segment encoding.
+type mode struct {
+	indicator  uint
+	countBits  [3]int // for versions 1-9, 10-26 and 27-40
+	bitsPerRun func(n int) int
+	write      func(b *bitBuffer, data string)
+}
+
+const alphanumeric = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+var (
+	numericMode = mode{
+		indicator: 0x1,
+		countBits: [3]int{10, 12, 14},
+		bitsPerRun: func(n int) int {
+			return n/3*10 + [3]int{0, 4, 7}[n%3]
+		},
+		write: func(b *bitBuffer, data string) {
+			for i := 0; i < len(data); i += 3 {
+				end := i + 3
+				if end > len(data) {
+					end = len(data)
+				}
+				v := 0
+				for _, c := range data[i:end] {
+					v = v*10 + int(c-'0')
+				}
+				b.append(uint(v), (end-i)*3+1)
+			}
+		},
+	}
+
+	alphanumericMode = mode{
+		indicator: 0x2,
+		countBits: [3]int{9, 11, 13},
+		bitsPerRun: func(n int) int {
+			return n/2*11 + n%2*6
+		},
+		write: func(b *bitBuffer, data string) {
+			for i := 0; i+1 < len(data); i += 2 {
+				v := strings.IndexByte(alphanumeric, data[i])*45 + strings.IndexByte(alphanumeric, data[i+1])
+				b.append(uint(v), 11)
+			}
+			if len(data)%2 == 1 {
+				b.append(uint(strings.IndexByte(alphanumeric, data[len(data)-1])), 6)
+			}
+		},
+	}
+
+	byteMode = mode{
+		indicator:  0x4,
+		countBits:  [3]int{8, 16, 16},
+		bitsPerRun: func(n int) int { return n * 8 },
+		write: func(b *bitBuffer, data string) {
+			for i := 0; i < len(data); i++ {
+				b.append(uint(data[i]), 8)
+			}
+		},
+	}
+)
+
+func modeFor(data string) mode {
+	numeric, alnum := true, true
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		if c < '0' || c > '9' {
+			numeric = false
+		}
+		if strings.IndexByte(alphanumeric, c) < 0 {
+			alnum = false
+		}
+	}
+	switch {
+	case numeric:
+		return numericMode
+	case alnum:
+		return alphanumericMode
+	}
+	return byteMode
+}
+
+func (m mode) count(version int) int {
+	switch {
+	case version <= 9:
+		return m.countBits[0]
+	case version <= 26:
+		return m.countBits[1]
+	}
+	return m.countBits[2]
+}
+
+// length is the size in bits of the segment holding data.
+func (m mode) length(data string, version int) int {
+	if len(data) >= 1<<m.count(version) {
+		return 1 << 30
+	}
+	return 4 + m.count(version) + m.bitsPerRun(len(data))
+}
+
+// encode writes the segment, the terminator and the padding up to
+// capacity bits.
+func (m mode) encode(data string, version, capacity int) []byte {
+	b := &bitBuffer{}
+	b.append(m.indicator, 4)
+	b.append(uint(len(data)), m.count(version))
+	m.write(b, data)
+
+	terminator := capacity - b.n
+	if terminator > 4 {
+		terminator = 4
+	}
+	b.append(0, terminator)
+	b.append(0, (8-b.n%8)%8)
+	for pad := byte(0xec); b.n < capacity; pad ^= 0xec ^ 0x11 {
+		b.append(uint(pad), 8)
+	}
+	return b.data
+}
+
+type bitBuffer struct {
+	data []byte
+	n    int
+}
+
+func (b *bitBuffer) append(v uint, bits int) {
+	for i := bits - 1; i >= 0; i-- {
+		if b.n%8 == 0 {
+			b.data = append(b.data, 0)
+		}
+		if v>>uint(i)&1 == 1 {
+			b.data[b.n/8] |= 0x80 >> uint(b.n%8)
+		}
+		b.n++
+	}
+}
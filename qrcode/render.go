@@ -0,0 +1,102 @@
+package qrcode
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// QuietZone is the light margin, in modules, around rendered symbols.
+// Readers need at least four.
+const QuietZone = 4
+
+// PNG encodes data at DefaultLevel as a PNG of size x size pixels.
+func PNG(data string, size int) ([]byte, error) {
+	c, err := Encode(data, DefaultLevel)
+	if err != nil {
+		return nil, err
+	}
+	return c.PNG(size)
+}
+
+// SVG encodes data at DefaultLevel as a scalable SVG document.
+func SVG(data string) (string, error) {
+	c, err := Encode(data, DefaultLevel)
+	if err != nil {
+		return "", err
+	}
+	return c.SVG(), nil
+}
+
+// Image renders the symbol with its quiet zone, scale pixels per module.
+func (c *Code) Image(scale int) *image.Paletted {
+	if scale < 1 {
+		scale = 1
+	}
+	return c.image(scale, (c.size+2*QuietZone)*scale)
+}
+
+// image draws the symbol centered in a side x side image.
+func (c *Code) image(scale, side int) *image.Paletted {
+	m := image.NewPaletted(image.Rect(0, 0, side, side), color.Palette{color.White, color.Black})
+	offset := (side - c.size*scale) / 2
+	for y := 0; y < c.size; y++ {
+		for x := 0; x < c.size; x++ {
+			if !c.Black(x, y) {
+				continue
+			}
+			for dy := 0; dy < scale; dy++ {
+				row := m.Pix[(offset+y*scale+dy)*m.Stride:]
+				for dx := 0; dx < scale; dx++ {
+					row[offset+x*scale+dx] = 1
+				}
+			}
+		}
+	}
+	return m
+}
+
+// PNG renders the symbol as a size x size PNG, with modules of a whole
+// number of pixels so they stay sharp. When size is too small for one
+// pixel per module and the quiet zone, the image is as large as needed.
+func (c *Code) PNG(size int) ([]byte, error) {
+	side := c.size + 2*QuietZone
+	scale := size / side
+	if scale < 1 {
+		scale, size = 1, side
+	}
+	var buf bytes.Buffer
+	e := png.Encoder{CompressionLevel: png.BestCompression}
+	if err := e.Encode(&buf, c.image(scale, size)); err != nil {
+		return nil, errors.Wrap(err, "png.Encode")
+	}
+	return buf.Bytes(), nil
+}
+
+// SVG renders the symbol in module units, one path of horizontal runs,
+// to be sized by the page with width and height or CSS.
+func (c *Code) SVG() string {
+	side := c.size + 2*QuietZone
+	var path strings.Builder
+	for y := 0; y < c.size; y++ {
+		for x := 0; x < c.size; {
+			if !c.Black(x, y) {
+				x++
+				continue
+			}
+			run := 1
+			for c.Black(x+run, y) {
+				run++
+			}
+			fmt.Fprintf(&path, "M%d %dh%dv1h-%dz", x+QuietZone, y+QuietZone, run, run)
+			x += run
+		}
+	}
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+
+		`<rect width="100%%" height="100%%" fill="#fff"/><path fill="#000" d="%s"/></svg>`, side, side, path.String())
+}
@@ -0,0 +1,194 @@
+package random
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"math/big"
+	mathrand "math/rand"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	Lowercase    = "abcdefghijklmnopqrstuvwxyz"
+	Uppercase    = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	Digits       = "0123456789"
+	Symbols      = "!@#$%^&*()-_=+[]{};:,.?/"
+	Hex          = "0123456789abcdef"
+	Alphanumeric = Lowercase + Uppercase + Digits
+
+	// Ambiguous characters are easy to confuse when read or typed.
+	Ambiguous = "Il1O0o"
+)
+
+func Bytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return nil, errors.Wrap(err, "rand.Read")
+	}
+	return b, nil
+}
+
+// Int returns a uniform crypto-random integer in [0, max).
+func Int(max int) (int, error) {
+	if max <= 0 {
+		return 0, errors.New("random: max must be positive")
+	}
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(max)))
+	if err != nil {
+		return 0, errors.Wrap(err, "rand.Int")
+	}
+	return int(n.Int64()), nil
+}
+
+// String returns n characters drawn uniformly from alphabet using
+// crypto/rand; an empty alphabet means Alphanumeric.
+func String(n int, alphabet string) (string, error) {
+	if alphabet == "" {
+		alphabet = Alphanumeric
+	}
+	runes := []rune(alphabet)
+
+	var b strings.Builder
+	b.Grow(n)
+	for i := 0; i < n; i++ {
+		idx, err := Int(len(runes))
+		if err != nil {
+			return "", err
+		}
+		b.WriteRune(runes[idx])
+	}
+	return b.String(), nil
+}
+
+func MustString(n int, alphabet string) string {
+	s, err := String(n, alphabet)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+type PasswordPolicy struct {
+	Length           int
+	Lower            bool
+	Upper            bool
+	Digits           bool
+	Symbols          bool
+	ExcludeAmbiguous bool
+}
+
+var DefaultPasswordPolicy = PasswordPolicy{
+	Length:           16,
+	Lower:            true,
+	Upper:            true,
+	Digits:           true,
+	Symbols:          true,
+	ExcludeAmbiguous: true,
+}
+
+// Password generates a password containing at least one character of every
+// enabled class.
+func Password(policy PasswordPolicy) (string, error) {
+	var classes []string
+	for _, c := range []struct {
+		enabled bool
+		chars   string
+	}{
+		{policy.Lower, Lowercase},
+		{policy.Upper, Uppercase},
+		{policy.Digits, Digits},
+		{policy.Symbols, Symbols},
+	} {
+		if !c.enabled {
+			continue
+		}
+		chars := c.chars
+		if policy.ExcludeAmbiguous {
+			chars = strings.Map(func(r rune) rune {
+				if strings.ContainsRune(Ambiguous, r) {
+					return -1
+				}
+				return r
+			}, chars)
+		}
+		classes = append(classes, chars)
+	}
+
+	if len(classes) == 0 {
+		return "", errors.New("random: password policy enables no character class")
+	}
+	if policy.Length < len(classes) {
+		return "", errors.Errorf("random: password length must be at least %d", len(classes))
+	}
+
+	out := make([]byte, 0, policy.Length)
+	for _, chars := range classes {
+		s, err := String(1, chars)
+		if err != nil {
+			return "", err
+		}
+		out = append(out, s...)
+	}
+
+	rest, err := String(policy.Length-len(out), strings.Join(classes, ""))
+	if err != nil {
+		return "", err
+	}
+	out = append(out, rest...)
+
+	for i := len(out) - 1; i > 0; i-- {
+		j, err := Int(i + 1)
+		if err != nil {
+			return "", err
+		}
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out), nil
+}
+
+// NewSource returns a math/rand generator seeded from crypto/rand, for
+// non-cryptographic uses where the sequence should not repeat across runs.
+func NewSource() *mathrand.Rand {
+	var seed [8]byte
+	if _, err := rand.Read(seed[:]); err != nil {
+		panic(err)
+	}
+	return mathrand.New(mathrand.NewSource(int64(binary.LittleEndian.Uint64(seed[:]))))
+}
+
+// NewSeeded returns a deterministic generator, useful in tests.
+func NewSeeded(seed int64) *mathrand.Rand {
+	return mathrand.New(mathrand.NewSource(seed))
+}
+
+// Pick returns a random element using r; it is not suitable for secrets.
+func Pick[T any](r *mathrand.Rand, items []T) (T, bool) {
+	var zero T
+	if len(items) == 0 {
+		return zero, false
+	}
+	return items[r.Intn(len(items))], true
+}
+
+// Shuffle permutes items in place using r.
+func Shuffle[T any](r *mathrand.Rand, items []T) {
+	r.Shuffle(len(items), func(i, j int) {
+		items[i], items[j] = items[j], items[i]
+	})
+}
+
+// Sample returns k distinct elements in random order without modifying items.
+func Sample[T any](r *mathrand.Rand, items []T, k int) []T {
+	if k > len(items) {
+		k = len(items)
+	}
+	idx := r.Perm(len(items))[:k]
+	out := make([]T, k)
+	for i, j := range idx {
+		out[i] = items[j]
+	}
+	return out
+}
@@ -0,0 +1,109 @@
+package random
+
+import (
+	"math"
+	mathrand "math/rand"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// Chooser picks items with probability proportional to their weights. Build
+// one with NewChooser when picking repeatedly from the same set, e.g. A/B
+// routing; each Pick is O(log n).
+type Chooser[T any] struct {
+	items      []T
+	cumulative []float64
+	total      float64
+}
+
+func NewChooser[T any](items []T, weights []float64) (*Chooser[T], error) {
+	if len(items) != len(weights) {
+		return nil, errors.Errorf("random: %d items but %d weights", len(items), len(weights))
+	}
+	c := &Chooser[T]{items: items, cumulative: make([]float64, len(weights))}
+	for i, w := range weights {
+		if w < 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+			return nil, errors.Errorf("random: invalid weight %v at index %d", w, i)
+		}
+		c.total += w
+		c.cumulative[i] = c.total
+	}
+	if c.total == 0 {
+		return nil, errors.New("random: weights sum to zero")
+	}
+	return c, nil
+}
+
+// Pick returns a weighted random item using r; a nil r uses NewSource.
+func (c *Chooser[T]) Pick(r *mathrand.Rand) T {
+	if r == nil {
+		r = NewSource()
+	}
+	target := r.Float64() * c.total
+	i := sort.Search(len(c.cumulative), func(i int) bool { return c.cumulative[i] > target })
+	if i == len(c.items) {
+		i--
+	}
+	return c.items[i]
+}
+
+// Weighted picks one item with probability proportional to its weight.
+func Weighted[T any](r *mathrand.Rand, items []T, weights []float64) (T, error) {
+	c, err := NewChooser(items, weights)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return c.Pick(r), nil
+}
+
+// ReservoirSampler keeps a uniform random sample of k items from a stream
+// of unknown length (Algorithm R) in O(k) memory.
+type ReservoirSampler[T any] struct {
+	r     *mathrand.Rand
+	k     int
+	seen  int
+	items []T
+}
+
+// NewReservoir samples k items using r; a nil r uses NewSource.
+func NewReservoir[T any](r *mathrand.Rand, k int) *ReservoirSampler[T] {
+	if r == nil {
+		r = NewSource()
+	}
+	if k < 0 {
+		k = 0
+	}
+	return &ReservoirSampler[T]{r: r, k: k, items: make([]T, 0, k)}
+}
+
+func (s *ReservoirSampler[T]) Add(item T) {
+	s.seen++
+	if len(s.items) < s.k {
+		s.items = append(s.items, item)
+		return
+	}
+	if j := s.r.Intn(s.seen); j < s.k {
+		s.items[j] = item
+	}
+}
+
+// Seen returns how many items were offered.
+func (s *ReservoirSampler[T]) Seen() int {
+	return s.seen
+}
+
+// Items returns the current sample.
+func (s *ReservoirSampler[T]) Items() []T {
+	return append([]T(nil), s.items...)
+}
+
+// Reservoir drains stream and returns a uniform sample of up to k items.
+func Reservoir[T any](r *mathrand.Rand, stream <-chan T, k int) []T {
+	s := NewReservoir[T](r, k)
+	for item := range stream {
+		s.Add(item)
+	}
+	return s.items
+}
@@ -0,0 +1,81 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type keyedLimiter struct {
+	limiter  Limiter
+	lastSeen time.Time
+}
+
+// PerKeyLimiter keeps an independent limiter per key, such as a tenant or a
+// host, created on first use by the given factory.
+type PerKeyLimiter struct {
+	mu        sync.Mutex
+	factory   func() Limiter
+	limiters  map[string]*keyedLimiter
+	idle      time.Duration
+	lastSweep time.Time
+}
+
+func NewPerKeyLimiter(factory func() Limiter) *PerKeyLimiter {
+	return &PerKeyLimiter{
+		factory:   factory,
+		limiters:  make(map[string]*keyedLimiter),
+		lastSweep: time.Now(),
+	}
+}
+
+// IdleTimeout drops limiters that were not used for the given duration.
+func (p *PerKeyLimiter) IdleTimeout(idle time.Duration) *PerKeyLimiter {
+	p.mu.Lock()
+	p.idle = idle
+	p.mu.Unlock()
+	return p
+}
+
+func (p *PerKeyLimiter) Allow(key string) bool {
+	return p.get(key).Allow()
+}
+
+func (p *PerKeyLimiter) Wait(ctx context.Context, key string) error {
+	return p.get(key).Wait(ctx)
+}
+
+func (p *PerKeyLimiter) Remove(key string) {
+	p.mu.Lock()
+	delete(p.limiters, key)
+	p.mu.Unlock()
+}
+
+func (p *PerKeyLimiter) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.limiters)
+}
+
+func (p *PerKeyLimiter) get(key string) Limiter {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	if p.idle > 0 && now.Sub(p.lastSweep) >= p.idle {
+		for k, l := range p.limiters {
+			if now.Sub(l.lastSeen) >= p.idle {
+				delete(p.limiters, k)
+			}
+		}
+		p.lastSweep = now
+	}
+
+	l, ok := p.limiters[key]
+	if !ok {
+		l = &keyedLimiter{limiter: p.factory()}
+		p.limiters[key] = l
+	}
+	l.lastSeen = now
+	return l.limiter
+}
@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SlidingWindow allows limit events in any trailing window. It keeps counts
+// for the current and previous fixed windows and weights the previous one by
+// how much of it still overlaps the sliding window.
+type SlidingWindow struct {
+	mu       sync.Mutex
+	limit    int
+	window   time.Duration
+	start    time.Time
+	current  int
+	previous int
+}
+
+func NewSlidingWindow(limit int, window time.Duration) *SlidingWindow {
+	return &SlidingWindow{
+		limit:  limit,
+		window: window,
+		start:  time.Now().Truncate(window),
+	}
+}
+
+func (w *SlidingWindow) Allow() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	ok, _ := w.take(time.Now())
+	return ok
+}
+
+func (w *SlidingWindow) Wait(ctx context.Context) error {
+	for {
+		w.mu.Lock()
+		ok, retry := w.take(time.Now())
+		w.mu.Unlock()
+
+		if ok {
+			return nil
+		}
+		if err := sleep(ctx, retry); err != nil {
+			return err
+		}
+	}
+}
+
+func (w *SlidingWindow) take(now time.Time) (bool, time.Duration) {
+	w.advance(now)
+
+	elapsed := now.Sub(w.start)
+	weight := 1 - float64(elapsed)/float64(w.window)
+	estimate := float64(w.previous)*weight + float64(w.current)
+
+	if estimate+1 <= float64(w.limit) {
+		w.current++
+		return true, 0
+	}
+
+	if w.previous == 0 {
+		return false, w.window - elapsed
+	}
+
+	// time until enough of the previous window slides out
+	excess := estimate + 1 - float64(w.limit)
+	retry := time.Duration(excess / float64(w.previous) * float64(w.window))
+	if retry <= 0 || retry > w.window-elapsed {
+		retry = w.window - elapsed
+	}
+	return false, retry
+}
+
+func (w *SlidingWindow) advance(now time.Time) {
+	start := now.Truncate(w.window)
+	if !start.After(w.start) {
+		return
+	}
+
+	if start.Sub(w.start) == w.window {
+		w.previous = w.current
+	} else {
+		w.previous = 0
+	}
+	w.current = 0
+	w.start = start
+}
@@ -0,0 +1,109 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TokenBucket refills at rate tokens per second up to burst tokens.
+type TokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &TokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Every builds a bucket allowing one event per interval.
+func Every(interval time.Duration, burst int) *TokenBucket {
+	return NewTokenBucket(float64(time.Second)/float64(interval), burst)
+}
+
+func (b *TokenBucket) Allow() bool {
+	return b.AllowN(1)
+}
+
+func (b *TokenBucket) AllowN(n int) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(time.Now())
+	if b.tokens < float64(n) {
+		return false
+	}
+	b.tokens -= float64(n)
+	return true
+}
+
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	return b.WaitN(ctx, 1)
+}
+
+// WaitN reserves n tokens and blocks until they are available. The
+// reservation is returned to the bucket if ctx ends first.
+func (b *TokenBucket) WaitN(ctx context.Context, n int) error {
+	if float64(n) > b.burst {
+		return errors.Errorf("ratelimit: %d tokens exceeds burst of %v", n, b.burst)
+	}
+
+	b.mu.Lock()
+	now := time.Now()
+	b.refill(now)
+	b.tokens -= float64(n)
+
+	var delay time.Duration
+	if b.tokens < 0 {
+		if b.rate <= 0 {
+			b.tokens += float64(n)
+			b.mu.Unlock()
+			return errors.New("ratelimit: bucket has zero rate")
+		}
+		delay = time.Duration(math.Ceil(-b.tokens / b.rate * float64(time.Second)))
+	}
+	b.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+
+	if err := sleep(ctx, delay); err != nil {
+		b.mu.Lock()
+		b.tokens += float64(n)
+		b.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (b *TokenBucket) Tokens() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(time.Now())
+	return b.tokens
+}
+
+func (b *TokenBucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last)
+	if elapsed <= 0 {
+		return
+	}
+	b.last = now
+	b.tokens = math.Min(b.burst, b.tokens+elapsed.Seconds()*b.rate)
+}
@@ -0,0 +1,100 @@
+package redisutil
+
+import (
+	"context"
+	"time"
+	"utils/log"
+	"utils/ratelimit"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+// limitScript counts a hit in a fixed window, returning the count so far
+// and the milliseconds until the window resets.
+var limitScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+local ttl = redis.call("PTTL", KEYS[1])
+if ttl < 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+	ttl = tonumber(ARGV[1])
+end
+return {count, ttl}`)
+
+// Limiter allows limit events per fixed window across every process
+// sharing the Redis server. It implements ratelimit.Limiter, so it can be
+// passed to the HTTP client's RateLimit.
+type Limiter struct {
+	client  *Client
+	key     string
+	limit   int
+	window  time.Duration
+	timeout time.Duration
+	logger  *log.Logger
+}
+
+var _ ratelimit.Limiter = (*Limiter)(nil)
+
+func (c *Client) Limiter(key string, limit int, window time.Duration) *Limiter {
+	return &Limiter{
+		client:  c,
+		key:     c.key(key),
+		limit:   limit,
+		window:  window,
+		timeout: time.Second,
+		logger:  log.Default().Named("redisutil"),
+	}
+}
+
+// Timeout bounds the Redis call made by Allow, which has no context.
+func (l *Limiter) Timeout(d time.Duration) *Limiter {
+	l.timeout = d
+	return l
+}
+
+// Take counts one event, reporting whether it is within the limit and how
+// long until the window resets.
+func (l *Limiter) Take(ctx context.Context) (bool, time.Duration, error) {
+	res, err := limitScript.Run(ctx, l.client.rdb, []string{l.key}, l.window.Milliseconds()).Int64Slice()
+	if err != nil {
+		return false, 0, errors.Wrap(err, "redisutil: rate limit")
+	}
+	if len(res) != 2 {
+		return false, 0, errors.Errorf("redisutil: unexpected rate limit reply %v", res)
+	}
+	return res[0] <= int64(l.limit), time.Duration(res[1]) * time.Millisecond, nil
+}
+
+// Allow reports whether an event may happen now. It fails open, allowing
+// the event, when Redis cannot be reached.
+func (l *Limiter) Allow() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), l.timeout)
+	defer cancel()
+
+	ok, _, err := l.Take(ctx)
+	if err != nil {
+		l.logger.Warn("rate limit unavailable, allowing", log.String("key", l.key), log.Err(err))
+		return true
+	}
+	return ok
+}
+
+// Wait blocks until an event is allowed or ctx is done.
+func (l *Limiter) Wait(ctx context.Context) error {
+	for {
+		ok, reset, err := l.Take(ctx)
+		if err != nil || ok {
+			return err
+		}
+		timer := time.NewTimer(reset)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package redisutil
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// ErrLocked is returned by TryLock when another owner holds the lock.
+	ErrLocked = errors.New("redisutil: lock is held by another owner")
+	// ErrLockLost is returned when the lock expired or was taken over
+	// before Release or Refresh.
+	ErrLockLost = errors.New("redisutil: lock no longer held")
+)
+
+// The token check keeps an owner whose lock expired from deleting or
+// extending the lock of the next owner.
+var (
+	releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+	refreshScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+)
+
+// Lock is a held distributed lock. It expires after its ttl unless
+// refreshed, so a crashed owner never blocks others for good.
+type Lock struct {
+	client *Client
+	key    string
+	token  string
+}
+
+// TryLock acquires key with SET NX PX, failing with ErrLocked when it is
+// already held.
+func (c *Client) TryLock(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	var raw [16]byte
+	if _, err := rand.Read(raw[:]); err != nil {
+		return nil, errors.Wrap(err, "rand.Read")
+	}
+	token := hex.EncodeToString(raw[:])
+
+	ok, err := c.rdb.SetNX(ctx, c.key(key), token, ttl).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "redis SET NX")
+	}
+	if !ok {
+		return nil, ErrLocked
+	}
+	return &Lock{client: c, key: c.key(key), token: token}, nil
+}
+
+// Lock retries TryLock every retry interval until it succeeds or ctx is
+// done.
+func (c *Client) Lock(ctx context.Context, key string, ttl, retry time.Duration) (*Lock, error) {
+	ticker := time.NewTicker(retry)
+	defer ticker.Stop()
+
+	for {
+		lock, err := c.TryLock(ctx, key, ttl)
+		if err != ErrLocked {
+			return lock, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, errors.Wrap(ctx.Err(), "redisutil: waiting for lock")
+		case <-ticker.C:
+		}
+	}
+}
+
+// Release frees the lock if this owner still holds it.
+func (l *Lock) Release(ctx context.Context) error {
+	n, err := releaseScript.Run(ctx, l.client.rdb, []string{l.key}, l.token).Int()
+	if err != nil {
+		return errors.Wrap(err, "redisutil: release lock")
+	}
+	if n == 0 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+// Refresh extends the lock to expire ttl from now, for work that outlives
+// the original ttl.
+func (l *Lock) Refresh(ctx context.Context, ttl time.Duration) error {
+	n, err := refreshScript.Run(ctx, l.client.rdb, []string{l.key}, l.token, ttl.Milliseconds()).Int()
+	if err != nil {
+		return errors.Wrap(err, "redisutil: refresh lock")
+	}
+	if n == 0 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+// WithLock runs fn while holding key, releasing it afterwards.
+func (c *Client) WithLock(ctx context.Context, key string, ttl time.Duration, fn func(ctx context.Context) error) error {
+	lock, err := c.TryLock(ctx, key, ttl)
+	if err != nil {
+		return err
+	}
+	defer lock.Release(ctx)
+	return fn(ctx)
+}
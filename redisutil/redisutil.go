@@ -0,0 +1,109 @@
+package redisutil
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+	"utils/cache"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+// Client adds typed values, locks and rate limiting on top of a go-redis
+// client, namespacing every key with an optional prefix.
+type Client struct {
+	rdb    redis.UniversalClient
+	prefix string
+}
+
+var _ cache.Backend = (*Client)(nil)
+
+// New wraps rdb, which may be a *redis.Client, *redis.ClusterClient or
+// *redis.Ring.
+func New(rdb redis.UniversalClient) *Client {
+	return &Client{rdb: rdb}
+}
+
+// Prefix is prepended to every key, such as "myapp:".
+func (c *Client) Prefix(prefix string) *Client {
+	c.prefix = prefix
+	return c
+}
+
+// Redis returns the wrapped client for commands not covered here.
+func (c *Client) Redis() redis.UniversalClient {
+	return c.rdb
+}
+
+func (c *Client) key(key string) string {
+	return c.prefix + key
+}
+
+// Get returns the raw value of key; found is false when it does not exist.
+func (c *Client) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := c.rdb.Get(ctx, c.key(key)).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, errors.Wrap(err, "redis GET")
+	}
+	return value, true, nil
+}
+
+// Set stores value under key; a zero ttl keeps it until deleted.
+func (c *Client) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return errors.Wrap(c.rdb.Set(ctx, c.key(key), value, ttl).Err(), "redis SET")
+}
+
+func (c *Client) Delete(ctx context.Context, key string) error {
+	return errors.Wrap(c.rdb.Del(ctx, c.key(key)).Err(), "redis DEL")
+}
+
+// TTL returns the remaining time to live of key, zero when it has no
+// expiration and found false when it does not exist.
+func (c *Client) TTL(ctx context.Context, key string) (time.Duration, bool, error) {
+	ttl, err := c.rdb.PTTL(ctx, c.key(key)).Result()
+	if err != nil {
+		return 0, false, errors.Wrap(err, "redis PTTL")
+	}
+	// go-redis reports -2 for a missing key and -1 for no expiration.
+	switch ttl {
+	case -2:
+		return 0, false, nil
+	case -1:
+		return 0, true, nil
+	}
+	return ttl, true, nil
+}
+
+// GetJSON decodes the value of key into dst.
+func (c *Client) GetJSON(ctx context.Context, key string, dst interface{}) (bool, error) {
+	raw, found, err := c.Get(ctx, key)
+	if err != nil || !found {
+		return found, err
+	}
+	return true, errors.Wrap(json.Unmarshal(raw, dst), "json.Unmarshal")
+}
+
+// SetJSON stores value encoded as JSON.
+func (c *Client) SetJSON(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return errors.Wrap(err, "json.Marshal")
+	}
+	return c.Set(ctx, key, raw, ttl)
+}
+
+// Get is the typed form of Client.GetJSON.
+func Get[T any](ctx context.Context, c *Client, key string) (T, bool, error) {
+	var value T
+	found, err := c.GetJSON(ctx, key, &value)
+	return value, found, err
+}
+
+// Set is the typed form of Client.SetJSON.
+func Set[T any](ctx context.Context, c *Client, key string, value T, ttl time.Duration) error {
+	return c.SetJSON(ctx, key, value, ttl)
+}
@@ -0,0 +1,82 @@
+package requestid
+
+import (
+	"context"
+	"net/http"
+	"utils/id"
+	"utils/log"
+)
+
+// DefaultHeader carries request IDs between services.
+const DefaultHeader = "X-Request-ID"
+
+type contextKey struct{}
+
+// New generates a request ID. UUIDv7 sorts by creation time, which keeps
+// IDs of nearby requests close together in logs and indexes.
+func New() string {
+	return id.MustV7().String()
+}
+
+// NewContext returns a copy of ctx carrying requestID.
+func NewContext(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, contextKey{}, requestID)
+}
+
+// FromContext returns the request ID in ctx, or "" when there is none.
+func FromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(contextKey{}).(string)
+	return requestID
+}
+
+// Valid rejects IDs received from clients that are empty, too long or
+// contain characters that could forge log lines.
+func Valid(s string) bool {
+	if s == "" || len(s) > 128 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x21 || s[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// Logger returns logger with a request_id field when ctx carries an ID.
+func Logger(ctx context.Context, logger *log.Logger) *log.Logger {
+	if requestID := FromContext(ctx); requestID != "" {
+		return logger.With(log.String("request_id", requestID))
+	}
+	return logger
+}
+
+type transport struct {
+	base   http.RoundTripper
+	header string
+}
+
+// Transport forwards the request ID in each request's context in header
+// (DefaultHeader when empty), for http.Clients other than the package's
+// own client, which forwards it by itself. Requests that already set the
+// header are left alone.
+func Transport(base http.RoundTripper, header string) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if header == "" {
+		header = DefaultHeader
+	}
+	return &transport{base: base, header: header}
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestID := FromContext(req.Context())
+	if requestID == "" || req.Header.Get(t.header) != "" {
+		return t.base.RoundTrip(req)
+	}
+	// A RoundTripper must not modify the caller's request.
+	req = req.Clone(req.Context())
+	req.Header.Set(t.header, requestID)
+	return t.base.RoundTrip(req)
+}
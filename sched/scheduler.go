@@ -0,0 +1,327 @@
+package sched
+
+import (
+	"context"
+	mathrand "math/rand"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+	"utils/log"
+	"utils/random"
+
+	"github.com/pkg/errors"
+)
+
+// Overlap decides what happens when a job is due while its previous run is
+// still going.
+type Overlap int
+
+const (
+	// Skip drops the activation (the default).
+	Skip Overlap = iota
+	// Allow starts another run concurrently.
+	Allow
+	// Delay runs jobs back to back: the next activation is computed only
+	// after the current run finishes.
+	Delay
+)
+
+type JobFunc func(ctx context.Context) error
+
+// Job is a registered task. Its setters may be chained right after
+// registration, before the scheduler starts.
+type Job struct {
+	name     string
+	schedule Schedule
+	fn       JobFunc
+	overlap  Overlap
+	jitter   time.Duration
+	timeout  time.Duration
+
+	mu       sync.Mutex
+	running  int
+	runs     int
+	lastRun  time.Time
+	lastTook time.Duration
+	lastErr  error
+	nextRun  time.Time
+}
+
+func (j *Job) Overlap(policy Overlap) *Job {
+	j.overlap = policy
+	return j
+}
+
+// Jitter delays each activation by a random amount up to max, spreading
+// load when many instances share a schedule.
+func (j *Job) Jitter(max time.Duration) *Job {
+	j.jitter = max
+	return j
+}
+
+// Timeout cancels the context of a run that takes longer than timeout.
+func (j *Job) Timeout(timeout time.Duration) *Job {
+	j.timeout = timeout
+	return j
+}
+
+// JobInfo is a snapshot of a job's state.
+type JobInfo struct {
+	Name         string
+	Running      bool
+	Runs         int
+	LastRun      time.Time
+	LastDuration time.Duration
+	LastErr      error
+	NextRun      time.Time
+}
+
+func (j *Job) Info() JobInfo {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return JobInfo{
+		Name:         j.name,
+		Running:      j.running > 0,
+		Runs:         j.runs,
+		LastRun:      j.lastRun,
+		LastDuration: j.lastTook,
+		LastErr:      j.lastErr,
+		NextRun:      j.nextRun,
+	}
+}
+
+// Scheduler runs jobs on cron or interval schedules until stopped. Panics
+// in jobs are recovered and recorded as the run's error.
+type Scheduler struct {
+	location *time.Location
+	logger   *log.Logger
+
+	mu      sync.Mutex
+	jobs    map[string]*Job
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	started bool
+}
+
+func NewScheduler() *Scheduler {
+	return &Scheduler{
+		location: time.Local,
+		logger:   log.Default().Named("sched"),
+		jobs:     make(map[string]*Job),
+	}
+}
+
+// Location sets the time zone cron expressions are evaluated in.
+func (s *Scheduler) Location(location *time.Location) *Scheduler {
+	s.location = location
+	return s
+}
+
+func (s *Scheduler) Logger(logger *log.Logger) *Scheduler {
+	s.logger = logger
+	return s
+}
+
+// Cron registers fn under name with a cron expression (see ParseCron).
+func (s *Scheduler) Cron(name, expr string, fn JobFunc) (*Job, error) {
+	schedule, err := ParseCron(expr)
+	if err != nil {
+		return nil, err
+	}
+	return s.Schedule(name, schedule, fn)
+}
+
+// Every registers fn under name to run at a fixed interval.
+func (s *Scheduler) Every(name string, interval time.Duration, fn JobFunc) (*Job, error) {
+	if interval <= 0 {
+		return nil, errors.Errorf("sched: invalid interval %s for job %q", interval, name)
+	}
+	return s.Schedule(name, Every(interval), fn)
+}
+
+// Schedule registers fn under name with any Schedule. Jobs added after
+// Start begin immediately.
+func (s *Scheduler) Schedule(name string, schedule Schedule, fn JobFunc) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.jobs[name]; ok {
+		return nil, errors.Errorf("sched: job %q already registered", name)
+	}
+	job := &Job{name: name, schedule: schedule, fn: fn}
+	s.jobs[name] = job
+	if s.started {
+		s.launch(job)
+	}
+	return job, nil
+}
+
+// Remove unregisters a job. A run in progress is allowed to finish.
+func (s *Scheduler) Remove(name string) {
+	s.mu.Lock()
+	delete(s.jobs, name)
+	s.mu.Unlock()
+}
+
+// Jobs returns a snapshot of every job, sorted by name.
+func (s *Scheduler) Jobs() []JobInfo {
+	s.mu.Lock()
+	jobs := make([]*Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job)
+	}
+	s.mu.Unlock()
+
+	out := make([]JobInfo, len(jobs))
+	for i, job := range jobs {
+		out[i] = job.Info()
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Start runs the registered jobs in the background until ctx is done or
+// Stop is called.
+func (s *Scheduler) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return errors.New("sched: scheduler already started")
+	}
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	s.started = true
+	for _, job := range s.jobs {
+		s.launch(job)
+	}
+	return nil
+}
+
+// Stop cancels the jobs' context and waits for running jobs to return.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	s.wg.Wait()
+
+	s.mu.Lock()
+	s.started = false
+	s.mu.Unlock()
+}
+
+// launch starts the loop of a job; s.mu must be held.
+func (s *Scheduler) launch(job *Job) {
+	ctx := s.ctx
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.loop(ctx, job)
+	}()
+}
+
+func (s *Scheduler) registered(job *Job) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.jobs[job.name] == job
+}
+
+func (s *Scheduler) loop(ctx context.Context, job *Job) {
+	var rng *mathrand.Rand
+	if job.jitter > 0 {
+		rng = random.NewSource()
+	}
+
+	last := time.Now().In(s.location)
+	for {
+		next := job.schedule.Next(last)
+		if next.IsZero() {
+			return
+		}
+		// Jitter only delays the wake-up; the schedule keeps advancing from
+		// the unjittered time so the delays do not accumulate.
+		fire := next
+		if job.jitter > 0 {
+			fire = fire.Add(time.Duration(rng.Int63n(int64(job.jitter))))
+		}
+
+		job.mu.Lock()
+		job.nextRun = fire
+		job.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(fire))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if !s.registered(job) {
+			return
+		}
+		last = next
+
+		// The check and the increment share the lock so that a run started
+		// here is counted before the next tick looks at it.
+		job.mu.Lock()
+		skip := job.running > 0 && job.overlap == Skip
+		if !skip {
+			job.running++
+		}
+		job.mu.Unlock()
+
+		switch {
+		case skip:
+			s.logger.Warn("skipping overlapping run", log.String("job", job.name))
+		case job.overlap == Delay:
+			s.run(ctx, job)
+			last = time.Now().In(s.location)
+		default:
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				s.run(ctx, job)
+			}()
+		}
+	}
+}
+
+// run calls the job, which the caller has already counted in
+// job.running.
+func (s *Scheduler) run(ctx context.Context, job *Job) {
+	if job.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, job.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := call(ctx, job.fn)
+	took := time.Since(start)
+
+	job.mu.Lock()
+	job.running--
+	job.runs++
+	job.lastRun = start
+	job.lastTook = took
+	job.lastErr = err
+	job.mu.Unlock()
+
+	if err != nil {
+		s.logger.Error("job failed", log.String("job", job.name), log.Duration("elapsed", took), log.Err(err))
+	}
+}
+
+func call(ctx context.Context, fn JobFunc) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+	return fn(ctx)
+}
@@ -0,0 +1,140 @@
+package set
+
+import "encoding/json"
+
+// Set is not safe for concurrent use, just like the map it is built on.
+type Set[T comparable] map[T]struct{}
+
+func New[T comparable](items ...T) Set[T] {
+	s := make(Set[T], len(items))
+	s.Add(items...)
+	return s
+}
+
+func (s Set[T]) Add(items ...T) {
+	for _, item := range items {
+		s[item] = struct{}{}
+	}
+}
+
+func (s Set[T]) Remove(items ...T) {
+	for _, item := range items {
+		delete(s, item)
+	}
+}
+
+func (s Set[T]) Contains(item T) bool {
+	_, ok := s[item]
+	return ok
+}
+
+func (s Set[T]) ContainsAll(items ...T) bool {
+	for _, item := range items {
+		if !s.Contains(item) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s Set[T]) Len() int {
+	return len(s)
+}
+
+func (s Set[T]) Clear() {
+	for item := range s {
+		delete(s, item)
+	}
+}
+
+func (s Set[T]) Clone() Set[T] {
+	out := make(Set[T], len(s))
+	for item := range s {
+		out[item] = struct{}{}
+	}
+	return out
+}
+
+func (s Set[T]) Union(other Set[T]) Set[T] {
+	out := s.Clone()
+	for item := range other {
+		out[item] = struct{}{}
+	}
+	return out
+}
+
+func (s Set[T]) Intersection(other Set[T]) Set[T] {
+	small, large := s, other
+	if len(large) < len(small) {
+		small, large = large, small
+	}
+
+	out := make(Set[T])
+	for item := range small {
+		if large.Contains(item) {
+			out[item] = struct{}{}
+		}
+	}
+	return out
+}
+
+func (s Set[T]) Difference(other Set[T]) Set[T] {
+	out := make(Set[T])
+	for item := range s {
+		if !other.Contains(item) {
+			out[item] = struct{}{}
+		}
+	}
+	return out
+}
+
+func (s Set[T]) SymmetricDifference(other Set[T]) Set[T] {
+	return s.Difference(other).Union(other.Difference(s))
+}
+
+func (s Set[T]) IsSubset(other Set[T]) bool {
+	if len(s) > len(other) {
+		return false
+	}
+	for item := range s {
+		if !other.Contains(item) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s Set[T]) Equal(other Set[T]) bool {
+	return len(s) == len(other) && s.IsSubset(other)
+}
+
+// Each calls fn for every item until it returns false. Order is unspecified.
+func (s Set[T]) Each(fn func(T) bool) {
+	for item := range s {
+		if !fn(item) {
+			return
+		}
+	}
+}
+
+// Items returns the elements in unspecified order.
+func (s Set[T]) Items() []T {
+	items := make([]T, 0, len(s))
+	for item := range s {
+		items = append(items, item)
+	}
+	return items
+}
+
+func (s Set[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Items())
+}
+
+func (s *Set[T]) UnmarshalJSON(data []byte) error {
+	var items []T
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+	*s = New(items...)
+	return nil
+}
@@ -0,0 +1,137 @@
+package sliceutil
+
+func Map[T, U any](items []T, fn func(T) U) []U {
+	out := make([]U, len(items))
+	for i, item := range items {
+		out[i] = fn(item)
+	}
+	return out
+}
+
+func Filter[T any](items []T, keep func(T) bool) []T {
+	out := make([]T, 0, len(items))
+	for _, item := range items {
+		if keep(item) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func Reduce[T, A any](items []T, initial A, fn func(A, T) A) A {
+	acc := initial
+	for _, item := range items {
+		acc = fn(acc, item)
+	}
+	return acc
+}
+
+// Chunk splits items into slices of at most size elements. The chunks share
+// the backing array of items.
+func Chunk[T any](items []T, size int) [][]T {
+	if size < 1 {
+		size = 1
+	}
+
+	chunks := make([][]T, 0, (len(items)+size-1)/size)
+	for start := 0; start < len(items); start += size {
+		end := start + size
+		if end > len(items) {
+			end = len(items)
+		}
+		chunks = append(chunks, items[start:end:end])
+	}
+	return chunks
+}
+
+// Unique keeps the first occurrence of each element, preserving order.
+func Unique[T comparable](items []T) []T {
+	return UniqueBy(items, func(item T) T { return item })
+}
+
+func UniqueBy[T any, K comparable](items []T, key func(T) K) []T {
+	seen := make(map[K]struct{}, len(items))
+	out := make([]T, 0, len(items))
+	for _, item := range items {
+		k := key(item)
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = struct{}{}
+		out = append(out, item)
+	}
+	return out
+}
+
+func GroupBy[T any, K comparable](items []T, key func(T) K) map[K][]T {
+	groups := make(map[K][]T)
+	for _, item := range items {
+		k := key(item)
+		groups[k] = append(groups[k], item)
+	}
+	return groups
+}
+
+func Partition[T any](items []T, pred func(T) bool) (matched []T, rest []T) {
+	for _, item := range items {
+		if pred(item) {
+			matched = append(matched, item)
+		} else {
+			rest = append(rest, item)
+		}
+	}
+	return matched, rest
+}
+
+// Difference returns the elements of a that are not in b.
+func Difference[T comparable](a, b []T) []T {
+	exclude := toSet(b)
+	return Filter(a, func(item T) bool {
+		_, ok := exclude[item]
+		return !ok
+	})
+}
+
+// Intersect returns the unique elements of a that are also in b, in the
+// order they appear in a.
+func Intersect[T comparable](a, b []T) []T {
+	include := toSet(b)
+	return Unique(Filter(a, func(item T) bool {
+		_, ok := include[item]
+		return ok
+	}))
+}
+
+func Contains[T comparable](items []T, target T) bool {
+	return IndexOf(items, target) >= 0
+}
+
+func IndexOf[T comparable](items []T, target T) int {
+	for i, item := range items {
+		if item == target {
+			return i
+		}
+	}
+	return -1
+}
+
+func Flatten[T any](groups [][]T) []T {
+	total := 0
+	for _, group := range groups {
+		total += len(group)
+	}
+
+	out := make([]T, 0, total)
+	for _, group := range groups {
+		out = append(out, group...)
+	}
+	return out
+}
+
+func toSet[T comparable](items []T) map[T]struct{} {
+	set := make(map[T]struct{}, len(items))
+	for _, item := range items {
+		set[item] = struct{}{}
+	}
+	return set
+}
@@ -0,0 +1,94 @@
+package sqlqueue
+
+import (
+	"context"
+	"sync"
+	"utils/log"
+
+	"github.com/pkg/errors"
+)
+
+// Handler processes one message; returning an error, or panicking, makes
+// the message be retried.
+type Handler func(ctx context.Context, msg *Message) error
+
+// Consume runs Workers loops that dequeue and handle messages until ctx
+// is done, acknowledging successes and nacking failures. It returns nil
+// on cancellation and the first database error otherwise.
+func (q *Queue) Consume(ctx context.Context, handler Handler) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+	for i := 0; i < q.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := q.work(ctx, handler); err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
+
+func (q *Queue) work(ctx context.Context, handler Handler) error {
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		msg, err := q.Dequeue(ctx)
+		if errors.Is(err, ErrEmpty) {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-q.clock.After(q.pollInterval):
+			}
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		if err := q.handle(ctx, handler, msg); err != nil {
+			q.logger.Warn("message failed", log.Int64("id", msg.ID), log.Int("attempts", msg.Attempts), log.Err(err))
+			// Record the failure even if ctx was cancelled meanwhile.
+			if err := q.Nack(context.Background(), msg, err); err != nil && !q.redelivered(msg, err) {
+				return err
+			}
+			continue
+		}
+		if err := q.Ack(context.Background(), msg); err != nil && !q.redelivered(msg, err) {
+			return err
+		}
+	}
+}
+
+func (q *Queue) handle(ctx context.Context, handler Handler, msg *Message) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("sqlqueue: panic in handler: %v", r)
+		}
+	}()
+	return handler(ctx, msg)
+}
+
+// redelivered logs and reports an ErrRedelivered, which leaves the message
+// to its newer delivery rather than stopping the worker.
+func (q *Queue) redelivered(msg *Message, err error) bool {
+	if !errors.Is(err, ErrRedelivered) {
+		return false
+	}
+	q.logger.Warn("message redelivered before it was settled", log.Int64("id", msg.ID), log.Int("attempts", msg.Attempts))
+	return true
+}
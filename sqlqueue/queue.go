@@ -0,0 +1,349 @@
+package sqlqueue
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"time"
+	"utils/clock"
+	"utils/log"
+
+	"github.com/pkg/errors"
+)
+
+// ErrEmpty is returned by Dequeue when no message is visible.
+var ErrEmpty = errors.New("sqlqueue: queue is empty")
+
+// ErrRedelivered is returned by Ack and Nack when the visibility timeout
+// of the delivery passed and the message was dequeued again: the later
+// delivery owns it now.
+var ErrRedelivered = errors.New("sqlqueue: message was redelivered")
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Message is a dequeued message. It stays invisible to other consumers
+// until the visibility timeout passes, and is delivered again unless
+// acknowledged, which makes delivery at-least-once.
+type Message struct {
+	ID         int64
+	Payload    []byte
+	Attempts   int
+	EnqueuedAt time.Time
+	LastError  string
+}
+
+// DeadLetter is a message that failed MaxAttempts times.
+type DeadLetter struct {
+	Message
+	FailedAt time.Time
+}
+
+// Queue is a durable FIFO queue stored in two SQLite tables: name for
+// pending messages and name_dead for dead letters. It works with any
+// database/sql SQLite driver (SQLite 3.35+ for RETURNING); registering the
+// driver is up to the caller.
+type Queue struct {
+	db           *sql.DB
+	name         string
+	visibility   time.Duration
+	maxAttempts  int
+	backoff      func(attempt int) time.Duration
+	pollInterval time.Duration
+	workers      int
+	clock        clock.Clock
+	logger       *log.Logger
+}
+
+// NewQueue uses table name in db; call Init once to create the tables.
+func NewQueue(db *sql.DB, name string) (*Queue, error) {
+	if !identifier.MatchString(name) {
+		return nil, errors.Errorf("sqlqueue: invalid queue name %q", name)
+	}
+	return &Queue{
+		db:           db,
+		name:         name,
+		visibility:   30 * time.Second,
+		maxAttempts:  5,
+		backoff:      ExponentialBackoff(time.Second, 5*time.Minute),
+		pollInterval: time.Second,
+		workers:      1,
+		clock:        clock.Real,
+		logger:       log.Default().Named("sqlqueue"),
+	}, nil
+}
+
+// VisibilityTimeout is how long a dequeued message stays hidden before it
+// is delivered again; it should exceed the longest handler run.
+func (q *Queue) VisibilityTimeout(d time.Duration) *Queue {
+	q.visibility = d
+	return q
+}
+
+// MaxAttempts moves a message to the dead-letter table after this many
+// failed deliveries.
+func (q *Queue) MaxAttempts(n int) *Queue {
+	q.maxAttempts = n
+	return q
+}
+
+// Backoff sets the delay before a failed message is retried, given the
+// attempts made so far.
+func (q *Queue) Backoff(backoff func(attempt int) time.Duration) *Queue {
+	q.backoff = backoff
+	return q
+}
+
+// PollInterval is how long Consume waits when the queue is empty.
+func (q *Queue) PollInterval(d time.Duration) *Queue {
+	q.pollInterval = d
+	return q
+}
+
+// Workers sets how many messages Consume handles concurrently.
+func (q *Queue) Workers(n int) *Queue {
+	if n < 1 {
+		n = 1
+	}
+	q.workers = n
+	return q
+}
+
+func (q *Queue) Clock(clk clock.Clock) *Queue {
+	q.clock = clock.Or(clk)
+	return q
+}
+
+func (q *Queue) Logger(logger *log.Logger) *Queue {
+	q.logger = logger
+	return q
+}
+
+// ExponentialBackoff doubles the delay from base for each attempt, capped
+// at max.
+func ExponentialBackoff(base, max time.Duration) func(attempt int) time.Duration {
+	return func(attempt int) time.Duration {
+		d := base
+		for i := 1; i < attempt && d < max; i++ {
+			d *= 2
+		}
+		if d > max {
+			d = max
+		}
+		return d
+	}
+}
+
+// Init creates the queue tables and index if they don't exist.
+func (q *Queue) Init(ctx context.Context) error {
+	statements := []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			payload BLOB NOT NULL,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			visible_at INTEGER NOT NULL,
+			enqueued_at INTEGER NOT NULL,
+			last_error TEXT NOT NULL DEFAULT ''
+		)`, q.name),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s_visible ON %s (visible_at, id)`, q.name, q.name),
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s_dead (
+			id INTEGER PRIMARY KEY,
+			payload BLOB NOT NULL,
+			attempts INTEGER NOT NULL,
+			enqueued_at INTEGER NOT NULL,
+			failed_at INTEGER NOT NULL,
+			last_error TEXT NOT NULL
+		)`, q.name),
+	}
+	for _, stmt := range statements {
+		if _, err := q.db.ExecContext(ctx, stmt); err != nil {
+			return errors.Wrap(err, "db.ExecContext")
+		}
+	}
+	return nil
+}
+
+func (q *Queue) Enqueue(ctx context.Context, payload []byte) (int64, error) {
+	return q.EnqueueDelayed(ctx, payload, 0)
+}
+
+// EnqueueDelayed adds a message that becomes visible after delay.
+func (q *Queue) EnqueueDelayed(ctx context.Context, payload []byte, delay time.Duration) (int64, error) {
+	now := q.clock.Now()
+	res, err := q.db.ExecContext(ctx,
+		fmt.Sprintf(`INSERT INTO %s (payload, visible_at, enqueued_at) VALUES (?, ?, ?)`, q.name),
+		payload, now.Add(delay).UnixNano(), now.UnixNano())
+	if err != nil {
+		return 0, errors.Wrap(err, "db.ExecContext")
+	}
+	id, err := res.LastInsertId()
+	return id, errors.Wrap(err, "res.LastInsertId")
+}
+
+// Dequeue claims the oldest visible message, hiding it for the
+// visibility timeout, or returns ErrEmpty. The claim is a single UPDATE,
+// so concurrent consumers never receive the same delivery. A message
+// whose last delivery was never settled, because the consumer crashed or
+// its visibility timeout passed, is moved to the dead-letter table instead
+// once it used up MaxAttempts.
+func (q *Queue) Dequeue(ctx context.Context) (*Message, error) {
+	for {
+		msg, err := q.claim(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if q.maxAttempts <= 0 || msg.Attempts <= q.maxAttempts {
+			return msg, nil
+		}
+		reason := "visibility timeout expired"
+		if msg.LastError != "" {
+			reason += "; last error: " + msg.LastError
+		}
+		if err := q.bury(ctx, msg, msg.Attempts-1, reason, q.clock.Now()); err != nil && !errors.Is(err, ErrRedelivered) {
+			return nil, err
+		}
+	}
+}
+
+func (q *Queue) claim(ctx context.Context) (*Message, error) {
+	now := q.clock.Now()
+	row := q.db.QueryRowContext(ctx, fmt.Sprintf(`UPDATE %[1]s
+		SET visible_at = ?, attempts = attempts + 1
+		WHERE id = (SELECT id FROM %[1]s WHERE visible_at <= ? ORDER BY id LIMIT 1)
+		RETURNING id, payload, attempts, enqueued_at, last_error`, q.name),
+		now.Add(q.visibility).UnixNano(), now.UnixNano())
+
+	var msg Message
+	var enqueuedAt int64
+	if err := row.Scan(&msg.ID, &msg.Payload, &msg.Attempts, &enqueuedAt, &msg.LastError); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrEmpty
+		}
+		return nil, errors.Wrap(err, "row.Scan")
+	}
+	msg.EnqueuedAt = time.Unix(0, enqueuedAt)
+	return &msg, nil
+}
+
+// Ack removes a successfully processed message. Acks and Nacks are fenced
+// on the delivery: they fail with ErrRedelivered once the message has
+// been dequeued again.
+func (q *Queue) Ack(ctx context.Context, msg *Message) error {
+	res, err := q.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE id = ? AND attempts = ?`, q.name),
+		msg.ID, msg.Attempts)
+	if err != nil {
+		return errors.Wrap(err, "db.ExecContext")
+	}
+	return fenced(res, msg)
+}
+
+// fenced reports ErrRedelivered when a statement fenced on msg.Attempts
+// matched no row.
+func fenced(res sql.Result, msg *Message) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "result.RowsAffected")
+	}
+	if n == 0 {
+		return errors.Wrapf(ErrRedelivered, "message %d attempt %d", msg.ID, msg.Attempts)
+	}
+	return nil
+}
+
+// Nack records a failed delivery: the message is retried after the
+// backoff, or moved to the dead-letter table once it reached MaxAttempts.
+func (q *Queue) Nack(ctx context.Context, msg *Message, cause error) error {
+	reason := ""
+	if cause != nil {
+		reason = cause.Error()
+	}
+	now := q.clock.Now()
+
+	if q.maxAttempts > 0 && msg.Attempts >= q.maxAttempts {
+		return q.bury(ctx, msg, msg.Attempts, reason, now)
+	}
+	res, err := q.db.ExecContext(ctx,
+		fmt.Sprintf(`UPDATE %s SET visible_at = ?, last_error = ? WHERE id = ? AND attempts = ?`, q.name),
+		now.Add(q.backoff(msg.Attempts)).UnixNano(), reason, msg.ID, msg.Attempts)
+	if err != nil {
+		return errors.Wrap(err, "db.ExecContext")
+	}
+	return fenced(res, msg)
+}
+
+// bury moves the delivery msg to the dead-letter table, recording attempts
+// as the number of deliveries made.
+func (q *Queue) bury(ctx context.Context, msg *Message, attempts int, reason string, now time.Time) error {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "db.BeginTx")
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE id = ? AND attempts = ?`, q.name),
+		msg.ID, msg.Attempts)
+	if err != nil {
+		return errors.Wrap(err, "tx.ExecContext")
+	}
+	if err := fenced(res, msg); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`INSERT OR REPLACE INTO %s_dead
+		(id, payload, attempts, enqueued_at, failed_at, last_error) VALUES (?, ?, ?, ?, ?, ?)`, q.name),
+		msg.ID, msg.Payload, attempts, msg.EnqueuedAt.UnixNano(), now.UnixNano(), reason); err != nil {
+		return errors.Wrap(err, "tx.ExecContext")
+	}
+	return errors.Wrap(tx.Commit(), "tx.Commit")
+}
+
+// Len returns the number of pending messages, visible or not.
+func (q *Queue) Len(ctx context.Context) (int, error) {
+	var n int
+	err := q.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM %s`, q.name)).Scan(&n)
+	return n, errors.Wrap(err, "row.Scan")
+}
+
+// DeadLetters returns up to limit dead letters, oldest failure first.
+func (q *Queue) DeadLetters(ctx context.Context, limit int) ([]DeadLetter, error) {
+	rows, err := q.db.QueryContext(ctx, fmt.Sprintf(`SELECT id, payload, attempts, enqueued_at, failed_at, last_error
+		FROM %s_dead ORDER BY failed_at, id LIMIT ?`, q.name), limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "db.QueryContext")
+	}
+	defer rows.Close()
+
+	var out []DeadLetter
+	for rows.Next() {
+		var d DeadLetter
+		var enqueuedAt, failedAt int64
+		if err := rows.Scan(&d.ID, &d.Payload, &d.Attempts, &enqueuedAt, &failedAt, &d.LastError); err != nil {
+			return nil, errors.Wrap(err, "rows.Scan")
+		}
+		d.EnqueuedAt, d.FailedAt = time.Unix(0, enqueuedAt), time.Unix(0, failedAt)
+		out = append(out, d)
+	}
+	return out, errors.Wrap(rows.Err(), "rows.Err")
+}
+
+// Requeue moves a dead letter back to the queue with its attempts reset.
+func (q *Queue) Requeue(ctx context.Context, id int64) error {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "db.BeginTx")
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, fmt.Sprintf(`INSERT INTO %[1]s (id, payload, attempts, visible_at, enqueued_at, last_error)
+		SELECT id, payload, 0, ?, enqueued_at, last_error FROM %[1]s_dead WHERE id = ?`, q.name),
+		q.clock.Now().UnixNano(), id)
+	if err != nil {
+		return errors.Wrap(err, "tx.ExecContext")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.Errorf("sqlqueue: dead letter %d not found", id)
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s_dead WHERE id = ?`, q.name), id); err != nil {
+		return errors.Wrap(err, "tx.ExecContext")
+	}
+	return errors.Wrap(tx.Commit(), "tx.Commit")
+}
@@ -0,0 +1,151 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+func Sum[T Number](xs []T) float64 {
+	var sum float64
+	for _, x := range xs {
+		sum += float64(x)
+	}
+	return sum
+}
+
+// Mean returns the arithmetic mean, or zero for an empty slice.
+func Mean[T Number](xs []T) float64 {
+	if len(xs) == 0 {
+		return 0
+	}
+	return Sum(xs) / float64(len(xs))
+}
+
+// Variance returns the population variance.
+func Variance[T Number](xs []T) float64 {
+	if len(xs) == 0 {
+		return 0
+	}
+	mean := Mean(xs)
+	var sum float64
+	for _, x := range xs {
+		d := float64(x) - mean
+		sum += d * d
+	}
+	return sum / float64(len(xs))
+}
+
+// StdDev returns the population standard deviation.
+func StdDev[T Number](xs []T) float64 {
+	return math.Sqrt(Variance(xs))
+}
+
+func Median[T Number](xs []T) float64 {
+	return Percentile(xs, 50)
+}
+
+// Percentile returns the p-th percentile, p in [0, 100], interpolating
+// linearly between the closest ranks. xs is not modified.
+func Percentile[T Number](xs []T, p float64) float64 {
+	return percentile(sorted(xs), p)
+}
+
+// MinMax returns the smallest and largest values; ok is false for an
+// empty slice.
+func MinMax[T Number](xs []T) (min, max T, ok bool) {
+	if len(xs) == 0 {
+		return min, max, false
+	}
+	min, max = xs[0], xs[0]
+	for _, x := range xs[1:] {
+		if x < min {
+			min = x
+		}
+		if x > max {
+			max = x
+		}
+	}
+	return min, max, true
+}
+
+func sorted[T Number](xs []T) []float64 {
+	out := make([]float64, len(xs))
+	for i, x := range xs {
+		out[i] = float64(x)
+	}
+	sort.Float64s(out)
+	return out
+}
+
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	if p <= 0 {
+		return sorted[0]
+	}
+	if p >= 100 {
+		return sorted[len(sorted)-1]
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(rank)
+	if lower+1 >= len(sorted) {
+		return sorted[lower]
+	}
+	return sorted[lower] + (rank-float64(lower))*(sorted[lower+1]-sorted[lower])
+}
+
+type Summary struct {
+	Count  int
+	Min    float64
+	Max    float64
+	Mean   float64
+	StdDev float64
+	P50    float64
+	P90    float64
+	P95    float64
+	P99    float64
+}
+
+// Summarize computes a Summary sorting xs only once.
+func Summarize[T Number](xs []T) Summary {
+	values := sorted(xs)
+	if len(values) == 0 {
+		return Summary{}
+	}
+	return Summary{
+		Count:  len(values),
+		Min:    values[0],
+		Max:    values[len(values)-1],
+		Mean:   Mean(values),
+		StdDev: StdDev(values),
+		P50:    percentile(values, 50),
+		P90:    percentile(values, 90),
+		P95:    percentile(values, 95),
+		P99:    percentile(values, 99),
+	}
+}
+
+// String renders the summary on one line, e.g.
+// "count=100 min=1 max=100 mean=50.5 stddev=28.87 p50=50.5 p90=90.1 p95=95.05 p99=99.01".
+func (s Summary) String() string {
+	parts := []string{fmt.Sprintf("count=%d", s.Count)}
+	for _, field := range []struct {
+		name  string
+		value float64
+	}{
+		{"min", s.Min}, {"max", s.Max}, {"mean", s.Mean}, {"stddev", s.StdDev},
+		{"p50", s.P50}, {"p90", s.P90}, {"p95", s.P95}, {"p99", s.P99},
+	} {
+		parts = append(parts, fmt.Sprintf("%s=%.4g", field.name, field.value))
+	}
+	return strings.Join(parts, " ")
+}
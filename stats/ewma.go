@@ -0,0 +1,52 @@
+package stats
+
+import "sync"
+
+// EWMA is an exponentially weighted moving average. It is safe for
+// concurrent use.
+type EWMA struct {
+	mu    sync.Mutex
+	alpha float64
+	value float64
+	init  bool
+}
+
+// NewEWMA creates an average where each update weighs alpha, in (0, 1].
+func NewEWMA(alpha float64) *EWMA {
+	if alpha <= 0 || alpha > 1 {
+		alpha = 0.1
+	}
+	return &EWMA{alpha: alpha}
+}
+
+// NewEWMASamples creates an average roughly equivalent to a simple moving
+// average over the last n samples.
+func NewEWMASamples(n int) *EWMA {
+	if n < 1 {
+		n = 1
+	}
+	return NewEWMA(2 / (float64(n) + 1))
+}
+
+// Update adds a sample; the first sample seeds the average.
+func (e *EWMA) Update(v float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.init {
+		e.value, e.init = v, true
+		return
+	}
+	e.value += e.alpha * (v - e.value)
+}
+
+func (e *EWMA) Value() float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.value
+}
+
+func (e *EWMA) Reset() {
+	e.mu.Lock()
+	e.value, e.init = 0, false
+	e.mu.Unlock()
+}
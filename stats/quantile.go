@@ -0,0 +1,125 @@
+package stats
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Quantile estimates a single quantile of a stream in constant memory with
+// the P² algorithm (Jain & Chlamtac), which keeps five markers instead of
+// the samples. It is safe for concurrent use.
+type Quantile struct {
+	mu      sync.Mutex
+	p       float64
+	count   int
+	heights [5]float64
+	pos     [5]float64
+	desired [5]float64
+	incr    [5]float64
+}
+
+// NewQuantile estimates the p-quantile, p in (0, 1), e.g. 0.99 for p99.
+func NewQuantile(p float64) (*Quantile, error) {
+	if !(p > 0 && p < 1) {
+		return nil, errors.Errorf("stats: quantile %v is not in (0, 1)", p)
+	}
+	q := &Quantile{p: p}
+	q.Reset()
+	return q, nil
+}
+
+func (q *Quantile) Reset() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	p := q.p
+	q.count = 0
+	q.pos = [5]float64{1, 2, 3, 4, 5}
+	q.desired = [5]float64{1, 1 + 2*p, 1 + 4*p, 3 + 2*p, 5}
+	q.incr = [5]float64{0, p / 2, p, (1 + p) / 2, 1}
+}
+
+func (q *Quantile) Add(v float64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.count < 5 {
+		q.heights[q.count] = v
+		q.count++
+		if q.count == 5 {
+			sort.Float64s(q.heights[:])
+		}
+		return
+	}
+	q.count++
+
+	var k int
+	switch {
+	case v < q.heights[0]:
+		q.heights[0] = v
+		k = 0
+	case v >= q.heights[4]:
+		q.heights[4] = v
+		k = 3
+	default:
+		for k = 0; k < 3 && v >= q.heights[k+1]; k++ {
+		}
+	}
+
+	for i := k + 1; i < 5; i++ {
+		q.pos[i]++
+	}
+	for i := range q.desired {
+		q.desired[i] += q.incr[i]
+	}
+
+	for i := 1; i < 4; i++ {
+		d := q.desired[i] - q.pos[i]
+		if (d >= 1 && q.pos[i+1]-q.pos[i] > 1) || (d <= -1 && q.pos[i-1]-q.pos[i] < -1) {
+			sign := math.Copysign(1, d)
+			h := q.parabolic(i, sign)
+			if q.heights[i-1] < h && h < q.heights[i+1] {
+				q.heights[i] = h
+			} else {
+				q.heights[i] = q.linear(i, sign)
+			}
+			q.pos[i] += sign
+		}
+	}
+}
+
+func (q *Quantile) parabolic(i int, d float64) float64 {
+	n, h := q.pos, q.heights
+	return h[i] + d/(n[i+1]-n[i-1])*((n[i]-n[i-1]+d)*(h[i+1]-h[i])/(n[i+1]-n[i])+
+		(n[i+1]-n[i]-d)*(h[i]-h[i-1])/(n[i]-n[i-1]))
+}
+
+func (q *Quantile) linear(i int, d float64) float64 {
+	j := i + int(d)
+	return q.heights[i] + d*(q.heights[j]-q.heights[i])/(q.pos[j]-q.pos[i])
+}
+
+// Value returns the current estimate; with fewer than five samples it is
+// computed exactly.
+func (q *Quantile) Value() float64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.count == 0 {
+		return 0
+	}
+	if q.count < 5 {
+		samples := append([]float64(nil), q.heights[:q.count]...)
+		sort.Float64s(samples)
+		return samples[int(math.Round(q.p*float64(q.count-1)))]
+	}
+	return q.heights[2]
+}
+
+// Count returns the number of samples added.
+func (q *Quantile) Count() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.count
+}
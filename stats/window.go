@@ -0,0 +1,120 @@
+package stats
+
+import (
+	"sync"
+	"time"
+	"utils/clock"
+)
+
+type bucket struct {
+	start time.Time
+	count uint64
+	sum   float64
+}
+
+// SlidingWindow aggregates count and sum of observations over the last
+// window, split into buckets so old data expires gradually. It is safe
+// for concurrent use.
+type SlidingWindow struct {
+	mu      sync.Mutex
+	window  time.Duration
+	width   time.Duration
+	buckets []bucket
+	clock   clock.Clock
+}
+
+// NewSlidingWindow covers window with the given number of buckets; more
+// buckets give smoother expiration at a small memory cost.
+func NewSlidingWindow(window time.Duration, buckets int) *SlidingWindow {
+	if buckets < 1 {
+		buckets = 1
+	}
+	width := window / time.Duration(buckets)
+	if width <= 0 {
+		width = time.Nanosecond
+	}
+	return &SlidingWindow{
+		window:  window,
+		width:   width,
+		buckets: make([]bucket, buckets),
+		clock:   clock.Real,
+	}
+}
+
+// Clock sets the time source, for deterministic tests.
+func (w *SlidingWindow) Clock(clk clock.Clock) *SlidingWindow {
+	w.mu.Lock()
+	w.clock = clock.Or(clk)
+	w.mu.Unlock()
+	return w
+}
+
+// Add records one observation of value v.
+func (w *SlidingWindow) Add(v float64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	start := w.clock.Now().Truncate(w.width)
+	// Times before 1970 have negative Unix times; keep the index positive.
+	n := int64(len(w.buckets))
+	i := (start.UnixNano()/int64(w.width))%n + n
+	b := &w.buckets[i%n]
+	if !b.start.Equal(start) {
+		*b = bucket{start: start}
+	}
+	b.count++
+	b.sum += v
+}
+
+// Inc records one observation without a value, for plain event counts.
+func (w *SlidingWindow) Inc() {
+	w.Add(0)
+}
+
+func (w *SlidingWindow) totals() (uint64, float64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	cutoff := w.clock.Now().Truncate(w.width).Add(-w.width * time.Duration(len(w.buckets)-1))
+	var count uint64
+	var sum float64
+	for _, b := range w.buckets {
+		if !b.start.Before(cutoff) {
+			count += b.count
+			sum += b.sum
+		}
+	}
+	return count, sum
+}
+
+// Count returns the number of observations in the window.
+func (w *SlidingWindow) Count() uint64 {
+	count, _ := w.totals()
+	return count
+}
+
+// Sum returns the sum of the values observed in the window.
+func (w *SlidingWindow) Sum() float64 {
+	_, sum := w.totals()
+	return sum
+}
+
+// Mean returns Sum/Count, or zero for an empty window.
+func (w *SlidingWindow) Mean() float64 {
+	count, sum := w.totals()
+	if count == 0 {
+		return 0
+	}
+	return sum / float64(count)
+}
+
+// Rate returns observations per second over the window.
+func (w *SlidingWindow) Rate() float64 {
+	return float64(w.Count()) / w.window.Seconds()
+}
+
+func (w *SlidingWindow) Reset() {
+	w.mu.Lock()
+	w.buckets = make([]bucket, len(w.buckets))
+	w.mu.Unlock()
+}
@@ -0,0 +1,108 @@
+package strcase
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+)
+
+var (
+	acronymsMu sync.RWMutex
+	acronyms   = map[string]string{}
+)
+
+// RegisterAcronym makes ToCamel and ToPascal keep words uppercase, e.g.
+// after RegisterAcronym("ID", "URL") ToPascal("user_id") returns UserID.
+func RegisterAcronym(words ...string) {
+	acronymsMu.Lock()
+	for _, w := range words {
+		acronyms[strings.ToLower(w)] = strings.ToUpper(w)
+	}
+	acronymsMu.Unlock()
+}
+
+// Words splits s into words at separators, lower-to-upper transitions and
+// the end of acronyms: "parseHTTPResponse2XX" gives parse, HTTP, Response2,
+// XX.
+func Words(s string) []string {
+	runes := []rune(s)
+	var words []string
+	start := -1
+
+	flush := func(end int) {
+		if start >= 0 && end > start {
+			words = append(words, string(runes[start:end]))
+		}
+		start = -1
+	}
+
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush(i)
+			continue
+		}
+		if start < 0 {
+			start = i
+			continue
+		}
+
+		prev := runes[i-1]
+		switch {
+		case unicode.IsUpper(r) && (unicode.IsLower(prev) || unicode.IsDigit(prev)):
+			// fooBar, v2Beta
+			flush(i)
+			start = i
+		case unicode.IsUpper(prev) && unicode.IsUpper(r) && i+1 < len(runes) && unicode.IsLower(runes[i+1]):
+			// HTTPServer: the last uppercase letter starts the next word
+			flush(i)
+			start = i
+		}
+	}
+	flush(len(runes))
+	return words
+}
+
+func ToSnake(s string) string {
+	return join(Words(s), "_", strings.ToLower)
+}
+
+func ToScreamingSnake(s string) string {
+	return join(Words(s), "_", strings.ToUpper)
+}
+
+func ToKebab(s string) string {
+	return join(Words(s), "-", strings.ToLower)
+}
+
+func ToPascal(s string) string {
+	return join(Words(s), "", title)
+}
+
+func ToCamel(s string) string {
+	words := Words(s)
+	if len(words) == 0 {
+		return ""
+	}
+	return strings.ToLower(words[0]) + join(words[1:], "", title)
+}
+
+func join(words []string, sep string, transform func(string) string) string {
+	out := make([]string, len(words))
+	for i, w := range words {
+		out[i] = transform(w)
+	}
+	return strings.Join(out, sep)
+}
+
+func title(word string) string {
+	acronymsMu.RLock()
+	acronym, ok := acronyms[strings.ToLower(word)]
+	acronymsMu.RUnlock()
+	if ok {
+		return acronym
+	}
+
+	runes := []rune(strings.ToLower(word))
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
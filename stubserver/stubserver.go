@@ -0,0 +1,277 @@
+package stubserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"utils/random"
+)
+
+// Route is one row of the stub table. Path segments starting with ':'
+// match any single segment and a trailing '*' matches the rest of the
+// path; an empty Method matches any method. Body is sent as is when it is
+// a string or []byte and encoded as JSON otherwise.
+type Route struct {
+	Method string
+	Path   string
+	Status int
+	Header map[string]string
+	Body   interface{}
+	// Latency delays the response, to exercise client timeouts.
+	Latency time.Duration
+	// FailureRate is the fraction of requests, from 0 to 1, answered with
+	// FailureStatus (500 by default) instead of the route's response.
+	FailureRate   float64
+	FailureStatus int
+}
+
+func (r Route) match(method, path string) (map[string]string, bool) {
+	if r.Method != "" && !strings.EqualFold(r.Method, method) {
+		return nil, false
+	}
+	pattern := strings.Split(strings.Trim(r.Path, "/"), "/")
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	params := make(map[string]string)
+	for i, part := range pattern {
+		if part == "*" && i == len(pattern)-1 {
+			params["*"] = strings.Join(segments[i:], "/")
+			return params, true
+		}
+		if i >= len(segments) {
+			return nil, false
+		}
+		switch {
+		case strings.HasPrefix(part, ":"):
+			params[part[1:]] = segments[i]
+		case part != segments[i]:
+			return nil, false
+		}
+	}
+	return params, len(pattern) == len(segments)
+}
+
+// Request is a captured request.
+type Request struct {
+	Method  string
+	Path    string
+	Query   url.Values
+	Header  http.Header
+	Body    []byte
+	Params  map[string]string
+	Matched bool
+	Failed  bool
+	Time    time.Time
+}
+
+// JSON decodes the captured body into v.
+func (r Request) JSON(v interface{}) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+// Server is a real HTTP server answering from a route table and recording
+// every request. Unmatched requests get 404.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	routes   []Route
+	requests []Request
+	rng      *rand.Rand
+}
+
+// New starts a server for routes; Close it when done.
+func New(routes ...Route) *Server {
+	s := &Server{routes: routes, rng: random.NewSource()}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// Seed makes failure injection deterministic.
+func (s *Server) Seed(seed int64) *Server {
+	s.mu.Lock()
+	s.rng = rand.New(rand.NewSource(seed))
+	s.mu.Unlock()
+	return s
+}
+
+// Add appends routes; earlier routes win when several match.
+func (s *Server) Add(routes ...Route) *Server {
+	s.mu.Lock()
+	s.routes = append(s.routes, routes...)
+	s.mu.Unlock()
+	return s
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	captured := Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.Query(),
+		Header: r.Header.Clone(),
+		Body:   body,
+		Time:   time.Now(),
+	}
+
+	s.mu.Lock()
+	var route Route
+	for _, candidate := range s.routes {
+		if params, ok := candidate.match(r.Method, r.URL.Path); ok {
+			route, captured.Params, captured.Matched = candidate, params, true
+			break
+		}
+	}
+	if captured.Matched && route.FailureRate > 0 {
+		captured.Failed = s.rng.Float64() < route.FailureRate
+	}
+	s.requests = append(s.requests, captured)
+	s.mu.Unlock()
+
+	if !captured.Matched {
+		http.Error(w, "stubserver: no route for "+r.Method+" "+r.URL.Path, http.StatusNotFound)
+		return
+	}
+
+	if route.Latency > 0 {
+		timer := time.NewTimer(route.Latency)
+		select {
+		case <-timer.C:
+		case <-r.Context().Done():
+			timer.Stop()
+			return
+		}
+	}
+
+	if captured.Failed {
+		status := route.FailureStatus
+		if status == 0 {
+			status = http.StatusInternalServerError
+		}
+		http.Error(w, "stubserver: injected failure", status)
+		return
+	}
+
+	payload, contentType := encode(route.Body)
+	if contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	for name, value := range route.Header {
+		w.Header().Set(name, value)
+	}
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	w.Write(payload)
+}
+
+func encode(body interface{}) ([]byte, string) {
+	switch body := body.(type) {
+	case nil:
+		return nil, ""
+	case string:
+		return []byte(body), ""
+	case []byte:
+		return body, ""
+	default:
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return []byte(err.Error()), "text/plain; charset=utf-8"
+		}
+		return payload, "application/json"
+	}
+}
+
+// Requests returns every captured request in arrival order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// RequestsTo returns the captured requests matching method and a path
+// pattern written like a Route path.
+func (s *Server) RequestsTo(method, path string) []Request {
+	pattern := Route{Method: method, Path: path}
+	var out []Request
+	for _, req := range s.Requests() {
+		if _, ok := pattern.match(req.Method, req.Path); ok {
+			out = append(out, req)
+		}
+	}
+	return out
+}
+
+// Last returns the most recent request, if any.
+func (s *Server) Last() (Request, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.requests) == 0 {
+		return Request{}, false
+	}
+	return s.requests[len(s.requests)-1], true
+}
+
+// Reset forgets captured requests, keeping the routes.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	s.requests = nil
+	s.mu.Unlock()
+}
+
+// AssertCalled fails t unless method and path were requested exactly
+// times times; a negative times accepts any positive count.
+func (s *Server) AssertCalled(t testing.TB, method, path string, times int) {
+	t.Helper()
+	got := len(s.RequestsTo(method, path))
+	if (times < 0 && got == 0) || (times >= 0 && got != times) {
+		want := fmt.Sprint(times)
+		if times < 0 {
+			want = "at least 1"
+		}
+		t.Errorf("stubserver: %s %s called %d times, want %s", method, path, got, want)
+	}
+}
+
+// AssertNotCalled fails t if method and path were requested.
+func (s *Server) AssertNotCalled(t testing.TB, method, path string) {
+	t.Helper()
+	if got := len(s.RequestsTo(method, path)); got > 0 {
+		t.Errorf("stubserver: %s %s called %d times, want none", method, path, got)
+	}
+}
+
+// AssertAllMatched fails t for every request that matched no route.
+func (s *Server) AssertAllMatched(t testing.TB) {
+	t.Helper()
+	for _, req := range s.Requests() {
+		if !req.Matched {
+			t.Errorf("stubserver: unexpected request %s %s", req.Method, req.Path)
+		}
+	}
+}
+
+// AssertBody fails t unless the last request to method and path carried
+// a body equal to want.
+func (s *Server) AssertBody(t testing.TB, method, path string, want []byte) {
+	t.Helper()
+	requests := s.RequestsTo(method, path)
+	if len(requests) == 0 {
+		t.Errorf("stubserver: %s %s was not called", method, path)
+		return
+	}
+	if got := requests[len(requests)-1].Body; !bytes.Equal(got, want) {
+		t.Errorf("stubserver: %s %s body = %q, want %q", method, path, got, want)
+	}
+}
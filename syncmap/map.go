@@ -0,0 +1,114 @@
+package syncmap
+
+import "sync"
+
+// Map is a typed map guarded by a RWMutex. Its zero value is not usable;
+// create it with New.
+type Map[K comparable, V any] struct {
+	mu    sync.RWMutex
+	items map[K]V
+}
+
+func New[K comparable, V any]() *Map[K, V] {
+	return &Map[K, V]{items: make(map[K]V)}
+}
+
+func (m *Map[K, V]) Load(key K) (V, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	v, ok := m.items[key]
+	return v, ok
+}
+
+func (m *Map[K, V]) Store(key K, value V) {
+	m.mu.Lock()
+	m.items[key] = value
+	m.mu.Unlock()
+}
+
+// LoadOrStore returns the existing value for key if present; otherwise it
+// stores and returns value. loaded reports which happened.
+func (m *Map[K, V]) LoadOrStore(key K, value V) (actual V, loaded bool) {
+	m.mu.RLock()
+	v, ok := m.items[key]
+	m.mu.RUnlock()
+	if ok {
+		return v, true
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if v, ok := m.items[key]; ok {
+		return v, true
+	}
+	m.items[key] = value
+	return value, false
+}
+
+func (m *Map[K, V]) LoadAndDelete(key K) (V, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	v, ok := m.items[key]
+	delete(m.items, key)
+	return v, ok
+}
+
+func (m *Map[K, V]) Delete(key K) {
+	m.mu.Lock()
+	delete(m.items, key)
+	m.mu.Unlock()
+}
+
+// Update replaces the value for key with fn's result while holding the
+// lock, making read-modify-write sequences atomic.
+func (m *Map[K, V]) Update(key K, fn func(current V, exists bool) V) V {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	current, ok := m.items[key]
+	next := fn(current, ok)
+	m.items[key] = next
+	return next
+}
+
+func (m *Map[K, V]) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.items)
+}
+
+func (m *Map[K, V]) Clear() {
+	m.mu.Lock()
+	m.items = make(map[K]V)
+	m.mu.Unlock()
+}
+
+// Snapshot returns a copy of the contents.
+func (m *Map[K, V]) Snapshot() map[K]V {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make(map[K]V, len(m.items))
+	for k, v := range m.items {
+		out[k] = v
+	}
+	return out
+}
+
+func (m *Map[K, V]) Keys() []K {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	keys := make([]K, 0, len(m.items))
+	for k := range m.items {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Range calls fn for each entry of a snapshot until fn returns false, so
+// fn may safely modify the map.
+func (m *Map[K, V]) Range(fn func(key K, value V) bool) {
+	for k, v := range m.Snapshot() {
+		if !fn(k, v) {
+			return
+		}
+	}
+}
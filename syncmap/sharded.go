@@ -0,0 +1,147 @@
+package syncmap
+
+import (
+	"fmt"
+	"hash/maphash"
+	"sync/atomic"
+)
+
+// Hasher maps a key to a shard.
+type Hasher[K comparable] func(key K) uint64
+
+var seed = maphash.MakeSeed()
+
+// StringHasher hashes string keys without allocating.
+func StringHasher(key string) uint64 {
+	var h maphash.Hash
+	h.SetSeed(seed)
+	h.WriteString(key)
+	return h.Sum64()
+}
+
+// IntHasher spreads integer keys with a Fibonacci multiplier.
+func IntHasher[K ~int | ~int64 | ~uint | ~uint64 | ~int32 | ~uint32](key K) uint64 {
+	return uint64(key) * 0x9E3779B97F4A7C15
+}
+
+// fmtHasher works for any key by hashing its printed form; pass a
+// specialised Hasher in hot paths.
+func fmtHasher[K comparable](key K) uint64 {
+	return StringHasher(fmt.Sprint(key))
+}
+
+// Sharded spreads entries over several independently locked Maps to
+// reduce contention when many goroutines write concurrently.
+type Sharded[K comparable, V any] struct {
+	shards []*Map[K, V]
+	hash   Hasher[K]
+	size   int64
+}
+
+// NewSharded creates a map with the given number of shards. A nil hash
+// falls back to hashing the key's fmt representation.
+func NewSharded[K comparable, V any](shards int, hash Hasher[K]) *Sharded[K, V] {
+	if shards < 1 {
+		shards = 1
+	}
+	if hash == nil {
+		hash = fmtHasher[K]
+	}
+	s := &Sharded[K, V]{shards: make([]*Map[K, V], shards), hash: hash}
+	for i := range s.shards {
+		s.shards[i] = New[K, V]()
+	}
+	return s
+}
+
+func (s *Sharded[K, V]) shard(key K) *Map[K, V] {
+	return s.shards[s.hash(key)%uint64(len(s.shards))]
+}
+
+func (s *Sharded[K, V]) Load(key K) (V, bool) {
+	return s.shard(key).Load(key)
+}
+
+func (s *Sharded[K, V]) Store(key K, value V) {
+	m := s.shard(key)
+	m.mu.Lock()
+	if _, ok := m.items[key]; !ok {
+		atomic.AddInt64(&s.size, 1)
+	}
+	m.items[key] = value
+	m.mu.Unlock()
+}
+
+func (s *Sharded[K, V]) LoadOrStore(key K, value V) (V, bool) {
+	actual, loaded := s.shard(key).LoadOrStore(key, value)
+	if !loaded {
+		atomic.AddInt64(&s.size, 1)
+	}
+	return actual, loaded
+}
+
+func (s *Sharded[K, V]) LoadAndDelete(key K) (V, bool) {
+	v, ok := s.shard(key).LoadAndDelete(key)
+	if ok {
+		atomic.AddInt64(&s.size, -1)
+	}
+	return v, ok
+}
+
+func (s *Sharded[K, V]) Delete(key K) {
+	s.LoadAndDelete(key)
+}
+
+func (s *Sharded[K, V]) Update(key K, fn func(current V, exists bool) V) V {
+	return s.shard(key).Update(key, func(current V, exists bool) V {
+		if !exists {
+			atomic.AddInt64(&s.size, 1)
+		}
+		return fn(current, exists)
+	})
+}
+
+// Len is tracked with a counter and does not lock the shards.
+func (s *Sharded[K, V]) Len() int {
+	return int(atomic.LoadInt64(&s.size))
+}
+
+func (s *Sharded[K, V]) Clear() {
+	for _, m := range s.shards {
+		m.mu.Lock()
+		atomic.AddInt64(&s.size, -int64(len(m.items)))
+		m.items = make(map[K]V)
+		m.mu.Unlock()
+	}
+}
+
+// Snapshot copies every shard; shards are copied one at a time, so the
+// result is not a single point-in-time view under concurrent writes.
+func (s *Sharded[K, V]) Snapshot() map[K]V {
+	out := make(map[K]V, s.Len())
+	for _, m := range s.shards {
+		m.mu.RLock()
+		for k, v := range m.items {
+			out[k] = v
+		}
+		m.mu.RUnlock()
+	}
+	return out
+}
+
+func (s *Sharded[K, V]) Keys() []K {
+	keys := make([]K, 0, s.Len())
+	for _, m := range s.shards {
+		keys = append(keys, m.Keys()...)
+	}
+	return keys
+}
+
+// Range calls fn for each entry of a snapshot until fn returns false.
+func (s *Sharded[K, V]) Range(fn func(key K, value V) bool) {
+	for k, v := range s.Snapshot() {
+		if !fn(k, v) {
+			return
+		}
+	}
+}
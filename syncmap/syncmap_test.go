@@ -0,0 +1,88 @@
+package syncmap
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+const benchKeys = 1024
+
+// store is the part of the API shared by the maps and sync.Map.
+type store interface {
+	Load(key string) (interface{}, bool)
+	Store(key string, value interface{})
+	LoadOrStore(key string, value interface{}) (interface{}, bool)
+}
+
+type syncMap struct{ m sync.Map }
+
+func (s *syncMap) Load(key string) (interface{}, bool) { return s.m.Load(key) }
+func (s *syncMap) Store(key string, value interface{}) { s.m.Store(key, value) }
+func (s *syncMap) LoadOrStore(key string, value interface{}) (interface{}, bool) {
+	return s.m.LoadOrStore(key, value)
+}
+
+func stores() map[string]func() store {
+	return map[string]func() store{
+		"Map":      func() store { return New[string, interface{}]() },
+		"Sharded":  func() store { return NewSharded[string, interface{}](32, StringHasher) },
+		"sync.Map": func() store { return &syncMap{} },
+	}
+}
+
+func keys() []string {
+	out := make([]string, benchKeys)
+	for i := range out {
+		out[i] = strconv.Itoa(i)
+	}
+	return out
+}
+
+// benchmark runs op in parallel on a store pre-filled with every key.
+func benchmark(b *testing.B, op func(s store, key string, i int)) {
+	keys := keys()
+	for name, newStore := range stores() {
+		b.Run(name, func(b *testing.B) {
+			s := newStore()
+			for i, key := range keys {
+				s.Store(key, i)
+			}
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for i := 0; pb.Next(); i++ {
+					op(s, keys[i%len(keys)], i)
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkLoad(b *testing.B) {
+	benchmark(b, func(s store, key string, _ int) {
+		s.Load(key)
+	})
+}
+
+func BenchmarkStore(b *testing.B) {
+	benchmark(b, func(s store, key string, i int) {
+		s.Store(key, i)
+	})
+}
+
+func BenchmarkLoadOrStore(b *testing.B) {
+	benchmark(b, func(s store, key string, i int) {
+		s.LoadOrStore(key, i)
+	})
+}
+
+// BenchmarkMixed loads nine times for every store.
+func BenchmarkMixed(b *testing.B) {
+	benchmark(b, func(s store, key string, i int) {
+		if i%10 == 0 {
+			s.Store(key, i)
+			return
+		}
+		s.Load(key)
+	})
+}
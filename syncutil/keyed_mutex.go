@@ -0,0 +1,68 @@
+package syncutil
+
+import "sync"
+
+// KeyedMutex provides one lock per key, e.g. per user ID, so operations on
+// the same entity are serialized without a global lock. Locks are created
+// on demand and dropped once nobody holds or waits for them.
+type KeyedMutex[K comparable] struct {
+	mu    sync.Mutex
+	locks map[K]*keyedLock
+}
+
+type keyedLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+func NewKeyedMutex[K comparable]() *KeyedMutex[K] {
+	return &KeyedMutex[K]{locks: make(map[K]*keyedLock)}
+}
+
+// Lock locks key and returns the function that unlocks it:
+//
+//	defer km.Lock(userID)()
+func (km *KeyedMutex[K]) Lock(key K) func() {
+	km.mu.Lock()
+	l, ok := km.locks[key]
+	if !ok {
+		l = &keyedLock{}
+		km.locks[key] = l
+	}
+	l.refs++
+	km.mu.Unlock()
+
+	l.mu.Lock()
+	return func() { km.unlock(key, l) }
+}
+
+// TryLock locks key only if it is free.
+func (km *KeyedMutex[K]) TryLock(key K) (func(), bool) {
+	km.mu.Lock()
+	defer km.mu.Unlock()
+
+	if _, ok := km.locks[key]; ok {
+		return nil, false
+	}
+	l := &keyedLock{refs: 1}
+	l.mu.Lock()
+	km.locks[key] = l
+	return func() { km.unlock(key, l) }, true
+}
+
+func (km *KeyedMutex[K]) unlock(key K, l *keyedLock) {
+	km.mu.Lock()
+	l.refs--
+	if l.refs == 0 {
+		delete(km.locks, key)
+	}
+	km.mu.Unlock()
+	l.mu.Unlock()
+}
+
+// Len reports how many keys are currently locked or awaited.
+func (km *KeyedMutex[K]) Len() int {
+	km.mu.Lock()
+	defer km.mu.Unlock()
+	return len(km.locks)
+}
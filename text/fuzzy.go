@@ -0,0 +1,108 @@
+package text
+
+import (
+	"sort"
+	"strings"
+)
+
+// Distance returns the Levenshtein edit distance between a and b, counting
+// runes rather than bytes.
+func Distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) < len(rb) {
+		ra, rb = rb, ra
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// Similarity normalizes Distance into [0, 1], where 1 means identical.
+func Similarity(a, b string) float64 {
+	longest := len([]rune(a))
+	if n := len([]rune(b)); n > longest {
+		longest = n
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(Distance(a, b))/float64(longest)
+}
+
+type Match struct {
+	Value string
+	Index int
+	Score float64
+}
+
+// FuzzyFind ranks the haystack entries resembling needle, ignoring case and
+// accents, and returns those scoring at least 0.5, best first.
+func FuzzyFind(needle string, haystack []string) []Match {
+	return FuzzyFindMin(needle, haystack, 0.5)
+}
+
+// FuzzyFindMin is FuzzyFind with a custom minimum score. Entries that
+// start with or contain the needle score higher than their edit distance
+// alone would give them, which suits command and name lookups.
+func FuzzyFindMin(needle string, haystack []string, minScore float64) []Match {
+	n := normalize(needle)
+
+	var matches []Match
+	for i, candidate := range haystack {
+		c := normalize(candidate)
+
+		score := Similarity(n, c)
+		switch {
+		case c == n:
+			score = 1
+		case n != "" && strings.HasPrefix(c, n):
+			score = maxFloat(score, 0.9)
+		case n != "" && strings.Contains(c, n):
+			score = maxFloat(score, 0.8)
+		}
+
+		if score >= minScore {
+			matches = append(matches, Match{Value: candidate, Index: i, Score: score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	return matches
+}
+
+func normalize(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(StripAccents(s)), " "))
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+func maxFloat(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,63 @@
+package text
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Slugger turns arbitrary text into URL-friendly slugs.
+type Slugger struct {
+	separator string
+	maxLength int
+	lowercase bool
+}
+
+func NewSlugger() *Slugger {
+	return &Slugger{separator: "-", maxLength: 100, lowercase: true}
+}
+
+func (s *Slugger) Separator(separator string) *Slugger {
+	s.separator = separator
+	return s
+}
+
+// MaxLength limits the slug length, cutting at a separator when possible;
+// zero disables the limit.
+func (s *Slugger) MaxLength(maxLength int) *Slugger {
+	s.maxLength = maxLength
+	return s
+}
+
+func (s *Slugger) Lowercase(lowercase bool) *Slugger {
+	s.lowercase = lowercase
+	return s
+}
+
+var defaultSlugger = NewSlugger()
+
+// Slugify turns "Promoção de Verão: 50% OFF!" into "promocao-de-verao-50-off".
+func Slugify(s string) string {
+	return defaultSlugger.Slugify(s)
+}
+
+func (s *Slugger) Slugify(input string) string {
+	input = StripAccents(input)
+	if s.lowercase {
+		input = strings.ToLower(input)
+	}
+
+	words := strings.FieldsFunc(input, func(r rune) bool {
+		return r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r))
+	})
+	slug := strings.Join(words, s.separator)
+
+	if s.maxLength <= 0 || len(slug) <= s.maxLength {
+		return slug
+	}
+
+	cut := slug[:s.maxLength]
+	if i := strings.LastIndex(cut, s.separator); i > 0 && s.separator != "" {
+		cut = cut[:i]
+	}
+	return strings.TrimSuffix(cut, s.separator)
+}
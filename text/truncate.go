@@ -0,0 +1,82 @@
+package text
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Truncate limits s to n runes, including ellipsis, without splitting
+// multi-byte characters: Truncate("Olá, mundo", 6, "...") returns "Olá...".
+func Truncate(s string, n int, ellipsis string) string {
+	if n <= 0 {
+		return ""
+	}
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+
+	e := []rune(ellipsis)
+	if len(e) >= n {
+		return string(e[:n])
+	}
+	return string([]rune(s)[:n-len(e)]) + ellipsis
+}
+
+// Abbreviate is like Truncate but cuts at the last word boundary that fits,
+// trimming trailing punctuation before appending "…". A single word longer
+// than n falls back to a plain truncation.
+func Abbreviate(s string, n int) string {
+	const ellipsis = "…"
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	if n <= 1 {
+		return Truncate(s, n, ellipsis)
+	}
+
+	runes := []rune(s)
+	cut := n - 1
+	end := -1
+	for i := cut; i > 0; i-- {
+		if unicode.IsSpace(runes[i]) {
+			end = i
+			break
+		}
+	}
+	if end <= 0 {
+		return Truncate(s, n, ellipsis)
+	}
+
+	head := strings.TrimRightFunc(string(runes[:end]), func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	})
+	return head + ellipsis
+}
+
+func PadLeft(s string, n int, pad rune) string {
+	missing := n - utf8.RuneCountInString(s)
+	if missing <= 0 {
+		return s
+	}
+	return strings.Repeat(string(pad), missing) + s
+}
+
+func PadRight(s string, n int, pad rune) string {
+	missing := n - utf8.RuneCountInString(s)
+	if missing <= 0 {
+		return s
+	}
+	return s + strings.Repeat(string(pad), missing)
+}
+
+// CenterPad centers s in n runes; when the padding is odd the extra rune
+// goes to the right.
+func CenterPad(s string, n int, pad rune) string {
+	missing := n - utf8.RuneCountInString(s)
+	if missing <= 0 {
+		return s
+	}
+	left := missing / 2
+	return strings.Repeat(string(pad), left) + s + strings.Repeat(string(pad), missing-left)
+}
@@ -0,0 +1,239 @@
+package timeutil
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"utils/format"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	Day  = 24 * time.Hour
+	Week = 7 * Day
+)
+
+// Humanize renders d with its two most significant units, e.g. "2h 15m",
+// "3d 4h" or "850ms".
+func Humanize(d time.Duration) string {
+	if d < 0 {
+		return "-" + Humanize(-d)
+	}
+	if d < time.Second {
+		if d < time.Millisecond {
+			return d.String()
+		}
+		return fmt.Sprintf("%dms", d/time.Millisecond)
+	}
+
+	units := []struct {
+		size   time.Duration
+		suffix string
+	}{
+		{Day, "d"},
+		{time.Hour, "h"},
+		{time.Minute, "m"},
+		{time.Second, "s"},
+	}
+
+	var parts []string
+	for _, u := range units {
+		if d >= u.size {
+			parts = append(parts, fmt.Sprintf("%d%s", d/u.size, u.suffix))
+			d %= u.size
+		} else if len(parts) > 0 {
+			break
+		}
+		if len(parts) == 2 {
+			break
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+var humanDurationToken = regexp.MustCompile(`^(\d+(?:\.\d+)?)(w|d|h|ms|us|µs|ns|m|s)`)
+
+// ParseHumanDuration extends time.ParseDuration with "d" (24h) and "w"
+// (7d) units and tolerates spaces between terms, e.g. "1d2h30m" or
+// "2w 3d".
+func ParseHumanDuration(s string) (time.Duration, error) {
+	rest := strings.ReplaceAll(strings.TrimSpace(s), " ", "")
+	negative := strings.HasPrefix(rest, "-")
+	rest = strings.TrimLeft(rest, "+-")
+	if rest == "" {
+		return 0, errors.Errorf("invalid duration %q", s)
+	}
+	if rest == "0" {
+		return 0, nil
+	}
+
+	var total float64
+	for rest != "" {
+		m := humanDurationToken.FindStringSubmatch(rest)
+		if m == nil {
+			return 0, errors.Errorf("invalid duration %q", s)
+		}
+		n, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			return 0, errors.Wrapf(err, "invalid duration %q", s)
+		}
+
+		var unit time.Duration
+		switch m[2] {
+		case "w":
+			unit = Week
+		case "d":
+			unit = Day
+		case "h":
+			unit = time.Hour
+		case "m":
+			unit = time.Minute
+		case "s":
+			unit = time.Second
+		case "ms":
+			unit = time.Millisecond
+		case "us", "µs":
+			unit = time.Microsecond
+		case "ns":
+			unit = time.Nanosecond
+		}
+		total += n * float64(unit)
+		rest = rest[len(m[0]):]
+	}
+
+	if total > math.MaxInt64 {
+		return 0, errors.Errorf("duration %q overflows", s)
+	}
+	if negative {
+		total = -total
+	}
+	return time.Duration(total), nil
+}
+
+// RelativeWords holds the phrases Ago uses for one locale. Past and Future
+// are format strings receiving the quantity, e.g. "%s ago"; each unit maps
+// to its singular and plural forms.
+type RelativeWords struct {
+	Now    string
+	Past   string
+	Future string
+	Second [2]string
+	Minute [2]string
+	Hour   [2]string
+	Day    [2]string
+	Week   [2]string
+	Month  [2]string
+	Year   [2]string
+}
+
+var (
+	relativeMu    sync.RWMutex
+	relativeWords = map[string]RelativeWords{
+		"en": {
+			Now: "just now", Past: "%s ago", Future: "in %s",
+			Second: [2]string{"second", "seconds"}, Minute: [2]string{"minute", "minutes"},
+			Hour: [2]string{"hour", "hours"}, Day: [2]string{"day", "days"},
+			Week: [2]string{"week", "weeks"}, Month: [2]string{"month", "months"},
+			Year: [2]string{"year", "years"},
+		},
+		"pt": {
+			Now: "agora mesmo", Past: "%s atrás", Future: "em %s",
+			Second: [2]string{"segundo", "segundos"}, Minute: [2]string{"minuto", "minutos"},
+			Hour: [2]string{"hora", "horas"}, Day: [2]string{"dia", "dias"},
+			Week: [2]string{"semana", "semanas"}, Month: [2]string{"mês", "meses"},
+			Year: [2]string{"ano", "anos"},
+		},
+		"es": {
+			Now: "ahora mismo", Past: "hace %s", Future: "en %s",
+			Second: [2]string{"segundo", "segundos"}, Minute: [2]string{"minuto", "minutos"},
+			Hour: [2]string{"hora", "horas"}, Day: [2]string{"día", "días"},
+			Week: [2]string{"semana", "semanas"}, Month: [2]string{"mes", "meses"},
+			Year: [2]string{"año", "años"},
+		},
+	}
+)
+
+// RegisterRelativeWords adds or replaces the phrases for a locale tag
+// ("pt-BR") or a bare language ("pt").
+func RegisterRelativeWords(tag string, words RelativeWords) {
+	relativeMu.Lock()
+	relativeWords[strings.ToLower(tag)] = words
+	relativeMu.Unlock()
+}
+
+// lookupRelativeWords tries the full tag, then its language, then the
+// format package's default locale.
+func lookupRelativeWords(tag string) RelativeWords {
+	relativeMu.RLock()
+	defer relativeMu.RUnlock()
+
+	for _, candidate := range []string{tag, language(tag), language(format.DefaultLocale)} {
+		if words, ok := relativeWords[strings.ToLower(candidate)]; ok {
+			return words
+		}
+	}
+	return relativeWords["en"]
+}
+
+func language(tag string) string {
+	tag = strings.ReplaceAll(tag, "_", "-")
+	if i := strings.IndexByte(tag, '-'); i >= 0 {
+		return tag[:i]
+	}
+	return tag
+}
+
+// Ago describes t relative to now in the format package's default locale.
+func Ago(t time.Time) string {
+	return Relative(t, time.Now(), format.DefaultLocale)
+}
+
+// AgoIn is Ago in the given locale, e.g. "3 dias atrás" for "pt-BR".
+func AgoIn(t time.Time, locale string) string {
+	return Relative(t, time.Now(), locale)
+}
+
+// Relative describes t relative to now, in the past ("3 days ago") or the
+// future ("in 3 days"), using the largest fitting unit.
+func Relative(t, now time.Time, locale string) string {
+	words := lookupRelativeWords(locale)
+
+	d := now.Sub(t)
+	pattern := words.Past
+	if d < 0 {
+		d = -d
+		pattern = words.Future
+	}
+
+	var n int64
+	var unit [2]string
+	switch {
+	case d < 10*time.Second:
+		return words.Now
+	case d < time.Minute:
+		n, unit = int64(d/time.Second), words.Second
+	case d < time.Hour:
+		n, unit = int64(d/time.Minute), words.Minute
+	case d < Day:
+		n, unit = int64(d/time.Hour), words.Hour
+	case d < Week:
+		n, unit = int64(d/Day), words.Day
+	case d < 30*Day:
+		n, unit = int64(d/Week), words.Week
+	case d < 365*Day:
+		n, unit = int64(d/(30*Day)), words.Month
+	default:
+		n, unit = int64(d/(365*Day)), words.Year
+	}
+
+	name := unit[1]
+	if n == 1 {
+		name = unit[0]
+	}
+	return fmt.Sprintf(pattern, fmt.Sprintf("%d %s", n, name))
+}
@@ -0,0 +1,198 @@
+package timeutil
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Pseudo layouts reported by Parse for inputs that are not handled by
+// time.Parse.
+const (
+	LayoutUnix      = "unix"
+	LayoutUnixMilli = "unixmilli"
+	LayoutUnixMicro = "unixmicro"
+	LayoutUnixNano  = "unixnano"
+	LayoutISOWeek   = "iso-week"
+)
+
+// DefaultLayouts are tried in order. Slashed dates are read day first, as
+// is usual in Brazil.
+var DefaultLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"02/01/2006 15:04:05",
+	"02/01/2006 15:04",
+	"02/01/2006",
+	"02-01-2006 15:04:05",
+	"02-01-2006",
+	"02.01.2006",
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+	"20060102T150405Z0700",
+	"20060102",
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC850,
+	time.RFC822Z,
+	time.RFC822,
+	time.ANSIC,
+	time.UnixDate,
+	LayoutUnix,
+	LayoutUnixMilli,
+	LayoutUnixMicro,
+	LayoutUnixNano,
+	LayoutISOWeek,
+}
+
+// Parser tries a list of layouts until one matches. Inputs without a zone
+// are read in the parser's location.
+type Parser struct {
+	layouts  []string
+	location *time.Location
+}
+
+func NewParser() *Parser {
+	return &Parser{
+		layouts:  DefaultLayouts,
+		location: time.UTC,
+	}
+}
+
+// Layouts replaces the layouts tried, which may include the Layout*
+// pseudo layouts.
+func (p *Parser) Layouts(layouts ...string) *Parser {
+	p.layouts = layouts
+	return p
+}
+
+// AddLayouts tries layouts before the current ones.
+func (p *Parser) AddLayouts(layouts ...string) *Parser {
+	p.layouts = append(append([]string{}, layouts...), p.layouts...)
+	return p
+}
+
+func (p *Parser) Location(location *time.Location) *Parser {
+	p.location = location
+	return p
+}
+
+// Parse returns the parsed time and the layout that matched.
+func (p *Parser) Parse(s string) (time.Time, string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, "", errors.New("empty time string")
+	}
+
+	for _, layout := range p.layouts {
+		var t time.Time
+		var ok bool
+		switch layout {
+		case LayoutUnix, LayoutUnixMilli, LayoutUnixMicro, LayoutUnixNano:
+			t, ok = parseUnix(s, layout)
+		case LayoutISOWeek:
+			t, ok = parseISOWeek(s, p.location)
+		default:
+			parsed, err := time.ParseInLocation(layout, s, p.location)
+			t, ok = parsed, err == nil
+		}
+		if ok {
+			return t, layout, nil
+		}
+	}
+	return time.Time{}, "", errors.Errorf("unrecognized time format %q", s)
+}
+
+var defaultParser = NewParser()
+
+// Parse parses s with DefaultLayouts, reading zoneless inputs as UTC.
+func Parse(s string) (time.Time, string, error) {
+	return defaultParser.Parse(s)
+}
+
+// parseUnix accepts an integer timestamp whose digit count matches the
+// unit: 10 for seconds, 13 millis, 16 micros and 19 nanos. Negative and
+// fractional-second values are accepted for LayoutUnix.
+func parseUnix(s, layout string) (time.Time, bool) {
+	digits := strings.TrimPrefix(s, "-")
+	if layout == LayoutUnix {
+		if sec, frac, ok := strings.Cut(digits, "."); ok {
+			f, err := strconv.ParseFloat(s, 64)
+			if err != nil || !isDigits(sec) || !isDigits(frac) {
+				return time.Time{}, false
+			}
+			whole := int64(f)
+			return time.Unix(whole, int64((f-float64(whole))*1e9)).UTC(), true
+		}
+	}
+	if !isDigits(digits) {
+		return time.Time{}, false
+	}
+
+	want := map[string]int{LayoutUnix: 10, LayoutUnixMilli: 13, LayoutUnixMicro: 16, LayoutUnixNano: 19}[layout]
+	if len(digits) != want && !(layout == LayoutUnix && len(digits) < 10) {
+		return time.Time{}, false
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	switch layout {
+	case LayoutUnixMilli:
+		return time.UnixMilli(n).UTC(), true
+	case LayoutUnixMicro:
+		return time.UnixMicro(n).UTC(), true
+	case LayoutUnixNano:
+		return time.Unix(0, n).UTC(), true
+	}
+	return time.Unix(n, 0).UTC(), true
+}
+
+var isoWeekPattern = regexp.MustCompile(`^(\d{4})-?W(\d{2})(?:-?([1-7]))?$`)
+
+// parseISOWeek accepts 2026-W42, 2026W42, 2026-W42-4 and 2026W424,
+// returning midnight on the given weekday (Monday by default).
+func parseISOWeek(s string, location *time.Location) (time.Time, bool) {
+	m := isoWeekPattern.FindStringSubmatch(s)
+	if m == nil {
+		return time.Time{}, false
+	}
+	year, _ := strconv.Atoi(m[1])
+	week, _ := strconv.Atoi(m[2])
+	day := 1
+	if m[3] != "" {
+		day, _ = strconv.Atoi(m[3])
+	}
+
+	// January 4th is always in week 1.
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, location)
+	offset := (int(jan4.Weekday()) + 6) % 7
+	t := jan4.AddDate(0, 0, -offset+(week-1)*7+day-1)
+	if y, w := t.ISOWeek(); y != year || w != week {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
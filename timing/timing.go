@@ -0,0 +1,188 @@
+package timing
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"utils/clock"
+	"utils/log"
+)
+
+// Reporter receives measured durations, e.g. to feed a metrics histogram.
+type Reporter func(name string, d time.Duration)
+
+var (
+	reportersMu sync.RWMutex
+	reporters   = []Reporter{LogReporter(log.Default().Named("timing"))}
+)
+
+// LogReporter logs each measurement at info level.
+func LogReporter(logger *log.Logger) Reporter {
+	return func(name string, d time.Duration) {
+		logger.Info("timing", log.String("name", name), log.Duration("elapsed", d))
+	}
+}
+
+// SetReporters replaces the reporters used by Track and Stopwatch.Report.
+// Calling it with no arguments silences reporting.
+func SetReporters(rs ...Reporter) {
+	reportersMu.Lock()
+	reporters = rs
+	reportersMu.Unlock()
+}
+
+// AddReporter adds r to the current reporters.
+func AddReporter(r Reporter) {
+	reportersMu.Lock()
+	reporters = append(reporters, r)
+	reportersMu.Unlock()
+}
+
+func report(name string, d time.Duration) {
+	reportersMu.RLock()
+	rs := reporters
+	reportersMu.RUnlock()
+
+	for _, r := range rs {
+		r(name, d)
+	}
+}
+
+// Track starts timing name and returns the function that stops and reports
+// it, meant for defer:
+//
+//	defer timing.Track("import.parse")()
+func Track(name string) func() time.Duration {
+	start := time.Now()
+	return func() time.Duration {
+		d := time.Since(start)
+		report(name, d)
+		return d
+	}
+}
+
+// Measure runs f and reports how long it took.
+func Measure(name string, f func()) time.Duration {
+	stop := Track(name)
+	f()
+	return stop()
+}
+
+type Lap struct {
+	Name     string
+	Duration time.Duration
+	// Elapsed is the stopwatch total when the lap was recorded.
+	Elapsed time.Duration
+}
+
+// Stopwatch measures a sequence of named laps. It is safe for concurrent
+// use.
+type Stopwatch struct {
+	mu      sync.Mutex
+	clock   clock.Clock
+	start   time.Time
+	last    time.Time
+	stopped time.Time
+	laps    []Lap
+}
+
+// NewStopwatch returns a running stopwatch.
+func NewStopwatch() *Stopwatch {
+	s := &Stopwatch{clock: clock.Real}
+	s.Reset()
+	return s
+}
+
+// Clock sets the time source and restarts the stopwatch.
+func (s *Stopwatch) Clock(clk clock.Clock) *Stopwatch {
+	s.mu.Lock()
+	s.clock = clock.Or(clk)
+	s.mu.Unlock()
+	s.Reset()
+	return s
+}
+
+func (s *Stopwatch) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.start = s.clock.Now()
+	s.last = s.start
+	s.stopped = time.Time{}
+	s.laps = nil
+}
+
+// Lap records the time since the previous lap (or the start) under name
+// and returns it.
+func (s *Stopwatch) Lap(name string) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	lap := Lap{Name: name, Duration: now.Sub(s.last), Elapsed: now.Sub(s.start)}
+	s.laps = append(s.laps, lap)
+	s.last = now
+	return lap.Duration
+}
+
+// Stop freezes the stopwatch and returns the total elapsed time.
+func (s *Stopwatch) Stop() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped.IsZero() {
+		s.stopped = s.clock.Now()
+	}
+	return s.stopped.Sub(s.start)
+}
+
+func (s *Stopwatch) Elapsed() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.now().Sub(s.start)
+}
+
+func (s *Stopwatch) Laps() []Lap {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Lap(nil), s.laps...)
+}
+
+// Report sends every lap to the reporters as "prefix.lap", followed by the
+// total as "prefix.total".
+func (s *Stopwatch) Report(prefix string) {
+	for _, lap := range s.Laps() {
+		report(prefix+"."+lap.Name, lap.Duration)
+	}
+	report(prefix+".total", s.Elapsed())
+}
+
+// Fields returns the laps and total as log fields.
+func (s *Stopwatch) Fields() []log.Field {
+	laps := s.Laps()
+	fields := make([]log.Field, 0, len(laps)+1)
+	for _, lap := range laps {
+		fields = append(fields, log.Duration(lap.Name, lap.Duration))
+	}
+	return append(fields, log.Duration("total", s.Elapsed()))
+}
+
+// String renders the laps as "parse=12ms render=3ms total=15ms".
+func (s *Stopwatch) String() string {
+	var b strings.Builder
+	for _, lap := range s.Laps() {
+		fmt.Fprintf(&b, "%s=%s ", lap.Name, lap.Duration)
+	}
+	fmt.Fprintf(&b, "total=%s", s.Elapsed())
+	return b.String()
+}
+
+// now is the current time, or the stop time once stopped; s.mu must be
+// held.
+func (s *Stopwatch) now() time.Time {
+	if !s.stopped.IsZero() {
+		return s.stopped
+	}
+	return s.clock.Now()
+}
@@ -0,0 +1,286 @@
+package tmpl
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"utils/br"
+	"utils/format"
+	"utils/money"
+	"utils/strcase"
+	"utils/text"
+)
+
+// FuncMap returns the functions available in every template:
+//
+//	strings:  upper lower title trim trimPrefix trimSuffix replace contains
+//	          hasPrefix hasSuffix split join repeat truncate abbreviate
+//	          slugify snake camel pascal kebab padLeft padRight
+//	math:     add sub mul div mod round floor ceil min max
+//	slices:   first last list seq len
+//	dates:    now date dateBR unix addDays
+//	format:   number currency percent money cpf cnpj cep
+//	misc:     default coalesce ternary json
+func FuncMap() map[string]interface{} {
+	return map[string]interface{}{
+		"upper":      strings.ToUpper,
+		"lower":      strings.ToLower,
+		"title":      title,
+		"trim":       strings.TrimSpace,
+		"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+		"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+		"replace":    func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+		"contains":   func(substr, s string) bool { return strings.Contains(s, substr) },
+		"hasPrefix":  func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+		"hasSuffix":  func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+		"split":      func(sep, s string) []string { return strings.Split(s, sep) },
+		"join":       join,
+		"repeat":     func(n int, s string) string { return strings.Repeat(s, n) },
+		"truncate":   func(n int, s string) string { return text.Truncate(s, n, "…") },
+		"abbreviate": func(n int, s string) string { return text.Abbreviate(s, n) },
+		"slugify":    text.Slugify,
+		"snake":      strcase.ToSnake,
+		"camel":      strcase.ToCamel,
+		"pascal":     strcase.ToPascal,
+		"kebab":      strcase.ToKebab,
+		"padLeft":    func(n int, s string) string { return text.PadLeft(s, n, ' ') },
+		"padRight":   func(n int, s string) string { return text.PadRight(s, n, ' ') },
+
+		"add":   add,
+		"sub":   sub,
+		"mul":   mul,
+		"div":   div,
+		"mod":   func(a, b int) int { return a % b },
+		"round": round,
+		"floor": func(v interface{}) (float64, error) { return unary(v, math.Floor) },
+		"ceil":  func(v interface{}) (float64, error) { return unary(v, math.Ceil) },
+		"min":   func(a, b interface{}) (float64, error) { return binary(a, b, math.Min) },
+		"max":   func(a, b interface{}) (float64, error) { return binary(a, b, math.Max) },
+
+		"first": first,
+		"last":  last,
+		"list":  func(items ...interface{}) []interface{} { return items },
+		"seq":   seq,
+
+		"now":     time.Now,
+		"date":    func(layout string, t time.Time) string { return t.Format(layout) },
+		"dateBR":  func(t time.Time) string { return t.Format("02/01/2006") },
+		"unix":    func(t time.Time) int64 { return t.Unix() },
+		"addDays": func(days int, t time.Time) time.Time { return t.AddDate(0, 0, days) },
+
+		"number":   number,
+		"currency": currency,
+		"percent":  percent,
+		"money":    func(locale string, m money.Money) string { return m.Format(locale) },
+		"cpf":      br.FormatCPF,
+		"cnpj":     br.FormatCNPJ,
+		"cep":      br.FormatCEP,
+
+		"default":  defaultValue,
+		"coalesce": coalesce,
+		"ternary":  func(yes, no interface{}, cond bool) interface{} { return ternary(cond, yes, no) },
+		"json":     toJSON,
+	}
+}
+
+// title upper-cases the first letter of every word, leaving the rest as
+// is so acronyms survive.
+func title(s string) string {
+	prev := ' '
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(prev) || prev == '-' {
+			prev = r
+			return unicode.ToTitle(r)
+		}
+		prev = r
+		return r
+	}, s)
+}
+
+// toFloat converts numbers and numeric strings, failing on anything else
+// so a typo in a template does not silently render 0.
+func toFloat(v interface{}) (float64, error) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	case reflect.String:
+		f, err := strconv.ParseFloat(strings.TrimSpace(rv.String()), 64)
+		if err != nil {
+			return 0, fmt.Errorf("not a number: %q", rv.String())
+		}
+		return f, nil
+	}
+	return 0, fmt.Errorf("not a number: %T", v)
+}
+
+func unary(v interface{}, op func(float64) float64) (float64, error) {
+	f, err := toFloat(v)
+	if err != nil {
+		return 0, err
+	}
+	return op(f), nil
+}
+
+func binary(a, b interface{}, op func(x, y float64) float64) (float64, error) {
+	x, err := toFloat(a)
+	if err != nil {
+		return 0, err
+	}
+	y, err := toFloat(b)
+	if err != nil {
+		return 0, err
+	}
+	return op(x, y), nil
+}
+
+func round(decimals int, v interface{}) (float64, error) {
+	p := math.Pow10(decimals)
+	return unary(v, func(f float64) float64 { return math.Round(f*p) / p })
+}
+
+func number(decimals int, locale string, v interface{}) (string, error) {
+	f, err := toFloat(v)
+	if err != nil {
+		return "", err
+	}
+	return format.Number(f, decimals, locale), nil
+}
+
+func currency(code, locale string, v interface{}) (string, error) {
+	f, err := toFloat(v)
+	if err != nil {
+		return "", err
+	}
+	return format.Currency(f, code, locale), nil
+}
+
+func percent(decimals int, locale string, v interface{}) (string, error) {
+	f, err := toFloat(v)
+	if err != nil {
+		return "", err
+	}
+	return format.Percent(f, decimals, locale), nil
+}
+
+func add(a, b interface{}) (float64, error) {
+	return binary(a, b, func(x, y float64) float64 { return x + y })
+}
+
+func sub(a, b interface{}) (float64, error) {
+	return binary(a, b, func(x, y float64) float64 { return x - y })
+}
+
+func mul(a, b interface{}) (float64, error) {
+	return binary(a, b, func(x, y float64) float64 { return x * y })
+}
+
+func div(a, b interface{}) (float64, error) {
+	d, err := toFloat(b)
+	if err != nil {
+		return 0, err
+	}
+	if d == 0 {
+		return 0, fmt.Errorf("division by zero")
+	}
+	n, err := toFloat(a)
+	if err != nil {
+		return 0, err
+	}
+	return n / d, nil
+}
+
+func join(sep string, items interface{}) string {
+	rv := reflect.ValueOf(items)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return fmt.Sprint(items)
+	}
+	parts := make([]string, rv.Len())
+	for i := range parts {
+		parts[i] = fmt.Sprint(rv.Index(i).Interface())
+	}
+	return strings.Join(parts, sep)
+}
+
+func first(items interface{}) interface{} {
+	rv := reflect.ValueOf(items)
+	if (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) || rv.Len() == 0 {
+		return nil
+	}
+	return rv.Index(0).Interface()
+}
+
+func last(items interface{}) interface{} {
+	rv := reflect.ValueOf(items)
+	if (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) || rv.Len() == 0 {
+		return nil
+	}
+	return rv.Index(rv.Len() - 1).Interface()
+}
+
+// seq returns [start, end], counting down when end < start.
+func seq(start, end int) []int {
+	step := 1
+	if end < start {
+		step = -1
+	}
+	out := make([]int, 0, (end-start)*step+1)
+	for i := start; ; i += step {
+		out = append(out, i)
+		if i == end {
+			break
+		}
+	}
+	return out
+}
+
+func isZero(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map, reflect.String, reflect.Array:
+		return rv.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return rv.IsNil()
+	}
+	return rv.IsZero()
+}
+
+func defaultValue(def, v interface{}) interface{} {
+	if isZero(v) {
+		return def
+	}
+	return v
+}
+
+func coalesce(values ...interface{}) interface{} {
+	for _, v := range values {
+		if !isZero(v) {
+			return v
+		}
+	}
+	return nil
+}
+
+func ternary(cond bool, yes, no interface{}) interface{} {
+	if cond {
+		return yes
+	}
+	return no
+}
+
+func toJSON(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	return string(data), err
+}
@@ -0,0 +1,118 @@
+package tmpl
+
+import (
+	"bytes"
+	"crypto/sha256"
+	htmltemplate "html/template"
+	"io"
+	"sync"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+type executor interface {
+	Execute(w io.Writer, data interface{}) error
+}
+
+type cached struct {
+	sum  [32]byte
+	tmpl executor
+}
+
+// Renderer parses templates once per name and source, keeping them cached
+// until the source for that name changes.
+type Renderer struct {
+	mu    sync.RWMutex
+	funcs map[string]interface{}
+	html  bool
+	cache map[string]cached
+}
+
+func NewRenderer() *Renderer {
+	return &Renderer{
+		funcs: FuncMap(),
+		cache: make(map[string]cached),
+	}
+}
+
+// HTML switches to html/template, escaping values for safe use in emails
+// and web pages.
+func (r *Renderer) HTML(html bool) *Renderer {
+	r.mu.Lock()
+	r.html = html
+	r.cache = make(map[string]cached)
+	r.mu.Unlock()
+	return r
+}
+
+// Funcs adds or replaces template functions.
+func (r *Renderer) Funcs(funcs map[string]interface{}) *Renderer {
+	r.mu.Lock()
+	for name, fn := range funcs {
+		r.funcs[name] = fn
+	}
+	r.cache = make(map[string]cached)
+	r.mu.Unlock()
+	return r
+}
+
+func (r *Renderer) Render(name, text string, data interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := r.Execute(&buf, name, text, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (r *Renderer) Execute(w io.Writer, name, text string, data interface{}) error {
+	t, err := r.template(name, text)
+	if err != nil {
+		return err
+	}
+	if err := t.Execute(w, data); err != nil {
+		return errors.Wrap(err, "template.Execute")
+	}
+	return nil
+}
+
+func (r *Renderer) template(name, text string) (executor, error) {
+	sum := sha256.Sum256([]byte(text))
+
+	r.mu.RLock()
+	c, ok := r.cache[name]
+	r.mu.RUnlock()
+	if ok && c.sum == sum {
+		return c.tmpl, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var t executor
+	var err error
+	if r.html {
+		t, err = htmltemplate.New(name).Funcs(r.funcs).Option("missingkey=zero").Parse(text)
+	} else {
+		t, err = template.New(name).Funcs(r.funcs).Option("missingkey=zero").Parse(text)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "template.Parse")
+	}
+
+	r.cache[name] = cached{sum: sum, tmpl: t}
+	return t, nil
+}
+
+var (
+	defaultText = NewRenderer()
+	defaultHTML = NewRenderer().HTML(true)
+)
+
+func Render(name, text string, data interface{}) (string, error) {
+	return defaultText.Render(name, text, data)
+}
+
+func RenderHTML(name, text string, data interface{}) (string, error) {
+	return defaultHTML.Render(name, text, data)
+}
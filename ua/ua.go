@@ -0,0 +1,179 @@
+package ua
+
+import (
+	"regexp"
+	"strings"
+)
+
+type Device string
+
+const (
+	Desktop Device = "desktop"
+	Mobile  Device = "mobile"
+	Tablet  Device = "tablet"
+	Bot     Device = "bot"
+	Unknown Device = "unknown"
+)
+
+// UserAgent is the result of Parse. Fields that could not be determined
+// are empty.
+type UserAgent struct {
+	Raw            string
+	Browser        string
+	BrowserVersion string
+	OS             string
+	OSVersion      string
+	Device         Device
+	Bot            bool
+	BotName        string
+}
+
+type rule struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+// bots are checked first; the generic pattern catches the long tail of
+// crawlers and HTTP libraries.
+var bots = []rule{
+	{"Googlebot", regexp.MustCompile(`Googlebot(?:-\w+)?/?([\d.]*)`)},
+	{"Bingbot", regexp.MustCompile(`bingbot/([\d.]+)`)},
+	{"YandexBot", regexp.MustCompile(`YandexBot/([\d.]+)`)},
+	{"DuckDuckBot", regexp.MustCompile(`DuckDuckBot/([\d.]+)`)},
+	{"Baiduspider", regexp.MustCompile(`Baiduspider/?([\d.]*)`)},
+	{"facebookexternalhit", regexp.MustCompile(`facebookexternalhit/([\d.]+)`)},
+	{"Twitterbot", regexp.MustCompile(`Twitterbot/([\d.]+)`)},
+	{"LinkedInBot", regexp.MustCompile(`LinkedInBot/([\d.]+)`)},
+	{"Slackbot", regexp.MustCompile(`Slackbot(?:-LinkExpanding)?[ /]?([\d.]*)`)},
+	{"WhatsApp", regexp.MustCompile(`WhatsApp/([\d.]+)`)},
+	{"curl", regexp.MustCompile(`^curl/([\d.]+)`)},
+	{"Wget", regexp.MustCompile(`^Wget/([\d.]+)`)},
+	{"python-requests", regexp.MustCompile(`python-requests/([\d.]+)`)},
+	{"Go-http-client", regexp.MustCompile(`Go-http-client/([\d.]+)`)},
+	{"okhttp", regexp.MustCompile(`okhttp/([\d.]+)`)},
+	{"PostmanRuntime", regexp.MustCompile(`PostmanRuntime/([\d.]+)`)},
+	{"HeadlessChrome", regexp.MustCompile(`HeadlessChrome/([\d.]+)`)},
+	{"", regexp.MustCompile(`(?i)(bot|crawler|spider|crawling|scraper|monitor)\b`)},
+}
+
+// browsers are ordered so that browsers built on Chromium or WebKit match
+// before the engines they claim to be.
+var browsers = []rule{
+	{"Edge", regexp.MustCompile(`Edg(?:e|A|iOS)?/([\d.]+)`)},
+	{"Opera", regexp.MustCompile(`(?:OPR|Opera)/([\d.]+)`)},
+	{"Samsung Internet", regexp.MustCompile(`SamsungBrowser/([\d.]+)`)},
+	{"Yandex Browser", regexp.MustCompile(`YaBrowser/([\d.]+)`)},
+	{"Vivaldi", regexp.MustCompile(`Vivaldi/([\d.]+)`)},
+	{"Firefox", regexp.MustCompile(`(?:Firefox|FxiOS)/([\d.]+)`)},
+	{"Chrome", regexp.MustCompile(`(?:Chrome|CriOS)/([\d.]+)`)},
+	{"Safari", regexp.MustCompile(`Version/([\d.]+).*Safari/`)},
+	{"Internet Explorer", regexp.MustCompile(`(?:MSIE |Trident/.*rv:)([\d.]+)`)},
+}
+
+var systems = []rule{
+	{"Windows Phone", regexp.MustCompile(`Windows Phone(?: OS)? ([\d.]+)`)},
+	{"Windows", regexp.MustCompile(`Windows NT ([\d.]+)`)},
+	{"iOS", regexp.MustCompile(`(?:iPhone|iPad|iPod).*? OS ([\d_]+)`)},
+	{"macOS", regexp.MustCompile(`Mac OS X ?([\d_.]*)`)},
+	{"Android", regexp.MustCompile(`Android ?([\d.]*)`)},
+	{"ChromeOS", regexp.MustCompile(`CrOS \S+ ([\d.]+)`)},
+	{"Linux", regexp.MustCompile(`Linux()`)},
+}
+
+var windowsVersions = map[string]string{
+	"10.0": "10",
+	"6.3":  "8.1",
+	"6.2":  "8",
+	"6.1":  "7",
+	"6.0":  "Vista",
+	"5.1":  "XP",
+}
+
+// Parse extracts browser, OS and device class from a User-Agent header.
+// It favours the common cases over exhaustive coverage.
+func Parse(s string) UserAgent {
+	ua := UserAgent{Raw: s, Device: Unknown}
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return ua
+	}
+
+	for _, r := range bots {
+		if m := r.pattern.FindStringSubmatch(s); m != nil {
+			ua.Bot = true
+			ua.Device = Bot
+			ua.BotName = r.name
+			if r.name == "" {
+				ua.BotName = botName(s)
+			}
+			break
+		}
+	}
+
+	for _, r := range browsers {
+		if m := r.pattern.FindStringSubmatch(s); m != nil {
+			ua.Browser, ua.BrowserVersion = r.name, m[1]
+			break
+		}
+	}
+
+	for _, r := range systems {
+		if m := r.pattern.FindStringSubmatch(s); m != nil {
+			ua.OS, ua.OSVersion = r.name, strings.ReplaceAll(m[1], "_", ".")
+			if r.name == "Windows" {
+				if v, ok := windowsVersions[ua.OSVersion]; ok {
+					ua.OSVersion = v
+				}
+			}
+			break
+		}
+	}
+
+	if !ua.Bot {
+		ua.Device = device(s, ua.OS)
+	}
+	return ua
+}
+
+func device(s, os string) Device {
+	switch {
+	case strings.Contains(s, "iPad"), strings.Contains(s, "Tablet"),
+		os == "Android" && !strings.Contains(s, "Mobile"):
+		return Tablet
+	case strings.Contains(s, "Mobi"), strings.Contains(s, "iPhone"), strings.Contains(s, "iPod"),
+		os == "Windows Phone":
+		return Mobile
+	case os != "" || strings.HasPrefix(s, "Mozilla/"):
+		return Desktop
+	}
+	return Unknown
+}
+
+var botNamePattern = regexp.MustCompile(`(?i)([\w.-]*(?:bot|crawler|spider|scraper|monitor)[\w.-]*)`)
+
+func botName(s string) string {
+	if m := botNamePattern.FindStringSubmatch(s); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+func (u UserAgent) IsMobile() bool {
+	return u.Device == Mobile
+}
+
+// String summarizes the agent, e.g. "Chrome 120.0 on Windows 10 (desktop)".
+func (u UserAgent) String() string {
+	if u.Bot {
+		return "bot " + u.BotName
+	}
+	var parts []string
+	if u.Browser != "" {
+		parts = append(parts, strings.TrimSpace(u.Browser+" "+u.BrowserVersion))
+	}
+	if u.OS != "" {
+		parts = append(parts, "on "+strings.TrimSpace(u.OS+" "+u.OSVersion))
+	}
+	parts = append(parts, "("+string(u.Device)+")")
+	return strings.Join(parts, " ")
+}
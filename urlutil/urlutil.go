@@ -0,0 +1,166 @@
+package urlutil
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+	"ws":    "80",
+	"wss":   "443",
+	"ftp":   "21",
+}
+
+// Normalizer rewrites URLs into a canonical form so equivalent URLs
+// compare equal, e.g. as cache keys.
+type Normalizer struct {
+	dropFragment bool
+	sortQuery    bool
+	dropQuery    []string
+}
+
+// NewNormalizer lowercases scheme and host, strips default ports,
+// resolves dot segments and sorts query parameters.
+func NewNormalizer() *Normalizer {
+	return &Normalizer{sortQuery: true}
+}
+
+// DropFragment removes the "#..." part.
+func (n *Normalizer) DropFragment(drop bool) *Normalizer {
+	n.dropFragment = drop
+	return n
+}
+
+// SortQuery orders query parameters by name, keeping the order of
+// repeated values.
+func (n *Normalizer) SortQuery(sort bool) *Normalizer {
+	n.sortQuery = sort
+	return n
+}
+
+// DropQuery removes the named parameters, e.g. "utm_source" tracking
+// parameters.
+func (n *Normalizer) DropQuery(names ...string) *Normalizer {
+	n.dropQuery = append(n.dropQuery, names...)
+	return n
+}
+
+func (n *Normalizer) Normalize(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", errors.Wrap(err, "url.Parse")
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	host, port := strings.ToLower(u.Hostname()), u.Port()
+	if port == defaultPorts[u.Scheme] {
+		port = ""
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port != "" {
+		host += ":" + port
+	}
+	u.Host = host
+
+	u.Path = resolveDots(u.Path)
+	if u.Path == "" && u.Host != "" {
+		u.Path = "/"
+	}
+	u.RawPath = ""
+
+	if n.sortQuery || len(n.dropQuery) > 0 {
+		query := u.Query()
+		for _, name := range n.dropQuery {
+			query.Del(name)
+		}
+		if n.sortQuery {
+			u.RawQuery = query.Encode()
+		} else {
+			u.RawQuery = filterQuery(u.RawQuery, n.dropQuery)
+		}
+	}
+	u.ForceQuery = false
+
+	if n.dropFragment {
+		u.Fragment, u.RawFragment = "", ""
+	}
+	return u.String(), nil
+}
+
+// resolveDots removes "." and ".." segments keeping a trailing slash.
+func resolveDots(p string) string {
+	if p == "" {
+		return ""
+	}
+	cleaned := path.Clean(p)
+	if cleaned == "." {
+		return ""
+	}
+	if strings.HasSuffix(p, "/") || strings.HasSuffix(p, "/.") || strings.HasSuffix(p, "/..") {
+		if cleaned != "/" {
+			cleaned += "/"
+		}
+	}
+	return cleaned
+}
+
+func filterQuery(raw string, drop []string) string {
+	var kept []string
+	for _, pair := range strings.Split(raw, "&") {
+		name, _, _ := strings.Cut(pair, "=")
+		if key, err := url.QueryUnescape(name); err == nil && contains(drop, key) {
+			continue
+		}
+		if pair != "" {
+			kept = append(kept, pair)
+		}
+	}
+	return strings.Join(kept, "&")
+}
+
+func contains(values []string, v string) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Normalize applies the NewNormalizer defaults, keeping the fragment.
+func Normalize(raw string) (string, error) {
+	return NewNormalizer().Normalize(raw)
+}
+
+// Validate checks that raw is an absolute URL with a host, no embedded
+// credentials and a scheme in schemes (http and https by default), as
+// expected of user-supplied webhook URLs.
+func Validate(raw string, schemes ...string) error {
+	if len(schemes) == 0 {
+		schemes = []string{"http", "https"}
+	}
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return errors.Wrap(err, "url.Parse")
+	}
+	if !u.IsAbs() {
+		return errors.Errorf("urlutil: %q is not an absolute URL", raw)
+	}
+	if !contains(schemes, strings.ToLower(u.Scheme)) {
+		return errors.Errorf("urlutil: scheme %q is not allowed", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return errors.Errorf("urlutil: %q has no host", raw)
+	}
+	if u.User != nil {
+		return errors.New("urlutil: URL must not embed credentials")
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package validate
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+	"utils/cache"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrEmailSyntax     = errors.New("invalid email address")
+	ErrEmailNoMX       = errors.New("email domain does not accept mail")
+	ErrEmailDisposable = errors.New("email domain is not allowed")
+)
+
+// EmailValidator checks email syntax and, optionally, that the domain
+// has mail servers. DNS answers, including negative ones, are cached.
+type EmailValidator struct {
+	checkMX   bool
+	timeout   time.Duration
+	resolver  *net.Resolver
+	cache     *cache.LRU[string, error]
+	blocklist func(domain string) bool
+}
+
+func NewEmailValidator() *EmailValidator {
+	return &EmailValidator{
+		timeout:  3 * time.Second,
+		resolver: net.DefaultResolver,
+		cache:    cache.NewLRU[string, error](1024).TTL(time.Hour),
+	}
+}
+
+// CheckMX enables the DNS lookup of the domain's MX records, falling back
+// to A/AAAA records as mail servers do.
+func (v *EmailValidator) CheckMX(check bool) *EmailValidator {
+	v.checkMX = check
+	return v
+}
+
+// Timeout bounds each DNS lookup.
+func (v *EmailValidator) Timeout(timeout time.Duration) *EmailValidator {
+	v.timeout = timeout
+	return v
+}
+
+func (v *EmailValidator) Resolver(resolver *net.Resolver) *EmailValidator {
+	v.resolver = resolver
+	return v
+}
+
+// CacheTTL sets how long DNS answers are reused.
+func (v *EmailValidator) CacheTTL(ttl time.Duration) *EmailValidator {
+	v.cache.TTL(ttl)
+	return v
+}
+
+// Blocklist rejects domains for which blocked returns true, e.g. a lookup
+// in a list of disposable email providers. Domains are passed lowercased.
+func (v *EmailValidator) Blocklist(blocked func(domain string) bool) *EmailValidator {
+	v.blocklist = blocked
+	return v
+}
+
+// Validate returns ErrEmailSyntax, ErrEmailDisposable or ErrEmailNoMX,
+// or a wrapped DNS error when the lookup itself failed.
+func (v *EmailValidator) Validate(ctx context.Context, addr string) error {
+	if !isEmail(addr) {
+		return ErrEmailSyntax
+	}
+	domain := strings.ToLower(addr[strings.LastIndexByte(addr, '@')+1:])
+	if v.blocklist != nil && v.blocklist(domain) {
+		return ErrEmailDisposable
+	}
+	if !v.checkMX {
+		return nil
+	}
+
+	if err, ok := v.cache.Get(domain); ok {
+		return err
+	}
+	err := v.lookup(ctx, domain)
+	if err == nil || errors.Is(err, ErrEmailNoMX) {
+		v.cache.Set(domain, err)
+	}
+	return err
+}
+
+func (v *EmailValidator) lookup(ctx context.Context, domain string) error {
+	if v.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, v.timeout)
+		defer cancel()
+	}
+
+	records, err := v.resolver.LookupMX(ctx, domain)
+	if err == nil {
+		// A single "." MX is the null MX of RFC 7505: no mail accepted.
+		if len(records) == 1 && records[0].Host == "." {
+			return ErrEmailNoMX
+		}
+		if len(records) > 0 {
+			return nil
+		}
+	} else if !isNotFound(err) {
+		return errors.Wrap(err, "resolver.LookupMX")
+	}
+
+	hosts, err := v.resolver.LookupHost(ctx, domain)
+	if err != nil {
+		if isNotFound(err) {
+			return ErrEmailNoMX
+		}
+		return errors.Wrap(err, "resolver.LookupHost")
+	}
+	if len(hosts) == 0 {
+		return ErrEmailNoMX
+	}
+	return nil
+}
+
+func isNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
+
+var (
+	defaultEmailValidator = NewEmailValidator()
+	mxEmailValidator      = NewEmailValidator().CheckMX(true)
+)
+
+// Email checks the syntax of addr.
+func Email(addr string) error {
+	return defaultEmailValidator.Validate(context.Background(), addr)
+}
+
+// EmailMX checks the syntax of addr and that its domain has mail servers.
+func EmailMX(ctx context.Context, addr string) error {
+	return mxEmailValidator.Validate(ctx, addr)
+}
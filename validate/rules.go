@@ -0,0 +1,106 @@
+package validate
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// patterns caches the compiled regexp rules by pattern; invalid patterns
+// are cached as nil.
+var (
+	patternsMu sync.RWMutex
+	patterns   = map[string]*regexp.Regexp{}
+)
+
+func init() {
+	RegisterRule("min", func(v reflect.Value, p string) bool {
+		return compare(v, p, func(have, want float64) bool { return have >= want })
+	}, "must be at least %s")
+
+	RegisterRule("max", func(v reflect.Value, p string) bool {
+		return compare(v, p, func(have, want float64) bool { return have <= want })
+	}, "must be at most %s")
+
+	RegisterRule("len", func(v reflect.Value, p string) bool {
+		return compare(v, p, func(have, want float64) bool { return have == want })
+	}, "must have length %s")
+
+	RegisterRule("oneof", func(v reflect.Value, p string) bool {
+		s := fmt.Sprintf("%v", v.Interface())
+		for _, option := range strings.Fields(p) {
+			if s == option {
+				return true
+			}
+		}
+		return false
+	}, "must be one of [%s]")
+
+	RegisterRule("email", func(v reflect.Value, _ string) bool {
+		return v.Kind() == reflect.String && isEmail(v.String())
+	}, "must be a valid email address")
+
+	RegisterRule("url", func(v reflect.Value, _ string) bool {
+		if v.Kind() != reflect.String {
+			return false
+		}
+		u, err := url.Parse(v.String())
+		return err == nil && u.Scheme != "" && u.Host != ""
+	}, "must be a valid URL")
+
+	RegisterRule("uuid", func(v reflect.Value, _ string) bool {
+		return v.Kind() == reflect.String && uuidPattern.MatchString(v.String())
+	}, "must be a valid UUID")
+
+	RegisterRule("numeric", stringOf(unicode.IsDigit), "must contain only digits")
+	RegisterRule("alpha", stringOf(unicode.IsLetter), "must contain only letters")
+	RegisterRule("alphanum", stringOf(func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}), "must contain only letters and digits")
+
+	RegisterRule("regexp", func(v reflect.Value, p string) bool {
+		re := compilePattern(p)
+		return re != nil && v.Kind() == reflect.String && re.MatchString(v.String())
+	}, "must match %s")
+}
+
+func compilePattern(p string) *regexp.Regexp {
+	patternsMu.RLock()
+	re, ok := patterns[p]
+	patternsMu.RUnlock()
+	if ok {
+		return re
+	}
+
+	re, _ = regexp.Compile(p)
+	patternsMu.Lock()
+	patterns[p] = re
+	patternsMu.Unlock()
+	return re
+}
+
+func stringOf(allowed func(rune) bool) Rule {
+	return func(v reflect.Value, _ string) bool {
+		if v.Kind() != reflect.String {
+			return false
+		}
+		for _, r := range v.String() {
+			if !allowed(r) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func isEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s && strings.Contains(s[strings.LastIndexByte(s, '@'):], ".")
+}
@@ -0,0 +1,277 @@
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type FieldError struct {
+	Field   string
+	Rule    string
+	Param   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// ByField groups error messages by field path, handy for API responses.
+func (e Errors) ByField() map[string][]string {
+	out := make(map[string][]string, len(e))
+	for _, fe := range e {
+		out[fe.Field] = append(out[fe.Field], fe.Message)
+	}
+	return out
+}
+
+// Rule reports whether value satisfies the rule given its tag parameter.
+type Rule func(value reflect.Value, param string) bool
+
+type ruleDef struct {
+	check   Rule
+	message string
+}
+
+var (
+	rulesMu sync.RWMutex
+	rules   = map[string]ruleDef{}
+)
+
+// RegisterRule adds or replaces a rule usable in validate tags. The message
+// may reference the parameter with %s.
+func RegisterRule(name string, check Rule, message string) {
+	rulesMu.Lock()
+	rules[name] = ruleDef{check: check, message: message}
+	rulesMu.Unlock()
+}
+
+// Validate checks the `validate` tags of a struct, or pointer to struct, and
+// of nested structs, returning Errors with one entry per failed rule.
+//
+//	type Signup struct {
+//		Name  string `json:"name" validate:"required,min=3,max=50"`
+//		Email string `json:"email" validate:"required,email"`
+//		Plan  string `json:"plan" validate:"oneof=free pro"`
+//	}
+func Validate(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return errors.New("validate: nil value")
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return errors.Errorf("validate: expected struct, got %s", rv.Kind())
+	}
+
+	var errs Errors
+	validateStruct(rv, "", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func validateStruct(rv reflect.Value, prefix string, errs *Errors) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := prefix + fieldName(field)
+		value := rv.Field(i)
+		tag := field.Tag.Get("validate")
+		if tag == "-" {
+			continue
+		}
+
+		if tag != "" {
+			if !validateField(value, name, tag, errs) {
+				continue
+			}
+		}
+
+		validateNested(value, name, errs)
+	}
+}
+
+func validateNested(value reflect.Value, name string, errs *Errors) {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		if value.Type() != timeType {
+			validateStruct(value, name+".", errs)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			validateNested(value.Index(i), fmt.Sprintf("%s[%d]", name, i), errs)
+		}
+	case reflect.Map:
+		iter := value.MapRange()
+		for iter.Next() {
+			validateNested(iter.Value(), fmt.Sprintf("%s[%v]", name, iter.Key()), errs)
+		}
+	}
+}
+
+// validateField applies the tag rules and reports whether the value should
+// be descended into.
+func validateField(value reflect.Value, name, tag string, errs *Errors) bool {
+	parts := splitTag(tag)
+
+	required := false
+	for _, part := range parts {
+		if strings.TrimSpace(part) == "required" {
+			required = true
+		}
+	}
+
+	if required && isEmpty(value) {
+		*errs = append(*errs, FieldError{Field: name, Rule: "required", Message: "is required"})
+		return false
+	}
+
+	// optional strings, collections and pointers are only checked when set;
+	// numbers and booleans are always checked
+	if isAbsent(value) {
+		return false
+	}
+
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" || part == "required" || part == "omitempty" {
+			continue
+		}
+
+		rule, param := part, ""
+		if i := strings.IndexByte(part, '='); i >= 0 {
+			rule, param = part[:i], part[i+1:]
+		}
+
+		rulesMu.RLock()
+		def, ok := rules[rule]
+		rulesMu.RUnlock()
+		if !ok {
+			*errs = append(*errs, FieldError{Field: name, Rule: rule, Param: param, Message: "unknown rule " + rule})
+			continue
+		}
+
+		if !def.check(indirect(value), param) {
+			msg := def.message
+			if strings.Contains(msg, "%s") {
+				msg = fmt.Sprintf(msg, param)
+			}
+			*errs = append(*errs, FieldError{Field: name, Rule: rule, Param: param, Message: msg})
+		}
+	}
+	return true
+}
+
+// splitTag splits a tag into its rules. A regexp rule must come last and
+// takes the rest of the tag, so its pattern may contain commas.
+func splitTag(tag string) []string {
+	var parts []string
+	for tag != "" {
+		if strings.HasPrefix(strings.TrimSpace(tag), "regexp=") {
+			return append(parts, tag)
+		}
+		i := strings.IndexByte(tag, ',')
+		if i < 0 {
+			return append(parts, tag)
+		}
+		parts = append(parts, tag[:i])
+		tag = tag[i+1:]
+	}
+	return parts
+}
+
+func fieldName(field reflect.StructField) string {
+	if tag := field.Tag.Get("json"); tag != "" {
+		if name := strings.Split(tag, ",")[0]; name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+func indirect(value reflect.Value) reflect.Value {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return value
+		}
+		value = value.Elem()
+	}
+	return value
+}
+
+func isEmpty(value reflect.Value) bool {
+	if isAbsent(value) {
+		return true
+	}
+	return value.IsZero()
+}
+
+func isAbsent(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return value.IsNil()
+	case reflect.String, reflect.Slice, reflect.Map:
+		return value.Len() == 0
+	}
+	return false
+}
+
+// size is the length of strings (in runes) and collections, or the numeric
+// value itself, so min and max work across kinds.
+func size(value reflect.Value) (float64, bool) {
+	switch value.Kind() {
+	case reflect.String:
+		return float64(len([]rune(value.String()))), true
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return float64(value.Len()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), true
+	}
+	return 0, false
+}
+
+func compare(value reflect.Value, param string, ok func(have, want float64) bool) bool {
+	want, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return false
+	}
+	have, valid := size(value)
+	return valid && ok(have, want)
+}
@@ -0,0 +1,125 @@
+package waitgroup
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var ErrTimeout = errors.New("waitgroup: timed out waiting for members")
+
+// Errors collects the failures of a Group's members in completion order.
+type Errors []error
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// PanicError is the error recorded for a member that panicked.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("waitgroup: panic: %v", e.Value)
+}
+
+// Group runs functions concurrently and collects every error, unlike
+// errgroup which keeps only the first. The zero value is ready to use and
+// runs members without a limit. A Group must not be copied after first use.
+type Group struct {
+	wg  sync.WaitGroup
+	sem chan struct{}
+
+	mu   sync.Mutex
+	errs Errors
+}
+
+func New() *Group {
+	return &Group{}
+}
+
+// Limit caps how many members run at once; Go blocks while the group is
+// full. Call it before the first Go.
+func (g *Group) Limit(n int) *Group {
+	if n > 0 {
+		g.sem = make(chan struct{}, n)
+	} else {
+		g.sem = nil
+	}
+	return g
+}
+
+// Go starts fn in a new goroutine. A panic in fn is recovered and recorded
+// as a *PanicError.
+func (g *Group) Go(fn func() error) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if g.sem != nil {
+			defer func() { <-g.sem }()
+		}
+		if err := run(fn); err != nil {
+			g.mu.Lock()
+			g.errs = append(g.errs, err)
+			g.mu.Unlock()
+		}
+	}()
+}
+
+func run(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return fn()
+}
+
+// Wait blocks until every member returns and reports their errors as
+// Errors, or nil when all succeeded.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	return g.err()
+}
+
+// WaitTimeout is Wait giving up after d with ErrTimeout. Members keep
+// running; a later Wait still collects them.
+func (g *Group) WaitTimeout(d time.Duration) error {
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return g.err()
+	case <-timer.C:
+		return ErrTimeout
+	}
+}
+
+func (g *Group) err() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.errs) == 0 {
+		return nil
+	}
+	return append(Errors(nil), g.errs...)
+}
@@ -0,0 +1,241 @@
+package watch
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type Op int
+
+const (
+	Create Op = iota + 1
+	Modify
+	Delete
+)
+
+func (o Op) String() string {
+	switch o {
+	case Create:
+		return "create"
+	case Modify:
+		return "modify"
+	case Delete:
+		return "delete"
+	}
+	return "unknown"
+}
+
+type Event struct {
+	Op   Op
+	Path string
+	Time time.Time
+}
+
+// Options configures Dir. Include and Exclude are glob patterns matched
+// against both the file name and the slash-separated path relative to the
+// watched directory; an empty Include matches everything.
+type Options struct {
+	Include   []string
+	Exclude   []string
+	Recursive bool
+	// Interval between directory scans. Defaults to 500ms.
+	Interval time.Duration
+	// Debounce holds an event back until the file has been quiet this long,
+	// so a burst of writes becomes a single event. Defaults to 1s; a
+	// negative value disables debouncing.
+	Debounce time.Duration
+	// OnError receives scan errors; they are otherwise dropped.
+	OnError func(err error)
+}
+
+type fileState struct {
+	size    int64
+	modTime time.Time
+	mode    fs.FileMode
+}
+
+type pending struct {
+	op   Op
+	last time.Time
+}
+
+// Dir watches path for file changes by periodic scanning, which works the
+// same on every platform and filesystem, including network mounts. The
+// returned channel is closed when ctx is done.
+func Dir(ctx context.Context, path string, opts Options) (<-chan Event, error) {
+	if opts.Interval <= 0 {
+		opts.Interval = 500 * time.Millisecond
+	}
+	if opts.Debounce < 0 {
+		opts.Debounce = 0
+	} else if opts.Debounce == 0 {
+		opts.Debounce = time.Second
+	}
+	for _, pattern := range append(append([]string{}, opts.Include...), opts.Exclude...) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, errors.Wrapf(err, "invalid pattern %q", pattern)
+		}
+	}
+
+	w := &watcher{root: path, opts: opts, pending: make(map[string]*pending)}
+	state, err := w.scan()
+	if err != nil {
+		return nil, err
+	}
+	w.state = state
+
+	events := make(chan Event)
+	go w.run(ctx, events)
+	return events, nil
+}
+
+type watcher struct {
+	root    string
+	opts    Options
+	state   map[string]fileState
+	pending map[string]*pending
+}
+
+func (w *watcher) run(ctx context.Context, events chan<- Event) {
+	defer close(events)
+
+	ticker := time.NewTicker(w.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			w.poll(now)
+			for _, event := range w.ready(now) {
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}
+}
+
+func (w *watcher) poll(now time.Time) {
+	state, err := w.scan()
+	if err != nil {
+		if w.opts.OnError != nil {
+			w.opts.OnError(err)
+		}
+		return
+	}
+
+	for path, cur := range state {
+		prev, ok := w.state[path]
+		switch {
+		case !ok:
+			w.record(path, Create, now)
+		case prev != cur:
+			w.record(path, Modify, now)
+		}
+	}
+	for path := range w.state {
+		if _, ok := state[path]; !ok {
+			w.record(path, Delete, now)
+		}
+	}
+	w.state = state
+}
+
+// record folds a new change into any pending event for the same path.
+func (w *watcher) record(path string, op Op, now time.Time) {
+	p, ok := w.pending[path]
+	if !ok {
+		w.pending[path] = &pending{op: op, last: now}
+		return
+	}
+
+	p.last = now
+	switch {
+	case p.op == Create && op == Delete:
+		delete(w.pending, path)
+	case p.op == Create:
+	case p.op == Delete && op == Create:
+		p.op = Modify
+	default:
+		p.op = op
+	}
+}
+
+func (w *watcher) ready(now time.Time) []Event {
+	var out []Event
+	for path, p := range w.pending {
+		if now.Sub(p.last) >= w.opts.Debounce {
+			out = append(out, Event{Op: p.op, Path: path, Time: p.last})
+			delete(w.pending, path)
+		}
+	}
+	return out
+}
+
+func (w *watcher) scan() (map[string]fileState, error) {
+	state := make(map[string]fileState)
+	err := filepath.WalkDir(w.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path != w.root && errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			if path != w.root && !w.opts.Recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(w.root, path)
+		if err != nil {
+			return errors.Wrap(err, "filepath.Rel")
+		}
+		if !w.matches(filepath.ToSlash(rel)) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "entry.Info")
+		}
+		state[path] = fileState{size: info.Size(), modTime: info.ModTime(), mode: info.Mode()}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "filepath.WalkDir")
+	}
+	return state, nil
+}
+
+func (w *watcher) matches(rel string) bool {
+	if len(w.opts.Include) > 0 && !matchAny(w.opts.Include, rel) {
+		return false
+	}
+	return !matchAny(w.opts.Exclude, rel)
+}
+
+func matchAny(patterns []string, rel string) bool {
+	base := filepath.Base(rel)
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, base); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, rel); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,270 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+	"utils/cache"
+	"utils/clock"
+	"utils/log"
+
+	"github.com/pkg/errors"
+)
+
+// ErrClosed is reported to senders, as a 503, after Close.
+var ErrClosed = errors.New("webhook: receiver closed")
+
+// Event is a verified delivery.
+type Event struct {
+	ID         string
+	Type       string
+	SignedAt   time.Time
+	ReceivedAt time.Time
+	Header     http.Header
+	Body       []byte
+}
+
+// Handler processes an event; an error makes a synchronous receiver
+// answer 500 so the sender retries.
+type Handler func(ctx context.Context, event Event) error
+
+// Endpoint is the http.Handler returned by Receiver. Its zero value is
+// not usable.
+type Endpoint struct {
+	secrets   [][]byte
+	handler   Handler
+	scheme    Scheme
+	tolerance time.Duration
+	maxBody   int64
+	clock     clock.Clock
+	logger    *log.Logger
+	seen      *cache.LRU[string, struct{}]
+	seenMu    sync.Mutex
+
+	mu      sync.RWMutex
+	queue   chan Event
+	closed  bool
+	workers sync.WaitGroup
+	cancel  context.CancelFunc
+}
+
+// Receiver verifies deliveries signed with secret before passing them to
+// handler. It defaults to the GitHub scheme, a five minute tolerance and
+// synchronous processing.
+func Receiver(secret string, handler Handler) *Endpoint {
+	return &Endpoint{
+		secrets:   [][]byte{[]byte(secret)},
+		handler:   handler,
+		scheme:    GitHub,
+		tolerance: 5 * time.Minute,
+		maxBody:   1 << 20,
+		clock:     clock.Real,
+		logger:    log.Default().Named("webhook"),
+		seen:      cache.NewLRU[string, struct{}](4096),
+	}
+}
+
+func (e *Endpoint) Scheme(scheme Scheme) *Endpoint {
+	e.scheme = scheme
+	return e
+}
+
+// Secrets adds secrets accepted alongside the first, for rotation.
+func (e *Endpoint) Secrets(secrets ...string) *Endpoint {
+	for _, secret := range secrets {
+		e.secrets = append(e.secrets, []byte(secret))
+	}
+	return e
+}
+
+// Tolerance is how far a signed timestamp may be from now. Up to 4096
+// delivery IDs are remembered for the same period, so a redelivery within
+// it is acknowledged without running the handler again.
+func (e *Endpoint) Tolerance(d time.Duration) *Endpoint {
+	e.tolerance = d
+	return e
+}
+
+func (e *Endpoint) MaxBodySize(n int64) *Endpoint {
+	e.maxBody = n
+	return e
+}
+
+// Clock sets the time source used for timestamp checks, for tests.
+func (e *Endpoint) Clock(clk clock.Clock) *Endpoint {
+	e.clock = clock.Or(clk)
+	e.seen.Clock(clk)
+	return e
+}
+
+func (e *Endpoint) Logger(logger *log.Logger) *Endpoint {
+	e.logger = logger
+	return e
+}
+
+// Async acknowledges verified events with 202 right away and hands them
+// to workers through a queue of the given size; when it is full senders
+// get 503 and retry later. Call Close on shutdown to drain the queue.
+func (e *Endpoint) Async(workers, queueSize int) *Endpoint {
+	if workers < 1 {
+		workers = 1
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	e.queue = make(chan Event, queueSize)
+	e.cancel = cancel
+	for i := 0; i < workers; i++ {
+		e.workers.Add(1)
+		go e.work(ctx)
+	}
+	return e
+}
+
+func (e *Endpoint) work(ctx context.Context) {
+	defer e.workers.Done()
+	for event := range e.queue {
+		if err := e.handler(ctx, event); err != nil {
+			e.logger.Error("processing event failed", log.String("id", event.ID), log.String("type", event.Type), log.Err(err))
+		}
+	}
+}
+
+// Close stops accepting events and waits until queued ones are processed
+// or ctx is done, in which case the handlers' context is cancelled.
+func (e *Endpoint) Close(ctx context.Context) error {
+	e.mu.Lock()
+	if e.closed || e.queue == nil {
+		e.closed = true
+		e.mu.Unlock()
+		return nil
+	}
+	e.closed = true
+	close(e.queue)
+	e.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		e.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		e.cancel()
+		return nil
+	case <-ctx.Done():
+		e.cancel()
+		return errors.Wrap(ctx.Err(), "webhook: draining queue")
+	}
+}
+
+func (e *Endpoint) verify(header http.Header, body []byte) (time.Time, error) {
+	var err error
+	for _, secret := range e.secrets {
+		var signedAt time.Time
+		if signedAt, err = e.scheme.Verify(secret, header, body); err == nil {
+			return signedAt, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+func (e *Endpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, e.maxBody+1))
+	if err != nil {
+		http.Error(w, "reading body failed", http.StatusBadRequest)
+		return
+	}
+	if int64(len(body)) > e.maxBody {
+		http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	signedAt, err := e.verify(r.Header, body)
+	if err != nil {
+		e.logger.Warn("rejected delivery", log.String("remote", r.RemoteAddr), log.Err(err))
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+	now := e.clock.Now()
+	if !signedAt.IsZero() && e.tolerance > 0 && (now.Sub(signedAt) > e.tolerance || signedAt.Sub(now) > e.tolerance) {
+		e.logger.Warn("rejected delivery", log.String("remote", r.RemoteAddr), log.Err(ErrTimestamp))
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	id, eventType := e.scheme.Describe(r.Header, body)
+	if !e.claim(id) {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	event := Event{ID: id, Type: eventType, SignedAt: signedAt, ReceivedAt: now, Header: r.Header.Clone(), Body: body}
+
+	if e.queue != nil {
+		if err := e.enqueue(event); err != nil {
+			e.forget(id)
+			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	if err := e.handler(r.Context(), event); err != nil {
+		e.forget(id)
+		e.logger.Error("processing event failed", log.String("id", id), log.String("type", eventType), log.Err(err))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (e *Endpoint) enqueue(event Event) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closed {
+		return ErrClosed
+	}
+	select {
+	case e.queue <- event:
+		return nil
+	default:
+		e.logger.Warn("queue full, asking sender to retry", log.String("id", event.ID))
+		return errors.New("webhook: queue full")
+	}
+}
+
+// claim records id as seen and reports whether it was new, so concurrent
+// deliveries of the same ID run the handler once. Deliveries without an ID
+// are always new.
+func (e *Endpoint) claim(id string) bool {
+	if id == "" {
+		return true
+	}
+	ttl := e.tolerance
+	if ttl <= 0 {
+		ttl = time.Hour
+	}
+
+	e.seenMu.Lock()
+	defer e.seenMu.Unlock()
+	if _, ok := e.seen.Get(id); ok {
+		return false
+	}
+	e.seen.SetWithTTL(id, struct{}{}, ttl)
+	return true
+}
+
+// forget releases a claimed id after a failure, so the sender's retry is
+// processed.
+func (e *Endpoint) forget(id string) {
+	if id != "" {
+		e.seen.Delete(id)
+	}
+}
@@ -0,0 +1,163 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrSignature = errors.New("webhook: missing or invalid signature")
+	ErrTimestamp = errors.New("webhook: timestamp outside tolerance")
+)
+
+// Scheme is a signature convention. Verify checks the signature of body
+// and returns the signing time, or the zero time when the scheme does not
+// sign one; Sign produces the headers a sender would add.
+type Scheme interface {
+	Verify(secret []byte, header http.Header, body []byte) (time.Time, error)
+	Sign(secret []byte, body []byte, now time.Time) http.Header
+	// Describe extracts the delivery ID and event type, when available.
+	Describe(header http.Header, body []byte) (id, eventType string)
+}
+
+func mac(secret []byte, parts ...[]byte) []byte {
+	h := hmac.New(sha256.New, secret)
+	for _, part := range parts {
+		h.Write(part)
+	}
+	return h.Sum(nil)
+}
+
+func equalHex(expected []byte, signature string) bool {
+	raw, err := hex.DecodeString(signature)
+	return err == nil && hmac.Equal(expected, raw)
+}
+
+// HMAC is a configurable HMAC-SHA256 scheme: the hex signature, after an
+// optional prefix such as "sha256=", is read from SignatureHeader. With a
+// TimestampHeader holding unix seconds the signed payload is
+// "timestamp.body", otherwise just the body.
+type HMAC struct {
+	SignatureHeader string
+	Prefix          string
+	TimestampHeader string
+	IDHeader        string
+	TypeHeader      string
+}
+
+func (s HMAC) Verify(secret []byte, header http.Header, body []byte) (time.Time, error) {
+	signature := header.Get(s.SignatureHeader)
+	if signature == "" || !strings.HasPrefix(signature, s.Prefix) {
+		return time.Time{}, ErrSignature
+	}
+	signature = strings.TrimPrefix(signature, s.Prefix)
+
+	if s.TimestampHeader == "" {
+		if !equalHex(mac(secret, body), signature) {
+			return time.Time{}, ErrSignature
+		}
+		return time.Time{}, nil
+	}
+
+	raw := header.Get(s.TimestampHeader)
+	seconds, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return time.Time{}, ErrSignature
+	}
+	if !equalHex(mac(secret, []byte(raw), []byte("."), body), signature) {
+		return time.Time{}, ErrSignature
+	}
+	return time.Unix(seconds, 0), nil
+}
+
+func (s HMAC) Sign(secret []byte, body []byte, now time.Time) http.Header {
+	header := make(http.Header)
+	if s.TimestampHeader == "" {
+		header.Set(s.SignatureHeader, s.Prefix+hex.EncodeToString(mac(secret, body)))
+		return header
+	}
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	header.Set(s.TimestampHeader, timestamp)
+	header.Set(s.SignatureHeader, s.Prefix+hex.EncodeToString(mac(secret, []byte(timestamp), []byte("."), body)))
+	return header
+}
+
+func (s HMAC) Describe(header http.Header, _ []byte) (string, string) {
+	var id, eventType string
+	if s.IDHeader != "" {
+		id = header.Get(s.IDHeader)
+	}
+	if s.TypeHeader != "" {
+		eventType = header.Get(s.TypeHeader)
+	}
+	return id, eventType
+}
+
+// GitHub signs the body alone in X-Hub-Signature-256, so it has no
+// timestamp. Redeliveries are only detected while the Endpoint still
+// remembers their ID; a captured delivery stays valid after that.
+var GitHub Scheme = HMAC{
+	SignatureHeader: "X-Hub-Signature-256",
+	Prefix:          "sha256=",
+	IDHeader:        "X-GitHub-Delivery",
+	TypeHeader:      "X-GitHub-Event",
+}
+
+// Stripe is the Stripe-Signature convention, "t=<unix>,v1=<hex>", which
+// may carry several v1 signatures while secrets are rolled.
+var Stripe Scheme = stripe{}
+
+type stripe struct{}
+
+const stripeHeader = "Stripe-Signature"
+
+func (stripe) Verify(secret []byte, header http.Header, body []byte) (time.Time, error) {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header.Get(stripeHeader), ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return time.Time{}, ErrSignature
+	}
+
+	expected := mac(secret, []byte(timestamp), []byte("."), body)
+	for _, signature := range signatures {
+		if equalHex(expected, signature) {
+			return time.Unix(seconds, 0), nil
+		}
+	}
+	return time.Time{}, ErrSignature
+}
+
+func (stripe) Sign(secret []byte, body []byte, now time.Time) http.Header {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	header := make(http.Header)
+	header.Set(stripeHeader, "t="+timestamp+",v1="+hex.EncodeToString(mac(secret, []byte(timestamp), []byte("."), body)))
+	return header
+}
+
+// Describe reads the id and type members of the event body.
+func (stripe) Describe(_ http.Header, body []byte) (string, string) {
+	var event struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+	}
+	json.Unmarshal(body, &event)
+	return event.ID, event.Type
+}
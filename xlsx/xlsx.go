@@ -0,0 +1,410 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	maxColumnWidth = 60
+	maxSheetName   = 31
+)
+
+// Cell style indexes into the cellXfs table written by stylesXML.
+const (
+	styleDefault = iota
+	styleHeader
+	styleDate
+	styleDateTime
+)
+
+type cell struct {
+	kind  byte // 's' string, 'n' number, 'b' bool, 'd' date, 0 empty
+	text  string
+	style int
+}
+
+type sheet struct {
+	name string
+	rows [][]cell
+}
+
+// Workbook collects sheets and writes them as an Office Open XML
+// spreadsheet. The first row of every sheet is a bold, frozen header and
+// column widths are sized to their content.
+type Workbook struct {
+	sheets []sheet
+}
+
+func NewWorkbook() *Workbook {
+	return &Workbook{}
+}
+
+// AddSheet adds a sheet built from records, which may be a slice of
+// structs (or struct pointers) or a [][]string whose first row is the
+// header. Struct columns are named by the xlsx tag, falling back to the
+// field name; a tag of "-" skips the field.
+func (wb *Workbook) AddSheet(name string, records interface{}) error {
+	var rows [][]cell
+	if table, ok := records.([][]string); ok {
+		for i, row := range table {
+			cells := make([]cell, len(row))
+			for j, text := range row {
+				cells[j] = cell{kind: 's', text: text}
+				if i == 0 {
+					cells[j].style = styleHeader
+				}
+			}
+			rows = append(rows, cells)
+		}
+	} else {
+		var err error
+		rows, err = structRows(records)
+		if err != nil {
+			return err
+		}
+	}
+
+	wb.sheets = append(wb.sheets, sheet{name: wb.sheetName(name), rows: rows})
+	return nil
+}
+
+// sheetName strips characters Excel rejects, truncates to 31 runes and
+// makes the name unique within the workbook.
+func (wb *Workbook) sheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "" {
+		name = fmt.Sprintf("Sheet%d", len(wb.sheets)+1)
+	}
+	if utf8.RuneCountInString(name) > maxSheetName {
+		name = string([]rune(name)[:maxSheetName])
+	}
+
+	base := name
+	for n := 2; wb.hasSheet(name); n++ {
+		suffix := fmt.Sprintf(" (%d)", n)
+		runes := []rune(base)
+		if len(runes)+len(suffix) > maxSheetName {
+			runes = runes[:maxSheetName-len(suffix)]
+		}
+		name = string(runes) + suffix
+	}
+	return name
+}
+
+func (wb *Workbook) hasSheet(name string) bool {
+	for _, s := range wb.sheets {
+		if strings.EqualFold(s.name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (wb *Workbook) Write(w io.Writer) error {
+	if len(wb.sheets) == 0 {
+		return errors.New("xlsx: workbook has no sheets")
+	}
+
+	zw := zip.NewWriter(w)
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", wb.contentTypesXML()},
+		{"_rels/.rels", rootRelsXML},
+		{"xl/workbook.xml", wb.workbookXML()},
+		{"xl/_rels/workbook.xml.rels", wb.workbookRelsXML()},
+		{"xl/styles.xml", stylesXML},
+	}
+	for i, s := range wb.sheets {
+		files = append(files, struct {
+			name    string
+			content string
+		}{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), s.xml()})
+	}
+
+	for _, f := range files {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return errors.Wrap(err, "zip.Create")
+		}
+		if _, err := io.WriteString(fw, f.content); err != nil {
+			return errors.Wrap(err, "zip.Write")
+		}
+	}
+	return errors.Wrap(zw.Close(), "zip.Close")
+}
+
+func (wb *Workbook) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.Wrap(err, "os.Create")
+	}
+	if err := wb.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return errors.Wrap(f.Close(), "file.Close")
+}
+
+// Write writes records as a single-sheet workbook.
+func Write(w io.Writer, records interface{}) error {
+	wb := NewWorkbook()
+	if err := wb.AddSheet("Sheet1", records); err != nil {
+		return err
+	}
+	return wb.Write(w)
+}
+
+func structRows(records interface{}) ([][]cell, error) {
+	slice := reflect.ValueOf(records)
+	if slice.Kind() != reflect.Slice && slice.Kind() != reflect.Array {
+		return nil, errors.Errorf("xlsx: records must be a slice, got %T", records)
+	}
+
+	elemType := slice.Type().Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return nil, errors.Errorf("xlsx: records must be structs, got %s", elemType)
+	}
+
+	type column struct {
+		name  string
+		index int
+	}
+	var columns []column
+	for i := 0; i < elemType.NumField(); i++ {
+		sf := elemType.Field(i)
+		tag := sf.Tag.Get("xlsx")
+		if sf.PkgPath != "" || tag == "-" {
+			continue
+		}
+		name := tag
+		if name == "" {
+			name = sf.Name
+		}
+		columns = append(columns, column{name: name, index: i})
+	}
+
+	header := make([]cell, len(columns))
+	for i, c := range columns {
+		header[i] = cell{kind: 's', text: c.name, style: styleHeader}
+	}
+	rows := [][]cell{header}
+
+	for i := 0; i < slice.Len(); i++ {
+		item := reflect.Indirect(slice.Index(i))
+		row := make([]cell, len(columns))
+		if item.IsValid() {
+			for j, c := range columns {
+				row[j] = valueCell(item.Field(c.index))
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func valueCell(value reflect.Value) cell {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return cell{}
+		}
+		value = value.Elem()
+	}
+
+	if value.Type() == timeType {
+		t := value.Interface().(time.Time)
+		if t.IsZero() {
+			return cell{}
+		}
+		style := styleDateTime
+		if h, m, s := t.Clock(); h == 0 && m == 0 && s == 0 && t.Nanosecond() == 0 {
+			style = styleDate
+		}
+		return cell{kind: 'n', text: fmt.Sprint(serial(t)), style: style}
+	}
+
+	if stringer, ok := value.Interface().(fmt.Stringer); ok {
+		return cell{kind: 's', text: stringer.String()}
+	}
+
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cell{kind: 'n', text: fmt.Sprint(value.Interface())}
+	case reflect.Float32, reflect.Float64:
+		f := value.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return cell{kind: 's', text: fmt.Sprint(f)}
+		}
+		return cell{kind: 'n', text: strconv.FormatFloat(f, 'f', -1, 64)}
+	case reflect.Bool:
+		if value.Bool() {
+			return cell{kind: 'b', text: "1"}
+		}
+		return cell{kind: 'b', text: "0"}
+	}
+	return cell{kind: 's', text: fmt.Sprint(value.Interface())}
+}
+
+// excelEpoch absorbs the 1900 leap-year bug for every date after
+// 1900-03-01.
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// serial converts t's wall-clock time into an Excel date serial.
+func serial(t time.Time) float64 {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	return wall.Sub(excelEpoch).Hours() / 24
+}
+
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+func escape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+func (s sheet) xml() string {
+	widths := map[int]int{}
+	for _, row := range s.rows {
+		for j, c := range row {
+			width := utf8.RuneCountInString(c.text)
+			switch c.style {
+			case styleDate:
+				width = 10
+			case styleDateTime:
+				width = 16
+			}
+			if width > widths[j] {
+				widths[j] = width
+			}
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	if len(s.rows) > 1 {
+		b.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	}
+
+	if len(widths) > 0 {
+		b.WriteString(`<cols>`)
+		for j := 0; j < len(widths); j++ {
+			width := widths[j] + 2
+			if width > maxColumnWidth {
+				width = maxColumnWidth
+			}
+			fmt.Fprintf(&b, `<col min="%d" max="%d" width="%d" customWidth="1"/>`, j+1, j+1, width)
+		}
+		b.WriteString(`</cols>`)
+	}
+
+	b.WriteString(`<sheetData>`)
+	for i, row := range s.rows {
+		fmt.Fprintf(&b, `<row r="%d">`, i+1)
+		for j, c := range row {
+			ref := fmt.Sprintf("%s%d", columnName(j), i+1)
+			switch c.kind {
+			case 's':
+				fmt.Fprintf(&b, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, c.style, escape(c.text))
+			case 'n':
+				fmt.Fprintf(&b, `<c r="%s" s="%d"><v>%s</v></c>`, ref, c.style, c.text)
+			case 'b':
+				fmt.Fprintf(&b, `<c r="%s" s="%d" t="b"><v>%s</v></c>`, ref, c.style, c.text)
+			}
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
+
+func (wb *Workbook) contentTypesXML() string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	b.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	b.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	b.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	b.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	for i := range wb.sheets {
+		fmt.Fprintf(&b, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+	}
+	b.WriteString(`</Types>`)
+	return b.String()
+}
+
+func (wb *Workbook) workbookXML() string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, s := range wb.sheets {
+		fmt.Fprintf(&b, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escape(s.name), i+1, i+1)
+	}
+	b.WriteString(`</sheets></workbook>`)
+	return b.String()
+}
+
+func (wb *Workbook) workbookRelsXML() string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := range wb.sheets {
+		fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+	}
+	fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(wb.sheets)+1)
+	b.WriteString(`</Relationships>`)
+	return b.String()
+}
+
+const rootRelsXML = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const stylesXML = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="2"><numFmt numFmtId="164" formatCode="dd/mm/yyyy"/><numFmt numFmtId="165" formatCode="dd/mm/yyyy hh:mm"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="4">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`</cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`</styleSheet>`
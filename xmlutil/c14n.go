@@ -0,0 +1,276 @@
+package xmlutil
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Canonicalization algorithms, as named in xmldsig CanonicalizationMethod
+// and Transform elements.
+const (
+	C14N                = "http://www.w3.org/TR/2001/REC-xml-c14n-20010315"
+	C14NWithComments    = C14N + "#WithComments"
+	ExcC14N             = "http://www.w3.org/2001/10/xml-exc-c14n#"
+	ExcC14NWithComments = ExcC14N + "WithComments"
+)
+
+var ErrUnknownAlgorithm = errors.New("xmlutil: unknown canonicalization algorithm")
+
+var (
+	c14nTextEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;")
+	c14nAttrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", "\"", "&quot;",
+		"\t", "&#x9;", "\n", "&#xA;", "\r", "&#xD;")
+)
+
+// Canonicalizer writes the canonical form of a subtree, as a document
+// subset: the element and its descendants, with the namespaces (and, for
+// inclusive canonicalization, the xml: attributes) it inherits from its
+// ancestors.
+type Canonicalizer struct {
+	exclusive bool
+	comments  bool
+	prefixes  map[string]bool
+	excluded  map[*Node]bool
+}
+
+// NewCanonicalizer returns a canonicalizer for one of the algorithm URIs.
+func NewCanonicalizer(algorithm string) (*Canonicalizer, error) {
+	c := &Canonicalizer{}
+	switch algorithm {
+	case C14N:
+	case C14NWithComments:
+		c.comments = true
+	case ExcC14N:
+		c.exclusive = true
+	case ExcC14NWithComments:
+		c.exclusive, c.comments = true, true
+	default:
+		return nil, errors.Wrap(ErrUnknownAlgorithm, algorithm)
+	}
+	return c, nil
+}
+
+// InclusivePrefixes sets the InclusiveNamespaces PrefixList of exclusive
+// canonicalization: prefixes rendered as in inclusive canonicalization.
+// "#default" stands for the default namespace.
+func (c *Canonicalizer) InclusivePrefixes(prefixes ...string) *Canonicalizer {
+	c.prefixes = make(map[string]bool, len(prefixes))
+	for _, p := range prefixes {
+		if p == "#default" {
+			p = ""
+		}
+		c.prefixes[p] = true
+	}
+	return c
+}
+
+// Exclude leaves nodes out of the output, as the enveloped-signature
+// transform does with the Signature element.
+func (c *Canonicalizer) Exclude(nodes ...*Node) *Canonicalizer {
+	if c.excluded == nil {
+		c.excluded = make(map[*Node]bool)
+	}
+	for _, n := range nodes {
+		c.excluded[n] = true
+	}
+	return c
+}
+
+func (c *Canonicalizer) Canonicalize(n *Node) []byte {
+	var buf bytes.Buffer
+	if n.Kind == DocumentNode {
+		c.document(&buf, n)
+	} else {
+		c.node(&buf, n, map[string]string{"": ""}, true)
+	}
+	return buf.Bytes()
+}
+
+// Canonicalize returns the inclusive canonical form (C14N 1.0 without
+// comments) of n.
+func Canonicalize(n *Node) []byte {
+	c, _ := NewCanonicalizer(C14N)
+	return c.Canonicalize(n)
+}
+
+// ExclusiveCanonicalize returns the exclusive canonical form without
+// comments of n.
+func ExclusiveCanonicalize(n *Node, inclusivePrefixes ...string) []byte {
+	c, _ := NewCanonicalizer(ExcC14N)
+	return c.InclusivePrefixes(inclusivePrefixes...).Canonicalize(n)
+}
+
+func (c *Canonicalizer) document(buf *bytes.Buffer, doc *Node) {
+	afterRoot := false
+	for _, child := range doc.Children {
+		switch child.Kind {
+		case ElementNode:
+			c.node(buf, child, map[string]string{"": ""}, true)
+			afterRoot = true
+		case CommentNode:
+			if !c.comments || c.excluded[child] {
+				continue
+			}
+			if afterRoot {
+				buf.WriteByte('\n')
+			}
+			buf.WriteString("<!--" + child.Data + "-->")
+			if !afterRoot {
+				buf.WriteByte('\n')
+			}
+		}
+	}
+}
+
+func (c *Canonicalizer) node(buf *bytes.Buffer, n *Node, rendered map[string]string, apex bool) {
+	if c.excluded[n] {
+		return
+	}
+	switch n.Kind {
+	case TextNode:
+		buf.WriteString(c14nTextEscaper.Replace(n.Data))
+		return
+	case CommentNode:
+		if c.comments {
+			buf.WriteString("<!--" + n.Data + "-->")
+		}
+		return
+	}
+
+	declared := c.namespaces(n, rendered)
+	if len(declared) > 0 {
+		scope := make(map[string]string, len(rendered)+len(declared))
+		for p, uri := range rendered {
+			scope[p] = uri
+		}
+		for _, a := range declared {
+			scope[a.Name] = a.Value
+		}
+		rendered = scope
+	}
+
+	buf.WriteString("<" + n.QName())
+	for _, a := range declared {
+		if a.Name == "" {
+			buf.WriteString(" xmlns=\"" + c14nAttrEscaper.Replace(a.Value) + "\"")
+		} else {
+			buf.WriteString(" xmlns:" + a.Name + "=\"" + c14nAttrEscaper.Replace(a.Value) + "\"")
+		}
+	}
+	for _, a := range c.attributes(n, apex) {
+		buf.WriteString(" " + a.QName() + "=\"" + c14nAttrEscaper.Replace(a.Value) + "\"")
+	}
+	buf.WriteByte('>')
+	for _, child := range n.Children {
+		c.node(buf, child, rendered, false)
+	}
+	buf.WriteString("</" + n.QName() + ">")
+}
+
+// namespaces returns the declarations to render on n, as Attrs named by
+// their prefix ("" for the default namespace), sorted by prefix.
+func (c *Canonicalizer) namespaces(n *Node, rendered map[string]string) []Attr {
+	inScope := make(map[string]string)
+	for e := n; e != nil; e = e.Parent {
+		for _, a := range e.Attrs {
+			prefix, ok := declaredPrefix(a)
+			if !ok || prefix == "xml" {
+				continue
+			}
+			if _, seen := inScope[prefix]; !seen {
+				inScope[prefix] = a.Value
+			}
+		}
+	}
+
+	candidates := make(map[string]bool)
+	if c.exclusive {
+		candidates[n.Prefix] = true
+		for _, a := range n.Attrs {
+			if a.Prefix != "" && a.Prefix != "xml" && !a.isNamespace() {
+				candidates[a.Prefix] = true
+			}
+		}
+		for p := range c.prefixes {
+			if _, ok := inScope[p]; ok {
+				candidates[p] = true
+			}
+		}
+	} else {
+		for p := range inScope {
+			candidates[p] = true
+		}
+	}
+
+	var out []Attr
+	for p := range candidates {
+		uri := inScope[p]
+		if have, ok := rendered[p]; ok && have == uri {
+			continue
+		}
+		if _, ok := rendered[p]; !ok && uri == "" {
+			continue
+		}
+		out = append(out, Attr{Name: p, Value: uri})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+func declaredPrefix(a Attr) (string, bool) {
+	switch {
+	case a.Prefix == "xmlns":
+		return a.Name, true
+	case a.Prefix == "" && a.Name == "xmlns":
+		return "", true
+	}
+	return "", false
+}
+
+// attributes returns the attributes of n sorted by namespace URI and
+// local name. The apex of an inclusive canonicalization also carries the
+// xml: attributes of its ancestors.
+func (c *Canonicalizer) attributes(n *Node, apex bool) []Attr {
+	type keyed struct {
+		Attr
+		uri string
+	}
+	var attrs []keyed
+	has := make(map[string]bool)
+	for _, a := range n.Attrs {
+		if a.isNamespace() {
+			continue
+		}
+		uri := ""
+		if a.Prefix != "" {
+			uri = n.LookupNamespace(a.Prefix)
+		}
+		attrs = append(attrs, keyed{a, uri})
+		has[a.QName()] = true
+	}
+	if apex && !c.exclusive {
+		for e := n.Parent; e != nil; e = e.Parent {
+			for _, a := range e.Attrs {
+				if a.Prefix == "xml" && !has[a.QName()] {
+					attrs = append(attrs, keyed{a, xmlNamespace})
+					has[a.QName()] = true
+				}
+			}
+		}
+	}
+
+	sort.Slice(attrs, func(i, j int) bool {
+		if attrs[i].uri != attrs[j].uri {
+			return attrs[i].uri < attrs[j].uri
+		}
+		return attrs[i].Name < attrs[j].Name
+	})
+	out := make([]Attr, len(attrs))
+	for i, a := range attrs {
+		out[i] = a.Attr
+	}
+	return out
+}
@@ -0,0 +1,209 @@
+package xmlutil
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+
+type Kind int
+
+const (
+	DocumentNode Kind = iota
+	ElementNode
+	TextNode
+	CommentNode
+)
+
+// Attr is an attribute as written, with its prefix. Namespace
+// declarations are attributes with the prefix "xmlns", or the name
+// "xmlns" for the default namespace.
+type Attr struct {
+	Prefix string
+	Name   string
+	Value  string
+}
+
+func (a Attr) QName() string {
+	if a.Prefix == "" {
+		return a.Name
+	}
+	return a.Prefix + ":" + a.Name
+}
+
+func (a Attr) isNamespace() bool {
+	return a.Prefix == "xmlns" || (a.Prefix == "" && a.Name == "xmlns")
+}
+
+// Node is a document, element, text or comment. Data holds the content
+// of text and comment nodes.
+type Node struct {
+	Kind     Kind
+	Prefix   string
+	Name     string
+	Attrs    []Attr
+	Children []*Node
+	Data     string
+	Parent   *Node
+}
+
+// Parse reads a whole document. The XML declaration, processing
+// instructions and DOCTYPE are dropped.
+func Parse(r io.Reader) (*Node, error) {
+	d := xml.NewDecoder(r)
+	doc := &Node{Kind: DocumentNode}
+	current := doc
+	for {
+		tok, err := d.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "xml.Decoder.RawToken")
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &Node{Kind: ElementNode, Prefix: t.Name.Space, Name: t.Name.Local, Parent: current}
+			for _, a := range t.Attr {
+				n.Attrs = append(n.Attrs, Attr{Prefix: a.Name.Space, Name: a.Name.Local, Value: a.Value})
+			}
+			current.Children = append(current.Children, n)
+			current = n
+		case xml.EndElement:
+			if current.Kind != ElementNode || current.Prefix != t.Name.Space || current.Name != t.Name.Local {
+				return nil, errors.Errorf("xmlutil: unexpected end element </%s>", qname(t.Name.Space, t.Name.Local))
+			}
+			current = current.Parent
+		case xml.CharData:
+			if current == doc {
+				continue
+			}
+			current.Children = append(current.Children, &Node{Kind: TextNode, Data: string(t), Parent: current})
+		case xml.Comment:
+			current.Children = append(current.Children, &Node{Kind: CommentNode, Data: string(t), Parent: current})
+		}
+	}
+	if current != doc {
+		return nil, errors.Errorf("xmlutil: unclosed element <%s>", current.QName())
+	}
+	if doc.Root() == nil {
+		return nil, errors.New("xmlutil: no root element")
+	}
+	return doc, nil
+}
+
+func ParseBytes(data []byte) (*Node, error) {
+	return Parse(bytes.NewReader(data))
+}
+
+func qname(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + ":" + name
+}
+
+func (n *Node) QName() string {
+	return qname(n.Prefix, n.Name)
+}
+
+// Root returns the document element of a document node, or n itself for
+// other nodes.
+func (n *Node) Root() *Node {
+	if n.Kind != DocumentNode {
+		return n
+	}
+	for _, c := range n.Children {
+		if c.Kind == ElementNode {
+			return c
+		}
+	}
+	return nil
+}
+
+// Elements returns the child elements of n.
+func (n *Node) Elements() []*Node {
+	var out []*Node
+	for _, c := range n.Children {
+		if c.Kind == ElementNode {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// Attr returns the value of an attribute by qualified or local name.
+func (n *Node) Attr(name string) (string, bool) {
+	for _, a := range n.Attrs {
+		if !a.isNamespace() && (a.QName() == name || a.Name == name) {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// Text returns the concatenated text of n and its descendants.
+func (n *Node) Text() string {
+	if n.Kind == TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	var walk func(*Node)
+	walk = func(n *Node) {
+		for _, c := range n.Children {
+			switch c.Kind {
+			case TextNode:
+				b.WriteString(c.Data)
+			case ElementNode:
+				walk(c)
+			}
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+// Namespace returns the namespace URI of the element.
+func (n *Node) Namespace() string {
+	return n.LookupNamespace(n.Prefix)
+}
+
+// LookupNamespace resolves prefix, or the default namespace for "", in
+// the scope of n.
+func (n *Node) LookupNamespace(prefix string) string {
+	if prefix == "xml" {
+		return xmlNamespace
+	}
+	for e := n; e != nil; e = e.Parent {
+		for _, a := range e.Attrs {
+			if (prefix == "" && a.Prefix == "" && a.Name == "xmlns") || (a.Prefix == "xmlns" && a.Name == prefix) {
+				return a.Value
+			}
+		}
+	}
+	return ""
+}
+
+// ByID returns the first element whose Id, ID or id attribute is id, as
+// referenced by signatures.
+func (n *Node) ByID(id string) *Node {
+	if n.Kind == ElementNode {
+		for _, a := range n.Attrs {
+			if a.Prefix == "" && (a.Name == "Id" || a.Name == "ID" || a.Name == "id") && a.Value == id {
+				return n
+			}
+		}
+	}
+	for _, c := range n.Children {
+		if found := c.ByID(id); found != nil {
+			return found
+		}
+	}
+	return nil
+}
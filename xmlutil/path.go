@@ -0,0 +1,278 @@
+package xmlutil
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Paths are a small subset of XPath, relative to the node they are
+// applied to (a document's first step matches its root element):
+//
+//	envelope/body/item        child elements by local or prefixed name
+//	envelope/*/item           any element
+//	envelope//item            item at any depth below envelope
+//	//item                    item anywhere
+//	items/item[2]             the second item of each items (1-based)
+//	items/item[@id]           items with an id attribute
+//	items/item[@id='a1']      items whose id is a1
+//	envelope/body/item@id     the id attribute of the items
+//
+// Names without a prefix match any namespace.
+
+type predicate struct {
+	index int
+	attr  string
+	value *string
+}
+
+type step struct {
+	descendant bool
+	name       string
+	predicates []predicate
+}
+
+type compiled struct {
+	steps []step
+	attr  string
+}
+
+var paths sync.Map // string -> *compiled
+
+func compile(path string) (*compiled, error) {
+	if c, ok := paths.Load(path); ok {
+		return c.(*compiled), nil
+	}
+
+	c := &compiled{}
+	rest := strings.TrimPrefix(path, "/")
+	descendant := strings.HasPrefix(path, "//")
+	if descendant {
+		rest = strings.TrimPrefix(rest, "/")
+	}
+	for rest != "" {
+		var part string
+		part, rest = splitStep(rest)
+		if part == "" {
+			if descendant {
+				return nil, errors.Errorf("xmlutil: invalid path %q", path)
+			}
+			descendant = true
+			continue
+		}
+		s, attr, err := parseStep(part)
+		if err != nil {
+			return nil, errors.Wrapf(err, "xmlutil: invalid path %q", path)
+		}
+		if attr != "" {
+			if rest != "" {
+				return nil, errors.Errorf("xmlutil: invalid path %q: attribute before the last step", path)
+			}
+			c.attr = attr
+		}
+		if s.name != "" {
+			s.descendant = descendant
+			c.steps = append(c.steps, s)
+		}
+		descendant = false
+	}
+	if len(c.steps) == 0 && c.attr == "" {
+		return nil, errors.Errorf("xmlutil: empty path %q", path)
+	}
+
+	paths.Store(path, c)
+	return c, nil
+}
+
+// splitStep splits at the first '/' outside a predicate.
+func splitStep(path string) (string, string) {
+	depth := 0
+	for i, r := range path {
+		switch r {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '/':
+			if depth == 0 {
+				return path[:i], path[i+1:]
+			}
+		}
+	}
+	return path, ""
+}
+
+func parseStep(part string) (step, string, error) {
+	var s step
+	name := part
+	if i := strings.IndexByte(part, '['); i >= 0 {
+		name = part[:i]
+		preds := part[i:]
+		for preds != "" && preds[0] == '[' {
+			end := strings.IndexByte(preds, ']')
+			if end < 0 {
+				return s, "", errors.New("unclosed predicate")
+			}
+			p, err := parsePredicate(preds[1:end])
+			if err != nil {
+				return s, "", err
+			}
+			s.predicates = append(s.predicates, p)
+			preds = preds[end+1:]
+		}
+		part = name + preds
+	}
+
+	attr := ""
+	if i := strings.IndexByte(part, '@'); i >= 0 {
+		name, attr = part[:i], part[i+1:]
+		if attr == "" {
+			return s, "", errors.New("empty attribute name")
+		}
+	} else if part != name {
+		return s, "", errors.Errorf("unexpected %q", part[len(name):])
+	}
+	if name == "" && len(s.predicates) > 0 {
+		return s, "", errors.New("predicate without element")
+	}
+	s.name = name
+	return s, attr, nil
+}
+
+func parsePredicate(p string) (predicate, error) {
+	p = strings.TrimSpace(p)
+	if !strings.HasPrefix(p, "@") {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 1 {
+			return predicate{}, errors.Errorf("unsupported predicate [%s]", p)
+		}
+		return predicate{index: n}, nil
+	}
+
+	attr, value, ok := strings.Cut(p[1:], "=")
+	pred := predicate{attr: strings.TrimSpace(attr)}
+	if ok {
+		value = strings.TrimSpace(value)
+		if len(value) < 2 || (value[0] != '\'' && value[0] != '"') || value[len(value)-1] != value[0] {
+			return predicate{}, errors.Errorf("unquoted value in [%s]", p)
+		}
+		value = value[1 : len(value)-1]
+		pred.value = &value
+	}
+	return pred, nil
+}
+
+func (s step) matches(n *Node) bool {
+	if n.Kind != ElementNode {
+		return false
+	}
+	switch {
+	case s.name == "*":
+		return true
+	case strings.Contains(s.name, ":"):
+		return n.QName() == s.name
+	default:
+		return n.Name == s.name
+	}
+}
+
+func (s step) apply(parent *Node) []*Node {
+	var matched []*Node
+	var walk func(*Node)
+	walk = func(n *Node) {
+		for _, c := range n.Children {
+			if s.matches(c) {
+				matched = append(matched, c)
+			}
+			if s.descendant && c.Kind == ElementNode {
+				walk(c)
+			}
+		}
+	}
+	walk(parent)
+
+	for _, p := range s.predicates {
+		if p.index > 0 {
+			if p.index > len(matched) {
+				return nil
+			}
+			matched = matched[p.index-1 : p.index]
+			continue
+		}
+		kept := matched[:0]
+		for _, n := range matched {
+			if v, ok := n.Attr(p.attr); ok && (p.value == nil || v == *p.value) {
+				kept = append(kept, n)
+			}
+		}
+		matched = kept
+	}
+	return matched
+}
+
+// Select returns the elements matching path, in document order. A path
+// ending in @attr selects the elements having that attribute.
+func Select(n *Node, path string) ([]*Node, error) {
+	c, err := compile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := []*Node{n}
+	for _, s := range c.steps {
+		var next []*Node
+		seen := make(map[*Node]bool)
+		for _, parent := range nodes {
+			for _, m := range s.apply(parent) {
+				if !seen[m] {
+					seen[m] = true
+					next = append(next, m)
+				}
+			}
+		}
+		nodes = next
+	}
+
+	if c.attr == "" {
+		return nodes, nil
+	}
+	out := nodes[:0]
+	for _, e := range nodes {
+		if _, ok := e.Attr(c.attr); ok {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// Find returns the values selected by path: attribute values for a path
+// ending in @attr, the text of the elements otherwise.
+func Find(n *Node, path string) ([]string, error) {
+	nodes, err := Select(n, path)
+	if err != nil {
+		return nil, err
+	}
+	c, _ := compile(path)
+
+	values := make([]string, len(nodes))
+	for i, e := range nodes {
+		if c.attr != "" {
+			values[i], _ = e.Attr(c.attr)
+		} else {
+			values[i] = e.Text()
+		}
+	}
+	return values, nil
+}
+
+// First returns the first value selected by path, or an empty string
+// when nothing matches or the path is invalid.
+func First(n *Node, path string) string {
+	values, err := Find(n, path)
+	if err != nil || len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
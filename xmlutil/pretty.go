@@ -0,0 +1,145 @@
+package xmlutil
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	attrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", "\"", "&quot;",
+		"\t", "&#x9;", "\n", "&#xA;", "\r", "&#xD;")
+)
+
+// Write serializes n as it was read, with attributes and prefixes in
+// their original order. Elements without children are self-closed.
+func Write(w io.Writer, n *Node) error {
+	return Indent(w, n, "", "")
+}
+
+// Indent serializes n with every element on its own line, nested by
+// indent after prefix. Whitespace-only text between elements is
+// replaced by the indentation; elements with mixed content are written
+// as they are so their text does not change. An empty indent writes n
+// unchanged, like Write.
+func Indent(w io.Writer, n *Node, prefix, indent string) error {
+	bw := bufio.NewWriter(w)
+	p := printer{w: bw, prefix: prefix, indent: indent}
+	p.node(n, 0)
+	return errors.Wrap(bw.Flush(), "bufio.Writer.Flush")
+}
+
+// Pretty parses data and indents it, keeping the XML declaration when
+// the input has one.
+func Pretty(data []byte, indent string) ([]byte, error) {
+	doc, err := ParseBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("<?xml")) {
+		buf.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	}
+	if err := Indent(&buf, doc, "", indent); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type printer struct {
+	w      *bufio.Writer
+	prefix string
+	indent string
+}
+
+func (p *printer) pretty() bool {
+	return p.indent != "" || p.prefix != ""
+}
+
+func (p *printer) newline(depth int) {
+	if !p.pretty() {
+		return
+	}
+	p.w.WriteByte('\n')
+	p.w.WriteString(p.prefix)
+	for i := 0; i < depth; i++ {
+		p.w.WriteString(p.indent)
+	}
+}
+
+func (p *printer) node(n *Node, depth int) {
+	switch n.Kind {
+	case DocumentNode:
+		first := true
+		for _, c := range n.Children {
+			if c.Kind == TextNode {
+				continue
+			}
+			if !first {
+				p.newline(0)
+			} else if p.prefix != "" {
+				p.w.WriteString(p.prefix)
+			}
+			first = false
+			p.node(c, 0)
+		}
+		if p.pretty() {
+			p.w.WriteByte('\n')
+		}
+	case TextNode:
+		p.w.WriteString(textEscaper.Replace(n.Data))
+	case CommentNode:
+		p.w.WriteString("<!--" + n.Data + "-->")
+	case ElementNode:
+		p.element(n, depth)
+	}
+}
+
+func (p *printer) element(n *Node, depth int) {
+	p.w.WriteString("<" + n.QName())
+	for _, a := range n.Attrs {
+		p.w.WriteString(" " + a.QName() + "=\"" + attrEscaper.Replace(a.Value) + "\"")
+	}
+	if len(n.Children) == 0 {
+		p.w.WriteString("/>")
+		return
+	}
+	p.w.WriteByte('>')
+
+	if !p.pretty() || !structural(n) {
+		for _, c := range n.Children {
+			p.node(c, depth+1)
+		}
+	} else {
+		for _, c := range n.Children {
+			if c.Kind == TextNode {
+				continue
+			}
+			p.newline(depth + 1)
+			p.node(c, depth+1)
+		}
+		p.newline(depth)
+	}
+	p.w.WriteString("</" + n.QName() + ">")
+}
+
+// structural reports whether n has child elements or comments and only
+// whitespace text, so it can be re-indented.
+func structural(n *Node) bool {
+	hasMarkup := false
+	for _, c := range n.Children {
+		switch c.Kind {
+		case TextNode:
+			if strings.TrimSpace(c.Data) != "" {
+				return false
+			}
+		default:
+			hasMarkup = true
+		}
+	}
+	return hasMarkup
+}